@@ -0,0 +1,32 @@
+package block_palette
+
+// Result 是校验单个方块的结果
+type Result struct {
+	// Name 是升级后的方块名称
+	Name string
+	// States 是与原始方块状态最接近的合法方块状态
+	States map[string]any
+	// Upgrades 描述了将旧版本的方块升级到当前版本时作出的改动，
+	// 例如方块被重命名或旧的方块状态被转换为新的方块状态
+	Upgrades []string
+	// Defaults 描述了原始方块缺少而被补全为默认值的每一个方块状态
+	Defaults []string
+	// Corrections 描述了为得到合法的方块而作出的每一处修正，
+	// 例如不合法或超出范围的值、类型不正确的值和不存在的方块状态。
+	// 升级和补全默认值不被视为修正，因此不会出现在 Corrections 中。
+	// 如果原始方块本身就是合法的，则 Corrections 为空
+	Corrections []string
+}
+
+// Valid 指示原始方块是否无需任何修正就是合法的。
+// 旧版本的方块和缺少部分方块状态的方块仍然被视为合法的
+func (r Result) Valid() bool {
+	return len(r.Corrections) == 0
+}
+
+// customBlock 是租赁服注册的自定义方块
+type customBlock struct {
+	// properties 是每个方块状态所有可能的值，
+	// 其中的第一个值被视为该方块状态的默认值
+	properties map[string][]any
+}
@@ -0,0 +1,182 @@
+package block_palette
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+
+	"github.com/TriM-Organization/bedrock-world-operator/block"
+	"github.com/df-mc/worldupgrader/blockupgrader"
+)
+
+// Palette 是用于校验方块的方块调色板。
+//
+// 原版方块的方块状态以内置的方块调色板为准，
+// 而方块是否可以被放置则以租赁服的方块注册表为准；
+// 自定义方块则完全以租赁服在登录序列发送的数据为准
+type Palette struct {
+	nameChecker  func(name string) bool
+	customBlocks map[string]customBlock
+}
+
+// NewPalette 创建并返回一个新的方块调色板。
+//
+// nameChecker 是一个可选的函数，用于检查 name 所指示的方块是否存在于
+// 租赁服的方块注册表 (例如可通过命令放置的方块)。如果不存在，则返回假。
+// 如果没有这样的 nameChecker 函数，则可以将其简单的置为 nil。
+//
+// customBlocks 是租赁服注册的自定义方块，它通常来自 minecraft.GameData
+func NewPalette(nameChecker func(name string) bool, customBlocks []protocol.BlockEntry) *Palette {
+	p := &Palette{
+		nameChecker:  nameChecker,
+		customBlocks: make(map[string]customBlock),
+	}
+	for _, entry := range customBlocks {
+		p.customBlocks[strings.ToLower(entry.Name)] = parseCustomBlock(entry)
+	}
+	return p
+}
+
+// parseCustomBlock 从自定义方块的注册数据 entry 解析其所有方块状态的可能值
+func parseCustomBlock(entry protocol.BlockEntry) customBlock {
+	result := customBlock{properties: make(map[string][]any)}
+
+	properties, _ := entry.Properties["properties"].([]any)
+	for _, value := range properties {
+		property, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := property["name"].(string)
+		enum, _ := property["enum"].([]any)
+		if len(name) == 0 || len(enum) == 0 {
+			continue
+		}
+		result.properties[name] = enum
+	}
+
+	return result
+}
+
+// Validate 校验名称为 blockName 且方块状态为 blockStates 的方块。
+//
+// 原版方块将首先被升级到当前版本，然后其方块状态将被规范化，
+// 并修正为与原始方块状态最接近的合法方块状态。
+// 升级、补全默认值和修正分别被记录在 Result 的不同字段中。
+// 如果方块不存在于方块调色板或租赁服的方块注册表，
+// 则无法给出任何建议，此时返回错误。
+//
+// Validate 不会修改 blockStates
+func (p *Palette) Validate(blockName string, blockStates map[string]any) (result Result, err error) {
+	name := strings.ToLower(blockName)
+	if !strings.Contains(name, ":") {
+		name = "minecraft:" + name
+	}
+
+	if custom, ok := p.customBlocks[name]; ok {
+		return custom.validate(name, blockStates), nil
+	}
+
+	original := utils.DeepCopyNBT(blockStates)
+	upgraded := blockupgrader.Upgrade(blockupgrader.BlockState{
+		Name:       name,
+		Properties: utils.DeepCopyNBT(blockStates),
+	})
+	result.Name = upgraded.Name
+	if upgraded.Name != name || !reflect.DeepEqual(upgraded.Properties, original) {
+		result.Upgrades = append(result.Upgrades, fmt.Sprintf(
+			"方块 %s %s 已被升级为 %s %s",
+			name, utils.MarshalBlockStates(original),
+			upgraded.Name, utils.MarshalBlockStates(upgraded.Properties),
+		))
+	}
+
+	if p.nameChecker != nil && !p.nameChecker(result.Name) {
+		return Result{}, fmt.Errorf("Validate: Block %#v is not registered on the server", result.Name)
+	}
+	rid, found := block.StateToRuntimeID(result.Name, map[string]any{})
+	if !found {
+		return Result{}, fmt.Errorf("Validate: Block %#v is not found in the block palette", result.Name)
+	}
+	_, defaults, found := block.RuntimeIDToState(rid)
+	if !found {
+		panic("Validate: Should never happened")
+	}
+
+	normalized, defaulted, corrections := normalizeStates(defaults, upgraded.Properties)
+	result.Defaults = defaulted
+	result.Corrections = append(result.Corrections, corrections...)
+	if statesValid(result.Name, normalized) {
+		result.States = normalized
+		return result, nil
+	}
+
+	result.States, corrections = nearestStates(result.Name, defaults, normalized)
+	result.Corrections = append(result.Corrections, corrections...)
+	return result, nil
+}
+
+// validate 校验名称为 name 且方块状态为 states 的自定义方块
+func (c customBlock) validate(name string, states map[string]any) (result Result) {
+	result = Result{
+		Name:   name,
+		States: make(map[string]any),
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(c.properties)) {
+		enum := c.properties[key]
+
+		value, ok := states[key]
+		if !ok {
+			result.States[key] = enum[0]
+			result.Defaults = append(result.Defaults, fmt.Sprintf("缺少方块状态 %s，已使用默认值 %v", key, enum[0]))
+			continue
+		}
+
+		converted, ok := convertValue(enum[0], value)
+		if ok && slices.Contains(enum, converted) {
+			result.States[key] = converted
+			continue
+		}
+
+		result.States[key] = nearestEnumValue(enum, value)
+		result.Corrections = append(result.Corrections, fmt.Sprintf("方块状态 %s 的值 %v 不合法，已被修正为 %v", key, value, result.States[key]))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(states)) {
+		if _, ok := c.properties[key]; !ok {
+			result.Corrections = append(result.Corrections, fmt.Sprintf("方块状态 %s 不存在，已被移除", key))
+		}
+	}
+
+	return
+}
+
+// nearestEnumValue 返回 enum 中与 value 最接近的值。
+// 如果 value 和 enum 中的值都是整数，则返回数值上最接近的值；
+// 否则返回 enum 中的第一个值 (即默认值)
+func nearestEnumValue(enum []any, value any) any {
+	target, ok := integerValue(value)
+	if !ok {
+		return enum[0]
+	}
+
+	result := enum[0]
+	bestDistance := int64(-1)
+	for _, candidate := range enum {
+		val, ok := integerValue(candidate)
+		if !ok {
+			continue
+		}
+		distance := max(val-target, target-val)
+		if bestDistance == -1 || distance < bestDistance {
+			result, bestDistance = candidate, distance
+		}
+	}
+	return result
+}
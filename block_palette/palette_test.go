@@ -0,0 +1,49 @@
+package block_palette
+
+import "testing"
+
+func TestValidateUpgradeAndDefaults(t *testing.T) {
+	palette := NewPalette(nil, nil)
+
+	result, err := palette.Validate("wool", map[string]any{"color": "red"})
+	if err != nil {
+		t.Fatalf("TestValidateUpgradeAndDefaults: %v", err)
+	}
+	if result.Name != "minecraft:red_wool" || len(result.Upgrades) == 0 {
+		t.Errorf("TestValidateUpgradeAndDefaults: Unexpected upgrade result %#v", result)
+	}
+	if !result.Valid() {
+		t.Errorf("TestValidateUpgradeAndDefaults: Upgraded block should be valid, but got %#v", result.Corrections)
+	}
+
+	result, err = palette.Validate("minecraft:oak_log", map[string]any{})
+	if err != nil {
+		t.Fatalf("TestValidateUpgradeAndDefaults: %v", err)
+	}
+	if result.States["pillar_axis"] != "y" || len(result.Defaults) != 1 {
+		t.Errorf("TestValidateUpgradeAndDefaults: Unexpected defaults result %#v", result)
+	}
+	if !result.Valid() {
+		t.Errorf("TestValidateUpgradeAndDefaults: Block with missing states should be valid, but got %#v", result.Corrections)
+	}
+}
+
+func TestValidateCorrections(t *testing.T) {
+	palette := NewPalette(nil, nil)
+
+	result, err := palette.Validate("minecraft:wheat", map[string]any{"growth": int32(9)})
+	if err != nil {
+		t.Fatalf("TestValidateCorrections: %v", err)
+	}
+	if result.Valid() || result.States["growth"] != int32(7) {
+		t.Errorf("TestValidateCorrections: Unexpected result %#v", result)
+	}
+
+	result, err = palette.Validate("minecraft:oak_log", map[string]any{"pillar_axis": "x", "not_a_state": byte(1)})
+	if err != nil {
+		t.Fatalf("TestValidateCorrections: %v", err)
+	}
+	if result.Valid() || len(result.Defaults) != 0 {
+		t.Errorf("TestValidateCorrections: Unexpected result %#v", result)
+	}
+}
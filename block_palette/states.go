@@ -0,0 +1,188 @@
+package block_palette
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/TriM-Organization/bedrock-world-operator/block"
+)
+
+// maxIntDistance 是为整数类型的方块状态寻找
+// 最接近的合法值时所尝试的最大距离
+const maxIntDistance = 64
+
+// statesValid 检查 states 是否是方块 name 的合法方块状态
+func statesValid(name string, states map[string]any) bool {
+	rid, found := block.StateToRuntimeID(name, states)
+	if !found {
+		return false
+	}
+	_, actual, found := block.RuntimeIDToState(rid)
+	if !found {
+		return false
+	}
+	return reflect.DeepEqual(actual, states)
+}
+
+// convertValue 按照默认值 defaultValue 的类型转换方块状态的值 value。
+// 如果 value 无法被转换为该类型，则 ok 为假
+func convertValue(defaultValue any, value any) (result any, ok bool) {
+	switch defaultValue.(type) {
+	case byte:
+		switch val := value.(type) {
+		case bool:
+			if val {
+				return byte(1), true
+			}
+			return byte(0), true
+		case string:
+			switch val {
+			case "true", "1":
+				return byte(1), true
+			case "false", "0":
+				return byte(0), true
+			}
+			return nil, false
+		}
+		val, ok := integerValue(value)
+		if !ok || (val != 0 && val != 1) {
+			return nil, false
+		}
+		return byte(val), true
+	case int32:
+		if val, ok := value.(string); ok {
+			result, err := strconv.ParseInt(val, 10, 32)
+			if err != nil {
+				return nil, false
+			}
+			return int32(result), true
+		}
+		val, ok := integerValue(value)
+		if !ok {
+			return nil, false
+		}
+		return int32(val), true
+	case string:
+		val, ok := value.(string)
+		return val, ok
+	}
+	return nil, false
+}
+
+// integerValue 将整数类型的 value 转换为 int64
+func integerValue(value any) (result int64, ok bool) {
+	switch val := value.(type) {
+	case int8:
+		return int64(val), true
+	case uint8:
+		return int64(val), true
+	case int16:
+		return int64(val), true
+	case uint16:
+		return int64(val), true
+	case int32:
+		return int64(val), true
+	case uint32:
+		return int64(val), true
+	case int64:
+		return val, true
+	case uint64:
+		return int64(val), true
+	case int:
+		return int64(val), true
+	case uint:
+		return int64(val), true
+	}
+	return 0, false
+}
+
+// normalizeStates 以方块的默认方块状态 defaults 为准规范化 states。
+// 缺失的方块状态将使用默认值，多余的方块状态将被移除，
+// 类型不正确的方块状态将被转换为正确的类型 (或在无法转换时使用默认值)。
+// defaulted 描述了每一个被补全为默认值的方块状态，
+// 而 corrections 描述了规范化时作出的其他修正
+func normalizeStates(defaults map[string]any, states map[string]any) (result map[string]any, defaulted []string, corrections []string) {
+	result = make(map[string]any)
+
+	for _, key := range slices.Sorted(maps.Keys(defaults)) {
+		defaultValue := defaults[key]
+
+		value, ok := states[key]
+		if !ok {
+			result[key] = defaultValue
+			defaulted = append(defaulted, fmt.Sprintf("缺少方块状态 %s，已使用默认值 %v", key, defaultValue))
+			continue
+		}
+
+		converted, ok := convertValue(defaultValue, value)
+		if !ok {
+			result[key] = defaultValue
+			corrections = append(corrections, fmt.Sprintf("方块状态 %s 的值 %#v 的类型不正确，已使用默认值 %v", key, value, defaultValue))
+			continue
+		}
+		result[key] = converted
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(states)) {
+		if _, ok := defaults[key]; !ok {
+			corrections = append(corrections, fmt.Sprintf("方块状态 %s 不存在，已被移除", key))
+		}
+	}
+
+	return
+}
+
+// nearestStates 返回方块 name 与已规范化的方块状态 states 最接近的合法方块状态。
+// defaults 是该方块的默认方块状态，它总是合法的。
+//
+// nearestStates 从 defaults 出发，按照方块状态名称的顺序逐个应用 states 中的值。
+// 如果某个值会导致方块状态不合法，则对于整数类型的方块状态，将尝试与其最接近的合法值；
+// 否则，该方块状态将保持默认值。corrections 描述了每一个未能被保留的值
+func nearestStates(name string, defaults map[string]any, states map[string]any) (result map[string]any, corrections []string) {
+	result = maps.Clone(defaults)
+
+	for _, key := range slices.Sorted(maps.Keys(states)) {
+		value := states[key]
+		if value == defaults[key] {
+			continue
+		}
+
+		result[key] = value
+		if statesValid(name, result) {
+			continue
+		}
+
+		result[key] = defaults[key]
+		if intValue, ok := value.(int32); ok {
+			for distance := int32(1); distance <= maxIntDistance; distance++ {
+				if candidate := nearestIntCandidate(name, result, key, intValue, distance); candidate != nil {
+					result[key] = candidate
+					break
+				}
+			}
+		}
+		corrections = append(corrections, fmt.Sprintf("方块状态 %s 的值 %v 不合法，已被修正为 %v", key, value, result[key]))
+	}
+
+	return
+}
+
+// nearestIntCandidate 检查与 value 距离为 distance 的两个整数是否可以作为方块状态 key 的值。
+// 如果可以，则返回其中较小的一个；否则返回 nil。states 是其余方块状态，它不会被修改
+func nearestIntCandidate(name string, states map[string]any, key string, value int32, distance int32) any {
+	original := states[key]
+	defer func() {
+		states[key] = original
+	}()
+
+	for _, candidate := range []int32{value - distance, value + distance} {
+		states[key] = candidate
+		if statesValid(name, states) {
+			return candidate
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+)
+
+// LoginMockServer 通过 authenticator 登录到离线的模拟租赁服。
+// authenticator 通常由 mock_server.Server 的 Authenticator 方法得到。
+//
+// 模拟租赁服不会发出 MCP 检查挑战，
+// 因此登录后直到可用命令数据包为止的所有数据包都将被缓存，
+// 并可通过 CachedPacket 取得
+func LoginMockServer(authenticator minecraft.Authenticator) (client *Client, err error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancelFunc()
+
+	conn, err := openConnection(ctx, authenticator)
+	if err != nil {
+		return nil, fmt.Errorf("LoginMockServer: %v", err)
+	}
+
+	cachedPkt := make(chan packet.Packet, 32767)
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("LoginMockServer: %v", err)
+		}
+		cachedPkt <- pk
+		if _, ok := pk.(*packet.AvailableCommands); ok {
+			break
+		}
+	}
+	close(cachedPkt)
+
+	return &Client{connection: conn, cachedPacket: cachedPkt}, nil
+}
@@ -0,0 +1,82 @@
+package command_translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// legacyColors 是旧版方块数据值所对应的颜色
+var legacyColors = []string{
+	"white", "orange", "magenta", "light_blue",
+	"yellow", "lime", "pink", "gray",
+	"light_gray", "cyan", "purple", "blue",
+	"brown", "green", "red", "black",
+}
+
+// legacyColoredBlocks 是以数据值区分颜色的旧版方块，
+// 其值是拆分后的方块名称中颜色之后的部分
+var legacyColoredBlocks = map[string]string{
+	"wool":                  "wool",
+	"carpet":                "carpet",
+	"concrete":              "concrete",
+	"concrete_powder":       "concrete_powder",
+	"stained_glass":         "stained_glass",
+	"stained_glass_pane":    "stained_glass_pane",
+	"stained_hardened_clay": "terracotta",
+	"shulker_box":           "shulker_box",
+}
+
+// legacyVariantBlocks 是以数据值区分种类的旧版方块，
+// 其值是按照数据值排列的拆分后的方块名称
+var legacyVariantBlocks = map[string][]string{
+	"stone":         {"stone", "granite", "polished_granite", "diorite", "polished_diorite", "andesite", "polished_andesite"},
+	"dirt":          {"dirt", "coarse_dirt"},
+	"sand":          {"sand", "red_sand"},
+	"sandstone":     {"sandstone", "chiseled_sandstone", "cut_sandstone", "smooth_sandstone"},
+	"red_sandstone": {"red_sandstone", "chiseled_red_sandstone", "cut_red_sandstone", "smooth_red_sandstone"},
+	"stonebrick":    {"stone_bricks", "mossy_stone_bricks", "cracked_stone_bricks", "chiseled_stone_bricks"},
+	"prismarine":    {"prismarine", "dark_prismarine", "prismarine_bricks"},
+	"planks":        {"oak_planks", "spruce_planks", "birch_planks", "jungle_planks", "acacia_planks", "dark_oak_planks"},
+}
+
+// legacyLogs 是旧版原木 (log 和 log2) 的数据值的低 2 位所对应的木材
+var legacyLogs = map[string][]string{
+	"log":  {"oak", "spruce", "birch", "jungle"},
+	"log2": {"acacia", "dark_oak"},
+}
+
+// legacyPillarAxis 是原木的数据值的第 3 至 4 位所对应的 pillar_axis
+var legacyPillarAxis = []string{"y", "x", "z"}
+
+// legacyDetectBlock 将旧版 detect 子命令中名为 blockName 且数据值为
+// blockData 的方块转换为新版 if block 子命令所使用的方块名称和方块状态。
+// states 可能为空，此时 if block 子命令只需要方块名称。
+//
+// 数据值为 0 的方块在没有被特殊处理时将只保留名称，
+// 而其他无法被转换的数据值将导致返回错误
+func legacyDetectBlock(blockName string, blockData int) (name string, states string, err error) {
+	prefix := ""
+	baseName := strings.ToLower(blockName)
+	if strings.HasPrefix(baseName, "minecraft:") {
+		prefix, baseName = "minecraft:", strings.TrimPrefix(baseName, "minecraft:")
+	}
+
+	if suffix, ok := legacyColoredBlocks[baseName]; ok && blockData >= 0 && blockData < len(legacyColors) {
+		return prefix + legacyColors[blockData] + "_" + suffix, "", nil
+	}
+	if variants, ok := legacyVariantBlocks[baseName]; ok && blockData >= 0 && blockData < len(variants) {
+		return prefix + variants[blockData], "", nil
+	}
+	if woods, ok := legacyLogs[baseName]; ok && blockData >= 0 && blockData < 16 && blockData&3 < len(woods) {
+		wood := woods[blockData&3]
+		if blockData>>2 == 3 {
+			return prefix + wood + "_wood", `["pillar_axis"="y"]`, nil
+		}
+		return prefix + wood + "_log", fmt.Sprintf(`["pillar_axis"="%s"]`, legacyPillarAxis[blockData>>2]), nil
+	}
+
+	if blockData == 0 {
+		return blockName, "", nil
+	}
+	return "", "", fmt.Errorf("legacyDetectBlock: Can not convert block data %d of %s to block states", blockData, blockName)
+}
@@ -0,0 +1,172 @@
+package command_translator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// executeSubcommands 是新版 execute 命令的子命令。
+// 如果 execute 后的第一个参数不是它们中的任何一个，
+// 则该命令被视为 1.19.50 之前的旧版 execute 命令
+var executeSubcommands = map[string]bool{
+	"align":      true,
+	"anchored":   true,
+	"as":         true,
+	"at":         true,
+	"facing":     true,
+	"in":         true,
+	"positioned": true,
+	"rotated":    true,
+	"if":         true,
+	"unless":     true,
+	"run":        true,
+}
+
+// legacyUpgrader 保存单次升级的状态
+type legacyUpgrader struct {
+	changes []string
+}
+
+// UpgradeLegacyExecute 将命令 command 中 1.19.50 之前的旧版 execute 语法
+// (包括嵌套的和含有 detect 的) 升级为新版的 execute as/at/positioned/if block 语法。
+// changes 描述了升级期间所作出的每一处改动。
+//
+// detect 的方块数据值将被转换为拆分后的方块名称或方块状态，
+// 数据值为 0 的方块在没有被特殊处理时只保留其名称。
+//
+// 如果 command 不含旧版 execute 语法，则 result 与 command 相同，且 changes 为空。
+// 如果 command 含有不完整的旧版 execute 语法，或 detect 的数据值无法被转换，则返回错误
+func UpgradeLegacyExecute(command string) (result string, changes []string, err error) {
+	u := new(legacyUpgrader)
+
+	trimmed := strings.TrimSpace(command)
+	hasSlash := strings.HasPrefix(trimmed, "/")
+	result, err = u.upgrade(strings.TrimPrefix(trimmed, "/"))
+	if err != nil {
+		return "", nil, fmt.Errorf("UpgradeLegacyExecute: %v", err)
+	}
+
+	if len(u.changes) == 0 {
+		return command, nil, nil
+	}
+	if hasSlash {
+		result = "/" + result
+	}
+	return result, u.changes, nil
+}
+
+// upgrade 升级一条不含前导斜杠的命令
+func (u *legacyUpgrader) upgrade(command string) (result string, err error) {
+	args := splitArgs(command)
+	if len(args) < 2 || !strings.EqualFold(args[0], "execute") {
+		return command, nil
+	}
+
+	if executeSubcommands[strings.ToLower(args[1])] {
+		return u.modern(command, args)
+	}
+	return u.legacy(args[1:])
+}
+
+// modern 升级新版 execute 命令 command 中 run 之后的命令。
+// args 是 command 按参数分割后的结果
+func (u *legacyUpgrader) modern(command string, args []string) (result string, err error) {
+	for index, arg := range args {
+		if arg != "run" || index == len(args)-1 {
+			continue
+		}
+
+		changeCount := len(u.changes)
+		inner, err := u.upgrade(strings.Join(args[index+1:], " "))
+		if err != nil {
+			return "", fmt.Errorf("modern: %v", err)
+		}
+		if len(u.changes) == changeCount {
+			return command, nil
+		}
+		return joinExecute(args[:index], inner), nil
+	}
+	return command, nil
+}
+
+// legacy 升级旧版 execute 命令。
+// args 是 execute 之后的所有参数
+func (u *legacyUpgrader) legacy(args []string) (result string, err error) {
+	if len(args) < 5 {
+		return "", fmt.Errorf("legacy: Incomplete legacy execute command %#v", "execute "+strings.Join(args, " "))
+	}
+
+	target := args[0]
+	position := args[1:4]
+	args = args[4:]
+
+	subcommands := []string{"execute", "as", target, "at", "@s"}
+	if strings.Join(position, " ") != "~ ~ ~" {
+		subcommands = append(subcommands, "positioned")
+		subcommands = append(subcommands, position...)
+	}
+	u.changes = append(u.changes, fmt.Sprintf(
+		"旧版 execute %s %s 已被转换为 %s",
+		target, strings.Join(position, " "), strings.Join(subcommands[1:], " "),
+	))
+
+	if strings.EqualFold(args[0], "detect") {
+		if len(args) < 7 {
+			return "", fmt.Errorf("legacy: Incomplete detect clause %#v", strings.Join(args, " "))
+		}
+
+		detectPos := args[1:4]
+		blockName := args[4]
+		blockData, err := strconv.Atoi(args[5])
+		if err != nil {
+			return "", fmt.Errorf("legacy: Invalid block data %#v in detect clause; err = %v", args[5], err)
+		}
+
+		condition := append([]string{"if", "block"}, detectPos...)
+		switch blockData {
+		case -1:
+			condition = append(condition, blockName)
+		default:
+			// 新版 if block 子命令不接受数据值，
+			// 因此需要将其转换为方块状态
+			name, states, err := legacyDetectBlock(blockName, blockData)
+			if err != nil {
+				return "", fmt.Errorf("legacy: %v", err)
+			}
+			condition = append(condition, name)
+			if len(states) > 0 {
+				condition = append(condition, states)
+			}
+		}
+		subcommands = append(subcommands, condition...)
+		u.changes = append(u.changes, fmt.Sprintf(
+			"旧版 detect %s %s %s 已被转换为 %s",
+			strings.Join(detectPos, " "), blockName, args[5], strings.Join(condition, " "),
+		))
+
+		args = args[6:]
+	}
+
+	inner, err := u.upgrade(strings.Join(args, " "))
+	if err != nil {
+		return "", fmt.Errorf("legacy: %v", err)
+	}
+	return joinExecute(subcommands[1:], inner), nil
+}
+
+// joinExecute 将 execute 子命令 subcommands 与需要运行的命令 command 连接。
+// 如果 command 本身也是新版 execute 命令，则它的子命令将被直接合并，
+// 而不会产生嵌套的 run execute。
+// subcommands 可以以 execute 开头，也可以不包含它
+func joinExecute(subcommands []string, command string) string {
+	if len(subcommands) > 0 && strings.EqualFold(subcommands[0], "execute") {
+		subcommands = subcommands[1:]
+	}
+
+	args := splitArgs(command)
+	if len(args) > 1 && strings.EqualFold(args[0], "execute") && executeSubcommands[strings.ToLower(args[1])] {
+		return "execute " + strings.Join(append(subcommands, args[1:]...), " ")
+	}
+	return "execute " + strings.Join(subcommands, " ") + " run " + command
+}
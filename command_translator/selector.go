@@ -0,0 +1,145 @@
+package command_translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rangeArguments 描述了 Java 版中使用范围表示的选择器参数
+// 到国际版中的最小值参数与最大值参数的映射
+var rangeArguments = map[string][2]string{
+	"distance":   {"rm", "r"},
+	"level":      {"lm", "l"},
+	"x_rotation": {"rxm", "rx"},
+	"y_rotation": {"rym", "ry"},
+}
+
+// renamedArguments 描述了 Java 版与国际版中名称不同的选择器参数
+var renamedArguments = map[string]string{
+	"limit":    "c",
+	"gamemode": "m",
+}
+
+// unsupportedArguments 描述了国际版不支持的 Java 版选择器参数
+var unsupportedArguments = map[string]bool{
+	"team":         true,
+	"nbt":          true,
+	"predicate":    true,
+	"advancements": true,
+}
+
+// isSelector 检查参数 arg 是否是目标选择器
+func isSelector(arg string) bool {
+	return len(arg) >= 2 && arg[0] == '@' && strings.ContainsRune("pareasn", rune(arg[1]))
+}
+
+// translateSelector 将 Java 版目标选择器 selector 转换为国际版的格式。
+// 如果 selector 不是目标选择器，则它将被原样返回
+func translateSelector(selector string) (result string, err error) {
+	if !isSelector(selector) {
+		return selector, nil
+	}
+
+	variable := selector[:2]
+	rawArgs := strings.TrimSpace(selector[2:])
+	if len(rawArgs) > 0 {
+		if !strings.HasPrefix(rawArgs, "[") || !strings.HasSuffix(rawArgs, "]") {
+			return "", fmt.Errorf("translateSelector: Invalid selector %#v", selector)
+		}
+		rawArgs = rawArgs[1 : len(rawArgs)-1]
+	}
+
+	args := make([]string, 0)
+	limit := ""
+	sort := ""
+	if variable == "@n" {
+		// @n 在国际版中不存在，它等价于 @e[c=1]
+		variable = "@e"
+		limit = "1"
+	}
+
+	for _, arg := range splitTopLevel(rawArgs, ',') {
+		arg = strings.TrimSpace(arg)
+		if len(arg) == 0 {
+			continue
+		}
+
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return "", fmt.Errorf("translateSelector: Invalid selector argument %#v", arg)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if unsupportedArguments[key] {
+			return "", fmt.Errorf("translateSelector: Selector argument %#v is not supported in bedrock edition", key)
+		}
+
+		switch key {
+		case "sort":
+			sort = value
+			continue
+		case "limit":
+			limit = value
+			continue
+		case "type":
+			value = strings.Replace(value, "minecraft:", "", 1)
+		}
+
+		if names, ok := rangeArguments[key]; ok {
+			minValue, maxValue, isRange := strings.Cut(value, "..")
+			if !isRange {
+				minValue, maxValue = value, value
+			}
+			if len(minValue) > 0 {
+				args = append(args, names[0]+"="+minValue)
+			}
+			if len(maxValue) > 0 {
+				args = append(args, names[1]+"="+maxValue)
+			}
+			continue
+		}
+
+		if newKey, ok := renamedArguments[key]; ok {
+			key = newKey
+		}
+		args = append(args, key+"="+value)
+	}
+
+	switch sort {
+	case "", "nearest", "arbitrary":
+		if len(limit) > 0 {
+			args = append(args, "c="+limit)
+		}
+	case "furthest":
+		if len(limit) == 0 {
+			limit = "1"
+		}
+		args = append(args, "c=-"+limit)
+	case "random":
+		// 国际版的 @r 在未指定 type 时只选择玩家
+		if variable == "@e" && !hasArgument(args, "type") {
+			return "", fmt.Errorf("translateSelector: Random selection of arbitrary entities is not supported in bedrock edition")
+		}
+		variable = "@r"
+		if len(limit) > 0 {
+			args = append(args, "c="+limit)
+		}
+	default:
+		return "", fmt.Errorf("translateSelector: Unknown sort method %#v", sort)
+	}
+
+	if len(args) == 0 {
+		return variable, nil
+	}
+	return variable + "[" + strings.Join(args, ",") + "]", nil
+}
+
+// hasArgument 检查 args 中是否存在键为 key 的选择器参数
+func hasArgument(args []string, key string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, key+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,279 @@
+package command_translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// colorCodes 描述了 Java 版文本颜色到格式化代码的映射
+var colorCodes = map[string]string{
+	"black":        "§0",
+	"dark_blue":    "§1",
+	"dark_green":   "§2",
+	"dark_aqua":    "§3",
+	"dark_red":     "§4",
+	"dark_purple":  "§5",
+	"gold":         "§6",
+	"gray":         "§7",
+	"dark_gray":    "§8",
+	"blue":         "§9",
+	"green":        "§a",
+	"aqua":         "§b",
+	"red":          "§c",
+	"light_purple": "§d",
+	"yellow":       "§e",
+	"white":        "§f",
+}
+
+// textStyle 是文本组件的样式
+type textStyle struct {
+	color      string
+	bold       bool
+	italic     bool
+	obfuscated bool
+}
+
+// codes 返回样式 t 所对应的格式化代码
+func (t textStyle) codes() string {
+	result := colorCodes[t.color]
+	if t.obfuscated {
+		result += "§k"
+	}
+	if t.bold {
+		result += "§l"
+	}
+	if t.italic {
+		result += "§o"
+	}
+	return result
+}
+
+// rawTextBuilder 将 Java 版文本组件逐个转换为国际版的 rawtext
+type rawTextBuilder struct {
+	rawText  []any
+	text     strings.Builder
+	current  string
+	warnings []string
+}
+
+// warn 记录一个转换期间的警告
+func (r *rawTextBuilder) warn(warning string) {
+	for _, value := range r.warnings {
+		if value == warning {
+			return
+		}
+	}
+	r.warnings = append(r.warnings, warning)
+}
+
+// applyStyle 在必要时写入切换到 style 所需的格式化代码
+func (r *rawTextBuilder) applyStyle(style textStyle) {
+	codes := style.codes()
+	if codes == r.current {
+		return
+	}
+	if len(r.current) > 0 {
+		r.text.WriteString("§r")
+	}
+	r.text.WriteString(codes)
+	r.current = codes
+}
+
+// flush 将已积累的文本写入 rawtext
+func (r *rawTextBuilder) flush() {
+	if r.text.Len() == 0 {
+		return
+	}
+	r.rawText = append(r.rawText, map[string]any{"text": r.text.String()})
+	r.text.Reset()
+}
+
+// add 将已解码的 Java 版文本组件 component 以父样式 parent 写入 rawtext
+func (r *rawTextBuilder) add(component any, parent textStyle) error {
+	switch val := component.(type) {
+	case string:
+		r.applyStyle(parent)
+		r.text.WriteString(val)
+		return nil
+	case float64, bool:
+		r.applyStyle(parent)
+		r.text.WriteString(fmt.Sprintf("%v", val))
+		return nil
+	case []any:
+		if len(val) == 0 {
+			return nil
+		}
+		// 数组中的第一个组件是其余组件的父组件
+		first, ok := val[0].(map[string]any)
+		if !ok {
+			for _, value := range val {
+				if err := r.add(value, parent); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		style := mergeStyle(parent, first)
+		if err := r.add(first, parent); err != nil {
+			return err
+		}
+		for _, value := range val[1:] {
+			if err := r.add(value, style); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		return r.addObject(val, parent)
+	}
+	return fmt.Errorf("add: Invalid text component %#v", component)
+}
+
+// mergeStyle 返回在父样式 parent 上应用 component 的样式后所得的样式
+func mergeStyle(parent textStyle, component map[string]any) textStyle {
+	style := parent
+	if color, ok := component["color"].(string); ok {
+		if _, ok := colorCodes[color]; ok {
+			style.color = color
+		}
+	}
+	if bold, ok := component["bold"].(bool); ok {
+		style.bold = bold
+	}
+	if italic, ok := component["italic"].(bool); ok {
+		style.italic = italic
+	}
+	if obfuscated, ok := component["obfuscated"].(bool); ok {
+		style.obfuscated = obfuscated
+	}
+	return style
+}
+
+// addObject 将 Java 版文本组件对象 component 以父样式 parent 写入 rawtext
+func (r *rawTextBuilder) addObject(component map[string]any, parent textStyle) error {
+	style := mergeStyle(parent, component)
+
+	if color, ok := component["color"].(string); ok {
+		if _, ok := colorCodes[color]; !ok {
+			r.warn(fmt.Sprintf("文本颜色 %s 不受支持，已被忽略", color))
+		}
+	}
+	for _, key := range []string{"underlined", "strikethrough"} {
+		if enabled, _ := component[key].(bool); enabled {
+			r.warn(fmt.Sprintf("文本样式 %s 不受支持，已被忽略", key))
+		}
+	}
+	for _, key := range []string{"clickEvent", "hoverEvent", "click_event", "hover_event", "insertion", "font"} {
+		if _, ok := component[key]; ok {
+			r.warn(fmt.Sprintf("文本组件的 %s 不受支持，已被忽略", key))
+		}
+	}
+
+	switch {
+	case component["text"] != nil:
+		if err := r.add(component["text"], style); err != nil {
+			return fmt.Errorf("addObject: %v", err)
+		}
+	case component["translate"] != nil:
+		translate, _ := component["translate"].(string)
+		r.applyStyle(style)
+		r.flush()
+		entry := map[string]any{"translate": translate}
+		if with, ok := component["with"].([]any); ok {
+			inner := new(rawTextBuilder)
+			for _, value := range with {
+				if err := inner.add(value, textStyle{}); err != nil {
+					return fmt.Errorf("addObject: %v", err)
+				}
+				inner.flush()
+			}
+			r.warnings = append(r.warnings, inner.warnings...)
+			entry["with"] = map[string]any{"rawtext": inner.rawText}
+		}
+		r.rawText = append(r.rawText, entry)
+	case component["score"] != nil:
+		score, _ := component["score"].(map[string]any)
+		name, _ := score["name"].(string)
+		objective, _ := score["objective"].(string)
+		name, err := translateSelector(name)
+		if err != nil {
+			return fmt.Errorf("addObject: %v", err)
+		}
+		r.applyStyle(style)
+		r.flush()
+		r.rawText = append(r.rawText, map[string]any{
+			"score": map[string]any{"name": name, "objective": objective},
+		})
+	case component["selector"] != nil:
+		selector, _ := component["selector"].(string)
+		selector, err := translateSelector(selector)
+		if err != nil {
+			return fmt.Errorf("addObject: %v", err)
+		}
+		r.applyStyle(style)
+		r.flush()
+		r.rawText = append(r.rawText, map[string]any{"selector": selector})
+	case component["keybind"] != nil, component["nbt"] != nil:
+		return fmt.Errorf("addObject: Keybind and NBT text components are not supported in bedrock edition")
+	}
+
+	if extra, ok := component["extra"].([]any); ok {
+		for _, value := range extra {
+			if err := r.add(value, style); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// translateText 将 Java 版的 JSON 文本组件 text 转换为国际版的 rawtext JSON。
+// warnings 是转换期间被忽略的内容
+func translateText(text string) (result string, warnings []string, err error) {
+	var component any
+	if err = json.Unmarshal([]byte(text), &component); err != nil {
+		return "", nil, fmt.Errorf("translateText: Invalid JSON text component; err = %v", err)
+	}
+
+	builder := new(rawTextBuilder)
+	if err = builder.add(component, textStyle{}); err != nil {
+		return "", nil, fmt.Errorf("translateText: %v", err)
+	}
+	builder.flush()
+	if builder.rawText == nil {
+		builder.rawText = make([]any, 0)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(map[string]any{"rawtext": builder.rawText}); err != nil {
+		return "", nil, fmt.Errorf("translateText: %v", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), builder.warnings, nil
+}
+
+// plainText 将 Java 版的 JSON 文本组件 text 转换为纯文本。
+// 如果 text 不是合法的 JSON，则它将被原样返回
+func plainText(text string) string {
+	var component any
+	if err := json.Unmarshal([]byte(text), &component); err != nil {
+		return text
+	}
+	builder := new(rawTextBuilder)
+	if err := builder.add(component, textStyle{}); err != nil {
+		return text
+	}
+	builder.flush()
+
+	var result strings.Builder
+	for _, value := range builder.rawText {
+		if entry, ok := value.(map[string]any); ok {
+			text, _ := entry["text"].(string)
+			result.WriteString(text)
+		}
+	}
+	return result.String()
+}
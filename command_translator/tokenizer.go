@@ -0,0 +1,72 @@
+package command_translator
+
+import "strings"
+
+// splitArgs 将命令参数 args 按照空白字符分割为若干个参数。
+// 位于引号或括号 ([], {}, ()) 内的空白字符不会导致分割，
+// 因此选择器、JSON 文本组件和 NBT 等参数总是被视为一个整体
+func splitArgs(args string) (result []string) {
+	var current strings.Builder
+	depth := 0
+	inQuote := false
+	escaped := false
+
+	for _, char := range args {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote && char == '\\':
+			escaped = true
+		case char == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case char == '[' || char == '{' || char == '(':
+			depth++
+		case char == ']' || char == '}' || char == ')':
+			depth = max(depth-1, 0)
+		case depth == 0 && (char == ' ' || char == '\t'):
+			if current.Len() > 0 {
+				result = append(result, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(char)
+	}
+
+	if current.Len() > 0 {
+		result = append(result, current.String())
+	}
+	return
+}
+
+// splitTopLevel 将 s 按照不在引号或括号内的分隔符 sep 进行分割
+func splitTopLevel(s string, sep rune) (result []string) {
+	var current strings.Builder
+	depth := 0
+	inQuote := false
+	escaped := false
+
+	for _, char := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote && char == '\\':
+			escaped = true
+		case char == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case char == '[' || char == '{' || char == '(':
+			depth++
+		case char == ']' || char == '}' || char == ')':
+			depth = max(depth-1, 0)
+		case depth == 0 && char == sep:
+			result = append(result, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(char)
+	}
+
+	return append(result, current.String())
+}
@@ -0,0 +1,651 @@
+package command_translator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/schematic"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// javaOnlyCommands 描述了国际版中不存在或语义完全不同的 Java 版命令
+var javaOnlyCommands = map[string]bool{
+	"data":        true,
+	"item":        true,
+	"loot":        true,
+	"attribute":   true,
+	"bossbar":     true,
+	"datapack":    true,
+	"advancement": true,
+	"trigger":     true,
+	"team":        true,
+	"spectate":    true,
+	"forceload":   true,
+	"schedule":    true,
+	"return":      true,
+	"random":      true,
+	"particle":    true,
+	"place":       true,
+	"ride":        true,
+	"damage":      true,
+}
+
+// dimensionNames 描述了 Java 版维度 ID 到国际版维度名称的映射
+var dimensionNames = map[string]string{
+	"minecraft:overworld":  "overworld",
+	"minecraft:the_nether": "nether",
+	"minecraft:the_end":    "the_end",
+	"overworld":            "overworld",
+	"the_nether":           "nether",
+	"the_end":              "the_end",
+}
+
+// translator 保存单次转换的状态
+type translator struct {
+	warnings []string
+}
+
+// Translate 将 Java 版命令 command 转换为国际版命令。
+// warnings 是转换期间被忽略或近似处理的内容。
+//
+// 如果 command 无法被转换，则返回的错误描述了其原因，
+// 此时调用者应当保留原始命令或将其标记为无法转换
+func Translate(command string) (result string, warnings []string, err error) {
+	t := new(translator)
+	result, err = t.command(command)
+	if err != nil {
+		return "", nil, fmt.Errorf("Translate: %v", err)
+	}
+	return result, t.warnings, nil
+}
+
+// warn 记录一个转换期间的警告
+func (t *translator) warn(warning string) {
+	for _, value := range t.warnings {
+		if value == warning {
+			return
+		}
+	}
+	t.warnings = append(t.warnings, warning)
+}
+
+// command 转换一条完整的命令
+func (t *translator) command(command string) (result string, err error) {
+	command = strings.TrimSpace(command)
+	hasSlash := strings.HasPrefix(command, "/")
+	command = strings.TrimPrefix(command, "/")
+	if len(command) == 0 {
+		return "", nil
+	}
+
+	name, rest, _ := strings.Cut(command, " ")
+	name = strings.TrimPrefix(strings.ToLower(name), "minecraft:")
+	args := splitArgs(rest)
+
+	if javaOnlyCommands[name] {
+		return "", fmt.Errorf("command: Command /%s is not supported in bedrock edition", name)
+	}
+
+	switch name {
+	case "execute":
+		args, err = t.execute(args)
+	case "tellraw":
+		args, err = t.tellraw(args)
+	case "title":
+		name, args, err = t.title(args)
+	case "setblock":
+		args, err = t.setblock(args)
+	case "fill":
+		args, err = t.fill(args)
+	case "clone":
+		args, err = t.clone(args)
+	case "give":
+		args, err = t.give(args)
+	case "clear":
+		args, err = t.clear(args)
+	case "summon":
+		args, err = t.summon(args)
+	case "effect":
+		args, err = t.effect(args)
+	case "playsound":
+		args, err = t.playsound(args)
+	case "stopsound":
+		args, err = t.stopsound(args)
+	case "scoreboard":
+		args, err = t.scoreboard(args)
+	default:
+		args, err = t.selectors(args)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	result = strings.Join(append([]string{name}, args...), " ")
+	if hasSlash {
+		result = "/" + result
+	}
+	return result, nil
+}
+
+// selectors 转换 args 中的所有目标选择器
+func (t *translator) selectors(args []string) (result []string, err error) {
+	result = make([]string, len(args))
+	for index, arg := range args {
+		result[index], err = translateSelector(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// text 转换 JSON 文本组件 text，并记录转换期间的警告
+func (t *translator) text(text string) (result string, err error) {
+	result, warnings, err := translateText(text)
+	if err != nil {
+		return "", err
+	}
+	for _, warning := range warnings {
+		t.warn(warning)
+	}
+	return result, nil
+}
+
+// block 将 Java 版方块参数 block 转换为国际版的方块名称和方块状态参数。
+// 如果 block 没有指定方块状态，则 states 为空。
+//
+// filter 指示 block 是否是筛选条件。筛选条件只包含由 block 的方块状态
+// 转换而来的方块状态，而不会补全其他的方块状态，这与 Java 版的匹配规则一致
+func (t *translator) block(block string, filter bool) (name string, states string, err error) {
+	if strings.HasPrefix(block, "#") {
+		return "", "", fmt.Errorf("block: Block tag %#v is not supported in bedrock edition", block)
+	}
+	if index := strings.Index(block, "{"); index != -1 {
+		return "", "", fmt.Errorf("block: Block NBT of %#v is not supported in bedrock edition", block)
+	}
+
+	javaBlock := schematic.ParseJavaBlock(block)
+	translate := schematic.TranslateBlock
+	if filter {
+		translate = schematic.TranslateBlockFilter
+	}
+	name, bedrockStates, problems, found := translate(javaBlock)
+	if !found {
+		return "", "", fmt.Errorf("block: Block %#v has no bedrock edition equivalent", javaBlock.Name)
+	}
+	for _, problem := range problems {
+		t.warn(fmt.Sprintf("%s: %s", javaBlock.String(), problem))
+	}
+
+	if len(javaBlock.Properties) > 0 && len(bedrockStates) > 0 {
+		states = utils.MarshalBlockStates(bedrockStates)
+	}
+	return name, states, nil
+}
+
+// appendBlock 将 Java 版方块参数 block 转换后追加到 result
+func (t *translator) appendBlock(result []string, block string) ([]string, error) {
+	return t.appendBlockArg(result, block, false)
+}
+
+// appendBlockFilter 将作为筛选条件的 Java 版方块参数 block 转换后追加到 result
+func (t *translator) appendBlockFilter(result []string, block string) ([]string, error) {
+	return t.appendBlockArg(result, block, true)
+}
+
+// appendBlockArg 将 Java 版方块参数 block 转换后追加到 result。
+// filter 指示 block 是否是筛选条件
+func (t *translator) appendBlockArg(result []string, block string, filter bool) ([]string, error) {
+	name, states, err := t.block(block, filter)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, name)
+	if len(states) > 0 {
+		result = append(result, states)
+	}
+	return result, nil
+}
+
+// item 将 Java 版物品参数 item 转换为国际版的物品名称和数据值
+func (t *translator) item(item string) (name string, damage int16, err error) {
+	if strings.HasPrefix(item, "#") {
+		return "", 0, fmt.Errorf("item: Item tag %#v is not supported in bedrock edition", item)
+	}
+	if strings.ContainsAny(item, "[{") {
+		return "", 0, fmt.Errorf("item: Item components or NBT of %#v is not supported in bedrock edition", item)
+	}
+	name, damage = schematic.TranslateItemName(item)
+	return name, damage, nil
+}
+
+// tellraw 转换 tellraw <targets> <message>
+func (t *translator) tellraw(args []string) (result []string, err error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("tellraw: Invalid arguments %v", args)
+	}
+	target, err := translateSelector(args[0])
+	if err != nil {
+		return nil, err
+	}
+	text, err := t.text(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return []string{target, text}, nil
+}
+
+// title 转换 title <targets> <title|subtitle|actionbar> <message>。
+// 带有文本组件的 title 命令将被转换为 titleraw
+func (t *translator) title(args []string) (name string, result []string, err error) {
+	if len(args) < 2 {
+		return "", nil, fmt.Errorf("title: Invalid arguments %v", args)
+	}
+	target, err := translateSelector(args[0])
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch args[1] {
+	case "title", "subtitle", "actionbar":
+		if len(args) != 3 {
+			return "", nil, fmt.Errorf("title: Invalid arguments %v", args)
+		}
+		text, err := t.text(args[2])
+		if err != nil {
+			return "", nil, err
+		}
+		return "titleraw", []string{target, args[1], text}, nil
+	default:
+		return "title", append([]string{target}, args[1:]...), nil
+	}
+}
+
+// setblock 转换 setblock <pos> <block> [destroy|keep|replace]
+func (t *translator) setblock(args []string) (result []string, err error) {
+	if len(args) < 4 {
+		return nil, fmt.Errorf("setblock: Invalid arguments %v", args)
+	}
+	result, err = t.appendBlock(append([]string{}, args[:3]...), args[3])
+	if err != nil {
+		return nil, err
+	}
+	return append(result, args[4:]...), nil
+}
+
+// fill 转换 fill <from> <to> <block> [destroy|hollow|keep|outline|replace [filter]]
+func (t *translator) fill(args []string) (result []string, err error) {
+	if len(args) < 7 {
+		return nil, fmt.Errorf("fill: Invalid arguments %v", args)
+	}
+	result, err = t.appendBlock(append([]string{}, args[:6]...), args[6])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 7 {
+		return result, nil
+	}
+
+	result = append(result, args[7])
+	if args[7] == "replace" && len(args) >= 9 {
+		result, err = t.appendBlockFilter(result, args[8])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// clone 转换 clone <begin> <end> <destination> [replace|masked|filtered <filter>] [force|move|normal]。
+// 国际版中 filtered 模式的参数顺序与 Java 版不同
+func (t *translator) clone(args []string) (result []string, err error) {
+	if len(args) < 9 {
+		return nil, fmt.Errorf("clone: Invalid arguments %v", args)
+	}
+	if args[0] == "from" || args[0] == "to" {
+		return nil, fmt.Errorf("clone: Cloning between dimensions is not supported in bedrock edition")
+	}
+
+	result = append([]string{}, args[:9]...)
+	if len(args) == 9 {
+		return result, nil
+	}
+	if args[9] != "filtered" {
+		return append(result, args[9:]...), nil
+	}
+
+	if len(args) < 11 {
+		return nil, fmt.Errorf("clone: Invalid arguments %v", args)
+	}
+	mode := "normal"
+	if len(args) >= 12 {
+		mode = args[11]
+	}
+	result = append(result, "filtered", mode)
+	return t.appendBlockFilter(result, args[10])
+}
+
+// give 转换 give <targets> <item> [count]
+func (t *translator) give(args []string) (result []string, err error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("give: Invalid arguments %v", args)
+	}
+	target, err := translateSelector(args[0])
+	if err != nil {
+		return nil, err
+	}
+	name, damage, err := t.item(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	count := "1"
+	if len(args) >= 3 {
+		count = args[2]
+	}
+	result = []string{target, name}
+	if len(args) >= 3 || damage != 0 {
+		result = append(result, count)
+	}
+	if damage != 0 {
+		result = append(result, fmt.Sprintf("%d", damage))
+	}
+	return result, nil
+}
+
+// clear 转换 clear [targets] [item] [maxCount]
+func (t *translator) clear(args []string) (result []string, err error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	target, err := translateSelector(args[0])
+	if err != nil {
+		return nil, err
+	}
+	result = []string{target}
+	if len(args) == 1 {
+		return result, nil
+	}
+
+	name, damage, err := t.item(args[1])
+	if err != nil {
+		return nil, err
+	}
+	data := "-1"
+	if damage != 0 {
+		data = fmt.Sprintf("%d", damage)
+	}
+	result = append(result, name)
+	if len(args) >= 3 || damage != 0 {
+		result = append(result, data)
+	}
+	return append(result, args[2:]...), nil
+}
+
+// summon 转换 summon <entity> [pos]
+func (t *translator) summon(args []string) (result []string, err error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("summon: Invalid arguments %v", args)
+	}
+	if len(args) > 4 {
+		return nil, fmt.Errorf("summon: Entity NBT is not supported in bedrock edition")
+	}
+	return args, nil
+}
+
+// effect 转换 effect give <targets> <effect> [seconds] [amplifier] [hideParticles]
+// 和 effect clear [targets] [effect]
+func (t *translator) effect(args []string) (result []string, err error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("effect: Invalid arguments %v", args)
+	}
+
+	switch args[0] {
+	case "give":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("effect: Invalid arguments %v", args)
+		}
+		result, err = t.selectors(args[1:])
+		if err != nil {
+			return nil, err
+		}
+		result[1] = strings.TrimPrefix(result[1], "minecraft:")
+		return result, nil
+	case "clear":
+		target := "@s"
+		if len(args) >= 2 {
+			target, err = translateSelector(args[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		result = []string{target, "clear"}
+		if len(args) >= 3 {
+			result = append(result, strings.TrimPrefix(args[2], "minecraft:"))
+		}
+		return result, nil
+	}
+
+	// 旧版 Java 版的 effect 命令与国际版相同
+	return t.selectors(args)
+}
+
+// playsound 转换 playsound <sound> <source> <targets> [pos] [volume] [pitch] [minVolume]。
+// 国际版没有声音类别
+func (t *translator) playsound(args []string) (result []string, err error) {
+	if len(args) < 3 {
+		return nil, fmt.Errorf("playsound: Invalid arguments %v", args)
+	}
+	result, err = t.selectors(append([]string{args[0]}, args[2:]...))
+	if err != nil {
+		return nil, err
+	}
+	result[0] = strings.TrimPrefix(result[0], "minecraft:")
+	t.warn("Java 版与国际版的声音 ID 不完全相同，请确认声音是否存在")
+	return result, nil
+}
+
+// stopsound 转换 stopsound <targets> [source] [sound]。
+// 国际版没有声音类别
+func (t *translator) stopsound(args []string) (result []string, err error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("stopsound: Invalid arguments %v", args)
+	}
+	target, err := translateSelector(args[0])
+	if err != nil {
+		return nil, err
+	}
+	result = []string{target}
+	if len(args) >= 3 {
+		result = append(result, strings.TrimPrefix(args[2], "minecraft:"))
+		t.warn("Java 版与国际版的声音 ID 不完全相同，请确认声音是否存在")
+	}
+	return result, nil
+}
+
+// scoreboard 转换 scoreboard 命令
+func (t *translator) scoreboard(args []string) (result []string, err error) {
+	if len(args) >= 2 {
+		switch args[0] + " " + args[1] {
+		case "objectives modify", "players enable", "players display":
+			return nil, fmt.Errorf("scoreboard: Subcommand %s %s is not supported in bedrock edition", args[0], args[1])
+		case "objectives add":
+			if len(args) >= 5 {
+				args = append(append([]string{}, args[:4]...), fmt.Sprintf("%#v", plainText(strings.Join(args[4:], " "))))
+			}
+			if len(args) >= 4 && args[3] != "dummy" {
+				return nil, fmt.Errorf("scoreboard: Criteria %#v is not supported in bedrock edition", args[3])
+			}
+		case "objectives setdisplay":
+			if len(args) >= 3 && strings.HasPrefix(args[2], "sidebar.team.") {
+				return nil, fmt.Errorf("scoreboard: Display slot %#v is not supported in bedrock edition", args[2])
+			}
+		}
+	}
+	return t.selectors(args)
+}
+
+// execute 转换 execute 命令的子命令
+func (t *translator) execute(args []string) (result []string, err error) {
+	for index := 0; index < len(args); {
+		need := func(count int) ([]string, error) {
+			if index+count > len(args) {
+				return nil, fmt.Errorf("execute: Invalid arguments %v", args)
+			}
+			return args[index : index+count], nil
+		}
+
+		subcommand := args[index]
+		switch subcommand {
+		case "as", "at":
+			part, err := need(2)
+			if err != nil {
+				return nil, err
+			}
+			selector, err := translateSelector(part[1])
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, subcommand, selector)
+			index += 2
+		case "positioned", "rotated":
+			part, err := need(2)
+			if err != nil {
+				return nil, err
+			}
+			if part[1] == "over" {
+				return nil, fmt.Errorf("execute: Subcommand positioned over is not supported in bedrock edition")
+			}
+			if part[1] == "as" {
+				part, err = need(3)
+				if err != nil {
+					return nil, err
+				}
+				selector, err := translateSelector(part[2])
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, subcommand, "as", selector)
+				index += 3
+				continue
+			}
+			count := 4
+			if subcommand == "rotated" {
+				count = 3
+			}
+			part, err = need(count)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, part...)
+			index += count
+		case "align", "anchored":
+			part, err := need(2)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, part...)
+			index += 2
+		case "facing":
+			part, err := need(2)
+			if err != nil {
+				return nil, err
+			}
+			if part[1] == "entity" {
+				part, err = need(4)
+				if err != nil {
+					return nil, err
+				}
+				selector, err := translateSelector(part[2])
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, "facing", "entity", selector, part[3])
+				index += 4
+				continue
+			}
+			part, err = need(4)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, part...)
+			index += 4
+		case "in":
+			part, err := need(2)
+			if err != nil {
+				return nil, err
+			}
+			dimension, ok := dimensionNames[part[1]]
+			if !ok {
+				return nil, fmt.Errorf("execute: Dimension %#v is not supported in bedrock edition", part[1])
+			}
+			result = append(result, "in", dimension)
+			index += 2
+		case "if", "unless":
+			consumed, part, err := t.executeCondition(args[index:])
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, part...)
+			index += consumed
+		case "run":
+			command, err := t.command(strings.Join(args[index+1:], " "))
+			if err != nil {
+				return nil, err
+			}
+			return append(result, "run", command), nil
+		default:
+			return nil, fmt.Errorf("execute: Subcommand %#v is not supported in bedrock edition", subcommand)
+		}
+	}
+	return result, nil
+}
+
+// executeCondition 转换以 if 或 unless 开头的 execute 子命令 args。
+// consumed 是该子命令所占用的参数数量
+func (t *translator) executeCondition(args []string) (consumed int, result []string, err error) {
+	if len(args) < 2 {
+		return 0, nil, fmt.Errorf("executeCondition: Invalid arguments %v", args)
+	}
+
+	switch args[1] {
+	case "block":
+		if len(args) < 6 {
+			return 0, nil, fmt.Errorf("executeCondition: Invalid arguments %v", args)
+		}
+		result, err = t.appendBlockFilter(append([]string{}, args[:5]...), args[5])
+		if err != nil {
+			return 0, nil, err
+		}
+		return 6, result, nil
+	case "blocks":
+		if len(args) < 12 {
+			return 0, nil, fmt.Errorf("executeCondition: Invalid arguments %v", args)
+		}
+		return 12, args[:12], nil
+	case "entity":
+		if len(args) < 3 {
+			return 0, nil, fmt.Errorf("executeCondition: Invalid arguments %v", args)
+		}
+		selector, err := translateSelector(args[2])
+		if err != nil {
+			return 0, nil, err
+		}
+		return 3, []string{args[0], "entity", selector}, nil
+	case "score":
+		count := 7
+		if len(args) >= 5 && args[4] == "matches" {
+			count = 6
+		}
+		if len(args) < count {
+			return 0, nil, fmt.Errorf("executeCondition: Invalid arguments %v", args)
+		}
+		result, err = t.selectors(args[:count])
+		if err != nil {
+			return 0, nil, err
+		}
+		return count, result, nil
+	}
+
+	return 0, nil, fmt.Errorf("executeCondition: Condition %s %s is not supported in bedrock edition", args[0], args[1])
+}
@@ -0,0 +1,103 @@
+package command_translator
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+// corpusCase 是语料库中的一个用例
+type corpusCase struct {
+	line     int
+	command  string
+	expected string
+	errorMsg string
+}
+
+// loadCorpus 读取语料库文件 path 中的所有用例
+func loadCorpus(t *testing.T, path string) (cases []corpusCase) {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("loadCorpus: %v", err)
+	}
+	defer file.Close()
+
+	var current *corpusCase
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if len(strings.TrimSpace(text)) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "=> "), strings.HasPrefix(text, "!! "):
+			if current == nil {
+				t.Fatalf("loadCorpus: Line %d has no command before it", line)
+			}
+			if strings.HasPrefix(text, "=> ") {
+				current.expected = strings.TrimPrefix(text, "=> ")
+			} else {
+				current.errorMsg = strings.TrimPrefix(text, "!! ")
+			}
+			cases = append(cases, *current)
+			current = nil
+		default:
+			if current != nil {
+				t.Fatalf("loadCorpus: Command at line %d has no expected result", current.line)
+			}
+			current = &corpusCase{line: line, command: text}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		t.Fatalf("loadCorpus: %v", err)
+	}
+	if current != nil {
+		t.Fatalf("loadCorpus: Command at line %d has no expected result", current.line)
+	}
+
+	return cases
+}
+
+// runCorpus 使用 convert 转换语料库文件 path 中的每个命令，
+// 并检查转换结果是否符合预期
+func runCorpus(t *testing.T, path string, convert func(command string) (string, error)) {
+	for _, c := range loadCorpus(t, path) {
+		result, err := convert(c.command)
+
+		if len(c.errorMsg) > 0 {
+			if err == nil {
+				t.Errorf("line %d: expected error containing %q, got %q", c.line, c.errorMsg, result)
+			} else if !strings.Contains(err.Error(), c.errorMsg) {
+				t.Errorf("line %d: expected error containing %q, got %q", c.line, c.errorMsg, err.Error())
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("line %d: unexpected error: %v", c.line, err)
+			continue
+		}
+		if result != c.expected {
+			t.Errorf("line %d:\n\tcommand:  %s\n\texpected: %s\n\tgot:      %s", c.line, c.command, c.expected, result)
+		}
+	}
+}
+
+func TestTranslateCorpus(t *testing.T) {
+	runCorpus(t, "testdata/corpus.txt", func(command string) (string, error) {
+		result, _, err := Translate(command)
+		return result, err
+	})
+}
+
+func TestUpgradeLegacyExecuteCorpus(t *testing.T) {
+	runCorpus(t, "testdata/legacy_execute.txt", func(command string) (string, error) {
+		result, changes, err := UpgradeLegacyExecute(command)
+		if err == nil && len(changes) == 0 && result != command {
+			t.Errorf("command %q was changed without reporting any change", command)
+		}
+		return result, err
+	})
+}
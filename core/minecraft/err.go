@@ -28,8 +28,7 @@ func (conn *Conn) wrap(err error, op string) error {
 }
 
 // DisconnectError is an error returned by operations from Conn when the connection is closed by the other
-// end through a packet.Disconnect. It is wrapped in a net.OpError and may be obtained using
-// errors.Unwrap(net.OpError).
+// end through a packet.Disconnect. It is wrapped in a net.OpError and may be obtained using errors.As.
 type DisconnectError string
 
 // Error returns the message held in the packet.Disconnect.
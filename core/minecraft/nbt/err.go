@@ -168,3 +168,14 @@ type InvalidVarintError struct {
 func (err InvalidVarintError) Error() string {
 	return fmt.Sprintf("nbt: varint did not terminate after %v bytes at offset %v", err.N, err.Off)
 }
+
+// SNBTSyntaxError is returned by UnmarshalSNBT when the SNBT passed is not valid.
+type SNBTSyntaxError struct {
+	Off int
+	Msg string
+}
+
+// Error ...
+func (err SNBTSyntaxError) Error() string {
+	return fmt.Sprintf("nbt: invalid SNBT at offset %v: %v", err.Off, err.Msg)
+}
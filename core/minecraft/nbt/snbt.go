@@ -0,0 +1,664 @@
+package nbt
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MarshalSNBT encodes a Go value to its stringified NBT (SNBT) representation on a single line. The value
+// may be anything that Marshal accepts, and it is first encoded to its binary representation, so that the
+// SNBT produced always reflects the tags that would be written to the binary encodings.
+//
+// Compound keys are sorted, so the result is stable for equal values. Keys are only quoted where necessary,
+// while strings are always quoted so that they are never mistaken for numbers. Non-finite floating point
+// numbers cannot be represented in SNBT and result in an error.
+func MarshalSNBT(v any) (string, error) {
+	return marshalSNBT(v, "")
+}
+
+// MarshalSNBTIndent is like MarshalSNBT, but places each element of a TAG_Compound or TAG_List on a new line
+// and indents them with one copy of indent per level of nesting. TAG_ByteArray, TAG_IntArray and
+// TAG_LongArray are always written on a single line.
+func MarshalSNBTIndent(v any, indent string) (string, error) {
+	return marshalSNBT(v, indent)
+}
+
+// UnmarshalSNBT decodes the SNBT string data into the value pointed to by v, following the same conversion
+// rules as Unmarshal. When decoding into an interface, the values produced are identical to those produced by
+// decoding the binary encodings, so that SNBT written by MarshalSNBT round-trips without loss.
+//
+// The following SNBT syntax is supported:
+//
+//	{key: value, "quoted key": value}     TAG_Compound
+//	[value, value]                        TAG_List (all elements must have the same tag type)
+//	[B; 1b, 2b], [I; 1, 2], [L; 1L, 2L]   TAG_ByteArray, TAG_IntArray, TAG_LongArray
+//	1b, 1s, 1, 1L, 1.5f, 1.5d, 1.5         TAG_Byte, TAG_Short, TAG_Int, TAG_Long, TAG_Float, TAG_Double
+//	true, false                           TAG_Byte (1 and 0)
+//	"text", 'text', text                  TAG_String
+//
+// Quoted strings support the escapes \\, \", \', \b, \f, \n, \r, \t and \uXXXX.
+func UnmarshalSNBT(data string, v any) error {
+	p := &snbtParser{data: data}
+	p.skipWhitespace()
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	p.skipWhitespace()
+	if p.off != len(p.data) {
+		return p.errorf("unexpected trailing data")
+	}
+
+	b, err := MarshalEncoding(value, LittleEndian)
+	if err != nil {
+		return err
+	}
+	return UnmarshalEncoding(b, v, LittleEndian)
+}
+
+// marshalSNBT encodes v to SNBT, using indent for each level of nesting if it is not empty.
+func marshalSNBT(v any, indent string) (string, error) {
+	b, err := MarshalEncoding(v, LittleEndian)
+	if err != nil {
+		return "", err
+	}
+	var value any
+	if err := UnmarshalEncoding(b, &value, LittleEndian); err != nil {
+		return "", err
+	}
+
+	w := &snbtWriter{indent: indent}
+	if err := w.write(value); err != nil {
+		return "", err
+	}
+	return w.b.String(), nil
+}
+
+// snbtBareString matches strings that may be written in SNBT without quotes.
+var snbtBareString = regexp.MustCompile(`^[A-Za-z0-9_\-.+]+$`)
+
+// snbtWriter writes values decoded from NBT as SNBT.
+type snbtWriter struct {
+	b      strings.Builder
+	indent string
+	depth  int
+}
+
+// newLine writes a new line followed by the indentation of the current depth, if indentation is enabled.
+func (w *snbtWriter) newLine() {
+	if w.indent == "" {
+		return
+	}
+	w.b.WriteByte('\n')
+	w.b.WriteString(strings.Repeat(w.indent, w.depth))
+}
+
+// separator writes the separator placed between two elements of a TAG_Compound or TAG_List.
+func (w *snbtWriter) separator() {
+	w.b.WriteByte(',')
+	if w.indent == "" {
+		w.b.WriteByte(' ')
+	}
+}
+
+// write writes a single value to the underlying builder.
+func (w *snbtWriter) write(value any) error {
+	switch val := value.(type) {
+	case byte:
+		// TAG_Byte is signed, so it is written as such.
+		w.b.WriteString(strconv.FormatInt(int64(int8(val)), 10) + "b")
+	case int16:
+		w.b.WriteString(strconv.FormatInt(int64(val), 10) + "s")
+	case int32:
+		w.b.WriteString(strconv.FormatInt(int64(val), 10))
+	case int64:
+		w.b.WriteString(strconv.FormatInt(val, 10) + "L")
+	case float32:
+		if math.IsNaN(float64(val)) || math.IsInf(float64(val), 0) {
+			return fmt.Errorf("nbt: cannot represent float %v in SNBT", val)
+		}
+		w.b.WriteString(strconv.FormatFloat(float64(val), 'g', -1, 32) + "f")
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return fmt.Errorf("nbt: cannot represent double %v in SNBT", val)
+		}
+		w.b.WriteString(strconv.FormatFloat(val, 'g', -1, 64) + "d")
+	case string:
+		w.b.WriteString(quoteSNBT(val))
+	case map[string]any:
+		return w.writeCompound(val)
+	default:
+		return w.writeSequence(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+// writeCompound writes a TAG_Compound with its keys sorted.
+func (w *snbtWriter) writeCompound(m map[string]any) error {
+	w.b.WriteByte('{')
+	if len(m) == 0 {
+		w.b.WriteByte('}')
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	w.depth++
+	for i, k := range keys {
+		if i != 0 {
+			w.separator()
+		}
+		w.newLine()
+		if snbtBareString.MatchString(k) {
+			w.b.WriteString(k)
+		} else {
+			w.b.WriteString(quoteSNBT(k))
+		}
+		w.b.WriteString(": ")
+		if err := w.write(m[k]); err != nil {
+			return err
+		}
+	}
+	w.depth--
+	w.newLine()
+	w.b.WriteByte('}')
+	return nil
+}
+
+// writeSequence writes a TAG_List, TAG_ByteArray, TAG_IntArray or TAG_LongArray.
+func (w *snbtWriter) writeSequence(val reflect.Value) error {
+	switch val.Kind() {
+	case reflect.Array:
+		w.b.WriteByte('[')
+		switch val.Type().Elem().Kind() {
+		case reflect.Uint8:
+			w.b.WriteString("B;")
+		case reflect.Int32:
+			w.b.WriteString("I;")
+		case reflect.Int64:
+			w.b.WriteString("L;")
+		default:
+			return fmt.Errorf("nbt: cannot represent %v in SNBT", val.Type())
+		}
+		for i := 0; i < val.Len(); i++ {
+			if i != 0 {
+				w.b.WriteByte(',')
+			}
+			w.b.WriteByte(' ')
+			if err := w.write(val.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		w.b.WriteByte(']')
+		return nil
+	case reflect.Slice:
+		w.b.WriteByte('[')
+		if val.Len() == 0 {
+			w.b.WriteByte(']')
+			return nil
+		}
+		w.depth++
+		for i := 0; i < val.Len(); i++ {
+			if i != 0 {
+				w.separator()
+			}
+			w.newLine()
+			if err := w.write(val.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		w.depth--
+		w.newLine()
+		w.b.WriteByte(']')
+		return nil
+	}
+	return fmt.Errorf("nbt: cannot represent %v in SNBT", val.Type())
+}
+
+// quoteSNBT returns s as a double quoted SNBT string.
+func quoteSNBT(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				b.WriteString(fmt.Sprintf(`\u%04x`, r))
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// snbtParser parses SNBT into the values produced when decoding the binary encodings into an interface.
+type snbtParser struct {
+	data  string
+	off   int
+	depth int
+}
+
+// errorf returns an SNBTSyntaxError at the current offset of the parser.
+func (p *snbtParser) errorf(format string, a ...any) error {
+	return SNBTSyntaxError{Off: p.off, Msg: fmt.Sprintf(format, a...)}
+}
+
+// skipWhitespace advances the parser past any whitespace.
+func (p *snbtParser) skipWhitespace() {
+	for p.off < len(p.data) && strings.IndexByte(" \t\r\n", p.data[p.off]) != -1 {
+		p.off++
+	}
+}
+
+// peek returns the next byte without consuming it, or 0 if the end of the data was reached.
+func (p *snbtParser) peek() byte {
+	if p.off >= len(p.data) {
+		return 0
+	}
+	return p.data[p.off]
+}
+
+// expect consumes the byte c after skipping whitespace, or returns an error if the next byte is not c.
+func (p *snbtParser) expect(c byte) error {
+	p.skipWhitespace()
+	if p.peek() != c {
+		return p.errorf("expected '%c'", c)
+	}
+	p.off++
+	return nil
+}
+
+// value parses a single SNBT value of any type.
+func (p *snbtParser) value() (any, error) {
+	p.skipWhitespace()
+	switch c := p.peek(); c {
+	case 0:
+		return nil, p.errorf("unexpected end of data")
+	case '{':
+		return p.compound()
+	case '[':
+		return p.listOrArray()
+	case '"', '\'':
+		return p.quoted()
+	}
+
+	s := p.bare()
+	if s == "" {
+		return nil, p.errorf("unexpected character '%c'", p.peek())
+	}
+	if value, ok := parseSNBTNumber(s); ok {
+		return value, nil
+	}
+	switch s {
+	case "true":
+		return byte(1), nil
+	case "false":
+		return byte(0), nil
+	}
+	return s, nil
+}
+
+// bare consumes and returns an unquoted string.
+func (p *snbtParser) bare() string {
+	start := p.off
+	for p.off < len(p.data) {
+		c := p.data[p.off]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("_-.+", c) != -1) {
+			break
+		}
+		p.off++
+	}
+	return p.data[start:p.off]
+}
+
+// quoted consumes and returns a string quoted with either single or double quotes.
+func (p *snbtParser) quoted() (string, error) {
+	quote := p.data[p.off]
+	p.off++
+
+	var b strings.Builder
+	for {
+		if p.off >= len(p.data) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.data[p.off]
+		p.off++
+		switch c {
+		case quote:
+			return b.String(), nil
+		case '\\':
+			if p.off >= len(p.data) {
+				return "", p.errorf("unterminated string")
+			}
+			e := p.data[p.off]
+			p.off++
+			switch e {
+			case '\\', '"', '\'':
+				b.WriteByte(e)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.off+4 > len(p.data) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.data[p.off:p.off+4], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				p.off += 4
+				b.WriteRune(rune(r))
+			default:
+				return "", p.errorf("invalid escape '\\%c'", e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// key parses the key of a TAG_Compound entry, which may be quoted or unquoted.
+func (p *snbtParser) key() (string, error) {
+	p.skipWhitespace()
+	switch p.peek() {
+	case '"', '\'':
+		return p.quoted()
+	}
+	k := p.bare()
+	if k == "" {
+		return "", p.errorf("expected compound key")
+	}
+	return k, nil
+}
+
+// compound parses a TAG_Compound.
+func (p *snbtParser) compound() (map[string]any, error) {
+	if p.depth >= maximumNestingDepth {
+		return nil, MaximumDepthReachedError{}
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	p.off++
+	m := make(map[string]any)
+	p.skipWhitespace()
+	if p.peek() == '}' {
+		p.off++
+		return m, nil
+	}
+	for {
+		k, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		if m[k], err = p.value(); err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.off++
+		case '}':
+			p.off++
+			return m, nil
+		default:
+			return nil, p.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// listOrArray parses a TAG_List, TAG_ByteArray, TAG_IntArray or TAG_LongArray.
+func (p *snbtParser) listOrArray() (any, error) {
+	if p.depth >= maximumNestingDepth {
+		return nil, MaximumDepthReachedError{}
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	p.off++
+	p.skipWhitespace()
+	switch arrayType := p.peek(); arrayType {
+	case 'B', 'I', 'L':
+		start := p.off
+		p.off++
+		p.skipWhitespace()
+		if p.peek() == ';' {
+			p.off++
+			return p.array(arrayType)
+		}
+		p.off = start
+	}
+
+	var elements []any
+	p.skipWhitespace()
+	if p.peek() == ']' {
+		p.off++
+		return []any{}, nil
+	}
+	for {
+		element, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		// Nested lists may hold elements of different types, so only the tag types are compared.
+		if len(elements) != 0 && tagFromType(reflect.TypeOf(element)) != tagFromType(reflect.TypeOf(elements[0])) {
+			return nil, p.errorf("list elements must all have the same type, but got %T and %T", elements[0], element)
+		}
+		elements = append(elements, element)
+
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.off++
+		case ']':
+			p.off++
+			return typedList(elements), nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// typedList converts the elements of a TAG_List into the slice type that decoding the binary encodings into
+// an interface produces. Lists of TAG_Byte, TAG_Int and TAG_Long become []byte, []int32 and []int64.
+func typedList(elements []any) any {
+	switch elements[0].(type) {
+	case byte:
+		result := make([]byte, len(elements))
+		for i, element := range elements {
+			result[i] = element.(byte)
+		}
+		return result
+	case int32:
+		result := make([]int32, len(elements))
+		for i, element := range elements {
+			result[i] = element.(int32)
+		}
+		return result
+	case int64:
+		result := make([]int64, len(elements))
+		for i, element := range elements {
+			result[i] = element.(int64)
+		}
+		return result
+	}
+	return elements
+}
+
+// array parses the elements of a TAG_ByteArray, TAG_IntArray or TAG_LongArray, after the type prefix was
+// consumed.
+func (p *snbtParser) array(arrayType byte) (any, error) {
+	var elemType reflect.Type
+	switch arrayType {
+	case 'B':
+		elemType = byteType
+	case 'I':
+		elemType = int32Type
+	case 'L':
+		elemType = int64Type
+	}
+
+	var elements []int64
+	p.skipWhitespace()
+	if p.peek() == ']' {
+		p.off++
+	} else {
+		for {
+			p.skipWhitespace()
+			s := p.bare()
+			n, ok := parseSNBTArrayElement(s, arrayType)
+			if !ok {
+				return nil, p.errorf("invalid element %q in [%c; ...] array", s, arrayType)
+			}
+			elements = append(elements, n)
+
+			p.skipWhitespace()
+			if p.peek() == ']' {
+				p.off++
+				break
+			}
+			if err := p.expect(','); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	value := reflect.New(reflect.ArrayOf(len(elements), elemType)).Elem()
+	for i, n := range elements {
+		switch arrayType {
+		case 'B':
+			value.Index(i).SetUint(uint64(uint8(n)))
+		default:
+			value.Index(i).SetInt(n)
+		}
+	}
+	return value.Interface(), nil
+}
+
+// parseSNBTArrayElement parses an element of an array with the type passed. The element may optionally
+// carry the suffix matching the array type.
+func parseSNBTArrayElement(s string, arrayType byte) (int64, bool) {
+	bitSize := 64
+	switch arrayType {
+	case 'B':
+		s = strings.TrimSuffix(strings.TrimSuffix(s, "b"), "B")
+		bitSize = 8
+	case 'I':
+		bitSize = 32
+	case 'L':
+		s = strings.TrimSuffix(strings.TrimSuffix(s, "l"), "L")
+	}
+	n, err := strconv.ParseInt(s, 10, bitSize)
+	if err != nil && arrayType == 'B' {
+		// Unsigned values are accepted in byte arrays as well.
+		u, err := strconv.ParseUint(s, 10, 8)
+		return int64(u), err == nil
+	}
+	return n, err == nil
+}
+
+// snbtInteger and snbtFloat match integer and floating point numbers without their type suffix.
+var (
+	snbtInteger = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)$`)
+	snbtFloat   = regexp.MustCompile(`^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+)
+
+// parseSNBTNumber attempts to parse s as a number with an optional type suffix. If s is not a valid number,
+// for example because it is out of range for its type, ok is false and s should be treated as a string.
+func parseSNBTNumber(s string) (value any, ok bool) {
+	if s == "" {
+		return nil, false
+	}
+	body, suffix := s[:len(s)-1], s[len(s)-1]
+
+	switch suffix {
+	case 'b', 'B':
+		if snbtInteger.MatchString(body) {
+			if n, err := strconv.ParseInt(body, 10, 8); err == nil {
+				return byte(n), true
+			}
+			// Unsigned values are accepted as well.
+			if n, err := strconv.ParseUint(body, 10, 8); err == nil {
+				return byte(n), true
+			}
+		}
+		return nil, false
+	case 's', 'S':
+		if snbtInteger.MatchString(body) {
+			if n, err := strconv.ParseInt(body, 10, 16); err == nil {
+				return int16(n), true
+			}
+		}
+		return nil, false
+	case 'l', 'L':
+		if snbtInteger.MatchString(body) {
+			if n, err := strconv.ParseInt(body, 10, 64); err == nil {
+				return n, true
+			}
+		}
+		return nil, false
+	case 'f', 'F':
+		if snbtFloat.MatchString(body) {
+			if f, err := strconv.ParseFloat(body, 32); err == nil {
+				return float32(f), true
+			}
+		}
+		return nil, false
+	case 'd', 'D':
+		if snbtFloat.MatchString(body) {
+			if f, err := strconv.ParseFloat(body, 64); err == nil {
+				return f, true
+			}
+		}
+		return nil, false
+	}
+
+	if snbtInteger.MatchString(s) {
+		if n, err := strconv.ParseInt(s, 10, 32); err == nil {
+			return int32(n), true
+		}
+		return nil, false
+	}
+	if snbtFloat.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
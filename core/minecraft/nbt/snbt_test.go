@@ -0,0 +1,95 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSNBTRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		snbt  string
+	}{
+		{"byte", byte(1), "1b"},
+		{"negative byte", byte(200), "-56b"},
+		{"short", int16(-3), "-3s"},
+		{"int", int32(7), "7"},
+		{"long", int64(1) << 40, "1099511627776L"},
+		{"float", float32(1.5), "1.5f"},
+		{"double", 0.25, "0.25d"},
+		{"string", "a \"quoted\"\nline", `"a \"quoted\"\nline"`},
+		{"byte array", [3]byte{1, 200, 0}, "[B; 1b, -56b, 0b]"},
+		{"int array", [2]int32{-1, 2}, "[I; -1, 2]"},
+		{"long array", [1]int64{3}, "[L; 3L]"},
+		{"empty list", []any{}, "[]"},
+		{"byte list", []byte{1, 255}, "[1b, -1b]"},
+		{"string list", []any{"a", "b"}, `["a", "b"]`},
+		{"nested lists", []any{[]int32{1}, []byte{2}}, "[[1], [2b]]"},
+		{
+			"compound",
+			map[string]any{
+				"Name":  "minecraft:apple",
+				"Count": byte(1),
+				"tag":   map[string]any{"display name": "x", "list": []any{map[string]any{}}},
+			},
+			`{Count: 1b, Name: "minecraft:apple", tag: {"display name": "x", list: [{}]}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snbt, err := MarshalSNBT(test.value)
+			if err != nil {
+				t.Fatalf("error marshaling %#v: %v", test.value, err)
+			}
+			if snbt != test.snbt {
+				t.Fatalf("marshaled %#v to %q, expected %q", test.value, snbt, test.snbt)
+			}
+
+			var value any
+			if err := UnmarshalSNBT(snbt, &value); err != nil {
+				t.Fatalf("error unmarshaling %q: %v", snbt, err)
+			}
+			again, err := MarshalSNBT(value)
+			if err != nil {
+				t.Fatalf("error marshaling %#v: %v", value, err)
+			}
+			if again != snbt {
+				t.Fatalf("round-trip of %q produced %q", snbt, again)
+			}
+		})
+	}
+}
+
+func TestUnmarshalSNBT(t *testing.T) {
+	tests := []struct {
+		snbt     string
+		expected any
+	}{
+		{"[B ;1b, 2b]", [2]byte{1, 2}},
+		{"[ I ; 1 , 2 ]", [2]int32{1, 2}},
+		{"[B; 255b]", [1]byte{255}},
+		{"200b", byte(200)},
+		{"[B, I]", []any{"B", "I"}},
+		{"true", byte(1)},
+		{"{'a': 'b'}", map[string]any{"a": "b"}},
+	}
+
+	for _, test := range tests {
+		var value any
+		if err := UnmarshalSNBT(test.snbt, &value); err != nil {
+			t.Fatalf("error unmarshaling %q: %v", test.snbt, err)
+		}
+		if !reflect.DeepEqual(value, test.expected) {
+			t.Fatalf("unmarshaled %q to %#v, expected %#v", test.snbt, value, test.expected)
+		}
+	}
+
+	for _, snbt := range []string{"[1, 1b]", "[B; 1b", "{a 1}", "[I; 1s]"} {
+		var value any
+		if err := UnmarshalSNBT(snbt, &value); err == nil {
+			t.Fatalf("expected an error unmarshaling %q, got %#v", snbt, value)
+		}
+	}
+}
@@ -84,6 +84,7 @@ func (b *BotClick) clickBlock(
 	request UseItemOnBlocks,
 	blockFace int32,
 	position mgl32.Vec3,
+	clickedPosition mgl32.Vec3,
 ) error {
 	// Step 1: 取得被点击方块的方块运行时 ID
 	blockRuntimeID, found := block.StateToRuntimeID(request.BlockName, request.BlockStates)
@@ -115,6 +116,7 @@ func (b *BotClick) clickBlock(
 			HotBarSlot:         int32(request.HotbarSlotID),
 			HeldItem:           *item,
 			Position:           position,
+			ClickedPosition:    clickedPosition,
 			BlockRuntimeID:     blockRuntimeID,
 		},
 	})
@@ -161,7 +163,7 @@ func (b *BotClick) ClickBlockWitchPosition(
 	request UseItemOnBlocks,
 	position mgl32.Vec3,
 ) error {
-	err := b.clickBlock(request, 0, position)
+	err := b.clickBlock(request, 0, position, mgl32.Vec3{})
 	if err != nil {
 		return fmt.Errorf("ClickBlockWitchPosition: %v", err)
 	}
@@ -179,13 +181,37 @@ func (b *BotClick) ClickBlockWitchPosition(
 此函数不会自动切换物品栏，但会等待租赁服响应更改
 */
 func (b *BotClick) ClickBlock(request UseItemOnBlocks) error {
-	err := b.clickBlock(request, 0, mgl32.Vec3{})
+	err := b.clickBlock(request, 0, mgl32.Vec3{}, mgl32.Vec3{})
 	if err != nil {
 		return fmt.Errorf("ClickBlock: %v", err)
 	}
 	return nil
 }
 
+/*
+让客户端点击 request 所指代方块的 blockFace 面，
+并且指定点击的位置为 clickedPosition 。
+
+clickedPosition 是点击位置相对于被点击方块
+西北下角的偏移量，其每个分量都应在 0 到 1 之间。
+
+该函数在通常情况下被用于那些与点击位置有关的方块，
+例如将书放入雕纹书架的特定槽位。
+
+此函数不会自动切换物品栏，但会等待租赁服响应更改
+*/
+func (b *BotClick) ClickBlockFace(
+	request UseItemOnBlocks,
+	blockFace int32,
+	clickedPosition mgl32.Vec3,
+) error {
+	err := b.clickBlock(request, blockFace, request.BotPos, clickedPosition)
+	if err != nil {
+		return fmt.Errorf("ClickBlockFace: %v", err)
+	}
+	return nil
+}
+
 // 使用快捷栏 hotbarSlotID 进行一次空点击操作。
 // realPosition 指示机器人在操作时的实际位置。
 // 此函数不会自动切换物品栏，但会等待租赁服响应更改
@@ -239,7 +265,7 @@ func (b *BotClick) PlaceBlock(
 	request UseItemOnBlocks,
 	blockFace int32,
 ) error {
-	err := b.clickBlock(request, blockFace, mgl32.Vec3{})
+	err := b.clickBlock(request, blockFace, mgl32.Vec3{}, mgl32.Vec3{})
 	if err != nil {
 		return fmt.Errorf("PlaceBlock: %v", err)
 	}
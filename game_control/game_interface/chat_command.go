@@ -0,0 +1,365 @@
+package game_interface
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+
+	"github.com/google/uuid"
+	"github.com/pterm/pterm"
+)
+
+// DefaultChatCommandPrefix 是聊天命令的默认前缀
+const DefaultChatCommandPrefix = "!"
+
+// ChatCommandSender 是聊天命令的发送者。
+//
+// XUID 是由租赁服担保的发送者身份。没有 XUID 的发送者
+// (例如通过翻译消息得到的聊天或私聊) 的名称可以被任意伪造，
+// 因此不被允许使用任何聊天命令
+type ChatCommandSender struct {
+	Name    string    // 发送者的名称
+	UUID    uuid.UUID // 发送者的 UUID。如果发送者不在玩家列表中，则为零值
+	XUID    string    // 发送者的 XUID。如果无法确认发送者的身份，则为空
+	Whisper bool      // 命令是否是通过私聊发送的
+}
+
+// ChatCommandRequest 是单次聊天命令的调用
+type ChatCommandRequest struct {
+	Sender  ChatCommandSender // 命令的发送者
+	Command string            // 被调用的命令的名称
+	Args    []string          // 命令名称之后的参数
+
+	c *ChatCommands
+}
+
+// Reply 在聊天栏中回复 message
+func (r ChatCommandRequest) Reply(message string) error {
+	err := r.c.commands.SendChat(message)
+	if err != nil {
+		return fmt.Errorf("Reply: %v", err)
+	}
+	return nil
+}
+
+// Title 以 actionbar 的形式向所有在线玩家显示 message
+func (r ChatCommandRequest) Title(message string) error {
+	err := r.c.commands.Title(message)
+	if err != nil {
+		return fmt.Errorf("Title: %v", err)
+	}
+	return nil
+}
+
+// ChatCommandHandler 处理单次聊天命令的调用。
+// 如果返回了错误，则错误将被回复给发送者。
+//
+// 由 Start 接收的每条命令都在独立的协程中处理，
+// 因此处理函数可能被并发调用，它应当自行保护共享的状态
+type ChatCommandHandler func(request ChatCommandRequest) error
+
+// ChatCommand 是可以通过聊天栏调用的命令
+type ChatCommand struct {
+	// Name 是命令的名称，它可以由多个单词组成，
+	// 例如 "cache stats"。命令的名称不区分大小写
+	Name string
+	// Usage 是命令参数的用法，例如 "<file>"
+	Usage string
+	// Description 是命令的简短描述
+	Description string
+	// Handler 是命令的处理函数
+	Handler ChatCommandHandler
+}
+
+// ChatCommands 是面向玩家的聊天命令前端。
+//
+// 被允许的玩家可以在聊天栏中发送以前缀开头的命令，
+// 或直接通过私聊向机器人发送命令。命令的处理函数
+// 是可插拔的，因此下游项目可以注册自己的命令。
+//
+// ChatCommands 是可选的，只有在调用 Start 后
+// 才会开始处理聊天消息
+type ChatCommands struct {
+	mu       *sync.Mutex
+	api      *ResourcesWrapper
+	commands *Commands
+
+	prefix   string
+	allowed  map[string]bool
+	handlers map[string]ChatCommand
+
+	subscription *resources_control.EventSubscription
+}
+
+// NewChatCommands 基于 api 和 commands 创建并返回一个新的 ChatCommands。
+// 新的 ChatCommands 只注册了 help 和 status 命令，且不允许任何玩家使用
+func NewChatCommands(api *ResourcesWrapper, commands *Commands) *ChatCommands {
+	c := &ChatCommands{
+		mu:       new(sync.Mutex),
+		api:      api,
+		commands: commands,
+		prefix:   DefaultChatCommandPrefix,
+		allowed:  make(map[string]bool),
+		handlers: make(map[string]ChatCommand),
+	}
+
+	_ = c.Register(ChatCommand{
+		Name:        "help",
+		Description: "列出所有可用的命令",
+		Handler:     c.commandHelp,
+	})
+	_ = c.Register(ChatCommand{
+		Name:        "status",
+		Description: "显示机器人的状态",
+		Handler:     c.commandStatus,
+	})
+
+	return c
+}
+
+// normalizeChatCommandName 返回规范化的命令名称
+func normalizeChatCommandName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// SetPrefix 设置在聊天栏中调用命令时所使用的前缀。
+// 通过私聊发送的命令可以省略前缀
+func (c *ChatCommands) SetPrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prefix = prefix
+}
+
+// Allow 允许名称、UUID 或 XUID 为 player 的玩家使用聊天命令
+func (c *ChatCommands) Allow(player string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.allowed[strings.ToLower(player)] = true
+}
+
+// Disallow 撤销名称、UUID 或 XUID 为 player 的玩家使用聊天命令的权限
+func (c *ChatCommands) Disallow(player string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.allowed, strings.ToLower(player))
+}
+
+// Allowed 检查 sender 是否被允许使用聊天命令。
+// 无法确认身份 (即没有 XUID) 的发送者总是不被允许的
+func (c *ChatCommands) Allowed(sender ChatCommandSender) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(sender.XUID) == 0 {
+		return false
+	}
+	if c.allowed[strings.ToLower(sender.Name)] || c.allowed[strings.ToLower(sender.XUID)] {
+		return true
+	}
+	return sender.UUID != uuid.Nil && c.allowed[sender.UUID.String()]
+}
+
+// Register 注册命令 command。
+// 如果同名的命令已被注册，则返回错误
+func (c *ChatCommands) Register(command ChatCommand) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := normalizeChatCommandName(command.Name)
+	if len(name) == 0 || command.Handler == nil {
+		return fmt.Errorf("Register: Command name or handler is empty")
+	}
+	if _, ok := c.handlers[name]; ok {
+		return fmt.Errorf("Register: Command %#v is already registered", name)
+	}
+
+	command.Name = name
+	c.handlers[name] = command
+	return nil
+}
+
+// Unregister 撤销名为 name 的命令。
+// 如果这样的命令不存在，则不会执行任何操作
+func (c *ChatCommands) Unregister(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.handlers, normalizeChatCommandName(name))
+}
+
+// Commands 返回所有已注册的命令，它们按名称排序
+func (c *ChatCommands) Commands() (result []ChatCommand) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, command := range c.handlers {
+		result = append(result, command)
+	}
+	slices.SortFunc(result, func(a, b ChatCommand) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return
+}
+
+// Start 开始处理聊天栏和私聊中的命令。
+// 每条命令都在独立的协程中被处理。
+// 如果已经开始，则不会执行任何操作
+func (c *ChatCommands) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subscription != nil {
+		return nil
+	}
+	subscription, err := c.api.Events().Subscribe(
+		nil, 0,
+		resources_control.EventTypeChat,
+		resources_control.EventTypeWhisper,
+	)
+	if err != nil {
+		return fmt.Errorf("Start: %v", err)
+	}
+	c.subscription = subscription
+
+	go func() {
+		for event := range subscription.Events() {
+			var sender ChatCommandSender
+			var message string
+
+			switch e := event.(type) {
+			case resources_control.ChatEvent:
+				sender, message = ChatCommandSender{Name: e.Sender, XUID: e.XUID}, e.Message
+			case resources_control.WhisperEvent:
+				sender, message = ChatCommandSender{Name: e.Sender, XUID: e.XUID, Whisper: true}, e.Message
+			default:
+				continue
+			}
+
+			sender.Name = utils.StripFormatting(sender.Name)
+			if sender.Name == c.api.BotName {
+				continue
+			}
+			// 发送者的身份只以租赁服给出的 XUID 为准，
+			// 而不能通过可以被伪造的名称在玩家列表中查找
+			if player, found := c.api.Entities().PlayerByXUID(sender.XUID); found {
+				sender.Name, sender.UUID = player.Username, player.UUID
+			}
+
+			// 每条命令都在独立的协程中处理，这使得耗时较长的
+			// 命令不会阻塞其他命令，也不会因为事件缓冲区被填满
+			// 而丢弃之后到达的命令
+			go func() {
+				if _, err := c.Dispatch(sender, message); err != nil {
+					pterm.Warning.Printfln("ChatCommands: %v", err)
+				}
+			}()
+		}
+	}()
+
+	return nil
+}
+
+// Stop 停止处理聊天命令
+func (c *ChatCommands) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subscription == nil {
+		return
+	}
+	c.api.Events().Unsubscribe(c.subscription.UniqueID())
+	c.subscription = nil
+}
+
+// parse 将 message 解析为命令及其参数。
+// 如果 message 不是命令，则 isCommand 为假
+func (c *ChatCommands) parse(sender ChatCommandSender, message string) (
+	command ChatCommand,
+	args []string,
+	found bool,
+	isCommand bool,
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	message = strings.TrimSpace(utils.StripFormatting(message))
+	if trimmed, ok := strings.CutPrefix(message, c.prefix); ok && len(c.prefix) > 0 {
+		message = trimmed
+	} else if !sender.Whisper {
+		return ChatCommand{}, nil, false, false
+	}
+
+	// 优先匹配最长的命令名称
+	fields := strings.Fields(message)
+	for length := len(fields); length > 0; length-- {
+		name := normalizeChatCommandName(strings.Join(fields[:length], " "))
+		if command, ok := c.handlers[name]; ok {
+			return command, fields[length:], true, true
+		}
+	}
+
+	return ChatCommand{}, nil, false, len(fields) > 0
+}
+
+// Dispatch 将 sender 发送的 message 视为聊天命令并执行。
+// 通常情况下，它由 Start 所启动的协程调用，但使用者也
+// 可以手动调用它，例如用于从其他来源接收命令。
+// 此时调用者应当自行确认发送者的身份，并设置其 XUID。
+//
+// 如果 message 不是命令，则 handled 为假。
+// 否则，命令的结果 (包括权限不足等) 将被回复给发送者
+func (c *ChatCommands) Dispatch(sender ChatCommandSender, message string) (handled bool, err error) {
+	command, args, found, isCommand := c.parse(sender, message)
+	if !isCommand {
+		return false, nil
+	}
+
+	request := ChatCommandRequest{
+		Sender:  sender,
+		Command: command.Name,
+		Args:    args,
+		c:       c,
+	}
+
+	if !c.Allowed(sender) {
+		err = request.Reply(fmt.Sprintf("%s: 您没有使用机器人命令的权限", sender.Name))
+	} else if !found {
+		err = request.Reply(fmt.Sprintf("%s: 未知的命令，请使用 help 查看所有可用的命令", sender.Name))
+	} else if handlerErr := command.Handler(request); handlerErr != nil {
+		err = request.Reply(fmt.Sprintf("%s: 命令 %s 执行失败: %v", sender.Name, command.Name, handlerErr))
+	}
+	if err != nil {
+		return true, fmt.Errorf("Dispatch: %v", err)
+	}
+
+	return true, nil
+}
+
+// commandHelp 实现 help 命令
+func (c *ChatCommands) commandHelp(request ChatCommandRequest) error {
+	lines := []string{"可用的命令:"}
+	for _, command := range c.Commands() {
+		line := command.Name
+		if len(command.Usage) > 0 {
+			line += " " + command.Usage
+		}
+		if len(command.Description) > 0 {
+			line += " - " + command.Description
+		}
+		lines = append(lines, line)
+	}
+	return request.Reply(strings.Join(lines, "\n"))
+}
+
+// commandStatus 实现 status 命令
+func (c *ChatCommands) commandStatus(request ChatCommandRequest) error {
+	_, _, containerOpened := c.api.Container().ContainerData()
+	return request.Reply(fmt.Sprintf(
+		"机器人 %s 在线，在线玩家 %d 名，已知实体 %d 个，容器%s",
+		c.api.BotName,
+		len(c.api.Entities().Players()),
+		len(c.api.Entities().Entities()),
+		map[bool]string{true: "已打开", false: "未打开"}[containerOpened],
+	))
+}
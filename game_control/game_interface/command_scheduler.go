@@ -0,0 +1,193 @@
+package game_interface
+
+import (
+	"sync"
+	"time"
+)
+
+// CommandLane 指示命令在调度器中所处的优先级通道
+type CommandLane uint8
+
+const (
+	// CommandLaneInteractive 是交互式通道。
+	// 需要等待租赁服响应的命令默认位于此通道，
+	// 并且总是优先于批量通道中的命令被发送
+	CommandLaneInteractive CommandLane = iota
+	// CommandLaneBulk 是批量通道。
+	// 无需等待响应的命令 (例如 Sizukana 命令) 默认位于此通道
+	CommandLaneBulk
+)
+
+const (
+	// DefaultCommandsPerTick 是每个游戏刻默认允许发送的命令数量
+	DefaultCommandsPerTick = 20
+	// DefaultMaxInFlightCommands 是默认允许的，
+	// 已发出但尚未得到响应的命令的最大数量
+	DefaultMaxInFlightCommands = 64
+	// DefaultCommandRetryTimes 是命令请求超时后的默认重试次数。
+	// 只有被调用者标记为可重复执行的命令才会被重试，
+	// 因此默认的重试次数对其他命令没有影响
+	DefaultCommandRetryTimes = 2
+	// commandSchedulerTick 是调度器计算命令预算的时间单位，
+	// 即一个游戏刻
+	commandSchedulerTick = time.Second / 20
+)
+
+// CommandSchedulerConfig 是命令调度器的配置
+type CommandSchedulerConfig struct {
+	// CommandsPerTick 是每个游戏刻允许发送的命令数量。
+	// 如果为 0 或负数，则不限制命令的发送速率
+	CommandsPerTick int
+	// MaxInFlight 是已发出但尚未得到响应的命令的最大数量。
+	// 如果为 0 或负数，则不限制此数量
+	MaxInFlight int
+	// RetryTimes 是命令请求超时后重新发送的次数。
+	// 只有被调用者标记为可重复执行的命令才会被重试，
+	// 因为重试可能导致同一命令在租赁服被执行多次
+	RetryTimes int
+}
+
+// DefaultCommandSchedulerConfig 返回命令调度器的默认配置
+func DefaultCommandSchedulerConfig() CommandSchedulerConfig {
+	return CommandSchedulerConfig{
+		CommandsPerTick: DefaultCommandsPerTick,
+		MaxInFlight:     DefaultMaxInFlightCommands,
+		RetryTimes:      DefaultCommandRetryTimes,
+	}
+}
+
+// commandScheduler 是 Commands 所使用的命令调度器。
+//
+// 调度器以游戏刻为单位分配命令预算，
+// 并限制已发出但尚未得到响应的命令数量。
+// 预算耗尽时，调用者将被阻塞直到下一个游戏刻，
+// 这为异步调用者提供了背压。
+//
+// 交互式通道中的等待者总是先于批量通道中的等待者得到预算
+type commandScheduler struct {
+	mu   *sync.Mutex
+	cond *sync.Cond
+
+	config    CommandSchedulerConfig
+	startTime time.Time
+
+	tick       int64
+	used       int
+	inFlight   int
+	waiting    [2]int
+	timerArmed bool
+}
+
+// newCommandScheduler 根据 config 创建并返回一个新的命令调度器
+func newCommandScheduler(config CommandSchedulerConfig) *commandScheduler {
+	mu := new(sync.Mutex)
+	return &commandScheduler{
+		mu:        mu,
+		cond:      sync.NewCond(mu),
+		config:    config,
+		startTime: time.Now(),
+	}
+}
+
+// refresh 在进入新的游戏刻时重置命令预算。
+// 调用者应当持有锁
+func (s *commandScheduler) refresh() {
+	tick := int64(time.Since(s.startTime) / commandSchedulerTick)
+	if tick != s.tick {
+		s.tick = tick
+		s.used = 0
+	}
+}
+
+// budgetExhausted 检查当前游戏刻的命令预算是否已耗尽。
+// 调用者应当持有锁
+func (s *commandScheduler) budgetExhausted() bool {
+	s.refresh()
+	return s.config.CommandsPerTick > 0 && s.used >= s.config.CommandsPerTick
+}
+
+// available 检查位于 lane 通道的命令现在是否可以被发送。
+// needResponse 指示该命令是否需要占用一个在途名额。
+// 调用者应当持有锁
+func (s *commandScheduler) available(lane CommandLane, needResponse bool) bool {
+	if lane == CommandLaneBulk && s.waiting[CommandLaneInteractive] > 0 {
+		return false
+	}
+	if s.budgetExhausted() {
+		return false
+	}
+	if needResponse && s.config.MaxInFlight > 0 && s.inFlight >= s.config.MaxInFlight {
+		return false
+	}
+	return true
+}
+
+// armTimer 确保在下一个游戏刻到来时唤醒所有等待者。
+// 调用者应当持有锁
+func (s *commandScheduler) armTimer() {
+	if s.timerArmed {
+		return
+	}
+	s.timerArmed = true
+
+	next := time.Duration(s.tick+1)*commandSchedulerTick - time.Since(s.startTime)
+	time.AfterFunc(max(next, 0), func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.timerArmed = false
+		s.cond.Broadcast()
+	})
+}
+
+// acquire 阻塞直到位于 lane 通道的命令可以被发送。
+// 如果 needResponse 为真，则该命令还将占用一个在途名额，
+// 调用者应当在得到响应或超时后调用 release 以归还
+func (s *commandScheduler) acquire(lane CommandLane, needResponse bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.waiting[lane]++
+	for !s.available(lane, needResponse) {
+		if s.budgetExhausted() {
+			s.armTimer()
+		}
+		s.cond.Wait()
+	}
+	s.waiting[lane]--
+
+	s.used++
+	if needResponse {
+		s.inFlight++
+	}
+	s.cond.Broadcast()
+}
+
+// release 归还由 acquire 占用的在途名额
+func (s *commandScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	s.cond.Broadcast()
+}
+
+// retryTimes 返回命令请求超时后的重试次数
+func (s *commandScheduler) retryTimes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return max(s.config.RetryTimes, 0)
+}
+
+// setConfig 将调度器的配置更新为 config
+func (s *commandScheduler) setConfig(config CommandSchedulerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+	s.cond.Broadcast()
+}
+
+// getConfig 返回调度器当前的配置
+func (s *commandScheduler) getConfig() CommandSchedulerConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config
+}
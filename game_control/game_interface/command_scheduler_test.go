@@ -0,0 +1,158 @@
+package game_interface
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcpol-studio/flowers-for-machines/client"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/mock_server"
+)
+
+// waitUntil 等待 condition 在调度器的锁下返回真
+func waitUntil(t *testing.T, s *commandScheduler, condition func() bool) {
+	deadline := time.Now().Add(time.Second * 5)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		ok := condition()
+		s.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("waitUntil: Time out")
+}
+
+func TestCommandSchedulerLanePriority(t *testing.T) {
+	s := newCommandScheduler(CommandSchedulerConfig{MaxInFlight: 1})
+	s.acquire(CommandLaneBulk, true)
+
+	var (
+		mu    sync.Mutex
+		order []CommandLane
+		wg    sync.WaitGroup
+	)
+	enter := func(lane CommandLane) {
+		defer wg.Done()
+		s.acquire(lane, true)
+		mu.Lock()
+		order = append(order, lane)
+		mu.Unlock()
+		s.release()
+	}
+
+	// 批量通道的等待者先于交互式通道的等待者开始等待
+	wg.Add(2)
+	go enter(CommandLaneBulk)
+	waitUntil(t, s, func() bool { return s.waiting[CommandLaneBulk] == 1 })
+	go enter(CommandLaneInteractive)
+	waitUntil(t, s, func() bool { return s.waiting[CommandLaneInteractive] == 1 })
+
+	s.release()
+	wg.Wait()
+
+	if len(order) != 2 || order[0] != CommandLaneInteractive || order[1] != CommandLaneBulk {
+		t.Fatalf("TestCommandSchedulerLanePriority: Unexpected order %v", order)
+	}
+}
+
+func TestCommandSchedulerBudget(t *testing.T) {
+	s := newCommandScheduler(CommandSchedulerConfig{CommandsPerTick: 2})
+
+	startTime := time.Now()
+	for range 6 {
+		s.acquire(CommandLaneBulk, false)
+	}
+	// 6 条命令至少需要跨越 2 个游戏刻
+	if elapsed := time.Since(startTime); elapsed < commandSchedulerTick {
+		t.Fatalf("TestCommandSchedulerBudget: Commands are sent too fast (%v)", elapsed)
+	}
+}
+
+// newMockCommands 启动一个模拟租赁服并返回该模拟租赁服和登录后的 Commands。
+// 对于使 drop 返回真的命令，模拟租赁服不会返回其输出
+func newMockCommands(t *testing.T, drop func(commandLine string) bool) (*mock_server.Server, *Commands) {
+	server, err := mock_server.NewServer(mock_server.Config{DropCommandOutput: drop})
+	if err != nil {
+		t.Fatalf("newMockCommands: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+
+	c, err := client.LoginMockServer(server.Authenticator())
+	if err != nil {
+		t.Fatalf("newMockCommands: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Conn().Close() })
+
+	return server, NewGameInterface(resources_control.NewResourcesControl(c)).Commands()
+}
+
+func TestCommandSchedulerRetry(t *testing.T) {
+	var setblockCount, testCount, queryCount atomic.Int32
+	_, commands := newMockCommands(t, func(commandLine string) bool {
+		switch {
+		case strings.HasPrefix(commandLine, "setblock"):
+			setblockCount.Add(1)
+			return true
+		case strings.HasPrefix(commandLine, "querytarget"):
+			queryCount.Add(1)
+			return true
+		case strings.HasPrefix(commandLine, "testforblock"):
+			// 只丢弃前两次检测
+			return testCount.Add(1) <= 2
+		}
+		return false
+	})
+	options := CommandOptions{Timeout: time.Millisecond * 200}
+	repeatable := options
+	repeatable.Repeatable = true
+
+	// 不可重复执行的命令只会被发送一次
+	_, isTimeOut, err := commands.SendWSCommandWithOptions("setblock 0 0 0 stone", options)
+	if !isTimeOut || err == nil || setblockCount.Load() != 1 {
+		t.Fatalf("TestCommandSchedulerRetry: Unexpected result %v %v (count = %d)", isTimeOut, err, setblockCount.Load())
+	}
+
+	// 默认情况下，可重复执行的命令在超时后被重试
+	resp, isTimeOut, err := commands.SendWSCommandWithOptions("testforblock 0 0 0 air", repeatable)
+	if isTimeOut || err != nil || resp == nil || testCount.Load() != 3 {
+		t.Fatalf("TestCommandSchedulerRetry: Unexpected result %v %v (count = %d)", isTimeOut, err, testCount.Load())
+	}
+
+	// 重试可以通过调度器的配置关闭
+	config := commands.SchedulerConfig()
+	config.RetryTimes = 0
+	commands.SetSchedulerConfig(config)
+	_, isTimeOut, err = commands.SendWSCommandWithOptions("querytarget @s", repeatable)
+	if !isTimeOut || err == nil || queryCount.Load() != 1 {
+		t.Fatalf("TestCommandSchedulerRetry: Unexpected result %v %v (count = %d)", isTimeOut, err, queryCount.Load())
+	}
+}
+
+func TestCommandSchedulerTimeout(t *testing.T) {
+	_, commands := newMockCommands(t, func(commandLine string) bool {
+		return commandLine == "list"
+	})
+
+	startTime := time.Now()
+	_, isTimeOut, err := commands.SendWSCommandWithOptions(
+		"list",
+		CommandOptions{Lane: CommandLaneBulk, Timeout: time.Millisecond * 200},
+	)
+	if !isTimeOut || err == nil {
+		t.Fatalf("TestCommandSchedulerTimeout: Expected time out, but got %v %v", isTimeOut, err)
+	}
+	if elapsed := time.Since(startTime); elapsed < time.Millisecond*200 || elapsed > time.Second*2 {
+		t.Fatalf("TestCommandSchedulerTimeout: Unexpected elapsed time %v", elapsed)
+	}
+
+	// 超时的命令归还了在途名额，因此后续的命令仍然可以被发送
+	waitUntil(t, commands.scheduler, func() bool { return commands.scheduler.inFlight == 0 })
+	if _, _, err = commands.SendWSCommandWithTimeout("testforblock 0 0 0 air", 0); err != nil {
+		t.Fatalf("TestCommandSchedulerTimeout: %v", err)
+	}
+}
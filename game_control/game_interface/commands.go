@@ -21,6 +21,20 @@ const (
 	DefaultAwaitChangesCount = 2
 )
 
+// CommandOptions 是发送需要响应的命令时的选项
+type CommandOptions struct {
+	// Lane 是命令在调度器中所处的通道。
+	// 零值为交互式通道
+	Lane CommandLane
+	// Timeout 指示超时处理。如果为负数则不考虑超时因素；
+	// 如果为 0 则使用默认超时设置
+	Timeout time.Duration
+	// Repeatable 指示命令是否可以被安全地执行多次，
+	// 例如 querytarget 和 testforblocks 等只读命令。
+	// 只有可重复执行的命令才会在超时后按照调度器的配置重试
+	Repeatable bool
+}
+
 // Commands 是基于 ResourcesWrapper
 // 实现的 MC 指令操作器，例如发送命令
 // 并得到其响应体。
@@ -28,14 +42,30 @@ const (
 // 另外，出于对旧时代的尊重和可能的兼容性，
 // 一些遗留实现也被同时迁移到此处
 type Commands struct {
-	api *ResourcesWrapper
+	api       *ResourcesWrapper
+	scheduler *commandScheduler
 }
 
 // ------------------------- Basic function -------------------------
 
 // NewCommands 基于 api 创建并返回一个新的 Commands
 func NewCommands(api *ResourcesWrapper) *Commands {
-	return &Commands{api: api}
+	return &Commands{
+		api:       api,
+		scheduler: newCommandScheduler(DefaultCommandSchedulerConfig()),
+	}
+}
+
+// SetSchedulerConfig 将命令调度器的配置更新为 config。
+// 所有命令 (包括异步命令) 都将经过该调度器发送，
+// 因此调用者不再需要自行控制命令的发送速率
+func (c *Commands) SetSchedulerConfig(config CommandSchedulerConfig) {
+	c.scheduler.setConfig(config)
+}
+
+// SchedulerConfig 返回命令调度器当前的配置
+func (c *Commands) SchedulerConfig() CommandSchedulerConfig {
+	return c.scheduler.getConfig()
 }
 
 // packCommandRequest 根据给定的命令 command，
@@ -60,6 +90,16 @@ func packCommandRequest(command string, origin uint32, requestID uuid.UUID) *pac
 // 当 dimensional 为真时，
 // 将使用 execute 更换命令执行环境为机器人所在的环境
 func (c *Commands) SendSettingsCommand(command string, dimensional bool) error {
+	err := c.SendSettingsCommandInLane(command, dimensional, CommandLaneBulk)
+	if err != nil {
+		return fmt.Errorf("SendSettingsCommand: %v", err)
+	}
+	return nil
+}
+
+// SendSettingsCommandInLane 与 SendSettingsCommand 相同，
+// 但命令将经过调度器的 lane 通道发送
+func (c *Commands) SendSettingsCommandInLane(command string, dimensional bool, lane CommandLane) error {
 	api := c.api
 
 	if dimensional {
@@ -70,12 +110,13 @@ func (c *Commands) SendSettingsCommand(command string, dimensional bool) error {
 		)
 	}
 
+	c.scheduler.acquire(lane, false)
 	err := api.WritePacket(&packet.SettingsCommand{
 		CommandLine:    command,
 		SuppressOutput: true,
 	})
 	if err != nil {
-		return fmt.Errorf("SendSettingsCommand: %v", err)
+		return fmt.Errorf("SendSettingsCommandInLane: %v", err)
 	}
 
 	return nil
@@ -85,6 +126,7 @@ func (c *Commands) SendSettingsCommand(command string, dimensional bool) error {
 
 // sendCommand 以 origin 的身份向租赁服发送命令 command 并无视返回值
 func (c *Commands) sendCommand(command string, origin uint32) error {
+	c.scheduler.acquire(CommandLaneBulk, false)
 	err := c.api.WritePacket(
 		packCommandRequest(
 			command, origin, uuid.New(),
@@ -116,10 +158,9 @@ func (c *Commands) SendWSCommand(command string) error {
 
 // ------------------------- Send command with response and timeout -------------------------
 
-// sendCommandWithResp 以 origin 的身份向租赁服发送命令 command 并获取响应体。
-// timeout 指示超时处理；如果为负数则不考虑超时因素；如果为 0 则使用默认超时设置。
-// 需要注意的是，如果命令请求超时，则返回的 err 不为空
-func (c *Commands) sendCommandWithResp(command string, origin uint32, timeout time.Duration) (
+// sendCommandWithRespOnce 以 origin 的身份向租赁服发送命令 command 并获取响应体。
+// 它是 sendCommandWithResp 的单次尝试，不会在超时后重试
+func (c *Commands) sendCommandWithRespOnce(command string, origin uint32, options CommandOptions) (
 	resp *packet.CommandOutput,
 	isTimeOut bool,
 	err error,
@@ -142,26 +183,26 @@ func (c *Commands) sendCommandWithResp(command string, origin uint32, timeout ti
 	)
 	defer api.Resources.Commands().DeleteCommandRequestCallback(requestID)
 
+	c.scheduler.acquire(options.Lane, true)
+	defer c.scheduler.release()
+
 	err = api.WritePacket(
 		packCommandRequest(
 			command, origin, requestID,
 		),
 	)
 	if err != nil {
-		return nil, false, fmt.Errorf("sendCommandWithResp: %v", err)
+		return nil, false, fmt.Errorf("sendCommandWithRespOnce: %v", err)
 	}
 
-	if timeout == 0 {
-		timeout = DefaultTimeoutCommandRequest
-	}
-	if timeout > 0 {
+	if timeout := options.Timeout; timeout > 0 {
 		timer := time.NewTimer(timeout)
 		defer timer.Stop()
 		select {
 		case <-channel:
 		case <-timer.C:
 			return nil, true, fmt.Errorf(
-				"sendCommandWithResp: Command request %#v (origin = %d) is time out (timeout = %v seconds)",
+				"sendCommandWithRespOnce: Command request %#v (origin = %d) is time out (timeout = %v seconds)",
 				command, origin, float64(timeout)/float64(time.Second),
 			)
 		}
@@ -169,7 +210,39 @@ func (c *Commands) sendCommandWithResp(command string, origin uint32, timeout ti
 	<-channel
 
 	if terminalErr != nil {
-		return nil, false, fmt.Errorf("sendCommandWithResp: %v", terminalErr)
+		return nil, false, fmt.Errorf("sendCommandWithRespOnce: %v", terminalErr)
+	}
+	return resp, false, nil
+}
+
+// sendCommandWithResp 以 origin 的身份向租赁服发送命令 command 并获取响应体。
+// options 指示命令所处的通道和超时处理等，详见 CommandOptions。
+//
+// 如果命令被标记为可重复执行，则命令请求超时后，
+// 将按照调度器的配置重新发送该命令。
+// 需要注意的是，如果命令请求最终超时，则返回的 err 不为空
+func (c *Commands) sendCommandWithResp(command string, origin uint32, options CommandOptions) (
+	resp *packet.CommandOutput,
+	isTimeOut bool,
+	err error,
+) {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeoutCommandRequest
+	}
+
+	retryTimes := 0
+	if options.Repeatable {
+		retryTimes = c.scheduler.retryTimes()
+	}
+	for attempt := 0; ; attempt++ {
+		resp, isTimeOut, err = c.sendCommandWithRespOnce(command, origin, options)
+		if !isTimeOut || attempt >= retryTimes {
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, isTimeOut, fmt.Errorf("sendCommandWithResp: %v", err)
 	}
 	return resp, false, nil
 }
@@ -183,7 +256,7 @@ func (c *Commands) SendPlayerCommandWithTimeout(command string, timeout time.Dur
 	isTimeOut bool,
 	err error,
 ) {
-	resp, isTimeOut, err = c.sendCommandWithResp(command, protocol.CommandOriginPlayer, max(0, timeout))
+	resp, isTimeOut, err = c.sendCommandWithResp(command, protocol.CommandOriginPlayer, CommandOptions{Timeout: max(0, timeout)})
 	if err != nil {
 		return nil, isTimeOut, fmt.Errorf("SendPlayerCommandWithTimeout: %v", err)
 	}
@@ -199,19 +272,49 @@ func (c *Commands) SendWSCommandWithTimeout(command string, timeout time.Duratio
 	isTimeOut bool,
 	err error,
 ) {
-	resp, isTimeOut, err = c.sendCommandWithResp(command, protocol.CommandOriginAutomationPlayer, max(0, timeout))
+	resp, isTimeOut, err = c.sendCommandWithResp(command, protocol.CommandOriginAutomationPlayer, CommandOptions{Timeout: max(0, timeout)})
 	if err != nil {
 		return nil, isTimeOut, fmt.Errorf("SendWSCommandWithTimeout: %v", err)
 	}
 	return
 }
 
+// SendPlayerCommandWithOptions 以玩家的身份向租赁服发送命令 command 并获取响应体。
+// options 指示命令所处的通道、超时处理以及命令是否可以被重试。
+// 需要注意的是，如果命令请求超时，则返回的 err 不为空
+func (c *Commands) SendPlayerCommandWithOptions(command string, options CommandOptions) (
+	resp *packet.CommandOutput,
+	isTimeOut bool,
+	err error,
+) {
+	resp, isTimeOut, err = c.sendCommandWithResp(command, protocol.CommandOriginPlayer, options)
+	if err != nil {
+		return nil, isTimeOut, fmt.Errorf("SendPlayerCommandWithOptions: %v", err)
+	}
+	return
+}
+
+// SendWSCommandWithOptions 以 Websocket 的身份向租赁服发送命令 command 并获取响应体。
+// options 指示命令所处的通道、超时处理以及命令是否可以被重试。
+// 需要注意的是，如果命令请求超时，则返回的 err 不为空
+func (c *Commands) SendWSCommandWithOptions(command string, options CommandOptions) (
+	resp *packet.CommandOutput,
+	isTimeOut bool,
+	err error,
+) {
+	resp, isTimeOut, err = c.sendCommandWithResp(command, protocol.CommandOriginAutomationPlayer, options)
+	if err != nil {
+		return nil, isTimeOut, fmt.Errorf("SendWSCommandWithOptions: %v", err)
+	}
+	return
+}
+
 // ------------------------- Send command with response and no timeout -------------------------
 
 // sendCommandWithRespNoTimeout 以 origin 的身份向租赁服发送命令 command 并获取响应体。
 // 区别于 sendCommandWithResp，此函数不考虑超时因素
 func (c *Commands) sendCommandWithRespNoTimeout(command string, origin uint32) (resp *packet.CommandOutput, err error) {
-	resp, _, err = c.sendCommandWithResp(command, origin, -1)
+	resp, _, err = c.sendCommandWithResp(command, origin, CommandOptions{Timeout: -1})
 	if err != nil {
 		return nil, fmt.Errorf("sendCommandWithRespNoTimeout: %v", err)
 	}
@@ -18,12 +18,14 @@ type GameInterface struct {
 	structureBackup       *StructureBackup
 	querytarget           *Querytarget
 	setblock              *SetBlock
+	region                *Region
 	replaceitem           *Replaceitem
 	botClick              *BotClick
 	itemStackOperation    *ItemStackOperation
 	containerOpenAndClose *ContainerOpenAndClose
 	itemCopy              *ItemCopy
 	itemTransition        *ItemTransition
+	chatCommands          *ChatCommands
 }
 
 // NewResourcesWrapper 基于 resources 创建一个新的游戏交互器
@@ -43,12 +45,14 @@ func NewGameInterface(resources *resources_control.Resources) *GameInterface {
 	result.structureBackup = NewStructureBackup(result.commands)
 	result.querytarget = NewQuerytarget(result.commands)
 	result.setblock = NewSetBlock(result.commands)
+	result.region = NewRegion(result.commands)
 	result.replaceitem = NewReplaceitem(result.commands)
 	result.botClick = NewBotClick(result.wrapper, result.commands, result.setblock)
-	result.itemStackOperation = NewItemStackOperation(result.wrapper)
+	result.itemStackOperation = NewItemStackOperation(result.wrapper, result.commands, result.replaceitem)
 	result.containerOpenAndClose = NewContainerOpenAndClose(result.wrapper, result.commands, result.botClick)
 	result.itemCopy = NewItemCopy(result.containerOpenAndClose, result.commands, result.itemStackOperation, result.structureBackup)
 	result.itemTransition = NewItemTransition(result.wrapper, result.itemStackOperation)
+	result.chatCommands = NewChatCommands(result.wrapper, result.commands)
 
 	return result
 }
@@ -88,6 +92,11 @@ func (g *GameInterface) SetBlock() *SetBlock {
 	return g.setblock
 }
 
+// Region 返回机器人在区域填充、复制和检查 (MC 命令的方式) 上的相关实现
+func (g *GameInterface) Region() *Region {
+	return g.region
+}
+
 // Replaceitem 返回机器人在 Replaceitem 命令上的简单包装
 func (g *GameInterface) Replaceitem() *Replaceitem {
 	return g.replaceitem
@@ -124,3 +133,9 @@ func (g *GameInterface) ItemCopy() *ItemCopy {
 func (g *GameInterface) ItemTransition() *ItemTransition {
 	return g.itemTransition
 }
+
+// ChatCommands 返回面向玩家的聊天命令前端。
+// 它默认不会启动，使用者需要手动调用其 Start 方法
+func (g *GameInterface) ChatCommands() *ChatCommands {
+	return g.chatCommands
+}
@@ -1,17 +1,36 @@
 package game_interface
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
 	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface/item_stack_transaction"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
 )
 
+// DefaultTimeoutResync 是 Resync 等待租赁服响应
+// 并重新发送库存数据的最长时间
+const DefaultTimeoutResync = time.Second * 5
+
 // ItemStackOperation 是物品操作请求的包装实现
 type ItemStackOperation struct {
-	api *ResourcesWrapper
+	api         *ResourcesWrapper
+	commands    *Commands
+	replaceitem *Replaceitem
 }
 
-// NewItemStackOperation 基于 api 创建并返回一个新的 ItemStackOperation
-func NewItemStackOperation(api *ResourcesWrapper) *ItemStackOperation {
-	return &ItemStackOperation{api: api}
+// NewItemStackOperation 基于 api、commands 和 replaceitem
+// 创建并返回一个新的 ItemStackOperation
+func NewItemStackOperation(api *ResourcesWrapper, commands *Commands, replaceitem *Replaceitem) *ItemStackOperation {
+	return &ItemStackOperation{
+		api:         api,
+		commands:    commands,
+		replaceitem: replaceitem,
+	}
 }
 
 // OpenTransaction 打开一个新的物品堆栈操作事务。
@@ -24,3 +43,174 @@ func NewItemStackOperation(api *ResourcesWrapper) *ItemStackOperation {
 func (i *ItemStackOperation) OpenTransaction() *item_stack_transaction.ItemStackTransaction {
 	return item_stack_transaction.NewItemStackTransaction(i.api.Resources)
 }
+
+// Restorer 返回基于 replaceitem 命令的物品恢复器，
+// 它可以用于 ItemStackTransaction.CommitWithRollback。
+//
+// 由于 replaceitem 命令的限制，它只能恢复物品的名称、
+// 数量、元数据和物品组件，而无法恢复其他的 NBT 数据。
+// 另外，已打开的容器只有在它是方块时才能被恢复
+func (i *ItemStackOperation) Restorer() item_stack_transaction.ItemRestorer {
+	return &replaceitemRestorer{i: i}
+}
+
+// replaceitemRestorer 是基于 replaceitem 命令的物品恢复器
+type replaceitemRestorer struct {
+	i *ItemStackOperation
+}
+
+// RestoreItem 使用 replaceitem 命令将 slotLocation 处的物品恢复为 item
+func (r *replaceitemRestorer) RestoreItem(slotLocation resources_control.SlotLocation, item protocol.ItemInstance) error {
+	var path ReplaceitemPath
+	api := r.i.api
+
+	info := ReplaceitemInfo{
+		Name:     "minecraft:air",
+		Count:    1,
+		MetaData: 0,
+		Slot:     slotLocation.SlotID,
+	}
+	if item.Stack.NetworkID != 0 && item.Stack.Count > 0 {
+		info.Name = api.ConstantPacket().ItemNameByNetworkID(item.Stack.NetworkID)
+		info.Count = uint8(item.Stack.Count)
+		info.MetaData = int16(item.Stack.MetadataValue)
+	}
+	method := utils.MarshalItemComponent(utils.ParseItemComponentNetwork(item.Stack))
+
+	switch slotLocation.WindowID {
+	case protocol.WindowIDInventory:
+		path = ReplacePathInventory
+	case protocol.WindowIDOffHand:
+		path, info.Slot = ReplacePathOffhand, 0
+	case protocol.WindowIDArmour:
+		paths := []ReplaceitemPath{ReplacePathArmorHead, ReplacePathArmorChest, ReplacePathArmorLegs, ReplacePathArmorFeet}
+		if int(slotLocation.SlotID) >= len(paths) {
+			return fmt.Errorf("RestoreItem: Armour slot %d is out of range", slotLocation.SlotID)
+		}
+		path, info.Slot = paths[slotLocation.SlotID], 0
+	default:
+		data, _, existed := api.Container().ContainerData()
+		if !existed || data.WindowID != byte(slotLocation.WindowID) || data.ContainerEntityUniqueID != -1 {
+			return fmt.Errorf("RestoreItem: Can not restore the item whose at %#v", slotLocation)
+		}
+		err := r.i.replaceitem.ReplaceitemInContainerAsync(data.ContainerPosition, info, method)
+		if err != nil {
+			return fmt.Errorf("RestoreItem: %v", err)
+		}
+		return nil
+	}
+
+	err := r.i.replaceitem.ReplaceitemInInventory("@s", path, info, method, true)
+	if err != nil {
+		return fmt.Errorf("RestoreItem: %v", err)
+	}
+	return nil
+}
+
+// Resync 等待租赁服将所有库存的变更同步到本地。
+//
+// replaceitem 命令的执行结果未必会及时同步到客户端，
+// 因此 Resync 还会发送一个必定被拒绝的物品堆栈操作请求，
+// 这将使租赁服重新发送背包 (和已打开容器) 的全部物品。
+// Resync 将等待这些窗口的 InventoryContent 数据包都被处理后才返回。
+//
+// 如果租赁服在 DefaultTimeoutResync 内没有响应
+// 或没有重新发送库存数据，则返回错误
+func (r *replaceitemRestorer) Resync() error {
+	api := r.i.api
+
+	err := r.i.commands.AwaitChangesGeneral()
+	if err != nil {
+		return fmt.Errorf("Resync: %v", err)
+	}
+
+	// 被拒绝的请求将使租赁服重新发送背包和已打开容器的全部物品
+	pending := map[uint32]bool{protocol.WindowIDInventory: true}
+	if data, _, existed := api.Container().ContainerData(); existed {
+		pending[uint32(data.WindowID)] = true
+	}
+
+	var mu sync.Mutex
+	var contentErr error
+	doOnce := new(sync.Once)
+	contentReceived := make(chan struct{})
+	packetListener := api.PacketListener()
+	uniqueID, err := packetListener.ListenPacket(
+		[]uint32{packet.IDInventoryContent},
+		func(p packet.Packet, connCloseErr error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if connCloseErr != nil {
+				contentErr = connCloseErr
+			} else {
+				delete(pending, p.(*packet.InventoryContent).WindowID)
+			}
+			if contentErr != nil || len(pending) == 0 {
+				doOnce.Do(func() {
+					close(contentReceived)
+				})
+			}
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("Resync: %v", err)
+	}
+	defer packetListener.DestroyListener(uniqueID)
+
+	// 数量为 0 的移动操作总是被拒绝
+	action := new(protocol.TakeStackRequestAction)
+	action.Source = protocol.StackRequestSlotInfo{ContainerID: protocol.ContainerCombinedHotBarAndInventory}
+	action.Destination = action.Source
+	requestID := api.ItemStackOperation().NewRequestID()
+	channel := make(chan error, 1)
+	api.ItemStackOperation().AddNewRequest(
+		requestID,
+		resources_control.ItemStackResponseMapping{
+			protocol.ContainerCombinedHotBarAndInventory: protocol.WindowIDInventory,
+		},
+		nil,
+		func(response *protocol.ItemStackResponse, connCloseErr error) {
+			channel <- connCloseErr
+		},
+	)
+	err = api.WritePacket(&packet.ItemStackRequest{
+		Requests: []protocol.ItemStackRequest{
+			{
+				RequestID:   int32(requestID),
+				Actions:     []protocol.StackRequestAction{action},
+				FilterCause: -1,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Resync: %v", err)
+	}
+
+	timer := time.NewTimer(DefaultTimeoutResync)
+	defer timer.Stop()
+	select {
+	case err = <-channel:
+		if err != nil {
+			return fmt.Errorf("Resync: %v", err)
+		}
+	case <-timer.C:
+		return fmt.Errorf("Resync: Item stack response is not received within %v", DefaultTimeoutResync)
+	}
+
+	// 租赁服在响应之后才发送库存数据，
+	// 因此需要等待受影响的窗口的库存数据都被处理
+	select {
+	case <-contentReceived:
+	case <-timer.C:
+		mu.Lock()
+		defer mu.Unlock()
+		return fmt.Errorf("Resync: Inventory content of %d window(s) is not received within %v", len(pending), DefaultTimeoutResync)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if contentErr != nil {
+		return fmt.Errorf("Resync: %v", contentErr)
+	}
+	return nil
+}
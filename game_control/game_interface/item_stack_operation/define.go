@@ -11,6 +11,7 @@ const (
 	IDItemStackOperationHighLevelLooming
 	IDItemStackOperationHighLevelCrafting
 	IDItemStackOperationHighLevelTrimming
+	IDItemStackOperationHighLevelMapLocking
 )
 
 // ItemStackOperation 指示所有实现了它的物品操作
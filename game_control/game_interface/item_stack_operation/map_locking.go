@@ -0,0 +1,94 @@
+package item_stack_operation
+
+import (
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+)
+
+// MapLocking 指示制图台的锁定地图操作
+type MapLocking struct {
+	MapItem    resources_control.SlotLocation
+	GlassPane  resources_control.SlotLocation
+	ResultItem resources_control.ExpectedNewItem
+}
+
+func (MapLocking) ID() uint8 {
+	return IDItemStackOperationHighLevelMapLocking
+}
+
+func (MapLocking) CanInline() bool {
+	return false
+}
+
+func (m MapLocking) Make(runtimeData MakingRuntime) []protocol.StackRequestAction {
+	data := runtimeData.(MapLockingRuntime)
+
+	moveMap := protocol.PlaceStackRequestAction{}
+	moveGlassPane := protocol.PlaceStackRequestAction{}
+	moveResult := protocol.TakeStackRequestAction{}
+
+	moveMap.Count = 1
+	moveMap.Source = protocol.StackRequestSlotInfo{
+		ContainerID:    data.MoveMapSrcContainerID,
+		Slot:           byte(m.MapItem.SlotID),
+		StackNetworkID: data.MoveMapSrcStackNetworkID,
+	}
+	moveMap.Destination = protocol.StackRequestSlotInfo{
+		ContainerID:    protocol.ContainerCartographyInput,
+		Slot:           0x0c,
+		StackNetworkID: data.MapStackNetworkID,
+	}
+
+	moveGlassPane.Count = 1
+	moveGlassPane.Source = protocol.StackRequestSlotInfo{
+		ContainerID:    data.MoveGlassPaneSrcContainerID,
+		Slot:           byte(m.GlassPane.SlotID),
+		StackNetworkID: data.MoveGlassPaneSrcStackNetworkID,
+	}
+	moveGlassPane.Destination = protocol.StackRequestSlotInfo{
+		ContainerID:    protocol.ContainerCartographyAdditional,
+		Slot:           0x0d,
+		StackNetworkID: data.GlassPaneStackNetworkID,
+	}
+
+	moveResult.Count = 1
+	moveResult.Source = protocol.StackRequestSlotInfo{
+		ContainerID:    protocol.ContainerCreatedOutput,
+		Slot:           0x32,
+		StackNetworkID: data.RequestID,
+	}
+	moveResult.Destination = protocol.StackRequestSlotInfo{
+		ContainerID:    data.MoveMapSrcContainerID,
+		Slot:           byte(m.MapItem.SlotID),
+		StackNetworkID: data.RequestID,
+	}
+
+	return []protocol.StackRequestAction{
+		&moveMap,
+		&moveGlassPane,
+		&protocol.CraftRecipeStackRequestAction{
+			RecipeNetworkID: data.RecipeNetworkID,
+		},
+		&protocol.ConsumeStackRequestAction{
+			DestroyStackRequestAction: protocol.DestroyStackRequestAction{
+				Count: 1,
+				Source: protocol.StackRequestSlotInfo{
+					ContainerID:    protocol.ContainerCartographyInput,
+					Slot:           0x0c,
+					StackNetworkID: data.RequestID,
+				},
+			},
+		},
+		&protocol.ConsumeStackRequestAction{
+			DestroyStackRequestAction: protocol.DestroyStackRequestAction{
+				Count: 1,
+				Source: protocol.StackRequestSlotInfo{
+					ContainerID:    protocol.ContainerCartographyAdditional,
+					Slot:           0x0d,
+					StackNetworkID: data.RequestID,
+				},
+			},
+		},
+		&moveResult,
+	}
+}
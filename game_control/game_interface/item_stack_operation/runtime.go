@@ -91,3 +91,17 @@ type TrimmingRuntime struct {
 	MoveTemplateSrcContainerID    byte
 	MoveTemplateSrcStackNetworkID int32
 }
+
+// MapLockingRuntime 是将制图台锁定地图操作内联为物品堆栈操作请求的运行时结构体
+type MapLockingRuntime struct {
+	RequestID       int32
+	RecipeNetworkID uint32
+
+	MapStackNetworkID        int32
+	MoveMapSrcContainerID    byte
+	MoveMapSrcStackNetworkID int32
+
+	GlassPaneStackNetworkID        int32
+	MoveGlassPaneSrcContainerID    byte
+	MoveGlassPaneSrcStackNetworkID int32
+}
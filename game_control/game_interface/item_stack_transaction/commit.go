@@ -21,6 +21,28 @@ func (i *ItemStackTransaction) Discord() *ItemStackTransaction {
 	return i
 }
 
+// splitOperations 将 operations 划分为若干物品堆栈操作请求。
+// 相邻的支持内联的操作将被划分到同一个请求中，
+// 而不支持内联的操作总是独占一个请求
+func splitOperations(operations []item_stack_operation.ItemStackOperation) (result [][]item_stack_operation.ItemStackOperation) {
+	currentRequest := make([]item_stack_operation.ItemStackOperation, 0)
+	for _, operation := range operations {
+		if !operation.CanInline() {
+			if len(currentRequest) != 0 {
+				result = append(result, currentRequest)
+			}
+			result = append(result, []item_stack_operation.ItemStackOperation{operation})
+			currentRequest = nil
+			continue
+		}
+		currentRequest = append(currentRequest, operation)
+	}
+	if len(currentRequest) != 0 {
+		result = append(result, currentRequest)
+	}
+	return
+}
+
 // Commit 将底层操作序列内联到单个物品堆栈操作请求数据包中执行物品堆栈操作事务。
 // 如果没有返回错误，Commit 在完成后将使用 Discord 清空底层操作序列。
 // 应当说明的是，如果事务没有全部成功，则若没有返回错误，则 Discord 仍然会执行。
@@ -47,7 +69,6 @@ func (i *ItemStackTransaction) Commit() (
 	mu := new(sync.Mutex)
 
 	pk = new(packet.ItemStackRequest)
-	allRequests := make([][]item_stack_operation.ItemStackOperation, 0)
 	waiters := make([]chan struct{}, 0)
 
 	handler := newItemStackOperationHandler(
@@ -58,23 +79,8 @@ func (i *ItemStackTransaction) Commit() (
 	)
 
 	// Step 1: Split by operations that can't inline
-	currentRequest := make([]item_stack_operation.ItemStackOperation, 0)
-	for _, operation := range i.operations {
-		if !operation.CanInline() {
-			if len(currentRequest) != 0 {
-				allRequests = append(allRequests, currentRequest)
-			}
-			allRequests = append(allRequests, []item_stack_operation.ItemStackOperation{operation})
-			currentRequest = nil
-			continue
-		}
-		currentRequest = append(currentRequest, operation)
-	}
-	if len(currentRequest) != 0 {
-		allRequests = append(allRequests, currentRequest)
-		currentRequest = nil
-	}
-	serverResponse = make([]*protocol.ItemStackResponse, len(allRequests))
+	allRequests := splitOperations(i.operations)
+	responses := make([]*protocol.ItemStackResponse, len(allRequests))
 
 	// Step 2: Construct actions
 	for index, requests := range allRequests {
@@ -127,7 +133,7 @@ func (i *ItemStackTransaction) Commit() (
 					doOnce.Do(func() {
 						mu.Lock()
 						defer mu.Unlock()
-						serverResponse[idx] = response
+						responses[idx] = response
 						close(channel)
 					})
 				},
@@ -152,6 +158,8 @@ func (i *ItemStackTransaction) Commit() (
 				result, err = handler.handleCrafting(op, requestID)
 			case item_stack_operation.Trimming:
 				result, err = handler.handleTrimming(op, requestID)
+			case item_stack_operation.MapLocking:
+				result, err = handler.handleMapLocking(op, requestID)
 			}
 			if err != nil {
 				return false, nil, nil, fmt.Errorf("Commit: %v", err)
@@ -181,7 +189,7 @@ func (i *ItemStackTransaction) Commit() (
 					doOnce.Do(func() {
 						mu.Lock()
 						defer mu.Unlock()
-						serverResponse[idx] = response
+						responses[idx] = response
 						close(channel)
 					})
 				},
@@ -199,6 +207,7 @@ func (i *ItemStackTransaction) Commit() (
 	for _, waiter := range waiters {
 		<-waiter
 	}
+	serverResponse = responses
 
 	// Step 5.1: Check failed and return if failed
 	for _, response := range serverResponse {
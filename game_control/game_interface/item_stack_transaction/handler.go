@@ -759,3 +759,121 @@ func (i *itemStackOperationHandler) handleTrimming(
 	// Make runtime data
 	return op.Make(runtimeData), nil
 }
+
+// handleMapLocking ..
+func (i *itemStackOperationHandler) handleMapLocking(
+	op item_stack_operation.MapLocking,
+	requestID resources_control.ItemStackRequestID,
+) (result []protocol.StackRequestAction, err error) {
+	// Prepare
+	runtimeData := item_stack_operation.MapLockingRuntime{
+		RequestID:       int32(requestID),
+		RecipeNetworkID: i.constantPacket.MapLockingRecipeNetworkID(),
+	}
+
+	// Basic check
+	if op.MapItem == op.GlassPane {
+		return nil, fmt.Errorf("handleMapLocking: MapItem (path) is equal to GlassPane (path)")
+	}
+
+	// Get opening container data
+	containerData, _, existed := i.api.ContainerData()
+	if !existed {
+		return nil, fmt.Errorf("handleMapLocking: Cartography table is not opened")
+	}
+
+	// Map item
+	{
+		// Prepare
+		cartographySlot := resources_control.SlotLocation{
+			WindowID: resources_control.WindowID(containerData.WindowID),
+			SlotID:   0x0c,
+		}
+
+		// Get item runtime ID
+		rid, err := i.virtualInventories.loadAndSetStackNetworkID(op.MapItem, requestID)
+		if err != nil {
+			return nil, fmt.Errorf("handleMapLocking: %v", err)
+		}
+		cartographyRID, err := i.virtualInventories.loadAndSetStackNetworkID(cartographySlot, requestID)
+		if err != nil {
+			return nil, fmt.Errorf("handleMapLocking: %v", err)
+		}
+
+		// Get container ID
+		cid, found := slotLocationToContainerID(i.api, op.MapItem)
+		if !found {
+			return nil, fmt.Errorf("handleMapLocking: Can not find the container ID of given item whose at %#v", op.MapItem)
+		}
+
+		// Bind container ID
+		i.responseMapping.bind(op.MapItem.WindowID, cid)
+		i.responseMapping.bind(resources_control.WindowID(containerData.WindowID), protocol.ContainerCartographyInput)
+
+		// Update map item data
+		err = i.virtualInventories.updateFromUpdater(op.MapItem, op.ResultItem)
+		if err != nil {
+			return nil, fmt.Errorf("handleMapLocking: %v", err)
+		}
+
+		// Set runtime data (MapItem related)
+		runtimeData.MapStackNetworkID = cartographyRID
+		runtimeData.MoveMapSrcContainerID = byte(cid)
+		runtimeData.MoveMapSrcStackNetworkID = rid
+	}
+
+	// Glass pane
+	{
+		// Prepare
+		cartographySlot := resources_control.SlotLocation{
+			WindowID: resources_control.WindowID(containerData.WindowID),
+			SlotID:   0x0d,
+		}
+
+		// Get item runtime ID
+		rid, err := i.virtualInventories.loadAndSetStackNetworkID(op.GlassPane, requestID)
+		if err != nil {
+			return nil, fmt.Errorf("handleMapLocking: %v", err)
+		}
+		cartographyRID, err := i.virtualInventories.loadAndSetStackNetworkID(cartographySlot, requestID)
+		if err != nil {
+			return nil, fmt.Errorf("handleMapLocking: %v", err)
+		}
+
+		// Get container ID
+		cid, found := slotLocationToContainerID(i.api, op.GlassPane)
+		if !found {
+			return nil, fmt.Errorf("handleMapLocking: Can not find the container ID of given item whose at %#v", op.GlassPane)
+		}
+
+		// Bind container ID
+		i.responseMapping.bind(op.GlassPane.WindowID, cid)
+		i.responseMapping.bind(resources_control.WindowID(containerData.WindowID), protocol.ContainerCartographyAdditional)
+
+		// Update item count
+		_, err = i.virtualInventories.loadAndAddItemCount(op.GlassPane, -1, false)
+		if err != nil {
+			return nil, fmt.Errorf("handleMapLocking: %v", err)
+		}
+
+		// Sync item data
+		resultCount, err := i.virtualInventories.loadItemCount(op.GlassPane)
+		if err != nil {
+			return nil, fmt.Errorf("handleMapLocking: %v", err)
+		}
+		if resultCount == 0 {
+			err = i.virtualInventories.setAir(op.GlassPane)
+			if err != nil {
+				return nil, fmt.Errorf("handleMapLocking: %v", err)
+			}
+		}
+
+		// Set runtime data (GlassPane related)
+		runtimeData.GlassPaneStackNetworkID = cartographyRID
+		runtimeData.MoveGlassPaneSrcContainerID = byte(cid)
+		runtimeData.MoveGlassPaneSrcStackNetworkID = rid
+	}
+
+	// Make runtime data
+	return op.Make(runtimeData), nil
+}
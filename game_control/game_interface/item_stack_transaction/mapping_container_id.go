@@ -2,6 +2,7 @@ package item_stack_transaction
 
 import (
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
 	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
 	"github.com/mcpol-studio/flowers-for-machines/mapping"
 )
@@ -14,6 +15,22 @@ func slotLocationToContainerID(
 ) (
 	result resources_control.ContainerID,
 	found bool,
+) {
+	containerData, containerID, existed := api.ContainerData()
+	return containerIDBySlotLocation(containerData, containerID, existed, slotLocation)
+}
+
+// containerIDBySlotLocation 找到 slotLocation 的容器 ID。
+// containerData 和 containerID 是已打开容器的数据及其容器 ID，
+// 而 opened 指示目前是否打开了容器
+func containerIDBySlotLocation(
+	containerData packet.ContainerOpen,
+	containerID resources_control.ContainerID,
+	opened bool,
+	slotLocation resources_control.SlotLocation,
+) (
+	result resources_control.ContainerID,
+	found bool,
 ) {
 	switch slotLocation.WindowID {
 	case protocol.WindowIDInventory:
@@ -28,8 +45,7 @@ func slotLocationToContainerID(
 		return 0, false // TODO: Figure out what WindowIDUI means
 	}
 
-	containerData, containerID, existed := api.ContainerData()
-	if !existed {
+	if !opened {
 		return 0, false
 	}
 	if containerData.WindowID != byte(slotLocation.WindowID) {
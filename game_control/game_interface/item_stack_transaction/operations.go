@@ -530,3 +530,52 @@ func (i *ItemStackTransaction) TrimmingFromInventory(
 		resultItem,
 	)
 }
+
+// MapLocking 将 mapItemPath 处的 1 个已填充地图和
+// glassPanePath 处的 1 个玻璃板放置在制图台中，
+// 并进行制图台的锁定地图操作。
+//
+// resultItem 指示期望得到的锁定地图的部分数据。
+// 如果操作成功，则被锁定的地图将回到原位。
+//
+// 该操作不支持内联，但它仍然可以被紧缩在单个
+// 的物品堆栈操作请求的数据包中
+func (i *ItemStackTransaction) MapLocking(
+	mapItemPath resources_control.SlotLocation,
+	glassPanePath resources_control.SlotLocation,
+	resultItem resources_control.ExpectedNewItem,
+) *ItemStackTransaction {
+	i.operations = append(i.operations, item_stack_operation.MapLocking{
+		MapItem:    mapItemPath,
+		GlassPane:  glassPanePath,
+		ResultItem: resultItem,
+	})
+	return i
+}
+
+// MapLockingFromInventory 将背包中 mapItemSlot 处的
+// 1 个已填充地图和 glassPaneSlot 处的 1 个玻璃板放置
+// 在制图台中，并进行制图台的锁定地图操作。
+//
+// resultItem 指示期望得到的锁定地图的部分数据。
+// 如果操作成功，则被锁定的地图将回到原位。
+//
+// 该操作不支持内联，但它仍然可以被紧缩在单个
+// 的物品堆栈操作请求的数据包中
+func (i *ItemStackTransaction) MapLockingFromInventory(
+	mapItemSlot resources_control.SlotID,
+	glassPaneSlot resources_control.SlotID,
+	resultItem resources_control.ExpectedNewItem,
+) *ItemStackTransaction {
+	return i.MapLocking(
+		resources_control.SlotLocation{
+			WindowID: protocol.WindowIDInventory,
+			SlotID:   mapItemSlot,
+		},
+		resources_control.SlotLocation{
+			WindowID: protocol.WindowIDInventory,
+			SlotID:   glassPaneSlot,
+		},
+		resultItem,
+	)
+}
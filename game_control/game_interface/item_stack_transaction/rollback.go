@@ -0,0 +1,302 @@
+package item_stack_transaction
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface/item_stack_operation"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// ItemRestorer 是在物品堆栈操作之外恢复物品的方式，
+// 例如使用 replaceitem 命令。它由 CommitWithRollback
+// 在补偿操作无法恢复物品时使用
+type ItemRestorer interface {
+	// RestoreItem 将 slotLocation 处的物品恢复为 item。
+	// 实现可以只恢复物品的部分数据 (例如名称、数量和物品组件)，
+	// 但不需要恢复物品的 NBT 数据
+	RestoreItem(slotLocation resources_control.SlotLocation, item protocol.ItemInstance) error
+	// Resync 等待租赁服将所有库存的变更同步到本地。
+	// 在它返回后，本地的库存应当与租赁服完全一致
+	Resync() error
+}
+
+// RejectedError 指示事务中的某个操作被租赁服拒绝
+type RejectedError struct {
+	// RequestIndex 是被拒绝的物品堆栈请求在事务中的索引
+	RequestIndex int
+	// OperationIndex 是被拒绝的操作在事务中的索引。
+	// 如果被拒绝的请求内联了多个操作，则它是通过
+	// 离线模拟推断的结果。如果推断失败，则它是
+	// 该请求中第一个操作的索引
+	OperationIndex int
+	// Operation 是被拒绝的操作
+	Operation item_stack_operation.ItemStackOperation
+	// Status 是租赁服返回的物品堆栈响应状态码
+	Status uint8
+	// RolledBack 指示事务所涉及的物品是否已全部恢复
+	RolledBack bool
+	// NBTNotRestored 是由 ItemRestorer 恢复的，
+	// 但其 NBT 数据 (例如魔咒和自定义名称) 未能被恢复的槽位。
+	// 这些槽位在检查回滚结果时不会比较 NBT 数据，
+	// 因此即便它非空，RolledBack 也可能为真
+	NBTNotRestored []resources_control.SlotLocation
+	// RollbackErr 是回滚时遇到的错误。
+	// 当 RolledBack 为真时，它总是空
+	RollbackErr error
+}
+
+// Error 实现 error 接口
+func (r *RejectedError) Error() string {
+	message := fmt.Sprintf(
+		"Operation %d (%T) in request %d is rejected with status %d",
+		r.OperationIndex, r.Operation, r.RequestIndex, r.Status,
+	)
+	if r.RolledBack && len(r.NBTNotRestored) > 0 {
+		return fmt.Sprintf("%s (rolled back, but NBT of %d slot(s) is not restored)", message, len(r.NBTNotRestored))
+	}
+	if r.RolledBack {
+		return message + " (rolled back)"
+	}
+	return fmt.Sprintf("%s (rollback failed: %v)", message, r.RollbackErr)
+}
+
+// affectedSlots 返回 operations 可能改变的所有槽位。
+// 铁砧和织布机等容器的临时槽位不在此列
+func (i *ItemStackTransaction) affectedSlots(
+	operations []item_stack_operation.ItemStackOperation,
+) (result []resources_control.SlotLocation) {
+	for _, operation := range operations {
+		switch op := operation.(type) {
+		case item_stack_operation.Move:
+			result = append(result, op.Source, op.Destination)
+		case item_stack_operation.Swap:
+			result = append(result, op.Source, op.Destination)
+		case item_stack_operation.Drop:
+			result = append(result, op.Path)
+		case item_stack_operation.CreativeItem:
+			result = append(result, op.Path)
+		case item_stack_operation.Renaming:
+			result = append(result, op.Path)
+		case item_stack_operation.Looming:
+			result = append(result, op.BannerPath, op.DyePath)
+			if op.UsePattern {
+				result = append(result, op.PatternPath)
+			}
+		case item_stack_operation.Crafting:
+			items, _ := i.api.Inventories().GetAllItemStack(protocol.WindowIDCrafting)
+			for slotID := range items {
+				result = append(result, resources_control.SlotLocation{
+					WindowID: protocol.WindowIDCrafting,
+					SlotID:   slotID,
+				})
+			}
+			result = append(result, resources_control.SlotLocation{
+				WindowID: protocol.WindowIDInventory,
+				SlotID:   op.ResultSlotID,
+			})
+		case item_stack_operation.Trimming:
+			result = append(result, op.TrimItem, op.Material, op.Template)
+		case item_stack_operation.MapLocking:
+			result = append(result, op.MapItem, op.GlassPane)
+		}
+	}
+
+	slices.SortFunc(result, compareSlotLocation)
+	return slices.Compact(result)
+}
+
+// compareSlotLocation 比较 a 和 b 的先后顺序
+func compareSlotLocation(a resources_control.SlotLocation, b resources_control.SlotLocation) int {
+	if a.WindowID != b.WindowID {
+		return int(a.WindowID) - int(b.WindowID)
+	}
+	return int(a.SlotID) - int(b.SlotID)
+}
+
+// snapshot 返回 slots 处的物品的快照
+func (i *ItemStackTransaction) snapshot(
+	slots []resources_control.SlotLocation,
+) map[resources_control.SlotLocation]protocol.ItemInstance {
+	result := make(map[resources_control.SlotLocation]protocol.ItemInstance)
+	for _, slot := range slots {
+		item, inventoryExisted := i.api.Inventories().GetItemStack(slot.WindowID, slot.SlotID)
+		if !inventoryExisted {
+			continue
+		}
+		result[slot] = utils.DeepCopyItemInstance(*item)
+	}
+	return result
+}
+
+// sameItemStack 检查 a 和 b 是否是相同的物品，且数量相等
+func sameItemStack(a protocol.ItemStack, b protocol.ItemStack) bool {
+	if isAir(a) || isAir(b) {
+		return isAir(a) && isAir(b)
+	}
+	return a.Count == b.Count && stackable(a, b)
+}
+
+// sameItemStackIgnoreNBT 与 sameItemStack 相同，
+// 但不比较物品的 NBT 数据
+func sameItemStackIgnoreNBT(a protocol.ItemStack, b protocol.ItemStack) bool {
+	if isAir(a) || isAir(b) {
+		return isAir(a) && isAir(b)
+	}
+	return a.Count == b.Count && a.NetworkID == b.NetworkID && a.MetadataValue == b.MetadataValue
+}
+
+// diffSlots 返回当前物品与 expected 中的不同的槽位
+func (i *ItemStackTransaction) diffSlots(
+	expected map[resources_control.SlotLocation]protocol.ItemInstance,
+) (result []resources_control.SlotLocation) {
+	current := i.snapshot(slices.Collect(maps.Keys(expected)))
+	for slot, item := range expected {
+		if !sameItemStack(current[slot].Stack, item.Stack) {
+			result = append(result, slot)
+		}
+	}
+	slices.SortFunc(result, compareSlotLocation)
+	return
+}
+
+// locateRejected 通过 simulator 推断 request 中被拒绝的操作。
+// 在调用前，simulator 应当处于执行 request 前的状态。
+// 如果推断失败，则返回 0
+func locateRejected(simulator *Simulator, request []item_stack_operation.ItemStackOperation) int {
+	for index, operation := range request {
+		response := simulator.ApplyRequest(
+			simulator.NewRequestID(),
+			[]item_stack_operation.ItemStackOperation{operation},
+		)
+		if response.Status != protocol.ItemStackResponseStatusOK {
+			return index
+		}
+	}
+	return 0
+}
+
+// compensate 试图通过交换物品将 expected 中的槽位恢复原状。
+// 物品可能只是在事务所涉及的槽位之间移动了，
+// 此时交换操作可以在不借助命令的情况下恢复它们
+func (i *ItemStackTransaction) compensate(expected map[resources_control.SlotLocation]protocol.ItemInstance) error {
+	diff := i.diffSlots(expected)
+	current := i.snapshot(diff)
+	compensation := NewItemStackTransaction(i.api)
+
+	for _, slot := range diff {
+		if sameItemStack(current[slot].Stack, expected[slot].Stack) {
+			continue
+		}
+		for _, other := range diff {
+			if other == slot || !sameItemStack(current[other].Stack, expected[slot].Stack) {
+				continue
+			}
+			_ = compensation.SwapItem(slot, other)
+			current[slot], current[other] = current[other], current[slot]
+			break
+		}
+	}
+
+	_, _, _, err := compensation.Commit()
+	if err != nil {
+		return fmt.Errorf("compensate: %v", err)
+	}
+	return nil
+}
+
+// rollback 将 expected 中的槽位恢复原状。
+// 它首先尝试补偿操作，然后使用 restorer 恢复剩余的槽位。
+//
+// 由 restorer 恢复的槽位在检查时不比较 NBT 数据，
+// nbtNotRestored 是其中 NBT 数据与 expected 不同的槽位
+func (i *ItemStackTransaction) rollback(
+	expected map[resources_control.SlotLocation]protocol.ItemInstance,
+	restorer ItemRestorer,
+) (nbtNotRestored []resources_control.SlotLocation, err error) {
+	err = i.compensate(expected)
+	if err != nil {
+		return nil, fmt.Errorf("rollback: %v", err)
+	}
+
+	restored := i.diffSlots(expected)
+	for _, slot := range restored {
+		err = restorer.RestoreItem(slot, expected[slot])
+		if err != nil {
+			return nil, fmt.Errorf("rollback: %v", err)
+		}
+	}
+
+	err = restorer.Resync()
+	if err != nil {
+		return nil, fmt.Errorf("rollback: %v", err)
+	}
+
+	var failed []resources_control.SlotLocation
+	current := i.snapshot(slices.Collect(maps.Keys(expected)))
+	for _, slot := range i.diffSlots(expected) {
+		if slices.Contains(restored, slot) && sameItemStackIgnoreNBT(current[slot].Stack, expected[slot].Stack) {
+			nbtNotRestored = append(nbtNotRestored, slot)
+			continue
+		}
+		failed = append(failed, slot)
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("rollback: Items in %d slot(s) are still not restored, and the first one is %#v", len(failed), failed[0])
+	}
+	return nbtNotRestored, nil
+}
+
+// CommitWithRollback 与 Commit 相同地提交事务，
+// 但在事务被租赁服拒绝时将事务所涉及的物品恢复原状。
+//
+// 在提交前，事务所涉及的槽位将被快照。如果事务中的某个
+// 操作被拒绝，则 CommitWithRollback 首先试图通过交换操作
+// 补偿已生效的更改，然后使用 restorer 恢复剩余的槽位，
+// 最后等待租赁服同步库存。restorer 通常无法恢复物品的
+// NBT 数据，这样的槽位将被记录在 RejectedError 中。
+//
+// 如果事务被拒绝，则返回的错误是 *RejectedError，
+// 它指示被拒绝的操作和原因，以及回滚是否成功
+func (i *ItemStackTransaction) CommitWithRollback(restorer ItemRestorer) (
+	pk *packet.ItemStackRequest,
+	serverResponse []*protocol.ItemStackResponse,
+	err error,
+) {
+	operations := slices.Clone(i.operations)
+	expected := i.snapshot(i.affectedSlots(operations))
+	simulator := NewSimulatorFromResources(i.api)
+
+	success, pk, serverResponse, err := i.Commit()
+	if err != nil {
+		return nil, nil, fmt.Errorf("CommitWithRollback: %v", err)
+	}
+	if success {
+		return pk, serverResponse, nil
+	}
+
+	rejected := &RejectedError{RequestIndex: -1}
+	operationIndex := 0
+	for index, request := range splitOperations(operations) {
+		response := serverResponse[index]
+		if response.Status == protocol.ItemStackResponseStatusOK {
+			_ = simulator.ApplyRequest(simulator.NewRequestID(), request)
+			operationIndex += len(request)
+			continue
+		}
+		located := locateRejected(simulator, request)
+		rejected.RequestIndex = index
+		rejected.OperationIndex = operationIndex + located
+		rejected.Operation = request[located]
+		rejected.Status = response.Status
+		break
+	}
+
+	rejected.NBTNotRestored, rejected.RollbackErr = i.rollback(expected, restorer)
+	rejected.RolledBack = rejected.RollbackErr == nil
+	return pk, serverResponse, rejected
+}
@@ -0,0 +1,482 @@
+package item_stack_transaction
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface/item_stack_operation"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// simulatedWindow 是模拟器中的单个库存
+type simulatedWindow map[resources_control.SlotID]protocol.ItemInstance
+
+// Simulator 是物品堆栈操作的离线模拟器。
+//
+// 它按照游戏的规则 (例如物品的最大堆叠数量、铁砧的花费
+// 和织布机的图案上限) 在虚拟的库存和容器上执行物品操作，
+// 并返回预测的物品堆栈响应，而无需连接到租赁服。这使得
+// 事务的构造者可以在本地进行单元测试或试运行。
+//
+// 模拟器只是尽力而为的。它不会校验合成配方，因此合成和
+// 锁定地图所得的物品将直接由对应操作的 ResultItem 给出。
+//
+// Simulator 不是线程安全的
+type Simulator struct {
+	itemNames     map[int32]string
+	creativeItems map[uint32]protocol.ItemStack
+	maxStackSize  map[int32]uint8
+
+	windows map[resources_control.WindowID]simulatedWindow
+
+	containerOpened bool
+	containerData   packet.ContainerOpen
+	containerID     resources_control.ContainerID
+
+	gameMode        int32
+	experienceLevel int32
+
+	currentRequestID      int32
+	currentStackNetworkID int32
+}
+
+// NewSimulator 基于物品列表 items 和创造物品栏 creativeItems
+// 创建并返回一个新的 Simulator。它们通常来自租赁服在登录
+// 序列中发送的数据包。
+//
+// 新的模拟器持有空的背包、副手、盔甲栏和合成栏，没有打开
+// 任何容器，且机器人处于经验等级为 0 的生存模式
+func NewSimulator(items []protocol.ItemEntry, creativeItems []protocol.CreativeItem) *Simulator {
+	s := &Simulator{
+		itemNames:        make(map[int32]string),
+		creativeItems:    make(map[uint32]protocol.ItemStack),
+		maxStackSize:     make(map[int32]uint8),
+		windows:          make(map[resources_control.WindowID]simulatedWindow),
+		containerID:      mapping.ContainerIDUnknown,
+		gameMode:         packet.GameTypeSurvival,
+		currentRequestID: 1,
+	}
+
+	for _, item := range items {
+		s.itemNames[int32(item.RuntimeID)] = item.Name
+	}
+	for _, item := range creativeItems {
+		s.creativeItems[item.CreativeItemNetworkID] = utils.DeepCopyItemStack(item.Item)
+	}
+	for _, windowID := range []resources_control.WindowID{
+		protocol.WindowIDInventory,
+		protocol.WindowIDOffHand,
+		protocol.WindowIDArmour,
+		protocol.WindowIDCrafting,
+	} {
+		s.windows[windowID] = make(simulatedWindow)
+	}
+
+	return s
+}
+
+// NewSimulatorFromResources 基于 api 创建并返回一个新的 Simulator。
+// 它持有 api 中所有库存和已打开容器的快照，此后二者互不影响。
+//
+// 由于资源中心不记录机器人的游戏模式和经验等级，
+// 调用者可能需要使用 SetGameMode 和 SetExperienceLevel 设置它们
+func NewSimulatorFromResources(api *resources_control.Resources) *Simulator {
+	s := NewSimulator(api.ConstantPacket().AllAvailableItems(), api.ConstantPacket().AllCreativeContent())
+
+	for _, windowID := range api.Inventories().GetAllWindowID() {
+		items, inventoryExisted := api.Inventories().GetAllItemStack(windowID)
+		if !inventoryExisted {
+			continue
+		}
+		window := make(simulatedWindow)
+		for slotID, item := range items {
+			window[slotID] = utils.DeepCopyItemInstance(*item)
+			s.currentStackNetworkID = max(s.currentStackNetworkID, item.StackNetworkID)
+		}
+		s.windows[windowID] = window
+	}
+
+	s.containerData, s.containerID, s.containerOpened = api.Container().ContainerData()
+	return s
+}
+
+// SetGameMode 设置机器人的游戏模式。
+// gameMode 是 packet.GameTypeSurvival 等常量
+func (s *Simulator) SetGameMode(gameMode int32) {
+	s.gameMode = gameMode
+}
+
+// GameMode 返回机器人的游戏模式
+func (s *Simulator) GameMode() int32 {
+	return s.gameMode
+}
+
+// SetExperienceLevel 设置机器人的经验等级
+func (s *Simulator) SetExperienceLevel(level int32) {
+	s.experienceLevel = level
+}
+
+// ExperienceLevel 返回机器人的经验等级
+func (s *Simulator) ExperienceLevel() int32 {
+	return s.experienceLevel
+}
+
+// SetMaxStackSize 将数值网络 ID 为 networkID 的物品的最大堆叠数量设置为 size。
+// 这会覆盖模拟器根据物品名称推断的结果
+func (s *Simulator) SetMaxStackSize(networkID int32, size uint8) {
+	s.maxStackSize[networkID] = size
+}
+
+// MaxStackSize 返回数值网络 ID 为 networkID 的物品的最大堆叠数量
+func (s *Simulator) MaxStackSize(networkID int32) uint8 {
+	if size, ok := s.maxStackSize[networkID]; ok {
+		return size
+	}
+	return maxStackSizeByName(s.itemNames[networkID])
+}
+
+// OpenContainer 打开窗口 ID 为 windowID 且类型为 containerType 的容器。
+// containerType 是 protocol.ContainerTypeAnvil 等常量。
+// 如果已经打开了其他容器，则那个容器将先被关闭
+func (s *Simulator) OpenContainer(windowID resources_control.WindowID, containerType byte) {
+	s.CloseContainer()
+	s.containerOpened = true
+	s.containerData = packet.ContainerOpen{
+		WindowID:                byte(windowID),
+		ContainerType:           containerType,
+		ContainerEntityUniqueID: -1,
+	}
+	s.containerID = mapping.ContainerIDUnknown
+	s.windows[windowID] = make(simulatedWindow)
+}
+
+// CloseContainer 关闭已打开的容器，并清空其中的物品。
+// 如果没有打开容器，则不会执行任何操作
+func (s *Simulator) CloseContainer() {
+	if !s.containerOpened {
+		return
+	}
+	delete(s.windows, resources_control.WindowID(s.containerData.WindowID))
+	s.containerOpened = false
+	s.containerData = packet.ContainerOpen{}
+	s.containerID = mapping.ContainerIDUnknown
+}
+
+// newStackNetworkID 返回一个新的物品堆栈网络 ID
+func (s *Simulator) newStackNetworkID() int32 {
+	s.currentStackNetworkID++
+	return s.currentStackNetworkID
+}
+
+// SetItemStack 将 slotLocation 处的物品设置为 itemStack，
+// 并为其分配新的物品堆栈网络 ID。调用者在调用后可以安全
+// 的继续修改 itemStack
+func (s *Simulator) SetItemStack(slotLocation resources_control.SlotLocation, itemStack protocol.ItemStack) error {
+	window, ok := s.windows[slotLocation.WindowID]
+	if !ok {
+		return fmt.Errorf("SetItemStack: Inventory whose window ID is %d is not existed", slotLocation.WindowID)
+	}
+
+	item := protocol.ItemInstance{Stack: utils.DeepCopyItemStack(itemStack)}
+	if !isAir(item.Stack) {
+		item.StackNetworkID = s.newStackNetworkID()
+	}
+	window[slotLocation.SlotID] = item
+
+	return nil
+}
+
+// GetItemStack 返回 slotLocation 处的物品。
+// 如果该处没有物品，则返回空气。如果对应的库存不存在，
+// 则 inventoryExisted 为假
+func (s *Simulator) GetItemStack(slotLocation resources_control.SlotLocation) (item protocol.ItemInstance, inventoryExisted bool) {
+	window, ok := s.windows[slotLocation.WindowID]
+	if !ok {
+		return protocol.ItemInstance{}, false
+	}
+	if item, ok := window[slotLocation.SlotID]; ok {
+		return utils.DeepCopyItemInstance(item), true
+	}
+	return *resources_control.NewAirItemInstance(), true
+}
+
+// NewRequestID 返回一个新的物品堆栈请求 ID。
+// 它与 resources_control.ItemStackOperationManager
+// 所返回的具有相同的格式
+func (s *Simulator) NewRequestID() resources_control.ItemStackRequestID {
+	s.currentRequestID -= 2
+	return resources_control.ItemStackRequestID(s.currentRequestID)
+}
+
+// ApplyRequest 在模拟器上执行由 operations 组成的单个物品堆栈请求，
+// 并返回预测的物品堆栈响应。
+//
+// 与租赁服相同，请求是原子的。如果其中的任何一个操作失败，
+// 则整个请求都不会生效，且返回的响应将指示第一个失败的原因
+func (s *Simulator) ApplyRequest(
+	requestID resources_control.ItemStackRequestID,
+	operations []item_stack_operation.ItemStackOperation,
+) protocol.ItemStackResponse {
+	sim := s.newSimulation()
+
+	for _, operation := range operations {
+		status := sim.apply(operation)
+		if status != protocol.ItemStackResponseStatusOK {
+			return protocol.ItemStackResponse{Status: status, RequestID: int32(requestID)}
+		}
+	}
+
+	return protocol.ItemStackResponse{
+		Status:        protocol.ItemStackResponseStatusOK,
+		RequestID:     int32(requestID),
+		ContainerInfo: s.commitSimulation(sim),
+	}
+}
+
+// commitSimulation 将 sim 中的更改提交到模拟器，
+// 并返回被改变的槽位的容器信息
+func (s *Simulator) commitSimulation(sim *simulation) []protocol.StackResponseContainerInfo {
+	result := make([]protocol.StackResponseContainerInfo, 0)
+	indexes := make(map[resources_control.ContainerID]int)
+
+	for _, touched := range sim.touched {
+		window := sim.windows[touched.location.WindowID]
+		item, ok := window[touched.location.SlotID]
+		if !ok {
+			item = *resources_control.NewAirItemInstance()
+		}
+
+		item.StackNetworkID = 0
+		if !isAir(item.Stack) {
+			item.StackNetworkID = s.newStackNetworkID()
+		}
+		window[touched.location.SlotID] = item
+
+		var customName string
+		if display, ok := item.Stack.NBTData["display"].(map[string]any); ok {
+			customName, _ = display["Name"].(string)
+		}
+
+		index, ok := indexes[touched.containerID]
+		if !ok {
+			index = len(result)
+			indexes[touched.containerID] = index
+			result = append(result, protocol.StackResponseContainerInfo{ContainerID: byte(touched.containerID)})
+		}
+		result[index].SlotInfo = append(result[index].SlotInfo, protocol.StackResponseSlotInfo{
+			Slot:           byte(touched.location.SlotID),
+			HotbarSlot:     byte(touched.location.SlotID),
+			Count:          byte(item.Stack.Count),
+			StackNetworkID: item.StackNetworkID,
+			CustomName:     customName,
+		})
+	}
+
+	s.windows = sim.windows
+	s.experienceLevel = sim.experienceLevel
+	return result
+}
+
+// DryRun 在 simulator 上试运行该事务，而不会向租赁服发送任何数据包。
+//
+// 与 Commit 相同，底层操作序列将被划分为若干物品堆栈请求，
+// 且每个请求都是原子的。success 和 serverResponse 的含义
+// 也与 Commit 的相同，只不过 serverResponse 是预测的结果。
+//
+// 与 Commit 不同，DryRun 不会清空底层操作序列，
+// 因此试运行后仍然可以继续调用 Commit 提交事务
+func (i *ItemStackTransaction) DryRun(simulator *Simulator) (success bool, serverResponse []*protocol.ItemStackResponse) {
+	success = true
+	serverResponse = make([]*protocol.ItemStackResponse, 0)
+
+	for _, request := range splitOperations(i.operations) {
+		response := simulator.ApplyRequest(simulator.NewRequestID(), request)
+		if response.Status != protocol.ItemStackResponseStatusOK {
+			success = false
+		}
+		serverResponse = append(serverResponse, &response)
+	}
+
+	return
+}
+
+// touchedSlot 是单个物品堆栈请求中被改变的槽位
+type touchedSlot struct {
+	location    resources_control.SlotLocation
+	containerID resources_control.ContainerID
+}
+
+// simulation 是执行单个物品堆栈请求时的模拟器副本。
+// 请求中的操作总是先在副本上进行，
+// 只有在所有操作都成功后，副本才会被提交
+type simulation struct {
+	s               *Simulator
+	windows         map[resources_control.WindowID]simulatedWindow
+	experienceLevel int32
+	touched         []touchedSlot
+}
+
+// newSimulation 基于模拟器当前的状态创建一个副本
+func (s *Simulator) newSimulation() *simulation {
+	windows := make(map[resources_control.WindowID]simulatedWindow)
+	for windowID, window := range s.windows {
+		windows[windowID] = maps.Clone(window)
+	}
+	return &simulation{
+		s:               s,
+		windows:         windows,
+		experienceLevel: s.experienceLevel,
+	}
+}
+
+// containerID 返回 slotLocation 的容器 ID
+func (sim *simulation) containerID(slotLocation resources_control.SlotLocation) (result resources_control.ContainerID, found bool) {
+	return containerIDBySlotLocation(sim.s.containerData, sim.s.containerID, sim.s.containerOpened, slotLocation)
+}
+
+// containerIs 检查已打开的容器的类型是否为 containerType
+func (sim *simulation) containerIs(containerType byte) bool {
+	return sim.s.containerOpened && sim.s.containerData.ContainerType == containerType
+}
+
+// containerSlot 返回已打开的容器中第 slotID 个槽位的位置
+func (sim *simulation) containerSlot(slotID resources_control.SlotID) resources_control.SlotLocation {
+	return resources_control.SlotLocation{
+		WindowID: resources_control.WindowID(sim.s.containerData.WindowID),
+		SlotID:   slotID,
+	}
+}
+
+// get 返回 slotLocation 处的物品的副本。
+// 如果对应的库存不存在，则 found 为假
+func (sim *simulation) get(slotLocation resources_control.SlotLocation) (item protocol.ItemInstance, found bool) {
+	window, ok := sim.windows[slotLocation.WindowID]
+	if !ok {
+		return protocol.ItemInstance{}, false
+	}
+	if item, ok := window[slotLocation.SlotID]; ok {
+		return utils.DeepCopyItemInstance(item), true
+	}
+	return *resources_control.NewAirItemInstance(), true
+}
+
+// set 将 slotLocation 处的物品设置为 item，并将该槽位标记为已改变。
+// containerID 是该槽位在物品堆栈响应中的容器 ID
+func (sim *simulation) set(
+	slotLocation resources_control.SlotLocation,
+	containerID resources_control.ContainerID,
+	item protocol.ItemInstance,
+) {
+	if isAir(item.Stack) {
+		item = *resources_control.NewAirItemInstance()
+	}
+	sim.windows[slotLocation.WindowID][slotLocation.SlotID] = item
+	sim.touch(slotLocation, containerID)
+}
+
+// touch 将 slotLocation 处的槽位标记为已改变
+func (sim *simulation) touch(slotLocation resources_control.SlotLocation, containerID resources_control.ContainerID) {
+	for _, value := range sim.touched {
+		if value.location == slotLocation {
+			return
+		}
+	}
+	sim.touched = append(sim.touched, touchedSlot{location: slotLocation, containerID: containerID})
+}
+
+// remove 从 slotLocation 处移除 count 个物品，并返回被移除的物品。
+// 如果物品的数量不足，则返回 failStatus
+func (sim *simulation) remove(
+	slotLocation resources_control.SlotLocation,
+	containerID resources_control.ContainerID,
+	count uint8,
+	failStatus uint8,
+) (result protocol.ItemStack, status uint8) {
+	item, found := sim.get(slotLocation)
+	if !found {
+		return protocol.ItemStack{}, protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	if count == 0 || isAir(item.Stack) || item.Stack.Count < uint16(count) {
+		return protocol.ItemStack{}, failStatus
+	}
+
+	result = utils.DeepCopyItemStack(item.Stack)
+	result.Count = uint16(count)
+	item.Stack.Count -= uint16(count)
+	sim.set(slotLocation, containerID, item)
+
+	return result, protocol.ItemStackResponseStatusOK
+}
+
+// place 将 stack 放入 slotLocation 处。
+// 如果该处已有物品，则 stack 必须可以与之堆叠
+func (sim *simulation) place(
+	slotLocation resources_control.SlotLocation,
+	containerID resources_control.ContainerID,
+	stack protocol.ItemStack,
+) uint8 {
+	item, found := sim.get(slotLocation)
+	if !found {
+		return protocol.ItemStackResponseStatusFailedToValidateDstSlot
+	}
+
+	if isAir(item.Stack) {
+		item.Stack = utils.DeepCopyItemStack(stack)
+	} else {
+		if !stackable(item.Stack, stack) {
+			return protocol.ItemStackResponseStatusCannotPlaceItem
+		}
+		item.Stack.Count += stack.Count
+	}
+	if item.Stack.Count > uint16(sim.s.MaxStackSize(item.Stack.NetworkID)) {
+		return protocol.ItemStackResponseStatusInvalidTransferAmount
+	}
+
+	sim.set(slotLocation, containerID, item)
+	return protocol.ItemStackResponseStatusOK
+}
+
+// slotsEmpty 检查已打开的容器中位于 slotIDs 的槽位是否都是空的
+func (sim *simulation) slotsEmpty(slotIDs ...resources_control.SlotID) bool {
+	for _, slotID := range slotIDs {
+		item, found := sim.get(sim.containerSlot(slotID))
+		if !found || !isAir(item.Stack) {
+			return false
+		}
+	}
+	return true
+}
+
+// itemName 返回 stack 的物品名称
+func (sim *simulation) itemName(stack protocol.ItemStack) string {
+	return sim.s.itemNames[stack.NetworkID]
+}
+
+// apply 在副本上执行单个物品操作
+func (sim *simulation) apply(operation item_stack_operation.ItemStackOperation) uint8 {
+	switch op := operation.(type) {
+	case item_stack_operation.Move:
+		return sim.move(op)
+	case item_stack_operation.Swap:
+		return sim.swap(op)
+	case item_stack_operation.Drop:
+		return sim.drop(op)
+	case item_stack_operation.CreativeItem:
+		return sim.creativeItem(op)
+	case item_stack_operation.Renaming:
+		return sim.renaming(op)
+	case item_stack_operation.Looming:
+		return sim.looming(op)
+	case item_stack_operation.Crafting:
+		return sim.crafting(op)
+	case item_stack_operation.Trimming:
+		return sim.trimming(op)
+	case item_stack_operation.MapLocking:
+		return sim.mapLocking(op)
+	}
+	return protocol.ItemStackResponseStatusInvalidRequestActionType
+}
@@ -0,0 +1,424 @@
+package item_stack_transaction
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface/item_stack_operation"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+)
+
+const (
+	// DefaultMaxStackSize 是物品默认的最大堆叠数量
+	DefaultMaxStackSize uint8 = 64
+	// AnvilTooExpensiveCost 是铁砧的花费上限。
+	// 非创造模式下，花费达到该值的铁砧操作将失败
+	AnvilTooExpensiveCost int32 = 40
+	// MaxBannerPatterns 是一个旗帜最多可以持有的图案数量
+	MaxBannerPatterns int = 6
+)
+
+// unstackableSuffixes 是不可堆叠的物品的名称后缀
+var unstackableSuffixes = []string{
+	"_sword", "_pickaxe", "_axe", "_shovel", "_hoe",
+	"_helmet", "_chestplate", "_leggings", "_boots",
+	"_bucket", "_boat", "_minecart", "_horse_armor",
+	"_shulker_box", "_smithing_template", "_banner_pattern",
+}
+
+// unstackableItems 是不可堆叠的物品的名称
+var unstackableItems = []string{
+	"bow", "crossbow", "trident", "shield", "elytra", "fishing_rod",
+	"shears", "flint_and_steel", "carrot_on_a_stick", "warped_fungus_on_a_stick",
+	"shulker_box", "undyed_shulker_box", "totem_of_undying", "saddle", "cake",
+	"writable_book", "written_book", "enchanted_book", "filled_map", "mace",
+	"potion", "splash_potion", "lingering_potion", "mushroom_stew",
+	"rabbit_stew", "beetroot_soup", "suspicious_stew", "bed", "banner_pattern",
+	"music_disc", "goat_horn", "brush", "spyglass", "bundle", "minecart",
+}
+
+// sixteenStackableItems 是最多堆叠 16 个的物品的名称
+var sixteenStackableItems = []string{
+	"ender_pearl", "snowball", "egg", "bucket", "honey_bottle",
+	"armor_stand", "banner", "sign", "hanging_sign", "wind_charge",
+}
+
+// trimMaterials 是可用于盔甲纹饰的材料
+var trimMaterials = []string{
+	"amethyst_shard", "copper_ingot", "diamond", "emerald",
+	"gold_ingot", "iron_ingot", "lapis_lazuli", "netherite_ingot",
+	"quartz", "redstone", "resin_brick",
+}
+
+// trimPatterns 是盔甲纹饰锻造模板的图案
+var trimPatterns = []string{
+	"bolt", "coast", "dune", "eye", "flow", "host", "raiser", "rib", "sentry",
+	"shaper", "silence", "snout", "spire", "tide", "vex", "ward", "wayfinder", "wild",
+}
+
+// shortItemName 返回不带命名空间的物品名称
+func shortItemName(name string) string {
+	return strings.TrimPrefix(name, "minecraft:")
+}
+
+// maxStackSizeByName 根据物品名称推断物品的最大堆叠数量
+func maxStackSizeByName(name string) uint8 {
+	name = shortItemName(name)
+	if slices.Contains(unstackableItems, name) || strings.HasPrefix(name, "music_disc_") {
+		return 1
+	}
+	for _, suffix := range unstackableSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return 1
+		}
+	}
+	if slices.Contains(sixteenStackableItems, name) {
+		return 16
+	}
+	for _, suffix := range []string{"_sign", "_banner", "_hanging_sign"} {
+		if strings.HasSuffix(name, suffix) {
+			return 16
+		}
+	}
+	return DefaultMaxStackSize
+}
+
+// isAir 检查 stack 是否是空气
+func isAir(stack protocol.ItemStack) bool {
+	return stack.NetworkID == 0 || stack.Count == 0
+}
+
+// stackable 检查 a 和 b 是否可以堆叠在一起
+func stackable(a protocol.ItemStack, b protocol.ItemStack) bool {
+	if a.NetworkID != b.NetworkID || a.MetadataValue != b.MetadataValue {
+		return false
+	}
+	if len(a.NBTData) == 0 && len(b.NBTData) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.NBTData, b.NBTData)
+}
+
+// move 模拟物品的移动
+func (sim *simulation) move(op item_stack_operation.Move) uint8 {
+	if op.Source == op.Destination {
+		return protocol.ItemStackResponseStatusDstContainerAndSlotEqualToSrcContainerAndSlot
+	}
+	srcCID, found := sim.containerID(op.Source)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	dstCID, found := sim.containerID(op.Destination)
+	if !found {
+		return protocol.ItemStackResponseStatusFailedToValidateDstSlot
+	}
+	if op.Count <= 0 || op.Count > 255 {
+		return protocol.ItemStackResponseStatusInvalidTransferAmount
+	}
+
+	stack, status := sim.remove(op.Source, srcCID, uint8(op.Count), protocol.ItemStackResponseStatusInvalidTransferAmount)
+	if status != protocol.ItemStackResponseStatusOK {
+		return status
+	}
+	return sim.place(op.Destination, dstCID, stack)
+}
+
+// swap 模拟物品的交换
+func (sim *simulation) swap(op item_stack_operation.Swap) uint8 {
+	if op.Source == op.Destination {
+		return protocol.ItemStackResponseStatusDstContainerAndSlotEqualToSrcContainerAndSlot
+	}
+	srcCID, found := sim.containerID(op.Source)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	dstCID, found := sim.containerID(op.Destination)
+	if !found {
+		return protocol.ItemStackResponseStatusFailedToValidateDstSlot
+	}
+
+	srcItem, _ := sim.get(op.Source)
+	dstItem, _ := sim.get(op.Destination)
+	sim.set(op.Source, srcCID, dstItem)
+	sim.set(op.Destination, dstCID, srcItem)
+
+	return protocol.ItemStackResponseStatusOK
+}
+
+// drop 模拟物品的丢弃
+func (sim *simulation) drop(op item_stack_operation.Drop) uint8 {
+	cid, found := sim.containerID(op.Path)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	_, status := sim.remove(op.Path, cid, op.Count, protocol.ItemStackResponseStatusInvalidRemovedAmount)
+	return status
+}
+
+// creativeItem 模拟从创造物品栏获取物品
+func (sim *simulation) creativeItem(op item_stack_operation.CreativeItem) uint8 {
+	if sim.s.gameMode != packet.GameTypeCreative {
+		return protocol.ItemStackResponseStatusPlayerNotInCreativeMode
+	}
+	stack, ok := sim.s.creativeItems[op.CINI]
+	if !ok {
+		return protocol.ItemStackResponseStatusFailedToCraftCreative
+	}
+	cid, found := sim.containerID(op.Path)
+	if !found {
+		return protocol.ItemStackResponseStatusFailedToValidateDstSlot
+	}
+	if op.Count == 0 {
+		return protocol.ItemStackResponseStatusInvalidTransferAmount
+	}
+
+	stack.Count = uint16(op.Count)
+	return sim.place(op.Path, cid, stack)
+}
+
+// renaming 模拟使用铁砧重命名物品
+func (sim *simulation) renaming(op item_stack_operation.Renaming) uint8 {
+	if !sim.containerIs(protocol.ContainerTypeAnvil) {
+		return protocol.ItemStackResponseStatusInvalidCraftRequestScreen
+	}
+	cid, found := sim.containerID(op.Path)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	item, _ := sim.get(op.Path)
+	if isAir(item.Stack) {
+		return protocol.ItemStackResponseStatusMissingInputItem
+	}
+	if !sim.slotsEmpty(1) {
+		return protocol.ItemStackResponseStatusCannotPlaceItem
+	}
+
+	repairCost, _ := item.Stack.NBTData["RepairCost"].(int32)
+	cost := repairCost + 1
+	if sim.s.gameMode != packet.GameTypeCreative {
+		if cost >= AnvilTooExpensiveCost || sim.experienceLevel < cost {
+			return protocol.ItemStackResponseStatusInvalidCraftResult
+		}
+		sim.experienceLevel -= cost
+	}
+
+	resources_control.UpdateItemClientSide(&item, op.Path, resources_control.ExpectedNewItem{
+		NBT: resources_control.ItemNewNBTData{ChangeRepairCost: true},
+	})
+	resources_control.UpdateDisplay(&item, protocol.StackResponseSlotInfo{CustomName: op.NewName})
+
+	sim.touch(sim.containerSlot(1), protocol.ContainerAnvilInput)
+	sim.set(op.Path, cid, item)
+	return protocol.ItemStackResponseStatusOK
+}
+
+// looming 模拟使用织布机为旗帜添加图案
+func (sim *simulation) looming(op item_stack_operation.Looming) uint8 {
+	if !sim.containerIs(protocol.ContainerTypeLoom) {
+		return protocol.ItemStackResponseStatusInvalidCraftRequestScreen
+	}
+	if op.BannerPath == op.DyePath || len(op.PatternName) == 0 {
+		return protocol.ItemStackResponseStatusInvalidCraftRequest
+	}
+	if op.UsePattern && (op.PatternPath == op.BannerPath || op.PatternPath == op.DyePath) {
+		return protocol.ItemStackResponseStatusInvalidCraftRequest
+	}
+	if !sim.slotsEmpty(9, 10, 11) {
+		return protocol.ItemStackResponseStatusCannotPlaceItem
+	}
+
+	bannerCID, found := sim.containerID(op.BannerPath)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	dyeCID, found := sim.containerID(op.DyePath)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+
+	banner, _ := sim.get(op.BannerPath)
+	if isAir(banner.Stack) || shortItemName(sim.itemName(banner.Stack)) != "banner" {
+		return protocol.ItemStackResponseStatusMissingInputItem
+	}
+	patterns, _ := banner.Stack.NBTData["Patterns"].([]any)
+	if len(patterns) >= MaxBannerPatterns {
+		return protocol.ItemStackResponseStatusInvalidCraftResult
+	}
+
+	dye, _ := sim.get(op.DyePath)
+	dyeName := shortItemName(sim.itemName(dye.Stack))
+	isDye := false
+	for _, name := range mapping.BannerColorToDyeName {
+		if shortItemName(name) == dyeName {
+			isDye = true
+			break
+		}
+	}
+	if isAir(dye.Stack) || !isDye {
+		return protocol.ItemStackResponseStatusMissingMaterialItem
+	}
+
+	if op.UsePattern {
+		pattern, _ := sim.get(op.PatternPath)
+		expected, ok := mapping.BannerPatternToItemName[op.PatternName]
+		if isAir(pattern.Stack) || !ok || shortItemName(sim.itemName(pattern.Stack)) != shortItemName(expected) {
+			return protocol.ItemStackResponseStatusMismatchedRecipeForInputGridItems
+		}
+		sim.touch(sim.containerSlot(11), protocol.ContainerLoomMaterial)
+	}
+
+	result, status := sim.remove(op.BannerPath, bannerCID, 1, protocol.ItemStackResponseStatusMissingInputItem)
+	if status != protocol.ItemStackResponseStatusOK {
+		return status
+	}
+	if _, status = sim.remove(op.DyePath, dyeCID, 1, protocol.ItemStackResponseStatusMissingMaterialItem); status != protocol.ItemStackResponseStatusOK {
+		return status
+	}
+	sim.touch(sim.containerSlot(9), protocol.ContainerLoomInput)
+	sim.touch(sim.containerSlot(10), protocol.ContainerLoomDye)
+
+	item := protocol.ItemInstance{Stack: result}
+	resources_control.UpdateItemClientSide(&item, op.BannerPath, op.ResultItem)
+	return sim.place(op.BannerPath, bannerCID, item.Stack)
+}
+
+// trimming 模拟使用锻造台为盔甲添加纹饰
+func (sim *simulation) trimming(op item_stack_operation.Trimming) uint8 {
+	if !sim.containerIs(protocol.ContainerTypeSmithingTable) {
+		return protocol.ItemStackResponseStatusInvalidCraftRequestScreen
+	}
+	if op.TrimItem == op.Material || op.TrimItem == op.Template || op.Material == op.Template {
+		return protocol.ItemStackResponseStatusInvalidCraftRequest
+	}
+	if !sim.slotsEmpty(0x33, 0x34, 0x35) {
+		return protocol.ItemStackResponseStatusCannotPlaceItem
+	}
+
+	trimCID, found := sim.containerID(op.TrimItem)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	materialCID, found := sim.containerID(op.Material)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	templateCID, found := sim.containerID(op.Template)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+
+	trimItem, _ := sim.get(op.TrimItem)
+	trimItemName := shortItemName(sim.itemName(trimItem.Stack))
+	isArmor := false
+	for _, suffix := range []string{"_helmet", "_chestplate", "_leggings", "_boots"} {
+		if strings.HasSuffix(trimItemName, suffix) {
+			isArmor = true
+			break
+		}
+	}
+	if isAir(trimItem.Stack) || !isArmor {
+		return protocol.ItemStackResponseStatusMissingInputItem
+	}
+
+	material, _ := sim.get(op.Material)
+	if isAir(material.Stack) || !slices.Contains(trimMaterials, shortItemName(sim.itemName(material.Stack))) {
+		return protocol.ItemStackResponseStatusMissingMaterialItem
+	}
+
+	template, _ := sim.get(op.Template)
+	pattern, isTemplate := strings.CutSuffix(shortItemName(sim.itemName(template.Stack)), "_armor_trim_smithing_template")
+	if isAir(template.Stack) || !isTemplate || !slices.Contains(trimPatterns, pattern) {
+		return protocol.ItemStackResponseStatusMismatchedRecipeForInputGridItems
+	}
+
+	if _, status := sim.remove(op.Material, materialCID, 1, protocol.ItemStackResponseStatusMissingMaterialItem); status != protocol.ItemStackResponseStatusOK {
+		return status
+	}
+	if _, status := sim.remove(op.Template, templateCID, 1, protocol.ItemStackResponseStatusMismatchedRecipeForInputGridItems); status != protocol.ItemStackResponseStatusOK {
+		return status
+	}
+	sim.touch(sim.containerSlot(0x33), protocol.ContainerSmithingTableInput)
+	sim.touch(sim.containerSlot(0x34), protocol.ContainerSmithingTableMaterial)
+	sim.touch(sim.containerSlot(0x35), protocol.ContainerSmithingTableTemplate)
+
+	resources_control.UpdateItemClientSide(&trimItem, op.TrimItem, op.ResultItem)
+	sim.set(op.TrimItem, trimCID, trimItem)
+	return protocol.ItemStackResponseStatusOK
+}
+
+// crafting 模拟使用合成栏合成物品。
+// 它不会校验合成配方，因此合成栏中的所有物品都将被消耗
+func (sim *simulation) crafting(op item_stack_operation.Crafting) uint8 {
+	window := sim.windows[protocol.WindowIDCrafting]
+
+	slotIDs := make([]resources_control.SlotID, 0)
+	for slotID, item := range window {
+		if !isAir(item.Stack) {
+			slotIDs = append(slotIDs, slotID)
+		}
+	}
+	if len(slotIDs) == 0 {
+		return protocol.ItemStackResponseStatusMissingInputItem
+	}
+	slices.Sort(slotIDs)
+
+	for _, slotID := range slotIDs {
+		location := resources_control.SlotLocation{WindowID: protocol.WindowIDCrafting, SlotID: slotID}
+		sim.set(location, protocol.ContainerCraftingInput, *resources_control.NewAirItemInstance())
+	}
+
+	result := resources_control.NewAirItemInstance()
+	resultPath := resources_control.SlotLocation{WindowID: protocol.WindowIDInventory, SlotID: op.ResultSlotID}
+	resources_control.UpdateItemClientSide(result, resultPath, op.ResultItem)
+	result.Stack.Count = uint16(op.ResultCount)
+	if isAir(result.Stack) {
+		return protocol.ItemStackResponseStatusInvalidCraftResultItem
+	}
+
+	return sim.place(resultPath, protocol.ContainerCombinedHotBarAndInventory, result.Stack)
+}
+
+// mapLocking 模拟使用制图台锁定地图
+func (sim *simulation) mapLocking(op item_stack_operation.MapLocking) uint8 {
+	if !sim.containerIs(protocol.ContainerTypeCartography) {
+		return protocol.ItemStackResponseStatusInvalidCraftRequestScreen
+	}
+	if op.MapItem == op.GlassPane {
+		return protocol.ItemStackResponseStatusInvalidCraftRequest
+	}
+	if !sim.slotsEmpty(0x0c, 0x0d) {
+		return protocol.ItemStackResponseStatusCannotPlaceItem
+	}
+
+	mapCID, found := sim.containerID(op.MapItem)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	paneCID, found := sim.containerID(op.GlassPane)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+
+	mapItem, _ := sim.get(op.MapItem)
+	if isAir(mapItem.Stack) || shortItemName(sim.itemName(mapItem.Stack)) != "filled_map" {
+		return protocol.ItemStackResponseStatusMissingInputItem
+	}
+	pane, _ := sim.get(op.GlassPane)
+	if isAir(pane.Stack) || shortItemName(sim.itemName(pane.Stack)) != "glass_pane" {
+		return protocol.ItemStackResponseStatusMissingMaterialItem
+	}
+
+	if _, status := sim.remove(op.GlassPane, paneCID, 1, protocol.ItemStackResponseStatusMissingMaterialItem); status != protocol.ItemStackResponseStatusOK {
+		return status
+	}
+	sim.touch(sim.containerSlot(0x0c), protocol.ContainerCartographyInput)
+	sim.touch(sim.containerSlot(0x0d), protocol.ContainerCartographyAdditional)
+
+	resources_control.UpdateItemClientSide(&mapItem, op.MapItem, op.ResultItem)
+	sim.set(op.MapItem, mapCID, mapItem)
+	return protocol.ItemStackResponseStatusOK
+}
@@ -0,0 +1,409 @@
+package item_stack_transaction_test
+
+import (
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface/item_stack_transaction"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+)
+
+const (
+	networkIDStone       = 1
+	networkIDDiamondAxe  = 2
+	networkIDEnderPearl  = 3
+	creativeNetworkStone = 7
+)
+
+// inventory 返回背包中第 slotID 个槽位的位置
+func inventory(slotID resources_control.SlotID) resources_control.SlotLocation {
+	return resources_control.SlotLocation{WindowID: protocol.WindowIDInventory, SlotID: slotID}
+}
+
+// newSimulator 创建一个用于测试的模拟器
+func newSimulator(t *testing.T) *item_stack_transaction.Simulator {
+	simulator := item_stack_transaction.NewSimulator(
+		[]protocol.ItemEntry{
+			{Name: "minecraft:stone", RuntimeID: networkIDStone},
+			{Name: "minecraft:diamond_axe", RuntimeID: networkIDDiamondAxe},
+			{Name: "minecraft:ender_pearl", RuntimeID: networkIDEnderPearl},
+		},
+		[]protocol.CreativeItem{
+			{
+				CreativeItemNetworkID: creativeNetworkStone,
+				Item:                  protocol.ItemStack{ItemType: protocol.ItemType{NetworkID: networkIDStone}, Count: 1},
+			},
+		},
+	)
+	for slotID, stack := range map[resources_control.SlotID]protocol.ItemStack{
+		0: {ItemType: protocol.ItemType{NetworkID: networkIDStone}, Count: 40},
+		1: {ItemType: protocol.ItemType{NetworkID: networkIDStone}, Count: 30},
+		2: {ItemType: protocol.ItemType{NetworkID: networkIDDiamondAxe}, Count: 1},
+		3: {ItemType: protocol.ItemType{NetworkID: networkIDEnderPearl}, Count: 10},
+	} {
+		if err := simulator.SetItemStack(inventory(slotID), stack); err != nil {
+			t.Fatalf("newSimulator: %v", err)
+		}
+	}
+	return simulator
+}
+
+// count 返回 slotLocation 处的物品数量
+func count(t *testing.T, simulator *item_stack_transaction.Simulator, slotLocation resources_control.SlotLocation) uint16 {
+	item, inventoryExisted := simulator.GetItemStack(slotLocation)
+	if !inventoryExisted {
+		t.Fatalf("count: Inventory of %#v is not existed", slotLocation)
+	}
+	return item.Stack.Count
+}
+
+func TestSimulatorMoveAndSwap(t *testing.T) {
+	simulator := newSimulator(t)
+
+	success, responses := item_stack_transaction.NewItemStackTransaction(nil).
+		MoveItem(inventory(1), inventory(0), 20).
+		SwapItem(inventory(2), inventory(5)).
+		DropItem(inventory(1), 4).
+		DryRun(simulator)
+	if !success || len(responses) != 1 {
+		t.Fatalf("TestSimulatorMoveAndSwap: Unexpected result %v %#v", success, responses)
+	}
+	if count(t, simulator, inventory(0)) != 60 || count(t, simulator, inventory(1)) != 6 {
+		t.Errorf("TestSimulatorMoveAndSwap: Move or drop is not applied")
+	}
+	if count(t, simulator, inventory(2)) != 0 || count(t, simulator, inventory(5)) != 1 {
+		t.Errorf("TestSimulatorMoveAndSwap: Swap is not applied")
+	}
+
+	slotInfo := responses[0].ContainerInfo[0].SlotInfo
+	if responses[0].ContainerInfo[0].ContainerID != protocol.ContainerCombinedHotBarAndInventory || len(slotInfo) != 4 {
+		t.Errorf("TestSimulatorMoveAndSwap: Unexpected container info %#v", responses[0].ContainerInfo)
+	}
+}
+
+func TestSimulatorStackRules(t *testing.T) {
+	simulator := newSimulator(t)
+
+	// 超过最大堆叠数量的移动将使整个请求失败
+	success, responses := item_stack_transaction.NewItemStackTransaction(nil).
+		MoveItem(inventory(1), inventory(6), 5).
+		MoveItem(inventory(1), inventory(0), 25).
+		MoveItem(inventory(3), inventory(7), 5).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusInvalidTransferAmount {
+		t.Fatalf("TestSimulatorStackRules: Unexpected result %v %#v", success, responses)
+	}
+	if count(t, simulator, inventory(1)) != 30 || count(t, simulator, inventory(6)) != 0 {
+		t.Errorf("TestSimulatorStackRules: Failed request is not atomic")
+	}
+
+	// 使用者设置的最大堆叠数量将覆盖推断的结果
+	success, _ = item_stack_transaction.NewItemStackTransaction(nil).
+		MoveItem(inventory(3), inventory(8), 5).
+		MoveItem(inventory(8), inventory(3), 5).
+		DryRun(simulator)
+	if !success {
+		t.Errorf("TestSimulatorStackRules: Move ender pearl back failed")
+	}
+	simulator.SetMaxStackSize(networkIDEnderPearl, 8)
+	success, _ = item_stack_transaction.NewItemStackTransaction(nil).
+		MoveItem(inventory(3), inventory(8), 9).
+		DryRun(simulator)
+	if success {
+		t.Errorf("TestSimulatorStackRules: Max stack size override is not used")
+	}
+
+	// 不同的物品不能堆叠
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		MoveItem(inventory(0), inventory(3), 1).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusCannotPlaceItem {
+		t.Errorf("TestSimulatorStackRules: Unexpected result %v %#v", success, responses)
+	}
+}
+
+func TestSimulatorCreativeAndRenaming(t *testing.T) {
+	simulator := newSimulator(t)
+
+	success, responses := item_stack_transaction.NewItemStackTransaction(nil).
+		GetCreativeItem(creativeNetworkStone, inventory(9), 16).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusPlayerNotInCreativeMode {
+		t.Fatalf("TestSimulatorCreativeAndRenaming: Unexpected result %v %#v", success, responses)
+	}
+
+	simulator.SetGameMode(packet.GameTypeCreative)
+	success, _ = item_stack_transaction.NewItemStackTransaction(nil).
+		GetCreativeItem(creativeNetworkStone, inventory(9), 16).
+		DryRun(simulator)
+	if !success || count(t, simulator, inventory(9)) != 16 {
+		t.Fatalf("TestSimulatorCreativeAndRenaming: Get creative item failed")
+	}
+
+	// 未打开铁砧时无法重命名
+	simulator.SetGameMode(packet.GameTypeSurvival)
+	success, _ = item_stack_transaction.NewItemStackTransaction(nil).
+		RenameItem(inventory(2), "axe").
+		DryRun(simulator)
+	if success {
+		t.Fatalf("TestSimulatorCreativeAndRenaming: Renaming without anvil succeeded")
+	}
+
+	// 经验等级不足时无法重命名
+	simulator.OpenContainer(1, protocol.ContainerTypeAnvil)
+	success, _ = item_stack_transaction.NewItemStackTransaction(nil).
+		RenameItem(inventory(2), "axe").
+		DryRun(simulator)
+	if success {
+		t.Fatalf("TestSimulatorCreativeAndRenaming: Renaming without experience succeeded")
+	}
+
+	simulator.SetExperienceLevel(3)
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		RenameItem(inventory(2), "axe").
+		DryRun(simulator)
+	if !success || simulator.ExperienceLevel() != 2 {
+		t.Fatalf("TestSimulatorCreativeAndRenaming: Unexpected result %v %#v", success, responses)
+	}
+
+	item, _ := simulator.GetItemStack(inventory(2))
+	display, _ := item.Stack.NBTData["display"].(map[string]any)
+	if display["Name"] != "axe" {
+		t.Errorf("TestSimulatorCreativeAndRenaming: Unexpected item %#v", item)
+	}
+	for _, info := range responses[0].ContainerInfo {
+		for _, slot := range info.SlotInfo {
+			if info.ContainerID == protocol.ContainerCombinedHotBarAndInventory && slot.CustomName != "axe" {
+				t.Errorf("TestSimulatorCreativeAndRenaming: Unexpected slot info %#v", slot)
+			}
+		}
+	}
+}
+
+// newSimulatorWithItems 创建一个只注册了 names 中物品的模拟器。
+// 第 i 个物品的数值网络 ID 为 i+1
+func newSimulatorWithItems(names ...string) *item_stack_transaction.Simulator {
+	items := make([]protocol.ItemEntry, 0, len(names))
+	for index, name := range names {
+		items = append(items, protocol.ItemEntry{Name: name, RuntimeID: int16(index + 1)})
+	}
+	return item_stack_transaction.NewSimulator(items, nil)
+}
+
+// setItems 将 stacks 放入 simulator 的背包
+func setItems(t *testing.T, simulator *item_stack_transaction.Simulator, stacks map[resources_control.SlotID]protocol.ItemStack) {
+	for slotID, stack := range stacks {
+		if err := simulator.SetItemStack(inventory(slotID), stack); err != nil {
+			t.Fatalf("setItems: %v", err)
+		}
+	}
+}
+
+// bannerPatterns 返回含有 count 个图案的旗帜图案列表
+func bannerPatterns(count int) []any {
+	patterns := make([]any, 0, count)
+	for range count {
+		patterns = append(patterns, map[string]any{"Color": int32(1), "Pattern": "bs"})
+	}
+	return patterns
+}
+
+func TestSimulatorMaxStackSize(t *testing.T) {
+	names := []string{
+		"minecraft:minecart", "minecraft:chest_minecart", "minecraft:creeper_banner_pattern",
+		"minecraft:flower_banner_pattern", "minecraft:banner", "minecraft:oak_sign",
+		"minecraft:ender_pearl", "minecraft:diamond_sword", "minecraft:apple",
+	}
+	expected := []uint8{1, 1, 1, 1, 16, 16, 16, 1, 64}
+
+	simulator := newSimulatorWithItems(names...)
+	for index, name := range names {
+		if size := simulator.MaxStackSize(int32(index + 1)); size != expected[index] {
+			t.Errorf("TestSimulatorMaxStackSize: Expected max stack size of %s is %d, but got %d", name, expected[index], size)
+		}
+	}
+}
+
+func TestSimulatorLooming(t *testing.T) {
+	const (
+		networkIDBanner = iota + 1
+		networkIDRedDye
+		networkIDCreeperPattern
+	)
+	simulator := newSimulatorWithItems("minecraft:banner", "minecraft:red_dye", "minecraft:creeper_banner_pattern")
+	setItems(t, simulator, map[resources_control.SlotID]protocol.ItemStack{
+		0: {
+			ItemType: protocol.ItemType{NetworkID: networkIDBanner},
+			Count:    1,
+			NBTData:  map[string]any{"Patterns": bannerPatterns(item_stack_transaction.MaxBannerPatterns - 1)},
+		},
+		1: {ItemType: protocol.ItemType{NetworkID: networkIDRedDye}, Count: 2},
+		2: {ItemType: protocol.ItemType{NetworkID: networkIDCreeperPattern}, Count: 1},
+	})
+	resultItem := func(count int) resources_control.ExpectedNewItem {
+		return resources_control.ExpectedNewItem{
+			NBT: resources_control.ItemNewNBTData{
+				UseNBTData: true,
+				NBTData:    map[string]any{"Patterns": bannerPatterns(count)},
+			},
+		}
+	}
+
+	// 未打开织布机时无法织布
+	success, responses := item_stack_transaction.NewItemStackTransaction(nil).
+		LoomingFromInventory("cre", 2, 0, 1, resultItem(item_stack_transaction.MaxBannerPatterns)).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusInvalidCraftRequestScreen {
+		t.Fatalf("TestSimulatorLooming: Unexpected result %v %#v", success, responses)
+	}
+
+	// 图案物品与图案不匹配
+	simulator.OpenContainer(1, protocol.ContainerTypeLoom)
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		LoomingFromInventory("flo", 2, 0, 1, resultItem(item_stack_transaction.MaxBannerPatterns)).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusMismatchedRecipeForInputGridItems {
+		t.Fatalf("TestSimulatorLooming: Unexpected result %v %#v", success, responses)
+	}
+	if count(t, simulator, inventory(1)) != 2 {
+		t.Fatalf("TestSimulatorLooming: Failed request consumed the dye")
+	}
+
+	// 添加最后一个图案将消耗染料，但不消耗图案物品
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		LoomingFromInventory("cre", 2, 0, 1, resultItem(item_stack_transaction.MaxBannerPatterns)).
+		DryRun(simulator)
+	if !success {
+		t.Fatalf("TestSimulatorLooming: Unexpected result %v %#v", success, responses)
+	}
+	if count(t, simulator, inventory(0)) != 1 || count(t, simulator, inventory(1)) != 1 || count(t, simulator, inventory(2)) != 1 {
+		t.Errorf("TestSimulatorLooming: Unexpected items after looming")
+	}
+	banner, _ := simulator.GetItemStack(inventory(0))
+	if patterns, _ := banner.Stack.NBTData["Patterns"].([]any); len(patterns) != item_stack_transaction.MaxBannerPatterns {
+		t.Errorf("TestSimulatorLooming: Unexpected banner %#v", banner)
+	}
+
+	// 图案已达上限的旗帜无法继续织布
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		LoomingFromInventory("bo", 0, 0, 1, resultItem(item_stack_transaction.MaxBannerPatterns+1)).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusInvalidCraftResult {
+		t.Fatalf("TestSimulatorLooming: Unexpected result %v %#v", success, responses)
+	}
+	if count(t, simulator, inventory(1)) != 1 {
+		t.Errorf("TestSimulatorLooming: Failed request consumed the dye")
+	}
+}
+
+func TestSimulatorTrimming(t *testing.T) {
+	const (
+		networkIDChestplate = iota + 1
+		networkIDDiamond
+		networkIDTemplate
+		networkIDStone
+	)
+	simulator := newSimulatorWithItems(
+		"minecraft:iron_chestplate", "minecraft:diamond",
+		"minecraft:coast_armor_trim_smithing_template", "minecraft:stone",
+	)
+	setItems(t, simulator, map[resources_control.SlotID]protocol.ItemStack{
+		0: {ItemType: protocol.ItemType{NetworkID: networkIDChestplate}, Count: 1},
+		1: {ItemType: protocol.ItemType{NetworkID: networkIDDiamond}, Count: 3},
+		2: {ItemType: protocol.ItemType{NetworkID: networkIDTemplate}, Count: 1},
+		3: {ItemType: protocol.ItemType{NetworkID: networkIDStone}, Count: 1},
+	})
+	resultItem := resources_control.ExpectedNewItem{
+		NBT: resources_control.ItemNewNBTData{
+			UseNBTData: true,
+			NBTData:    map[string]any{"Trim": map[string]any{"Material": "diamond", "Pattern": "coast"}},
+		},
+	}
+
+	// 未打开锻造台时无法锻造
+	success, responses := item_stack_transaction.NewItemStackTransaction(nil).
+		TrimmingFromInventory(0, 1, 2, resultItem).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusInvalidCraftRequestScreen {
+		t.Fatalf("TestSimulatorTrimming: Unexpected result %v %#v", success, responses)
+	}
+
+	// 石头不是纹饰材料
+	simulator.OpenContainer(1, protocol.ContainerTypeSmithingTable)
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		TrimmingFromInventory(0, 3, 2, resultItem).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusMissingMaterialItem {
+		t.Fatalf("TestSimulatorTrimming: Unexpected result %v %#v", success, responses)
+	}
+
+	// 锻造将消耗材料和模板，而盔甲将回到原位
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		TrimmingFromInventory(0, 1, 2, resultItem).
+		DryRun(simulator)
+	if !success {
+		t.Fatalf("TestSimulatorTrimming: Unexpected result %v %#v", success, responses)
+	}
+	if count(t, simulator, inventory(1)) != 2 || count(t, simulator, inventory(2)) != 0 {
+		t.Errorf("TestSimulatorTrimming: Material or template is not consumed")
+	}
+	chestplate, _ := simulator.GetItemStack(inventory(0))
+	if trim, _ := chestplate.Stack.NBTData["Trim"].(map[string]any); trim["Pattern"] != "coast" {
+		t.Errorf("TestSimulatorTrimming: Unexpected chestplate %#v", chestplate)
+	}
+
+	// 模板已被消耗
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		TrimmingFromInventory(0, 1, 2, resultItem).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusMismatchedRecipeForInputGridItems {
+		t.Fatalf("TestSimulatorTrimming: Unexpected result %v %#v", success, responses)
+	}
+}
+
+func TestSimulatorCrafting(t *testing.T) {
+	simulator := newSimulator(t)
+	resultItem := resources_control.ExpectedNewItem{
+		ItemType: resources_control.ItemNewType{UseNetworkID: true, NetworkID: networkIDDiamondAxe},
+	}
+
+	// 合成栏为空时无法合成
+	success, responses := item_stack_transaction.NewItemStackTransaction(nil).
+		Crafting(0, 9, 1, resultItem).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusMissingInputItem {
+		t.Fatalf("TestSimulatorCrafting: Unexpected result %v %#v", success, responses)
+	}
+
+	transaction := item_stack_transaction.NewItemStackTransaction(nil)
+	for slotID := range resources_control.SlotID(4) {
+		transaction.MoveToCraftingTable(0, 28+slotID, 1)
+	}
+	success, responses = transaction.DryRun(simulator)
+	if !success || count(t, simulator, inventory(0)) != 36 {
+		t.Fatalf("TestSimulatorCrafting: Unexpected result %v %#v", success, responses)
+	}
+
+	// 合成所得的物品无法与目标槽位的物品堆叠，因此合成栏中的物品不被消耗
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		Crafting(0, 3, 1, resultItem).
+		DryRun(simulator)
+	if success || responses[0].Status != protocol.ItemStackResponseStatusCannotPlaceItem {
+		t.Fatalf("TestSimulatorCrafting: Unexpected result %v %#v", success, responses)
+	}
+	crafting := resources_control.SlotLocation{WindowID: protocol.WindowIDCrafting, SlotID: 28}
+	if count(t, simulator, crafting) != 1 {
+		t.Fatalf("TestSimulatorCrafting: Failed request consumed the crafting input")
+	}
+
+	success, responses = item_stack_transaction.NewItemStackTransaction(nil).
+		Crafting(0, 9, 1, resultItem).
+		DryRun(simulator)
+	if !success || count(t, simulator, inventory(9)) != 1 || count(t, simulator, crafting) != 0 {
+		t.Fatalf("TestSimulatorCrafting: Unexpected result %v %#v", success, responses)
+	}
+	if item, _ := simulator.GetItemStack(inventory(9)); item.Stack.NetworkID != networkIDDiamondAxe {
+		t.Errorf("TestSimulatorCrafting: Unexpected item %#v", item)
+	}
+}
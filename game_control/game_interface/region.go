@@ -0,0 +1,491 @@
+package game_interface
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+)
+
+const (
+	// MaxRegionCommandBlocks 是单条 fill, clone 或
+	// testforblocks 命令所能操作的最大方块数量
+	MaxRegionCommandBlocks = 32768
+	// MaxRegionCommandLength 是单条区域命令在 X 轴
+	// 或 Z 轴上的最大长度。这确保机器人被传送到该区域
+	// 的中心后，该区域所在的区块都已被加载
+	MaxRegionCommandLength = 64
+	// MaxMismatchChecks 是在单个子区域中查找不满足要求的
+	// 方块时所能进行的最大检查次数。当超过此限制时，整个
+	// 子区域中的所有方块都将被视为不满足要求
+	MaxMismatchChecks = 256
+)
+
+// RegionBox 是由两个角所围成的方块区域 (包含两端)
+type RegionBox struct {
+	Start protocol.BlockPos // Start 是区域的最小坐标
+	End   protocol.BlockPos // End 是区域的最大坐标
+}
+
+// NewRegionBox 返回由 pos1 和 pos2 所围成的方块区域
+func NewRegionBox(pos1 protocol.BlockPos, pos2 protocol.BlockPos) (result RegionBox) {
+	for index := range 3 {
+		result.Start[index] = min(pos1[index], pos2[index])
+		result.End[index] = max(pos1[index], pos2[index])
+	}
+	return
+}
+
+// Size 返回区域在各个轴上的长度
+func (r RegionBox) Size() protocol.BlockPos {
+	return protocol.BlockPos{
+		r.End[0] - r.Start[0] + 1,
+		r.End[1] - r.Start[1] + 1,
+		r.End[2] - r.Start[2] + 1,
+	}
+}
+
+// Volume 返回区域所包含的方块数量
+func (r RegionBox) Volume() int {
+	size := r.Size()
+	return int(size[0]) * int(size[1]) * int(size[2])
+}
+
+// Center 返回区域的中心
+func (r RegionBox) Center() protocol.BlockPos {
+	return protocol.BlockPos{
+		(r.Start[0] + r.End[0]) / 2,
+		(r.Start[1] + r.End[1]) / 2,
+		(r.Start[2] + r.End[2]) / 2,
+	}
+}
+
+// Offset 返回将区域平移 offset 后的新区域
+func (r RegionBox) Offset(offset protocol.BlockPos) RegionBox {
+	return RegionBox{
+		Start: protocol.BlockPos{r.Start[0] + offset[0], r.Start[1] + offset[1], r.Start[2] + offset[2]},
+		End:   protocol.BlockPos{r.End[0] + offset[0], r.End[1] + offset[1], r.End[2] + offset[2]},
+	}
+}
+
+// Intersects 检查区域是否与 other 相交
+func (r RegionBox) Intersects(other RegionBox) bool {
+	for index := range 3 {
+		if r.End[index] < other.Start[index] || other.End[index] < r.Start[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// Split 将区域切分为若干子区域，使得每个子区域都可以被单条区域命令操作。
+// 子区域在 X 轴和 Z 轴上的长度不超过 MaxRegionCommandLength，且方块数量
+// 不超过 MaxRegionCommandBlocks
+func (r RegionBox) Split() (result []RegionBox) {
+	size := r.Size()
+	tileX := min(size[0], MaxRegionCommandLength)
+	tileZ := min(size[2], MaxRegionCommandLength, MaxRegionCommandBlocks/tileX)
+	tileY := min(size[1], MaxRegionCommandBlocks/(tileX*tileZ))
+
+	for x := r.Start[0]; x <= r.End[0]; x += tileX {
+		for y := r.Start[1]; y <= r.End[1]; y += tileY {
+			for z := r.Start[2]; z <= r.End[2]; z += tileZ {
+				result = append(result, RegionBox{
+					Start: protocol.BlockPos{x, y, z},
+					End: protocol.BlockPos{
+						min(x+tileX-1, r.End[0]),
+						min(y+tileY-1, r.End[1]),
+						min(z+tileZ-1, r.End[2]),
+					},
+				})
+			}
+		}
+	}
+
+	return
+}
+
+// union 返回同时包含区域 r 和 other 的最小区域
+func (r RegionBox) union(other RegionBox) (result RegionBox) {
+	for index := range 3 {
+		result.Start[index] = min(r.Start[index], other.Start[index])
+		result.End[index] = max(r.End[index], other.End[index])
+	}
+	return
+}
+
+// positions 返回区域中所有方块的坐标
+func (r RegionBox) positions() (result []protocol.BlockPos) {
+	result = make([]protocol.BlockPos, 0, r.Volume())
+	for x := r.Start[0]; x <= r.End[0]; x++ {
+		for y := r.Start[1]; y <= r.End[1]; y++ {
+			for z := r.Start[2]; z <= r.End[2]; z++ {
+				result = append(result, protocol.BlockPos{x, y, z})
+			}
+		}
+	}
+	return
+}
+
+// bisect 沿区域最长的轴将区域对半切分
+func (r RegionBox) bisect() (first RegionBox, second RegionBox) {
+	size := r.Size()
+	axis := 0
+	for index := range 3 {
+		if size[index] > size[axis] {
+			axis = index
+		}
+	}
+
+	first, second = r, r
+	first.End[axis] = r.Start[axis] + size[axis]/2 - 1
+	second.Start[axis] = first.End[axis] + 1
+	return
+}
+
+// Region 是基于 Commands 实现的，
+// 通过 fill, clone 和 testforblocks
+// 等命令批量操作方块区域的若干实现
+type Region struct {
+	api *Commands
+}
+
+// NewRegion 基于 api 创建并返回一个新的 Region
+func NewRegion(api *Commands) *Region {
+	return &Region{api: api}
+}
+
+// teleportAndAwait 将机器人传送到 pos 并等待租赁服完成更改。
+// 这通常用于确保 pos 附近的区块已被加载
+func teleportAndAwait(api *Commands, pos protocol.BlockPos) error {
+	err := api.SendSettingsCommand(fmt.Sprintf("tp %d %d %d", pos[0], pos[1], pos[2]), true)
+	if err != nil {
+		return fmt.Errorf("teleportAndAwait: %v", err)
+	}
+	err = api.AwaitChangesGeneral()
+	if err != nil {
+		return fmt.Errorf("teleportAndAwait: %v", err)
+	}
+	return nil
+}
+
+// runOnBox 为区域 box 发送命令 request。
+// 如果命令执行失败，则会将机器人传送到该区域的中心并重试一次。
+//
+// noChange 是命令因区域中没有方块需要被更改而失败时的输出消息，
+// 例如 commands.fill.failed。这种情况被视为成功，且不会重试。
+// 返回的 success 指示命令最终是否成功执行
+func (r *Region) runOnBox(box RegionBox, request string, noChange string) (success bool, err error) {
+	api := r.api
+
+	resp, err := api.SendWSCommandWithResp(request)
+	if err != nil {
+		return false, fmt.Errorf("runOnBox: %v", err)
+	}
+	if commandSucceedOrNoChange(resp, noChange) {
+		return true, nil
+	}
+
+	err = teleportAndAwait(api, box.Center())
+	if err != nil {
+		return false, fmt.Errorf("runOnBox: %v", err)
+	}
+	resp, err = api.SendWSCommandWithResp(request)
+	if err != nil {
+		return false, fmt.Errorf("runOnBox: %v", err)
+	}
+
+	return commandSucceedOrNoChange(resp, noChange), nil
+}
+
+// commandSucceedOrNoChange 检查命令输出 resp 是否指示命令成功执行，
+// 或命令的输出消息为 noChange (即命令没有需要更改的方块)
+func commandSucceedOrNoChange(resp *packet.CommandOutput, noChange string) bool {
+	if resp.SuccessCount > 0 {
+		return true
+	}
+	for _, message := range resp.OutputMessages {
+		if message.Message == noChange {
+			return true
+		}
+	}
+	return false
+}
+
+// testCommand 发送命令 request 并返回其是否成功执行。
+// request 应当是只读的检测命令，因此它可以在超时后被重试
+func (r *Region) testCommand(request string) (success bool, err error) {
+	resp, _, err := r.api.SendWSCommandWithOptions(request, CommandOptions{Repeatable: true})
+	if err != nil {
+		return false, fmt.Errorf("testCommand: %v", err)
+	}
+	return resp.SuccessCount > 0, nil
+}
+
+// findMismatch 找出 box 中所有不满足 check 的方块。
+// check 检查给定的区域是否完全满足要求，而 findMismatch
+// 会不断对半切分不满足要求的区域，直到找到单个的方块。
+//
+// 调用 check 的次数不会超过 MaxMismatchChecks。
+// 如果达到此限制，则 box 中的所有方块都将被返回
+func findMismatch(box RegionBox, check func(box RegionBox) (bool, error)) (result []protocol.BlockPos, err error) {
+	budget := MaxMismatchChecks
+	result, exhausted, err := searchMismatch(box, check, &budget)
+	if err != nil {
+		return nil, fmt.Errorf("findMismatch: %v", err)
+	}
+	if exhausted {
+		return box.positions(), nil
+	}
+	return result, nil
+}
+
+// searchMismatch 是 findMismatch 的递归实现。
+// budget 是剩余的检查次数，而 exhausted 指示
+// 检查次数是否在搜索完成前就已被用尽
+func searchMismatch(box RegionBox, check func(box RegionBox) (bool, error), budget *int) (
+	result []protocol.BlockPos,
+	exhausted bool,
+	err error,
+) {
+	if *budget <= 0 {
+		return nil, true, nil
+	}
+	*budget--
+
+	ok, err := check(box)
+	if err != nil {
+		return nil, false, fmt.Errorf("searchMismatch: %v", err)
+	}
+	if ok {
+		return nil, false, nil
+	}
+	if box.Volume() == 1 {
+		return []protocol.BlockPos{box.Start}, false, nil
+	}
+
+	first, second := box.bisect()
+	for _, half := range []RegionBox{first, second} {
+		mismatch, exhausted, err := searchMismatch(half, check, budget)
+		if err != nil || exhausted {
+			return nil, exhausted, err
+		}
+		result = append(result, mismatch...)
+	}
+
+	return result, false, nil
+}
+
+// isFilledWith 检查 box 中的所有方块是否都是名为
+// name 且方块状态为 states 的方块。
+//
+// 它先使用 testforblock 检查区域的起点，然后使用
+// testforblocks 检查区域在每个轴上平移一格后是否
+// 与其自身相同，从而只需要常数条命令即可完成检查
+func (r *Region) isFilledWith(box RegionBox, name string, states string) (result bool, err error) {
+	ok, err := r.testCommand(
+		fmt.Sprintf("testforblock %d %d %d %s %s", box.Start[0], box.Start[1], box.Start[2], name, states),
+	)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	size := box.Size()
+	for axis := range 3 {
+		if size[axis] == 1 {
+			continue
+		}
+
+		src := box
+		src.End[axis]--
+		offset := protocol.BlockPos{}
+		offset[axis] = 1
+		dst := src.Offset(offset)
+
+		ok, err = r.testCommand(
+			fmt.Sprintf(
+				"testforblocks %d %d %d %d %d %d %d %d %d",
+				src.Start[0], src.Start[1], src.Start[2],
+				src.End[0], src.End[1], src.End[2],
+				dst.Start[0], dst.Start[1], dst.Start[2],
+			),
+		)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// VerifyFill 检查 pos1 和 pos2 所围成的区域中的所有方块
+// 是否都是名为 name 且方块状态为 states 的方块，并返回所
+// 有不满足此条件的方块的坐标。
+//
+// 如果某个子区域中不满足条件的方块过多，
+// 以至于超过了 MaxMismatchChecks 的限制，
+// 则该子区域中的所有方块都将被返回
+func (r *Region) VerifyFill(pos1 protocol.BlockPos, pos2 protocol.BlockPos, name string, states string) (
+	mismatch []protocol.BlockPos,
+	err error,
+) {
+	for _, box := range NewRegionBox(pos1, pos2).Split() {
+		result, err := findMismatch(box, func(box RegionBox) (bool, error) {
+			return r.isFilledWith(box, name, states)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("VerifyFill: %v", err)
+		}
+		mismatch = append(mismatch, result...)
+	}
+	return
+}
+
+// Fill 使用名为 name 且方块状态为 states 的方块
+// 填充 pos1 和 pos2 所围成的区域。
+//
+// 该区域将被切分为若干子区域，并对每个子区域使用
+// 单条 fill 命令。如果某个子区域所在的区块尚未被
+// 加载，则机器人将被传送到该子区域处。
+//
+// 在填充完成后，Fill 将使用 VerifyFill 检查填充结果，
+// 并返回所有未能被成功更改的方块的坐标
+func (r *Region) Fill(pos1 protocol.BlockPos, pos2 protocol.BlockPos, name string, states string) (
+	failed []protocol.BlockPos,
+	err error,
+) {
+	for _, box := range NewRegionBox(pos1, pos2).Split() {
+		_, err = r.runOnBox(
+			box,
+			fmt.Sprintf(
+				"fill %d %d %d %d %d %d %s %s",
+				box.Start[0], box.Start[1], box.Start[2],
+				box.End[0], box.End[1], box.End[2],
+				name, states,
+			),
+			"commands.fill.failed",
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Fill: %v", err)
+		}
+	}
+
+	failed, err = r.VerifyFill(pos1, pos2, name, states)
+	if err != nil {
+		return nil, fmt.Errorf("Fill: %v", err)
+	}
+	return failed, nil
+}
+
+// FillReplace 使用名为 name 且方块状态为 states 的方块
+// 替换 pos1 和 pos2 所围成的区域中所有名为 filterName
+// 且方块状态为 filterStates 的方块。
+//
+// 与 Fill 不同，替换后的区域通常不是均匀的，
+// 因此 FillReplace 不会检查替换结果。
+// failed 是所有未能成功执行替换的子区域。
+// 如果子区域中没有可替换的方块，则该子区域
+// 不会被认为是失败的
+func (r *Region) FillReplace(
+	pos1 protocol.BlockPos, pos2 protocol.BlockPos,
+	name string, states string,
+	filterName string, filterStates string,
+) (failed []RegionBox, err error) {
+	for _, box := range NewRegionBox(pos1, pos2).Split() {
+		success, err := r.runOnBox(
+			box,
+			fmt.Sprintf(
+				"fill %d %d %d %d %d %d %s %s replace %s %s",
+				box.Start[0], box.Start[1], box.Start[2],
+				box.End[0], box.End[1], box.End[2],
+				name, states, filterName, filterStates,
+			),
+			"commands.fill.failed",
+		)
+		if err != nil {
+			return nil, fmt.Errorf("FillReplace: %v", err)
+		}
+		if !success {
+			failed = append(failed, box)
+		}
+	}
+	return
+}
+
+// VerifyClone 检查 pos1 和 pos2 所围成的区域是否与以 dest
+// 为起点的等大区域完全相同，并返回后者中所有不相同的方块的坐标。
+// 与 VerifyFill 相同，超过 MaxMismatchChecks 限制的子区域
+// 中的所有方块都将被返回
+func (r *Region) VerifyClone(pos1 protocol.BlockPos, pos2 protocol.BlockPos, dest protocol.BlockPos) (
+	mismatch []protocol.BlockPos,
+	err error,
+) {
+	src := NewRegionBox(pos1, pos2)
+	offset := protocol.BlockPos{dest[0] - src.Start[0], dest[1] - src.Start[1], dest[2] - src.Start[2]}
+
+	for _, box := range src.Split() {
+		result, err := findMismatch(box, func(box RegionBox) (bool, error) {
+			dst := box.Offset(offset)
+			return r.testCommand(
+				fmt.Sprintf(
+					"testforblocks %d %d %d %d %d %d %d %d %d",
+					box.Start[0], box.Start[1], box.Start[2],
+					box.End[0], box.End[1], box.End[2],
+					dst.Start[0], dst.Start[1], dst.Start[2],
+				),
+			)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("VerifyClone: %v", err)
+		}
+		for _, pos := range result {
+			mismatch = append(mismatch, protocol.BlockPos{pos[0] + offset[0], pos[1] + offset[1], pos[2] + offset[2]})
+		}
+	}
+
+	return
+}
+
+// Clone 将 pos1 和 pos2 所围成的区域复制到以 dest 为起点的等大区域。
+//
+// 与 Fill 相同，该区域将被切分为若干子区域，并对每个子区域使用
+// 单条 clone 命令。源区域与目标区域不能相交。
+// 如果命令执行失败，则机器人将被传送到同时包含
+// 源子区域和目标子区域的最小区域的中心并重试，
+// 因此它们需要足够接近以在此处被同时加载。
+//
+// 在复制完成后，Clone 将使用 VerifyClone 检查复制结果，
+// 并返回目标区域中所有未能被成功更改的方块的坐标
+func (r *Region) Clone(pos1 protocol.BlockPos, pos2 protocol.BlockPos, dest protocol.BlockPos) (
+	failed []protocol.BlockPos,
+	err error,
+) {
+	src := NewRegionBox(pos1, pos2)
+	offset := protocol.BlockPos{dest[0] - src.Start[0], dest[1] - src.Start[1], dest[2] - src.Start[2]}
+	if src.Intersects(src.Offset(offset)) {
+		return nil, fmt.Errorf("Clone: The source region %#v and the destination region start from %v are overlapping", src, dest)
+	}
+
+	for _, box := range src.Split() {
+		dst := box.Offset(offset)
+		_, err = r.runOnBox(
+			box.union(dst),
+			fmt.Sprintf(
+				"clone %d %d %d %d %d %d %d %d %d",
+				box.Start[0], box.Start[1], box.Start[2],
+				box.End[0], box.End[1], box.End[2],
+				dst.Start[0], dst.Start[1], dst.Start[2],
+			),
+			"commands.clone.failed",
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Clone: %v", err)
+		}
+	}
+
+	failed, err = r.VerifyClone(pos1, pos2, dest)
+	if err != nil {
+		return nil, fmt.Errorf("Clone: %v", err)
+	}
+	return failed, nil
+}
@@ -0,0 +1,106 @@
+package game_interface
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mock_server"
+)
+
+func TestFindMismatch(t *testing.T) {
+	box := NewRegionBox(protocol.BlockPos{0, 0, 0}, protocol.BlockPos{15, 3, 15})
+	target := protocol.BlockPos{7, 2, 9}
+
+	var checks int
+	result, err := findMismatch(box, func(box RegionBox) (bool, error) {
+		checks++
+		return !box.Intersects(RegionBox{Start: target, End: target}), nil
+	})
+	if err != nil {
+		t.Fatalf("TestFindMismatch: %v", err)
+	}
+	if len(result) != 1 || result[0] != target {
+		t.Fatalf("TestFindMismatch: Unexpected result %v", result)
+	}
+
+	checks = 0
+	result, err = findMismatch(box, func(box RegionBox) (bool, error) {
+		checks++
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("TestFindMismatch: %v", err)
+	}
+	if checks > MaxMismatchChecks || len(result) != box.Volume() {
+		t.Fatalf("TestFindMismatch: Unexpected result (checks = %d, mismatch = %d)", checks, len(result))
+	}
+}
+
+func TestRegionFill(t *testing.T) {
+	var fillCount atomic.Int32
+	server, commands := newMockCommands(t, func(commandLine string) bool {
+		if strings.HasPrefix(commandLine, "fill") {
+			fillCount.Add(1)
+		}
+		return false
+	})
+	region := NewRegion(commands)
+	world := server.World()
+
+	pos1, pos2 := protocol.BlockPos{0, 0, 0}, protocol.BlockPos{3, 1, 3}
+	failed, err := region.Fill(pos1, pos2, "stone", "[]")
+	if err != nil {
+		t.Fatalf("TestRegionFill: %v", err)
+	}
+	if len(failed) != 0 {
+		t.Fatalf("TestRegionFill: Unexpected failed blocks %v", failed)
+	}
+	if block := world.Block(protocol.BlockPos{3, 1, 3}); block.Name != "minecraft:stone" {
+		t.Fatalf("TestRegionFill: Region is not filled (got %#v)", block)
+	}
+
+	// 区域已被填充，因此 fill 命令会因没有
+	// 方块被更改而失败，但这不应导致重试
+	fillCount.Store(0)
+	if _, err = region.Fill(pos1, pos2, "stone", "[]"); err != nil {
+		t.Fatalf("TestRegionFill: %v", err)
+	}
+	if count := fillCount.Load(); count != 1 {
+		t.Fatalf("TestRegionFill: Unexpected fill count %d", count)
+	}
+
+	mismatch := protocol.BlockPos{2, 1, 1}
+	world.SetBlock(mismatch, mock_server.Block{Name: "minecraft:dirt"})
+	result, err := region.VerifyFill(pos1, pos2, "stone", "[]")
+	if err != nil {
+		t.Fatalf("TestRegionFill: %v", err)
+	}
+	if len(result) != 1 || result[0] != mismatch {
+		t.Fatalf("TestRegionFill: Unexpected mismatch %v", result)
+	}
+}
+
+func TestRegionClone(t *testing.T) {
+	server, commands := newMockCommands(t, nil)
+	region := NewRegion(commands)
+	world := server.World()
+
+	// 源区域与目标区域相距 100 格，因此机器人需要被传送到
+	// 它们之间才能使两者同时被加载
+	pos1, pos2 := protocol.BlockPos{1000, 0, 0}, protocol.BlockPos{1003, 1, 3}
+	dest := protocol.BlockPos{1100, 0, 0}
+	world.SetBlock(protocol.BlockPos{1001, 1, 2}, mock_server.Block{Name: "minecraft:stone"})
+
+	failed, err := region.Clone(pos1, pos2, dest)
+	if err != nil {
+		t.Fatalf("TestRegionClone: %v", err)
+	}
+	if len(failed) != 0 {
+		t.Fatalf("TestRegionClone: Unexpected failed blocks %v", failed)
+	}
+	if block := world.Block(protocol.BlockPos{1101, 1, 2}); block.Name != "minecraft:stone" {
+		t.Fatalf("TestRegionClone: Region is not cloned (got %#v)", block)
+	}
+}
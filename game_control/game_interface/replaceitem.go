@@ -15,6 +15,11 @@ const (
 	ReplacePathInventoryOnly ReplaceitemPath = "slot.inventory"
 	ReplacePathHotbarOnly    ReplaceitemPath = "slot.hotbar"
 	ReplacePathInventory     ReplaceitemPath = "slot.inventory | slot.hotbar"
+	ReplacePathOffhand       ReplaceitemPath = "slot.weapon.offhand"
+	ReplacePathArmorHead     ReplaceitemPath = "slot.armor.head"
+	ReplacePathArmorChest    ReplaceitemPath = "slot.armor.chest"
+	ReplacePathArmorLegs     ReplaceitemPath = "slot.armor.legs"
+	ReplacePathArmorFeet     ReplaceitemPath = "slot.armor.feet"
 )
 
 // ReplaceitemInfo 指示要通过 replaceitem 生成的物品的基本信息
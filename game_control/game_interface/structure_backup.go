@@ -19,32 +19,28 @@ func NewStructureBackup(api *Commands) *StructureBackup {
 	return &StructureBackup{api: api}
 }
 
+// structureSaveCommand 返回将 startPos 到 endPos 所围成的区域
+// 保存为唯一标识符为 uniqueID 的结构的 structure save 命令
+func structureSaveCommand(uniqueID uuid.UUID, startPos protocol.BlockPos, endPos protocol.BlockPos) string {
+	return fmt.Sprintf(
+		`structure save "%s" %d %d %d %d %d %d`,
+		utils.MakeUUIDSafeString(uniqueID),
+		startPos[0], startPos[1], startPos[2],
+		endPos[0], endPos[1], endPos[2],
+	)
+}
+
 // backupStructure 是一个内部实现细节，
 // 不应被其他人所使用
 func (s *StructureBackup) backupStructure(startPos protocol.BlockPos, endPos protocol.BlockPos) (result uuid.UUID, err error) {
 	api := s.api
 
 	uniqueId := uuid.New()
-	request := fmt.Sprintf(
-		`structure save "%s" %d %d %d %d %d %d`,
-		utils.MakeUUIDSafeString(uniqueId),
-		startPos[0], startPos[1], startPos[2],
-		endPos[0], endPos[1], endPos[2],
-	)
-	resp, isTimeout, err := api.SendWSCommandWithTimeout(request, DefaultTimeoutCommandRequest)
-
-	if isTimeout {
-		err = api.SendSettingsCommand(request, true)
-		if err != nil {
-			return uuid.UUID{}, fmt.Errorf("backupStructure: %v", err)
-		}
-		err = api.AwaitChangesGeneral()
-		if err != nil {
-			return uuid.UUID{}, fmt.Errorf("backupStructure: %v", err)
-		}
-		return uniqueId, nil
-	}
+	request := structureSaveCommand(uniqueId, startPos, endPos)
 
+	// 由于结构的名称是固定的，重复保存只会覆盖为相同的结构，
+	// 因此 structure save 命令可以在超时后由调度器重试
+	resp, _, err := api.SendWSCommandWithOptions(request, CommandOptions{Repeatable: true})
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("backupStructure: %v", err)
 	}
@@ -97,20 +93,10 @@ func (s *StructureBackup) RevertStructure(uniqueID uuid.UUID, pos protocol.Block
 		pos[1],
 		pos[2],
 	)
-	resp, isTimeOut, err := api.SendWSCommandWithTimeout(request, DefaultTimeoutCommandRequest)
-
-	if isTimeOut {
-		err = api.SendSettingsCommand(request, true)
-		if err != nil {
-			return fmt.Errorf("RevertStructure: %v", err)
-		}
-		err = api.AwaitChangesGeneral()
-		if err != nil {
-			return fmt.Errorf("RevertStructure: %v", err)
-		}
-		return nil
-	}
 
+	// 在同一位置重复加载同一结构的结果是相同的，
+	// 因此 structure load 命令可以在超时后由调度器重试
+	resp, _, err := api.SendWSCommandWithOptions(request, CommandOptions{Repeatable: true})
 	if err != nil {
 		return fmt.Errorf("RevertStructure: %v", err)
 	}
@@ -0,0 +1,226 @@
+package game_interface
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxStructureSizeX 是单个结构在 X 轴上的最大长度
+	MaxStructureSizeX = 64
+	// MaxStructureSizeY 是单个结构在 Y 轴上的最大长度
+	MaxStructureSizeY = 384
+	// MaxStructureSizeZ 是单个结构在 Z 轴上的最大长度
+	MaxStructureSizeZ = 64
+)
+
+// StructureTile 是区域备份中的单个结构
+type StructureTile struct {
+	// UniqueID 是该结构的唯一标识符
+	UniqueID uuid.UUID `json:"unique_id"`
+	// Origin 是该结构的起点
+	Origin protocol.BlockPos `json:"origin"`
+	// Size 是该结构的尺寸
+	Size protocol.BlockPos `json:"size"`
+}
+
+// RegionBackup 是区域备份的清单。
+// 它可以被序列化为 JSON 以便于持久化
+type RegionBackup struct {
+	// StartPos 是区域的最小坐标
+	StartPos protocol.BlockPos `json:"start_pos"`
+	// EndPos 是区域的最大坐标
+	EndPos protocol.BlockPos `json:"end_pos"`
+	// Tiles 是组成该区域的所有结构，
+	// 它们以备份的顺序排列
+	Tiles []StructureTile `json:"tiles"`
+}
+
+// splitRegion 将 startPos 到 endPos 所围成的区域 (包含两端)
+// 切分为若干合法的结构。返回的结构尚未被分配唯一标识符
+func splitRegion(startPos protocol.BlockPos, endPos protocol.BlockPos) (minPos protocol.BlockPos, maxPos protocol.BlockPos, tiles []StructureTile) {
+	limit := protocol.BlockPos{MaxStructureSizeX, MaxStructureSizeY, MaxStructureSizeZ}
+	for index := range 3 {
+		minPos[index] = min(startPos[index], endPos[index])
+		maxPos[index] = max(startPos[index], endPos[index])
+	}
+
+	for x := minPos[0]; x <= maxPos[0]; x += limit[0] {
+		for y := minPos[1]; y <= maxPos[1]; y += limit[1] {
+			for z := minPos[2]; z <= maxPos[2]; z += limit[2] {
+				tiles = append(tiles, StructureTile{
+					Origin: protocol.BlockPos{x, y, z},
+					Size: protocol.BlockPos{
+						min(limit[0], maxPos[0]-x+1),
+						min(limit[1], maxPos[1]-y+1),
+						min(limit[2], maxPos[2]-z+1),
+					},
+				})
+			}
+		}
+	}
+
+	return
+}
+
+// teleportToTile 将机器人传送到 tile 的中心，
+// 以确保该结构所在的区块已被加载
+func (s *StructureBackup) teleportToTile(tile StructureTile) error {
+	err := teleportAndAwait(
+		s.api,
+		protocol.BlockPos{
+			tile.Origin[0] + tile.Size[0]/2,
+			tile.Origin[1] + tile.Size[1]/2,
+			tile.Origin[2] + tile.Size[2]/2,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("teleportToTile: %v", err)
+	}
+	return nil
+}
+
+// saveTile 保存 tile 所指示的结构，并返回该结构的唯一标识符。
+//
+// 与 backupStructure 不同，如果 structure save 命令超时，
+// 则无法确认该结构是否已被保存。此时 saveTile 将删除可能
+// 已被保存的结构并返回错误，以确保清单中只记录已确认保存的结构
+func (s *StructureBackup) saveTile(tile StructureTile) (result uuid.UUID, err error) {
+	uniqueID := uuid.New()
+	request := structureSaveCommand(
+		uniqueID,
+		tile.Origin,
+		protocol.BlockPos{
+			tile.Origin[0] + tile.Size[0] - 1,
+			tile.Origin[1] + tile.Size[1] - 1,
+			tile.Origin[2] + tile.Size[2] - 1,
+		},
+	)
+
+	// 与 backupStructure 相同，重复保存同名的结构是安全的
+	resp, isTimeout, err := s.api.SendWSCommandWithOptions(request, CommandOptions{Repeatable: true})
+	if isTimeout {
+		_ = s.DeleteStructure(uniqueID)
+		return uuid.UUID{}, fmt.Errorf("saveTile: Command %#v is timed out, so the structure may not be saved", request)
+	}
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("saveTile: %v", err)
+	}
+	if resp.SuccessCount == 0 {
+		return uuid.UUID{}, fmt.Errorf("saveTile: The success count of the command %#v is 0", request)
+	}
+
+	return uniqueID, nil
+}
+
+// backupTile 备份 tile 所指示的结构，并返回该结构的唯一标识符。
+// 如果备份失败，则会将机器人传送到该结构处并重试一次
+func (s *StructureBackup) backupTile(tile StructureTile) (result uuid.UUID, err error) {
+	result, err = s.saveTile(tile)
+	if err == nil {
+		return
+	}
+
+	err = s.teleportToTile(tile)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("backupTile: %v", err)
+	}
+	result, err = s.saveTile(tile)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("backupTile: %v", err)
+	}
+
+	return
+}
+
+// revertTile 在 tile 的起点处恢复该结构。
+// 如果恢复失败，则会将机器人传送到该结构处并重试一次
+func (s *StructureBackup) revertTile(tile StructureTile) error {
+	err := s.RevertStructure(tile.UniqueID, tile.Origin)
+	if err == nil {
+		return nil
+	}
+
+	err = s.teleportToTile(tile)
+	if err != nil {
+		return fmt.Errorf("revertTile: %v", err)
+	}
+	err = s.RevertStructure(tile.UniqueID, tile.Origin)
+	if err != nil {
+		return fmt.Errorf("revertTile: %v", err)
+	}
+
+	return nil
+}
+
+// BackupRegion 备份 startPos 到 endPos 所围成的区域 (包含两端)。
+//
+// 该区域将被切分为若干不超过结构尺寸限制的结构，并逐个保存。
+// 每个结构被保存后，progress 将被调用，其中 finished 是已保存
+// 的结构数量，而 total 是结构的总数。progress 可以为空。
+//
+// 为了确保目标区块已被加载，机器人可能会被传送到某些结构处。
+// 如果备份失败，则所有已保存的结构都将被删除
+func (s *StructureBackup) BackupRegion(
+	startPos protocol.BlockPos,
+	endPos protocol.BlockPos,
+	progress func(finished int, total int),
+) (manifest RegionBackup, err error) {
+	minPos, maxPos, tiles := splitRegion(startPos, endPos)
+	manifest = RegionBackup{StartPos: minPos, EndPos: maxPos}
+
+	for index, tile := range tiles {
+		tile.UniqueID, err = s.backupTile(tile)
+		if err != nil {
+			_ = s.DeleteRegion(manifest)
+			return RegionBackup{}, fmt.Errorf("BackupRegion: %v", err)
+		}
+		manifest.Tiles = append(manifest.Tiles, tile)
+		if progress != nil {
+			progress(index+1, len(tiles))
+		}
+	}
+
+	return manifest, nil
+}
+
+// RevertRegion 按备份的顺序恢复 manifest 所指示的区域。
+//
+// 每个结构被恢复后，progress 将被调用，其中 finished 是已恢复
+// 的结构数量，而 total 是结构的总数。progress 可以为空。
+//
+// 与 BackupRegion 相同，机器人可能会被传送到某些结构处。
+// 如果恢复失败，则已被恢复的结构不会被撤销
+func (s *StructureBackup) RevertRegion(manifest RegionBackup, progress func(finished int, total int)) error {
+	for index, tile := range manifest.Tiles {
+		err := s.revertTile(tile)
+		if err != nil {
+			return fmt.Errorf("RevertRegion: %v", err)
+		}
+		if progress != nil {
+			progress(index+1, len(manifest.Tiles))
+		}
+	}
+	return nil
+}
+
+// DeleteRegion 删除 manifest 所指示的所有结构。
+// 即便删除某个结构时出现错误，其余的结构也仍然会被删除，
+// 而返回的错误包含了删除每个结构时出现的所有错误
+func (s *StructureBackup) DeleteRegion(manifest RegionBackup) error {
+	var errs []error
+	for _, tile := range manifest.Tiles {
+		err := s.DeleteStructure(tile.UniqueID)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("DeleteRegion: %v", errors.Join(errs...))
+	}
+	return nil
+}
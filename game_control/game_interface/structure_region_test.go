@@ -0,0 +1,91 @@
+package game_interface
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mock_server"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+func TestSplitRegion(t *testing.T) {
+	minPos, maxPos, tiles := splitRegion(protocol.BlockPos{70, 0, 0}, protocol.BlockPos{0, 1, 1})
+	if minPos != (protocol.BlockPos{0, 0, 0}) || maxPos != (protocol.BlockPos{70, 1, 1}) {
+		t.Fatalf("TestSplitRegion: Unexpected bounds %v %v", minPos, maxPos)
+	}
+	if len(tiles) != 2 || tiles[0].Size != (protocol.BlockPos{64, 2, 2}) || tiles[1].Size != (protocol.BlockPos{7, 2, 2}) {
+		t.Fatalf("TestSplitRegion: Unexpected tiles %#v", tiles)
+	}
+}
+
+func TestStructureRegion(t *testing.T) {
+	server, commands := newMockCommands(t, nil)
+	backup := NewStructureBackup(commands)
+	world := server.World()
+
+	stone := mock_server.Block{Name: "minecraft:stone"}
+	for _, pos := range []protocol.BlockPos{{0, 0, 0}, {65, 0, 0}} {
+		world.SetBlock(pos, stone)
+	}
+
+	manifest, err := backup.BackupRegion(protocol.BlockPos{0, 0, 0}, protocol.BlockPos{70, 0, 0}, nil)
+	if err != nil {
+		t.Fatalf("TestStructureRegion: %v", err)
+	}
+	if len(manifest.Tiles) != 2 {
+		t.Fatalf("TestStructureRegion: Unexpected tiles %#v", manifest.Tiles)
+	}
+
+	for _, pos := range []protocol.BlockPos{{0, 0, 0}, {65, 0, 0}} {
+		world.SetBlock(pos, mock_server.Block{Name: mock_server.AirBlock})
+	}
+	if err = backup.RevertRegion(manifest, nil); err != nil {
+		t.Fatalf("TestStructureRegion: %v", err)
+	}
+	for _, pos := range []protocol.BlockPos{{0, 0, 0}, {65, 0, 0}} {
+		if block := world.Block(pos); block.Name != stone.Name {
+			t.Fatalf("TestStructureRegion: Block at %v is not reverted (got %#v)", pos, block)
+		}
+	}
+
+	if err = backup.DeleteRegion(manifest); err != nil {
+		t.Fatalf("TestStructureRegion: %v", err)
+	}
+	if err = commands.AwaitChangesGeneral(); err != nil {
+		t.Fatalf("TestStructureRegion: %v", err)
+	}
+	for _, tile := range manifest.Tiles {
+		if _, found := world.Structure(utils.MakeUUIDSafeString(tile.UniqueID)); found {
+			t.Fatalf("TestStructureRegion: Structure of tile %#v is not deleted", tile)
+		}
+	}
+}
+
+func TestStructureRegionSaveTimeout(t *testing.T) {
+	// 只丢弃第一次保存的输出
+	var saveCount atomic.Int32
+	server, commands := newMockCommands(t, func(commandLine string) bool {
+		return strings.HasPrefix(commandLine, "structure save") && saveCount.Add(1) == 1
+	})
+	backup := NewStructureBackup(commands)
+
+	manifest, err := backup.BackupRegion(protocol.BlockPos{0, 0, 0}, protocol.BlockPos{1, 1, 1}, nil)
+	if err != nil {
+		t.Fatalf("TestStructureRegionSaveTimeout: %v", err)
+	}
+	if saveCount.Load() != 2 || len(manifest.Tiles) != 1 {
+		t.Fatalf("TestStructureRegionSaveTimeout: Unexpected result %#v (count = %d)", manifest, saveCount.Load())
+	}
+	if err = commands.AwaitChangesGeneral(); err != nil {
+		t.Fatalf("TestStructureRegionSaveTimeout: %v", err)
+	}
+
+	// 超时的保存无法被确认，因此它被删除，
+	// 而清单中只记录了已确认保存的结构
+	structures := server.World().Structures()
+	if len(structures) != 1 || structures[0] != utils.MakeUUIDSafeString(manifest.Tiles[0].UniqueID) {
+		t.Fatalf("TestStructureRegionSaveTimeout: Unexpected structures %#v", structures)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
 
+	"github.com/google/uuid"
 	"github.com/pterm/pterm"
 )
 
@@ -16,6 +17,10 @@ import (
 // 若 ItemCanGetByCommand 返回假，是否需要在控制台打印相应的警告
 const DebugPrintUnknownItem = true
 
+// mapLockingRecipeUUID 是制图台锁定地图操作
+// 所对应的特殊合成配方 (MultiRecipe) 的 UUID
+var mapLockingRecipeUUID = uuid.MustParse("602234E4-CAC1-4353-8BB7-B1EBFF70024B")
+
 // ConstantPacket 记载在登录序列期间，
 // 由租赁服发送的在整个连接期间不会变化的常量
 type ConstantPacket struct {
@@ -31,8 +36,15 @@ type ConstantPacket struct {
 	// 所有可通过指令获得的物品
 	commandItems        []string
 	commandItemsMapping map[string]bool
+	// 所有可通过指令放置的方块
+	commandBlocks        []string
+	commandBlocksMapping map[string]bool
+	// 租赁服注册的自定义方块
+	customBlocks []protocol.BlockEntry
 	// 锻造台纹饰操作对应合成配方的网络 ID
 	trimRecipeNetworkID uint32
+	// 制图台锁定地图操作对应合成配方的网络 ID
+	mapLockingRecipeNetworkID uint32
 }
 
 // NewConstantPacket 创建并返回一个新的 ConstantPacket
@@ -47,6 +59,9 @@ func NewConstantPacket() *ConstantPacket {
 		creativeCNIMapping:   make(map[uint32]int),
 		commandItems:         nil,
 		commandItemsMapping:  make(map[string]bool),
+		commandBlocks:        nil,
+		commandBlocksMapping: make(map[string]bool),
+		customBlocks:         nil,
 	}
 }
 
@@ -122,8 +137,15 @@ func (c ConstantPacket) ItemNameByNetworkID(networkID int32) string {
 	return c.itemNameMappingInv[c.itemNetworkIDMapping[networkID]]
 }
 
+// CustomBlocks 返回租赁服在登录序列发送的自定义方块。
+// 使用者不应修改返回的值，否则不保证程序的行为是正确的
+func (c ConstantPacket) CustomBlocks() []protocol.BlockEntry {
+	return c.customBlocks
+}
+
 // updateByGameData ..
 func (c *ConstantPacket) updateByGameData(data minecraft.GameData) {
+	c.customBlocks = data.CustomBlocks
 	c.availableItems = data.Items
 	c.itemNameMappingInv = make([]string, len(c.availableItems))
 	for index, item := range c.availableItems {
@@ -156,45 +178,94 @@ func (c ConstantPacket) ItemCanGetByCommand(name string) bool {
 	return result
 }
 
+// AllCommandBlocks 返回可以通过指令放置的全部方块。
+// 使用者不应修改返回的值，否则不保证程序的行为是正确的
+func (c ConstantPacket) AllCommandBlocks() []string {
+	return c.commandBlocks
+}
+
+// BlockCanSetByCommand 检查方块名为 name 的方块是否可以通过命令放置。
+// 如果租赁服没有发送方块的命令枚举，则 BlockCanSetByCommand 总是返回真
+func (c ConstantPacket) BlockCanSetByCommand(name string) bool {
+	if len(c.commandBlocks) == 0 {
+		return true
+	}
+
+	name = strings.ToLower(name)
+	if !strings.Contains(name, ":") {
+		name = "minecraft:" + name
+	}
+	return c.commandBlocksMapping[name]
+}
+
 // onAvailableCommands ..
 func (c *ConstantPacket) onAvailableCommands(p *packet.AvailableCommands) {
+	var foundItems bool
+
 	c.commandItems = []string{"minecraft:written_book"}
 	c.commandItemsMapping = map[string]bool{
 		"minecraft:written_book": true,
 	}
+	c.commandBlocks = nil
+	c.commandBlocksMapping = make(map[string]bool)
 
 	for _, enum := range p.Enums {
-		if enum.Type != "Item" {
-			continue
-		}
-
-		for _, index := range enum.ValueIndices {
-			itemName := p.EnumValues[index]
-			if !strings.HasPrefix(itemName, "minecraft:") {
+		switch enum.Type {
+		case "Item":
+			if foundItems {
 				continue
 			}
-			c.commandItems = append(c.commandItems, itemName)
-			c.commandItemsMapping[itemName] = true
+			for _, index := range enum.ValueIndices {
+				itemName := p.EnumValues[index]
+				if !strings.HasPrefix(itemName, "minecraft:") {
+					continue
+				}
+				c.commandItems = append(c.commandItems, itemName)
+				c.commandItemsMapping[itemName] = true
+			}
+			foundItems = true
+		case "Block":
+			for _, index := range enum.ValueIndices {
+				blockName := strings.ToLower(p.EnumValues[index])
+				if !strings.Contains(blockName, ":") {
+					blockName = "minecraft:" + blockName
+				}
+				if c.commandBlocksMapping[blockName] {
+					continue
+				}
+				c.commandBlocks = append(c.commandBlocks, blockName)
+				c.commandBlocksMapping[blockName] = true
+			}
 		}
-
-		return
 	}
 
-	panic("onAvailableCommands: Should never happened")
+	if !foundItems {
+		panic("onAvailableCommands: Should never happened")
+	}
 }
 
-// ------------------------- Trim Recipe Network ID -------------------------
+// ------------------------- Recipe Network ID -------------------------
 
 // TrimRecipeNetworkID 返回锻造台纹饰操作对应的合成 ID
 func (c *ConstantPacket) TrimRecipeNetworkID() uint32 {
 	return c.trimRecipeNetworkID
 }
 
+// MapLockingRecipeNetworkID 返回制图台锁定地图操作对应的合成 ID
+func (c *ConstantPacket) MapLockingRecipeNetworkID() uint32 {
+	return c.mapLockingRecipeNetworkID
+}
+
 // onCraftingData ..
 func (c *ConstantPacket) onCraftingData(p *packet.CraftingData) {
 	for _, recipe := range p.Recipes {
-		if data, ok := recipe.(*protocol.SmithingTrimRecipe); ok {
+		switch data := recipe.(type) {
+		case *protocol.SmithingTrimRecipe:
 			c.trimRecipeNetworkID = data.RecipeNetworkID
+		case *protocol.MultiRecipe:
+			if data.UUID == mapLockingRecipeUUID {
+				c.mapLockingRecipeNetworkID = data.RecipeNetworkID
+			}
 		}
 	}
 }
@@ -50,7 +50,7 @@ func NewContainerManager(ctx context.Context) *ContainerManager {
 //   - 0: 曾经没有打开过容器
 //   - 1: 目前存在一个已被打开的容器
 //   - 2: 曾经打开过容器，但是关闭了
-func (c ContainerManager) States() uint8 {
+func (c *ContainerManager) States() uint8 {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -68,7 +68,7 @@ func (c ContainerManager) States() uint8 {
 // containerID 是提前预设的，这意味着其值如果
 // 不是 mapping.ContainerIDUnknown 则应当优
 // 先使用
-func (c ContainerManager) ContainerData() (data packet.ContainerOpen, containerID ContainerID, existed bool) {
+func (c *ContainerManager) ContainerData() (data packet.ContainerOpen, containerID ContainerID, existed bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -0,0 +1,395 @@
+package resources_control
+
+import (
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/google/uuid"
+)
+
+const (
+	// EntityTypePlayer 是玩家的实体类型
+	EntityTypePlayer = "minecraft:player"
+	// EntityTypeItem 是掉落物的实体类型
+	EntityTypeItem = "minecraft:item"
+	// EntityTypePainting 是画的实体类型
+	EntityTypePainting = "minecraft:painting"
+)
+
+// Entity 描述租赁服已向机器人发送的单个实体。
+// 机器人自身不会被视为实体
+type Entity struct {
+	EntityUniqueID  int64          // 实体的唯一 ID
+	EntityRuntimeID uint64         // 实体的运行时 ID
+	EntityType      string         // 实体的类型，例如 minecraft:zombie
+	Username        string         // 玩家的名称。如果不是玩家，则为空
+	UUID            uuid.UUID      // 玩家的 UUID。如果不是玩家，则为零值
+	Position        mgl32.Vec3     // 实体的位置
+	Rotation        mgl32.Vec3     // 实体的朝向，依次为 Pitch, Yaw 和 HeadYaw
+	EntityMetadata  map[uint32]any // 实体的元数据
+
+	Item          protocol.ItemStack // 掉落物的物品堆栈。如果不是掉落物，则为零值
+	PaintingTitle string             // 画的图案名称。如果不是画，则为空
+}
+
+// NameTag 返回实体的名称。
+// 对于玩家，它是玩家的名称；
+// 对于其他实体，它是实体的命名牌名称
+func (e Entity) NameTag() string {
+	if e.Username != "" {
+		return e.Username
+	}
+	name, _ := e.EntityMetadata[protocol.EntityDataKeyName].(string)
+	return name
+}
+
+// PlayerInfo 描述玩家列表中的单个玩家。
+// 与 Entity 不同，只要玩家在线，
+// 它就会出现在玩家列表中
+type PlayerInfo struct {
+	UUID           uuid.UUID // 玩家的 UUID
+	EntityUniqueID int64     // 玩家的唯一 ID
+	Username       string    // 玩家的名称
+	XUID           string    // 玩家的 XUID
+	BuildPlatform  int32     // 玩家的设备平台
+}
+
+// EntityRegistry 记录租赁服已向机器人发送的所有实体，
+// 以及租赁服的玩家列表
+type EntityRegistry struct {
+	mu        *sync.RWMutex
+	entities  map[uint64]*Entity
+	uniqueIDs map[int64]uint64
+	players   map[uuid.UUID]PlayerInfo
+}
+
+// NewEntityRegistry 创建并返回一个新的 EntityRegistry
+func NewEntityRegistry() *EntityRegistry {
+	return &EntityRegistry{
+		mu:        new(sync.RWMutex),
+		entities:  make(map[uint64]*Entity),
+		uniqueIDs: make(map[int64]uint64),
+		players:   make(map[uuid.UUID]PlayerInfo),
+	}
+}
+
+// copyEntity 返回 entity 的副本
+func copyEntity(entity *Entity) Entity {
+	result := *entity
+	result.EntityMetadata = maps.Clone(entity.EntityMetadata)
+	if entity.Item.NBTData != nil {
+		result.Item.NBTData = utils.DeepCopyNBT(entity.Item.NBTData)
+	}
+	return result
+}
+
+// EntityByRuntimeID 查找运行时 ID 为 runtimeID 的实体
+func (e *EntityRegistry) EntityByRuntimeID(runtimeID uint64) (entity Entity, found bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	result, ok := e.entities[runtimeID]
+	if !ok {
+		return Entity{}, false
+	}
+	return copyEntity(result), true
+}
+
+// EntityByUniqueID 查找唯一 ID 为 uniqueID 的实体
+func (e *EntityRegistry) EntityByUniqueID(uniqueID int64) (entity Entity, found bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	runtimeID, ok := e.uniqueIDs[uniqueID]
+	if !ok {
+		return Entity{}, false
+	}
+	return copyEntity(e.entities[runtimeID]), true
+}
+
+// filter 返回所有使 f 为真的实体
+func (e *EntityRegistry) filter(f func(entity *Entity) bool) (result []Entity) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, entity := range e.entities {
+		if f(entity) {
+			result = append(result, copyEntity(entity))
+		}
+	}
+	return
+}
+
+// Entities 返回所有已知的实体
+func (e *EntityRegistry) Entities() []Entity {
+	return e.filter(func(entity *Entity) bool {
+		return true
+	})
+}
+
+// EntitiesByName 返回所有名称为 name 的实体。
+// 名称的含义与 Entity.NameTag 的相同
+func (e *EntityRegistry) EntitiesByName(name string) []Entity {
+	return e.filter(func(entity *Entity) bool {
+		return entity.NameTag() == name
+	})
+}
+
+// EntitiesByType 返回所有类型为 entityType 的实体。
+// entityType 可以省略 minecraft 命名空间
+func (e *EntityRegistry) EntitiesByType(entityType string) []Entity {
+	if !strings.Contains(entityType, ":") {
+		entityType = "minecraft:" + entityType
+	}
+	return e.filter(func(entity *Entity) bool {
+		return entity.EntityType == entityType
+	})
+}
+
+// EntitiesNearby 返回所有与 center 的距离不超过 radius 的实体
+func (e *EntityRegistry) EntitiesNearby(center mgl32.Vec3, radius float32) []Entity {
+	return e.filter(func(entity *Entity) bool {
+		return entity.Position.Sub(center).Len() <= radius
+	})
+}
+
+// EntitiesInArea 返回所有位于由 startPos 和 endPos
+// 所围成的方块区域 (包含两端) 中的实体
+func (e *EntityRegistry) EntitiesInArea(startPos protocol.BlockPos, endPos protocol.BlockPos) []Entity {
+	var minPos, maxPos mgl32.Vec3
+	for index := range 3 {
+		minPos[index] = float32(min(startPos[index], endPos[index]))
+		maxPos[index] = float32(max(startPos[index], endPos[index]) + 1)
+	}
+	return e.filter(func(entity *Entity) bool {
+		for index := range 3 {
+			if entity.Position[index] < minPos[index] || entity.Position[index] >= maxPos[index] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Players 返回玩家列表中的所有玩家
+func (e *EntityRegistry) Players() (result []PlayerInfo) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, player := range e.players {
+		result = append(result, player)
+	}
+	return
+}
+
+// PlayerByName 在玩家列表中查找名为 name 的玩家
+func (e *EntityRegistry) PlayerByName(name string) (player PlayerInfo, found bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, player := range e.players {
+		if player.Username == name {
+			return player, true
+		}
+	}
+	return PlayerInfo{}, false
+}
+
+// PlayerByXUID 在玩家列表中查找 XUID 为 xuid 的玩家。
+// 与名称不同，XUID 由租赁服担保，因此可以用于确认玩家的身份
+func (e *EntityRegistry) PlayerByXUID(xuid string) (player PlayerInfo, found bool) {
+	if len(xuid) == 0 {
+		return PlayerInfo{}, false
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, player := range e.players {
+		if player.XUID == xuid {
+			return player, true
+		}
+	}
+	return PlayerInfo{}, false
+}
+
+// addEntity 添加实体 entity。调用者应当持有锁
+func (e *EntityRegistry) addEntity(entity *Entity) {
+	if old, ok := e.entities[entity.EntityRuntimeID]; ok {
+		delete(e.uniqueIDs, old.EntityUniqueID)
+	}
+	e.entities[entity.EntityRuntimeID] = entity
+	e.uniqueIDs[entity.EntityUniqueID] = entity.EntityRuntimeID
+}
+
+// onAddActor 处理非玩家实体的生成
+func (e *EntityRegistry) onAddActor(p *packet.AddActor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.addEntity(&Entity{
+		EntityUniqueID:  p.EntityUniqueID,
+		EntityRuntimeID: p.EntityRuntimeID,
+		EntityType:      p.EntityType,
+		Position:        p.Position,
+		Rotation:        mgl32.Vec3{p.Pitch, p.Yaw, p.HeadYaw},
+		EntityMetadata:  p.EntityMetadata,
+	})
+}
+
+// onAddItemActor 处理掉落物的生成
+func (e *EntityRegistry) onAddItemActor(p *packet.AddItemActor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.addEntity(&Entity{
+		EntityUniqueID:  p.EntityUniqueID,
+		EntityRuntimeID: p.EntityRuntimeID,
+		EntityType:      EntityTypeItem,
+		Position:        p.Position,
+		EntityMetadata:  p.EntityMetadata,
+		Item:            p.Item.Stack,
+	})
+}
+
+// onAddPainting 处理画的生成。
+// 画的朝向由 Direction 给出，
+// 它被转换为以度为单位的 Yaw
+func (e *EntityRegistry) onAddPainting(p *packet.AddPainting) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.addEntity(&Entity{
+		EntityUniqueID:  p.EntityUniqueID,
+		EntityRuntimeID: p.EntityRuntimeID,
+		EntityType:      EntityTypePainting,
+		Position:        p.Position,
+		Rotation:        mgl32.Vec3{0, float32(p.Direction) * 90, 0},
+		PaintingTitle:   p.Title,
+	})
+}
+
+// onAddPlayer 处理玩家实体的生成
+func (e *EntityRegistry) onAddPlayer(p *packet.AddPlayer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.addEntity(&Entity{
+		EntityUniqueID:  p.AbilityData.EntityUniqueID,
+		EntityRuntimeID: p.EntityRuntimeID,
+		EntityType:      EntityTypePlayer,
+		Username:        p.Username,
+		UUID:            p.UUID,
+		Position:        p.Position,
+		Rotation:        mgl32.Vec3{p.Pitch, p.Yaw, p.HeadYaw},
+		EntityMetadata:  p.EntityMetadata,
+	})
+}
+
+// onRemoveActor 处理实体的移除
+func (e *EntityRegistry) onRemoveActor(p *packet.RemoveActor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	runtimeID, ok := e.uniqueIDs[p.EntityUniqueID]
+	if !ok {
+		return
+	}
+	delete(e.uniqueIDs, p.EntityUniqueID)
+	delete(e.entities, runtimeID)
+}
+
+// onMoveActorAbsolute 处理实体的绝对移动
+func (e *EntityRegistry) onMoveActorAbsolute(p *packet.MoveActorAbsolute) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if entity, ok := e.entities[p.EntityRuntimeID]; ok {
+		entity.Position = p.Position
+		entity.Rotation = p.Rotation
+	}
+}
+
+// onMoveActorDelta 处理实体的相对移动。
+// 数据包中已给出的分量总是绝对的
+func (e *EntityRegistry) onMoveActorDelta(p *packet.MoveActorDelta) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entity, ok := e.entities[p.EntityRuntimeID]
+	if !ok {
+		return
+	}
+	for index, flag := range []uint16{
+		packet.MoveActorDeltaFlagHasX,
+		packet.MoveActorDeltaFlagHasY,
+		packet.MoveActorDeltaFlagHasZ,
+	} {
+		if p.Flags&flag != 0 {
+			entity.Position[index] = p.Position[index]
+		}
+	}
+	for index, flag := range []uint16{
+		packet.MoveActorDeltaFlagHasRotX,
+		packet.MoveActorDeltaFlagHasRotY,
+		packet.MoveActorDeltaFlagHasRotZ,
+	} {
+		if p.Flags&flag != 0 {
+			entity.Rotation[index] = p.Rotation[index]
+		}
+	}
+}
+
+// onMovePlayer 处理其他玩家的移动
+func (e *EntityRegistry) onMovePlayer(p *packet.MovePlayer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if entity, ok := e.entities[p.EntityRuntimeID]; ok {
+		entity.Position = p.Position
+		entity.Rotation = mgl32.Vec3{p.Pitch, p.Yaw, p.HeadYaw}
+	}
+}
+
+// onSetActorData 处理实体元数据的更新
+func (e *EntityRegistry) onSetActorData(p *packet.SetActorData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entity, ok := e.entities[p.EntityRuntimeID]
+	if !ok {
+		return
+	}
+	metadata := maps.Clone(entity.EntityMetadata)
+	if metadata == nil {
+		metadata = make(map[uint32]any)
+	}
+	maps.Copy(metadata, p.EntityMetadata)
+	entity.EntityMetadata = metadata
+}
+
+// onPlayerList 处理玩家列表的更新
+func (e *EntityRegistry) onPlayerList(p *packet.PlayerList) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, entry := range p.Entries {
+		switch p.ActionType {
+		case packet.PlayerListActionAdd:
+			e.players[entry.UUID] = PlayerInfo{
+				UUID:           entry.UUID,
+				EntityUniqueID: entry.EntityUniqueID,
+				Username:       entry.Username,
+				XUID:           entry.XUID,
+				BuildPlatform:  entry.BuildPlatform,
+			}
+		case packet.PlayerListActionRemove:
+			delete(e.players, entry.UUID)
+		}
+	}
+}
+
+// onChangeDimension 在机器人切换维度时清空所有实体。
+// 玩家列表与维度无关，因此不会被清空
+func (e *EntityRegistry) onChangeDimension() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.entities = make(map[uint64]*Entity)
+	e.uniqueIDs = make(map[int64]uint64)
+}
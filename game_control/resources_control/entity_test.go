@@ -0,0 +1,51 @@
+package resources_control
+
+import (
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestEntityRegistryItemAndPainting(t *testing.T) {
+	e := NewEntityRegistry()
+
+	e.onAddItemActor(&packet.AddItemActor{
+		EntityUniqueID:  2,
+		EntityRuntimeID: 2,
+		Item: protocol.ItemInstance{Stack: protocol.ItemStack{
+			Count:   3,
+			NBTData: map[string]any{"display": map[string]any{"Name": "apple"}},
+		}},
+		Position: mgl32.Vec3{1.5, 2, 3.5},
+	})
+	e.onAddPainting(&packet.AddPainting{
+		EntityUniqueID:  3,
+		EntityRuntimeID: 3,
+		Position:        mgl32.Vec3{10, 2, 10},
+		Direction:       1,
+		Title:           "Kebab",
+	})
+
+	items := e.EntitiesInArea(protocol.BlockPos{0, 0, 0}, protocol.BlockPos{5, 5, 5})
+	if len(items) != 1 || items[0].EntityType != EntityTypeItem || items[0].Item.Count != 3 {
+		t.Fatalf("TestEntityRegistryItemAndPainting: Unexpected items %#v", items)
+	}
+	// 返回的实体是副本，对其的修改不会影响注册表
+	items[0].Item.NBTData["display"] = nil
+	if entity, _ := e.EntityByRuntimeID(2); entity.Item.NBTData["display"] == nil {
+		t.Fatalf("TestEntityRegistryItemAndPainting: Item NBT is shared with the registry")
+	}
+
+	paintings := e.EntitiesByType("painting")
+	if len(paintings) != 1 || paintings[0].PaintingTitle != "Kebab" || paintings[0].Rotation[1] != 90 {
+		t.Fatalf("TestEntityRegistryItemAndPainting: Unexpected paintings %#v", paintings)
+	}
+
+	e.onRemoveActor(&packet.RemoveActor{EntityUniqueID: 3})
+	if len(e.Entities()) != 1 {
+		t.Fatalf("TestEntityRegistryItemAndPainting: Painting is not removed")
+	}
+}
@@ -0,0 +1,172 @@
+package resources_control
+
+import (
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// EventType 是游戏事件的类型
+type EventType uint8
+
+const (
+	EventTypeChat            EventType = iota // 聊天消息
+	EventTypeWhisper                          // 私聊消息
+	EventTypeCommandFeedback                  // 命令反馈
+	EventTypeKick                             // 机器人被踢出租赁服
+	EventTypeGameMode                         // 游戏模式变更
+	EventTypeContainerOpen                    // 容器被打开
+	EventTypeContainerClose                   // 容器被关闭
+	EventTypeInventorySlot                    // 库存中的物品变更
+	EventTypeRespawn                          // 机器人重生
+)
+
+// Event 是由 EventBus 分发的游戏事件。
+// 它的具体类型是本文件中以 Event 结尾的结构体
+type Event interface {
+	// EventType 返回该事件的类型
+	EventType() EventType
+}
+
+// ChatEvent 是其他玩家发送的聊天消息。
+//
+// XUID 由租赁服在聊天数据包中给出，因此可以用于确认发送者的身份。
+// 通过翻译消息得到的聊天消息不含 XUID，其发送者的名称
+// 只是翻译参数，任何人都可以伪造
+type ChatEvent struct {
+	Sender  string // 发送者的名称
+	Message string // 聊天消息
+	XUID    string // 发送者的 XUID。如果消息来自翻译消息，则为空
+}
+
+// WhisperEvent 是发送给机器人的私聊消息，
+// 例如 /tell 或 /msg 命令的消息。
+// 与 ChatEvent 相同，只有 XUID 可以用于确认发送者的身份
+type WhisperEvent struct {
+	Sender  string // 发送者的名称
+	Message string // 私聊消息
+	XUID    string // 发送者的 XUID。如果消息来自翻译消息，则为空
+}
+
+// CommandFeedbackEvent 是租赁服返回的命令反馈。
+// 与 CommandRequestCallback 不同，它包含所有收到的命令反馈，
+// 无论对应的命令请求是否设置了回调函数
+type CommandFeedbackEvent struct {
+	CommandOrigin  protocol.CommandOrigin          // 命令的来源
+	SuccessCount   uint32                          // 命令成功的次数
+	OutputMessages []protocol.CommandOutputMessage // 命令的输出
+}
+
+// KickReason 是机器人被踢出租赁服的原因
+type KickReason uint8
+
+const (
+	KickReasonUnknown        KickReason = iota // 未知原因
+	KickReasonKicked                           // 被管理员踢出
+	KickReasonBanned                           // 被封禁
+	KickReasonServerFull                       // 租赁服人数已满
+	KickReasonServerClosed                     // 租赁服关闭或重启
+	KickReasonTimeout                          // 连接超时
+	KickReasonDuplicateLogin                   // 账号在其他位置登录
+)
+
+// kickReasonKeywords 是用于识别踢出原因的关键字。
+// 它们按顺序被匹配，且不区分大小写
+var kickReasonKeywords = []struct {
+	reason   KickReason
+	keywords []string
+}{
+	{KickReasonBanned, []string{"封禁", "banned"}},
+	{KickReasonDuplicateLogin, []string{"其他位置登录", "其他地方登录", "异地登录", "重复登录", "loggedinotherlocation"}},
+	{KickReasonServerFull, []string{"人数已满", "已满", "serverfull"}},
+	{KickReasonServerClosed, []string{"关闭", "重启", "停服", "shutdown", "disconnect.closed"}},
+	{KickReasonTimeout, []string{"超时", "timeout", "timedout"}},
+	{KickReasonKicked, []string{"踢出", "kicked"}},
+}
+
+// KickEvent 是机器人被租赁服断开连接的事件
+type KickEvent struct {
+	Reason     KickReason // 解析得到的踢出原因
+	Detail     string     // 断开连接信息中 "原因" 之后的部分。如果不存在，则为空
+	Message    string     // 移除格式化代码后的断开连接信息
+	RawMessage string     // 原始的断开连接信息
+}
+
+// parseKickMessage 解析网易租赁服的断开连接信息 message。
+// 网易的断开连接信息没有固定的格式，因此这只是尽力而为的
+func parseKickMessage(message string) KickEvent {
+	event := KickEvent{
+		Message:    strings.TrimSpace(utils.StripFormatting(message)),
+		RawMessage: message,
+	}
+
+	for _, label := range []string{"原因：", "原因:", "reason:", "Reason:"} {
+		if _, detail, found := strings.Cut(event.Message, label); found {
+			event.Detail = strings.TrimSpace(detail)
+			break
+		}
+	}
+
+	lower := strings.ToLower(event.Message)
+	for _, value := range kickReasonKeywords {
+		for _, keyword := range value.keywords {
+			if strings.Contains(lower, keyword) {
+				event.Reason = value.reason
+				return event
+			}
+		}
+	}
+
+	return event
+}
+
+// GameModeEvent 是玩家的游戏模式变更
+type GameModeEvent struct {
+	// PlayerUniqueID 是游戏模式发生变更的玩家的唯一 ID。
+	// 如果变更的是机器人的游戏模式，则它是机器人的唯一 ID
+	PlayerUniqueID int64
+	// GameMode 是新的游戏模式
+	GameMode int32
+}
+
+// ContainerOpenEvent 是容器被打开的事件
+type ContainerOpenEvent struct {
+	WindowID                WindowID          // 容器的窗口 ID
+	ContainerType           byte              // 容器的类型
+	ContainerPosition       protocol.BlockPos // 容器的位置
+	ContainerEntityUniqueID int64             // 容器实体的唯一 ID。如果容器不是实体，则为 -1
+}
+
+// ContainerCloseEvent 是容器被关闭的事件
+type ContainerCloseEvent struct {
+	WindowID      WindowID // 容器的窗口 ID
+	ContainerType byte     // 容器的类型
+	ServerSide    bool     // 容器是否是由租赁服关闭的
+}
+
+// InventorySlotEvent 是库存中单个物品的变更。
+// 它仅由租赁服的 InventorySlot 和 InventoryContent
+// 数据包产生，而物品堆栈操作所导致的变更不在此列
+type InventorySlotEvent struct {
+	WindowID WindowID              // 库存的窗口 ID
+	SlotID   SlotID                // 物品所在的槽位
+	NewItem  protocol.ItemInstance // 变更后的物品
+}
+
+// RespawnEvent 是机器人重生的事件
+type RespawnEvent struct {
+	Position mgl32.Vec3 // 机器人的重生点
+}
+
+func (ChatEvent) EventType() EventType            { return EventTypeChat }
+func (WhisperEvent) EventType() EventType         { return EventTypeWhisper }
+func (CommandFeedbackEvent) EventType() EventType { return EventTypeCommandFeedback }
+func (KickEvent) EventType() EventType            { return EventTypeKick }
+func (GameModeEvent) EventType() EventType        { return EventTypeGameMode }
+func (ContainerOpenEvent) EventType() EventType   { return EventTypeContainerOpen }
+func (ContainerCloseEvent) EventType() EventType  { return EventTypeContainerClose }
+func (InventorySlotEvent) EventType() EventType   { return EventTypeInventorySlot }
+func (RespawnEvent) EventType() EventType         { return EventTypeRespawn }
@@ -0,0 +1,229 @@
+package resources_control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+
+	"github.com/google/uuid"
+)
+
+// DefaultEventBufferSize 是订阅的默认缓冲区大小
+const DefaultEventBufferSize = 64
+
+// EventSubscription 是对 EventBus 的单个订阅
+type EventSubscription struct {
+	uniqueID   string
+	eventTypes []EventType
+	filter     func(event Event) bool
+	events     chan Event
+	dropped    atomic.Uint64
+}
+
+// UniqueID 返回该订阅的唯一标识符
+func (s *EventSubscription) UniqueID() string {
+	return s.uniqueID
+}
+
+// Events 返回用于接收事件的管道。
+// 当订阅被撤销或底层连接关闭后，
+// 该管道将被关闭
+func (s *EventSubscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped 返回因缓冲区已满而被丢弃的事件数量
+func (s *EventSubscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// match 检查 event 是否满足该订阅的条件
+func (s *EventSubscription) match(event Event) bool {
+	if len(s.eventTypes) > 0 && !slices.Contains(s.eventTypes, event.EventType()) {
+		return false
+	}
+	return s.filter == nil || s.filter(event)
+}
+
+// EventBus 将租赁服发来的数据包转换为类型化的游戏事件，
+// 并将它们分发给所有订阅者。
+//
+// 事件在读取数据包的协程中被分发，且分发是非阻塞的。
+// 如果订阅者的缓冲区已满，则新的事件将被丢弃，
+// 而不会阻塞数据包的读取
+type EventBus struct {
+	mu            *sync.Mutex
+	ctx           context.Context
+	botUniqueID   int64
+	subscriptions map[string]*EventSubscription
+}
+
+// NewEventBus 基于 ctx 创建并返回一个新的 EventBus。
+// botUniqueID 是机器人的唯一 ID
+func NewEventBus(ctx context.Context, botUniqueID int64) *EventBus {
+	return &EventBus{
+		mu:            new(sync.Mutex),
+		ctx:           ctx,
+		botUniqueID:   botUniqueID,
+		subscriptions: make(map[string]*EventSubscription),
+	}
+}
+
+// Subscribe 订阅类型在 eventTypes 中的事件。
+// 如果 eventTypes 置空，则订阅所有事件。
+//
+// filter 用于进一步筛选事件，它可以为空。filter 在读取
+// 数据包的协程中被调用，因此它应当尽快返回，且不应调用
+// EventBus 的任何方法。
+//
+// bufferSize 是订阅的缓冲区大小。如果它不是正数，
+// 则使用 DefaultEventBufferSize
+func (e *EventBus) Subscribe(
+	filter func(event Event) bool,
+	bufferSize int,
+	eventTypes ...EventType,
+) (subscription *EventSubscription, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	select {
+	case <-e.ctx.Done():
+		return nil, fmt.Errorf("Subscribe: Subscribe events on closed connection")
+	default:
+	}
+
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+	subscription = &EventSubscription{
+		uniqueID:   uuid.NewString(),
+		eventTypes: slices.Clone(eventTypes),
+		filter:     filter,
+		events:     make(chan Event, bufferSize),
+	}
+	e.subscriptions[subscription.uniqueID] = subscription
+
+	return subscription, nil
+}
+
+// Unsubscribe 撤销唯一标识为 uniqueID 的订阅，并关闭其管道。
+// 如果这样的订阅不存在，则不会执行任何操作
+func (e *EventBus) Unsubscribe(uniqueID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	subscription, ok := e.subscriptions[uniqueID]
+	if !ok {
+		return
+	}
+	delete(e.subscriptions, uniqueID)
+	close(subscription.events)
+}
+
+// publish 将 event 非阻塞地分发给所有匹配的订阅者
+func (e *EventBus) publish(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, subscription := range e.subscriptions {
+		if !subscription.match(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.dropped.Add(1)
+		}
+	}
+}
+
+// onText 将文本数据包转换为聊天或私聊事件
+func (e *EventBus) onText(p *packet.Text) {
+	switch p.TextType {
+	case packet.TextTypeChat:
+		e.publish(ChatEvent{Sender: p.SourceName, Message: p.Message, XUID: p.XUID})
+	case packet.TextTypeWhisper:
+		e.publish(WhisperEvent{Sender: p.SourceName, Message: p.Message, XUID: p.XUID})
+	case packet.TextTypeTranslation:
+		if len(p.Parameters) != 2 {
+			return
+		}
+		switch p.Message {
+		case "chat.type.text":
+			e.publish(ChatEvent{Sender: p.Parameters[0], Message: p.Parameters[1]})
+		case "commands.message.display.incoming":
+			e.publish(WhisperEvent{Sender: p.Parameters[0], Message: p.Parameters[1]})
+		}
+	}
+}
+
+// onPacket 将数据包 pk 转换为事件并分发
+func (e *EventBus) onPacket(pk packet.Packet) {
+	switch p := pk.(type) {
+	case *packet.Text:
+		e.onText(p)
+	case *packet.CommandOutput:
+		e.publish(CommandFeedbackEvent{
+			CommandOrigin:  p.CommandOrigin,
+			SuccessCount:   p.SuccessCount,
+			OutputMessages: p.OutputMessages,
+		})
+	case *packet.SetPlayerGameType:
+		e.publish(GameModeEvent{PlayerUniqueID: e.botUniqueID, GameMode: p.GameType})
+	case *packet.UpdatePlayerGameType:
+		e.publish(GameModeEvent{PlayerUniqueID: p.PlayerUniqueID, GameMode: p.GameType})
+	case *packet.ContainerOpen:
+		e.publish(ContainerOpenEvent{
+			WindowID:                WindowID(p.WindowID),
+			ContainerType:           p.ContainerType,
+			ContainerPosition:       p.ContainerPosition,
+			ContainerEntityUniqueID: p.ContainerEntityUniqueID,
+		})
+	case *packet.ContainerClose:
+		e.publish(ContainerCloseEvent{
+			WindowID:      WindowID(p.WindowID),
+			ContainerType: p.ContainerType,
+			ServerSide:    p.ServerSide,
+		})
+	case *packet.InventorySlot:
+		e.publish(InventorySlotEvent{
+			WindowID: WindowID(p.WindowID),
+			SlotID:   SlotID(p.Slot),
+			NewItem:  p.NewItem,
+		})
+	case *packet.InventoryContent:
+		for index, item := range p.Content {
+			e.publish(InventorySlotEvent{
+				WindowID: WindowID(p.WindowID),
+				SlotID:   SlotID(index),
+				NewItem:  item,
+			})
+		}
+	case *packet.Respawn:
+		if p.State == packet.RespawnStateReadyToSpawn {
+			e.publish(RespawnEvent{Position: p.Position})
+		}
+	}
+}
+
+// handleConnClose 在底层连接关闭时分发踢出事件 (如果有)，
+// 然后关闭所有订阅的管道
+func (e *EventBus) handleConnClose(err error) {
+	var disconnectErr minecraft.DisconnectError
+	if errors.As(err, &disconnectErr) {
+		e.publish(parseKickMessage(string(disconnectErr)))
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for uniqueID, subscription := range e.subscriptions {
+		close(subscription.events)
+		delete(e.subscriptions, uniqueID)
+	}
+}
@@ -203,12 +203,33 @@ func (r *Resources) handleContainerClose(p *packet.ContainerClose) {
 	r.container.onContainerClose(p)
 }
 
+// chunk data (and request sub chunks if needed)
+func (r *Resources) handleLevelChunk(p *packet.LevelChunk) {
+	request, err := r.world.onLevelChunk(p)
+	if err != nil {
+		pterm.Warning.Printfln("handleLevelChunk: %v", err)
+		return
+	}
+	if request != nil {
+		_ = r.WritePacket(request)
+	}
+}
+
+// sub chunk data
+func (r *Resources) handleSubChunk(p *packet.SubChunk) {
+	err := r.world.onSubChunk(p)
+	if err != nil {
+		pterm.Warning.Printfln("handleSubChunk: %v", err)
+	}
+}
+
 // 根据收到的数据包更新客户端的资源数据
 func (r *Resources) handlePacket(pk packet.Packet) {
 	// internal
 	switch p := pk.(type) {
 	case *packet.MovePlayer:
 		r.handleMovePlayer(p)
+		r.entity.onMovePlayer(p)
 	case *packet.Respawn:
 		r.handleRespawn(p)
 	case *packet.CommandOutput:
@@ -233,9 +254,43 @@ func (r *Resources) handlePacket(pk packet.Packet) {
 		r.constant.onAvailableCommands(p)
 	case *packet.CraftingData:
 		r.constant.onCraftingData(p)
+	case *packet.LevelChunk:
+		r.handleLevelChunk(p)
+	case *packet.SubChunk:
+		r.handleSubChunk(p)
+	case *packet.UpdateBlock:
+		r.world.onUpdateBlock(p)
+	case *packet.UpdateSubChunkBlocks:
+		r.world.onUpdateSubChunkBlocks(p)
+	case *packet.BlockActorData:
+		r.world.onBlockActorData(p)
+	case *packet.NetworkChunkPublisherUpdate:
+		r.world.onChunkPublisherUpdate(p)
+	case *packet.ChangeDimension:
+		r.world.onChangeDimension(p)
+		r.entity.onChangeDimension()
+	case *packet.AddActor:
+		r.entity.onAddActor(p)
+	case *packet.AddItemActor:
+		r.entity.onAddItemActor(p)
+	case *packet.AddPainting:
+		r.entity.onAddPainting(p)
+	case *packet.AddPlayer:
+		r.entity.onAddPlayer(p)
+	case *packet.RemoveActor:
+		r.entity.onRemoveActor(p)
+	case *packet.MoveActorAbsolute:
+		r.entity.onMoveActorAbsolute(p)
+	case *packet.MoveActorDelta:
+		r.entity.onMoveActorDelta(p)
+	case *packet.SetActorData:
+		r.entity.onSetActorData(p)
+	case *packet.PlayerList:
+		r.entity.onPlayerList(p)
 	}
 	// for other implements
 	r.listener.onPacket(pk)
+	r.events.onPacket(pk)
 }
 
 // handleConnClose ..
@@ -244,4 +299,5 @@ func (r *Resources) handleConnClose(err error) {
 	r.itemStack.handleConnClose(err)
 	r.container.handleConnClose(err)
 	r.listener.handleConnClose(err)
+	r.events.handleConnClose(err)
 }
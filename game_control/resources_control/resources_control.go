@@ -30,6 +30,12 @@ type Resources struct {
 	listener *PacketListener
 	// constant 是常量数据包的简要记录实现
 	constant *ConstantPacket
+	// world 是机器人周围已加载区块的世界镜像
+	world *WorldMirror
+	// entity 记录租赁服已向机器人发送的实体和玩家列表
+	entity *EntityRegistry
+	// events 将数据包转换为类型化的游戏事件并分发
+	events *EventBus
 }
 
 // NewResourcesControl 基于 client 创建一个新的资源中心。
@@ -46,6 +52,9 @@ func NewResourcesControl(client *client.Client) *Resources {
 		itemStack: NewItemStackOperationManager(clientCtx),
 		container: NewContainerManager(clientCtx),
 		listener:  NewPacketListener(clientCtx),
+		world:     NewWorldMirror(client.Conn().GameData().Dimension),
+		entity:    NewEntityRegistry(),
+		events:    NewEventBus(clientCtx, client.Conn().GameData().EntityUniqueID),
 	}
 
 	inventory := NewInventories()
@@ -124,3 +133,18 @@ func (r *Resources) PacketListener() *PacketListener {
 func (r *Resources) ConstantPacket() *ConstantPacket {
 	return r.constant
 }
+
+// World 返回机器人周围已加载区块的世界镜像
+func (r *Resources) World() *WorldMirror {
+	return r.world
+}
+
+// Entities 返回租赁服已向机器人发送的实体和玩家列表
+func (r *Resources) Entities() *EntityRegistry {
+	return r.entity
+}
+
+// Events 返回类型化游戏事件的订阅实现
+func (r *Resources) Events() *EventBus {
+	return r.events
+}
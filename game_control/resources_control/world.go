@@ -0,0 +1,356 @@
+package resources_control
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+
+	"github.com/TriM-Organization/bedrock-world-operator/block"
+	"github.com/TriM-Organization/bedrock-world-operator/define"
+)
+
+// WorldMirror 是客户端侧的世界镜像。
+//
+// 它根据租赁服发送的区块、子区块和方块更新数据包
+// 维护机器人周围已加载区块的方块和方块实体数据，
+// 使得调用者无需发送命令即可查询这些方块。
+//
+// 需要注意的是，镜像只包含租赁服已向机器人发送的区块，
+// 并且不会包含机器人所在维度以外的任何方块
+type WorldMirror struct {
+	mu            *sync.RWMutex
+	dimension     define.Dimension
+	chunks        map[define.ChunkPos]*chunkColumn
+	blockEntities map[protocol.BlockPos]map[string]any
+}
+
+// NewWorldMirror 根据机器人所在的维度 dimension 创建并返回一个新的 WorldMirror
+func NewWorldMirror(dimension int32) *WorldMirror {
+	return &WorldMirror{
+		mu:            new(sync.RWMutex),
+		dimension:     define.Dimension(dimension),
+		chunks:        make(map[define.ChunkPos]*chunkColumn),
+		blockEntities: make(map[protocol.BlockPos]map[string]any),
+	}
+}
+
+// chunkPosOf 返回方块坐标 pos 所在区块的坐标
+func chunkPosOf(pos protocol.BlockPos) define.ChunkPos {
+	return define.ChunkPos{pos[0] >> 4, pos[2] >> 4}
+}
+
+// inRange 检查 Y 坐标 y 是否位于当前维度的高度范围内。
+// 调用者应当持有锁
+func (w *WorldMirror) inRange(y int32) bool {
+	r := w.dimension.Range()
+	return int(y) >= r.Min() && int(y) <= r.Max()
+}
+
+// Dimension 返回世界镜像当前所在的维度 ID
+func (w *WorldMirror) Dimension() int32 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return int32(w.dimension)
+}
+
+// ChunkLoaded 检查 pos 处的方块所在的区块是否已被加载
+func (w *WorldMirror) ChunkLoaded(pos protocol.BlockPos) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.chunks[chunkPosOf(pos)]
+	return ok
+}
+
+// BlockRuntimeIDAt 返回 pos 处方块的运行时 ID。
+// 如果 pos 所在的子区块尚未被加载，或 pos 超出了
+// 当前维度的高度范围，则 found 为假
+func (w *WorldMirror) BlockRuntimeIDAt(pos protocol.BlockPos) (runtimeID uint32, found bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	c, ok := w.chunks[chunkPosOf(pos)]
+	if !ok || !w.inRange(pos[1]) {
+		return 0, false
+	}
+	return c.block(pos[0], pos[1], pos[2], 0, block.AirRuntimeID)
+}
+
+// BlockAt 返回 pos 处方块的名称和方块状态。
+// 如果 pos 所在的区块尚未被加载，或该方块
+// 无法在方块注册表中被找到，则 found 为假
+func (w *WorldMirror) BlockAt(pos protocol.BlockPos) (name string, states map[string]any, found bool) {
+	runtimeID, found := w.BlockRuntimeIDAt(pos)
+	if !found {
+		return "", nil, false
+	}
+	return block.RuntimeIDToState(runtimeID)
+}
+
+// BlockEntityAt 返回 pos 处方块实体的 NBT 数据的深拷贝。
+// 如果 pos 处没有已知的方块实体，则 found 为假
+func (w *WorldMirror) BlockEntityAt(pos protocol.BlockPos) (blockNBT map[string]any, found bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	blockNBT, found = w.blockEntities[pos]
+	if !found {
+		return nil, false
+	}
+	return utils.DeepCopyNBT(blockNBT), true
+}
+
+// reset 清空世界镜像，并将其维度设置为 dimension。
+// 调用者应当持有锁
+func (w *WorldMirror) reset(dimension int32) {
+	w.dimension = define.Dimension(dimension)
+	w.chunks = make(map[define.ChunkPos]*chunkColumn)
+	w.blockEntities = make(map[protocol.BlockPos]map[string]any)
+}
+
+// deleteBlockEntities 删除区块 chunkPos 中 Y 坐标位于
+// [minY, maxY] 的所有方块实体。调用者应当持有锁
+func (w *WorldMirror) deleteBlockEntities(chunkPos define.ChunkPos, minY int32, maxY int32) {
+	for pos := range w.blockEntities {
+		if chunkPosOf(pos) == chunkPos && pos[1] >= minY && pos[1] <= maxY {
+			delete(w.blockEntities, pos)
+		}
+	}
+}
+
+// decodeBlockEntities 从 buf 中解码剩余的所有方块实体并保存它们。
+// 调用者应当持有锁
+func (w *WorldMirror) decodeBlockEntities(buf *bytes.Buffer) error {
+	decoder := nbt.NewDecoderWithEncoding(buf, nbt.NetworkLittleEndian)
+	for buf.Len() > 0 {
+		var blockNBT map[string]any
+		if err := decoder.Decode(&blockNBT); err != nil {
+			return fmt.Errorf("decodeBlockEntities: %v", err)
+		}
+		x, _ := blockNBT["x"].(int32)
+		y, _ := blockNBT["y"].(int32)
+		z, _ := blockNBT["z"].(int32)
+		w.blockEntities[protocol.BlockPos{x, y, z}] = blockNBT
+	}
+	return nil
+}
+
+// onLevelChunk 处理区块数据。
+// 如果租赁服要求客户端请求子区块，
+// 则返回的 request 是应当发送的子区块请求
+func (w *WorldMirror) onLevelChunk(p *packet.LevelChunk) (request *packet.SubChunkRequest, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if p.Dimension != int32(w.dimension) {
+		return nil, nil
+	}
+
+	r := w.dimension.Range()
+	subChunkCount := r.Height()>>4 + 1
+	chunkPos := define.ChunkPos{p.Position[0], p.Position[1]}
+	w.deleteBlockEntities(chunkPos, int32(r.Min()), int32(r.Max()))
+
+	// 客户端在登录时声明了不支持区块缓存，
+	// 因此这样的区块无法被解码。为了避免镜像
+	// 保留过时的数据，该区块将被视为未加载
+	if p.CacheEnabled {
+		delete(w.chunks, chunkPos)
+		return nil, fmt.Errorf(
+			"onLevelChunk: Chunk %v is sent with client blob cache enabled, which is not supported; it is removed from the world mirror",
+			chunkPos,
+		)
+	}
+
+	c := newChunkColumn(int32(r.Min()))
+	w.chunks[chunkPos] = c
+
+	switch p.SubChunkCount {
+	case protocol.SubChunkRequestModeLimitless, protocol.SubChunkRequestModeLimited:
+		count := subChunkCount
+		if p.SubChunkCount == protocol.SubChunkRequestModeLimited {
+			count = min(count, int(p.HighestSubChunk))
+		}
+		request = &packet.SubChunkRequest{
+			Dimension: p.Dimension,
+			Position:  protocol.SubChunkPos{p.Position[0], c.minSubY, p.Position[1]},
+		}
+		for index := range count {
+			request.Offsets = append(request.Offsets, protocol.SubChunkOffset{0, int8(index), 0})
+		}
+		// 高于 HighestSubChunk 的子区块总是只包含空气
+		for index := count; index < subChunkCount; index++ {
+			c.subChunks[c.minSubY+int32(index)] = newAirSubChunk(block.AirRuntimeID)
+		}
+		return request, nil
+	}
+
+	buf := bytes.NewBuffer(p.RawPayload)
+	for index := range min(int(p.SubChunkCount), subChunkCount) {
+		sub, subY, err := decodeSubChunk(buf, c.minSubY+int32(index))
+		if err != nil {
+			return nil, fmt.Errorf("onLevelChunk: %v", err)
+		}
+		c.subChunks[subY] = sub
+	}
+	// 未被发送的子区块总是只包含空气
+	for index := range subChunkCount {
+		if _, ok := c.subChunks[c.minSubY+int32(index)]; !ok {
+			c.subChunks[c.minSubY+int32(index)] = newAirSubChunk(block.AirRuntimeID)
+		}
+	}
+	if err = skipBiomes(buf, subChunkCount); err != nil {
+		return nil, fmt.Errorf("onLevelChunk: %v", err)
+	}
+	// 跳过边界方块的数量，它总是 0
+	if _, err = buf.ReadByte(); err != nil {
+		return nil, nil
+	}
+	if err = w.decodeBlockEntities(buf); err != nil {
+		return nil, fmt.Errorf("onLevelChunk: %v", err)
+	}
+
+	return nil, nil
+}
+
+// onSubChunk 处理子区块数据
+func (w *WorldMirror) onSubChunk(p *packet.SubChunk) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if p.Dimension != int32(w.dimension) {
+		return nil
+	}
+
+	// 与 onLevelChunk 相同，启用了区块缓存的子区块无法被解码，
+	// 因此相应的区块将被视为未加载
+	if p.CacheEnabled {
+		var removed []define.ChunkPos
+		for _, entry := range p.SubChunkEntries {
+			chunkPos := define.ChunkPos{
+				p.Position[0] + int32(entry.Offset[0]),
+				p.Position[2] + int32(entry.Offset[2]),
+			}
+			if _, ok := w.chunks[chunkPos]; !ok {
+				continue
+			}
+			r := w.dimension.Range()
+			delete(w.chunks, chunkPos)
+			w.deleteBlockEntities(chunkPos, int32(r.Min()), int32(r.Max()))
+			removed = append(removed, chunkPos)
+		}
+		return fmt.Errorf(
+			"onSubChunk: Sub chunks are sent with client blob cache enabled, which is not supported; chunks %v are removed from the world mirror",
+			removed,
+		)
+	}
+
+	for _, entry := range p.SubChunkEntries {
+		chunkPos := define.ChunkPos{
+			p.Position[0] + int32(entry.Offset[0]),
+			p.Position[2] + int32(entry.Offset[2]),
+		}
+		c, ok := w.chunks[chunkPos]
+		if !ok {
+			continue
+		}
+
+		subY := p.Position[1] + int32(entry.Offset[1])
+		if !w.inRange(subY << 4) {
+			continue
+		}
+
+		switch entry.Result {
+		case protocol.SubChunkResultSuccessAllAir:
+			w.deleteBlockEntities(chunkPos, subY<<4, subY<<4+15)
+			c.subChunks[subY] = newAirSubChunk(block.AirRuntimeID)
+		case protocol.SubChunkResultSuccess:
+			buf := bytes.NewBuffer(entry.RawPayload)
+			sub, _, err := decodeSubChunk(buf, subY)
+			if err != nil {
+				return fmt.Errorf("onSubChunk: %v", err)
+			}
+			w.deleteBlockEntities(chunkPos, subY<<4, subY<<4+15)
+			c.subChunks[subY] = sub
+			if err = w.decodeBlockEntities(buf); err != nil {
+				return fmt.Errorf("onSubChunk: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// setBlock 将 pos 处第 layer 层的方块设置为 runtimeID。
+// 调用者应当持有锁
+func (w *WorldMirror) setBlock(pos protocol.BlockPos, layer uint8, runtimeID uint32) {
+	c, ok := w.chunks[chunkPosOf(pos)]
+	if !ok || !w.inRange(pos[1]) {
+		return
+	}
+	c.setBlock(pos[0], pos[1], pos[2], layer, runtimeID, block.AirRuntimeID)
+	if layer == 0 {
+		delete(w.blockEntities, pos)
+	}
+}
+
+// onUpdateBlock 处理单个方块的更新
+func (w *WorldMirror) onUpdateBlock(p *packet.UpdateBlock) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.setBlock(p.Position, uint8(p.Layer), p.NewBlockRuntimeID)
+}
+
+// onUpdateSubChunkBlocks 处理子区块中多个方块的更新
+func (w *WorldMirror) onUpdateSubChunkBlocks(p *packet.UpdateSubChunkBlocks) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, entry := range p.Blocks {
+		w.setBlock(entry.BlockPos, 0, entry.BlockRuntimeID)
+	}
+	for _, entry := range p.Extra {
+		w.setBlock(entry.BlockPos, 1, entry.BlockRuntimeID)
+	}
+}
+
+// onBlockActorData 处理方块实体数据的更新
+func (w *WorldMirror) onBlockActorData(p *packet.BlockActorData) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.chunks[chunkPosOf(p.Position)]; ok {
+		w.blockEntities[p.Position] = p.NBTData
+	}
+}
+
+// onChunkPublisherUpdate 卸载所有位于
+// 租赁服区块发布范围以外的区块
+func (w *WorldMirror) onChunkPublisherUpdate(p *packet.NetworkChunkPublisherUpdate) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	center := chunkPosOf(p.Position)
+	radius := int32(p.Radius>>4) + 1
+	for chunkPos := range w.chunks {
+		dx, dz := chunkPos[0]-center[0], chunkPos[1]-center[1]
+		if dx*dx+dz*dz <= radius*radius {
+			continue
+		}
+		delete(w.chunks, chunkPos)
+		for pos := range w.blockEntities {
+			if chunkPosOf(pos) == chunkPos {
+				delete(w.blockEntities, pos)
+			}
+		}
+	}
+}
+
+// onChangeDimension 在机器人切换维度时清空世界镜像
+func (w *WorldMirror) onChangeDimension(p *packet.ChangeDimension) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.reset(p.Dimension)
+}
@@ -0,0 +1,241 @@
+package resources_control
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// subChunkBlockCount 是单个子区块中方块的数量
+	subChunkBlockCount = 4096
+	// storageHeaderCopyLast 是生物群系储存的头部，
+	// 它指示该储存与上一个储存完全相同
+	storageHeaderCopyLast = 0x7f<<1 | 1
+)
+
+// subChunkLayer 是子区块中的单个方块层
+type subChunkLayer struct {
+	// palette 是该层的方块调色板
+	palette []uint32
+	// indices 是每个方块在调色板中的索引。
+	// 如果为空，则该层的所有方块都是 palette[0]
+	indices []uint16
+}
+
+// subChunk 是 16*16*16 的子区块
+type subChunk struct {
+	layers []*subChunkLayer
+}
+
+// chunkColumn 是由若干子区块组成的区块
+type chunkColumn struct {
+	// minSubY 是最低子区块的 Y 索引 (方块 Y 坐标右移 4 位)
+	minSubY int32
+	// subChunks 保存已知的子区块，
+	// 它们以 Y 索引为键
+	subChunks map[int32]*subChunk
+}
+
+// newChunkColumn 根据维度的最低 Y 坐标 minY 创建一个新的空区块
+func newChunkColumn(minY int32) *chunkColumn {
+	return &chunkColumn{
+		minSubY:   minY >> 4,
+		subChunks: make(map[int32]*subChunk),
+	}
+}
+
+// blockIndex 返回子区块中相对坐标为 (x, y, z) 的方块的索引
+func blockIndex(x, y, z int32) int {
+	return int(x&15)<<8 | int(z&15)<<4 | int(y&15)
+}
+
+// block 返回该层中相对坐标为 (x, y, z) 的方块
+func (l *subChunkLayer) block(x, y, z int32) uint32 {
+	if len(l.indices) == 0 {
+		return l.palette[0]
+	}
+	return l.palette[l.indices[blockIndex(x, y, z)]]
+}
+
+// setBlock 将该层中相对坐标为 (x, y, z) 的方块设置为 runtimeID
+func (l *subChunkLayer) setBlock(x, y, z int32, runtimeID uint32) {
+	paletteIndex := -1
+	for index, value := range l.palette {
+		if value == runtimeID {
+			paletteIndex = index
+			break
+		}
+	}
+	if paletteIndex == -1 {
+		l.palette = append(l.palette, runtimeID)
+		paletteIndex = len(l.palette) - 1
+	}
+	if len(l.indices) == 0 {
+		if paletteIndex == 0 {
+			return
+		}
+		l.indices = make([]uint16, subChunkBlockCount)
+	}
+	l.indices[blockIndex(x, y, z)] = uint16(paletteIndex)
+}
+
+// newAirSubChunk 返回一个只包含空气的子区块
+func newAirSubChunk(air uint32) *subChunk {
+	return &subChunk{layers: []*subChunkLayer{{palette: []uint32{air}}}}
+}
+
+// block 返回方块坐标为 (x, y, z) 且位于第 layer 层的方块。
+// 如果该方块所在的子区块未知，则 found 为假
+func (c *chunkColumn) block(x, y, z int32, layer uint8, air uint32) (runtimeID uint32, found bool) {
+	sub, ok := c.subChunks[y>>4]
+	if !ok {
+		return 0, false
+	}
+	if int(layer) >= len(sub.layers) {
+		return air, true
+	}
+	return sub.layers[layer].block(x, y, z), true
+}
+
+// setBlock 将方块坐标为 (x, y, z) 且位于第 layer 层的方块设置为 runtimeID。
+// 如果该方块所在的子区块未知，则不进行任何操作
+func (c *chunkColumn) setBlock(x, y, z int32, layer uint8, runtimeID uint32, air uint32) {
+	sub, ok := c.subChunks[y>>4]
+	if !ok {
+		return
+	}
+	for int(layer) >= len(sub.layers) {
+		sub.layers = append(sub.layers, &subChunkLayer{palette: []uint32{air}})
+	}
+	sub.layers[layer].setBlock(x, y, z, runtimeID)
+}
+
+// readVarint32 从 buf 中读取一个 varint32
+func readVarint32(buf *bytes.Buffer) (int32, error) {
+	value, err := binary.ReadVarint(buf)
+	if err != nil {
+		return 0, err
+	}
+	return int32(value), nil
+}
+
+// 下面的解码函数只实现了 chunk.NetworkDecode 和 chunk.DecodeSubChunk
+// 中世界镜像所需的部分。bedrock-world-operator 的 chunk 包依赖于
+// github.com/Happy2018new/worldupgrader，而该模块目前无法从模块代理
+// 获取，因此不能在此处直接使用。当该依赖可用时，应改为调用上述函数
+
+// decodeStorage 从 buf 中解码网络编码的调色板储存。
+// 如果储存的头部为 storageHeaderCopyLast，则 copyLast 为真
+func decodeStorage(buf *bytes.Buffer) (layer *subChunkLayer, copyLast bool, err error) {
+	header, err := buf.ReadByte()
+	if err != nil {
+		return nil, false, fmt.Errorf("decodeStorage: %v", err)
+	}
+	if header == storageHeaderCopyLast {
+		return nil, true, nil
+	}
+
+	bitsPerBlock := int(header >> 1)
+	layer = new(subChunkLayer)
+
+	if bitsPerBlock > 0 {
+		if bitsPerBlock > 16 {
+			return nil, false, fmt.Errorf("decodeStorage: Invalid bits per block %d", bitsPerBlock)
+		}
+
+		blocksPerWord := 32 / bitsPerBlock
+		words := make([]uint32, (subChunkBlockCount+blocksPerWord-1)/blocksPerWord)
+		if err = binary.Read(buf, binary.LittleEndian, words); err != nil {
+			return nil, false, fmt.Errorf("decodeStorage: %v", err)
+		}
+
+		mask := uint32(1)<<bitsPerBlock - 1
+		layer.indices = make([]uint16, subChunkBlockCount)
+		for index := range subChunkBlockCount {
+			word := words[index/blocksPerWord]
+			layer.indices[index] = uint16(word >> (uint(index%blocksPerWord) * uint(bitsPerBlock)) & mask)
+		}
+	}
+
+	paletteSize := int32(1)
+	if bitsPerBlock > 0 {
+		if paletteSize, err = readVarint32(buf); err != nil {
+			return nil, false, fmt.Errorf("decodeStorage: %v", err)
+		}
+	}
+	if paletteSize <= 0 || paletteSize > subChunkBlockCount {
+		return nil, false, fmt.Errorf("decodeStorage: Invalid palette size %d", paletteSize)
+	}
+
+	layer.palette = make([]uint32, paletteSize)
+	for index := range layer.palette {
+		value, err := readVarint32(buf)
+		if err != nil {
+			return nil, false, fmt.Errorf("decodeStorage: %v", err)
+		}
+		layer.palette[index] = uint32(value)
+	}
+	for _, value := range layer.indices {
+		if int32(value) >= paletteSize {
+			return nil, false, fmt.Errorf("decodeStorage: Palette index %d is out of range", value)
+		}
+	}
+
+	return layer, false, nil
+}
+
+// decodeSubChunk 从 buf 中解码网络编码的子区块。
+// defaultSubY 是当子区块版本不携带 Y 索引时所使用的 Y 索引
+func decodeSubChunk(buf *bytes.Buffer, defaultSubY int32) (sub *subChunk, subY int32, err error) {
+	version, err := buf.ReadByte()
+	if err != nil {
+		return nil, 0, fmt.Errorf("decodeSubChunk: %v", err)
+	}
+
+	storageCount, subY := byte(1), defaultSubY
+	switch version {
+	case 1:
+	case 8, 9:
+		if storageCount, err = buf.ReadByte(); err != nil {
+			return nil, 0, fmt.Errorf("decodeSubChunk: %v", err)
+		}
+		if version == 9 {
+			index, err := buf.ReadByte()
+			if err != nil {
+				return nil, 0, fmt.Errorf("decodeSubChunk: %v", err)
+			}
+			subY = int32(int8(index))
+		}
+	default:
+		return nil, 0, fmt.Errorf("decodeSubChunk: Unknown sub chunk version %d", version)
+	}
+
+	sub = &subChunk{layers: make([]*subChunkLayer, storageCount)}
+	for index := range sub.layers {
+		layer, copyLast, err := decodeStorage(buf)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decodeSubChunk: %v", err)
+		}
+		if copyLast {
+			return nil, 0, fmt.Errorf("decodeSubChunk: Block storage can not refer to the previous one")
+		}
+		sub.layers[index] = layer
+	}
+
+	return sub, subY, nil
+}
+
+// skipBiomes 跳过 buf 中的 count 个生物群系储存
+func skipBiomes(buf *bytes.Buffer, count int) error {
+	for index := range count {
+		_, copyLast, err := decodeStorage(buf)
+		if err != nil {
+			return fmt.Errorf("skipBiomes: %v", err)
+		}
+		if copyLast && index == 0 {
+			return fmt.Errorf("skipBiomes: The first biome storage can not refer to the previous one")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package resources_control
+
+import (
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+
+	"github.com/TriM-Organization/bedrock-world-operator/block"
+)
+
+// emptyLevelChunk 返回位于 pos 且只包含空气的区块数据包
+func emptyLevelChunk(pos protocol.ChunkPos) *packet.LevelChunk {
+	// 第一个生物群系储存只有一种生物群系，其余储存都与其相同
+	payload := []byte{1, 0}
+	for range 23 {
+		payload = append(payload, storageHeaderCopyLast)
+	}
+	// 边界方块的数量
+	payload = append(payload, 0)
+	return &packet.LevelChunk{Position: pos, RawPayload: payload}
+}
+
+func TestWorldMirrorCacheEnabled(t *testing.T) {
+	w := NewWorldMirror(0)
+	pos := protocol.BlockPos{1, 2, 3}
+
+	if _, err := w.onLevelChunk(emptyLevelChunk(protocol.ChunkPos{0, 0})); err != nil {
+		t.Fatalf("TestWorldMirrorCacheEnabled: %v", err)
+	}
+	if runtimeID, found := w.BlockRuntimeIDAt(pos); !found || runtimeID != block.AirRuntimeID {
+		t.Fatalf("TestWorldMirrorCacheEnabled: Expected air, but got %d (found = %v)", runtimeID, found)
+	}
+
+	// 启用了区块缓存的区块无法被解码，因此已有的区块被视为未加载
+	chunk := emptyLevelChunk(protocol.ChunkPos{0, 0})
+	chunk.CacheEnabled = true
+	if _, err := w.onLevelChunk(chunk); err == nil {
+		t.Fatalf("TestWorldMirrorCacheEnabled: Expected an error for cached chunk")
+	}
+	if w.ChunkLoaded(pos) {
+		t.Fatalf("TestWorldMirrorCacheEnabled: Outdated chunk is kept in the mirror")
+	}
+
+	if _, err := w.onLevelChunk(emptyLevelChunk(protocol.ChunkPos{0, 0})); err != nil {
+		t.Fatalf("TestWorldMirrorCacheEnabled: %v", err)
+	}
+	err := w.onSubChunk(&packet.SubChunk{
+		CacheEnabled:    true,
+		Position:        protocol.SubChunkPos{0, 0, 0},
+		SubChunkEntries: []protocol.SubChunkEntry{{Result: protocol.SubChunkResultSuccess}},
+	})
+	if err == nil {
+		t.Fatalf("TestWorldMirrorCacheEnabled: Expected an error for cached sub chunk")
+	}
+	if w.ChunkLoaded(pos) {
+		t.Fatalf("TestWorldMirrorCacheEnabled: Outdated chunk is kept in the mirror")
+	}
+}
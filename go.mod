@@ -4,6 +4,7 @@ go 1.24
 
 require (
 	github.com/TriM-Organization/bedrock-world-operator v1.4.0
+	github.com/df-mc/goleveldb v1.1.9
 	github.com/go-gl/mathgl v1.2.0
 	github.com/go-jose/go-jose/v3 v3.0.4
 	github.com/google/uuid v1.6.0
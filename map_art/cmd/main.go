@@ -63,7 +63,6 @@ func doImport() {
 		}
 	}
 
-	counter := 0
 	importPos, result := map_art.GenerateMapArtStructure(
 		[3]int32{-4416, -30, 6976},
 		pixels,
@@ -83,12 +82,6 @@ func doImport() {
 			if err != nil {
 				panic(err)
 			}
-
-			counter++
-			if counter >= 20 {
-				time.Sleep(time.Second / 20)
-				counter = 0
-			}
 		}
 	}
 }
@@ -0,0 +1,54 @@
+package map_art
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// ParseMapColors 解析基岩版地图数据中 colors 字段所记载的像素。
+// colors 是长度为 128*128*4 的字节数组，它按行 (Z 轴) 依次存放
+// 每个像素的 RGBA 值。
+//
+// 返回的 pixels 以 pixels[x][z] 的形式存放，
+// 这使得它可以直接被 GenerateMapArtStructure 使用
+func ParseMapColors(colors []byte) (pixels [128][128]color.RGBA, err error) {
+	if len(colors) != 128*128*4 {
+		return pixels, fmt.Errorf("ParseMapColors: Map colors must be %d bytes, but got %d", 128*128*4, len(colors))
+	}
+
+	idx := 0
+	for z := range 128 {
+		for x := range 128 {
+			pixels[x][z] = color.RGBA{
+				colors[idx],
+				colors[idx+1],
+				colors[idx+2],
+				colors[idx+3],
+			}
+			idx += 4
+		}
+	}
+
+	return pixels, nil
+}
+
+// ParseMapData 从基岩版的地图数据 mapNBT 中解析地图的像素。
+// mapNBT 通常是存档中以 map_ 为前缀的键所对应的 NBT 数据
+func ParseMapData(mapNBT map[string]any) (pixels [128][128]color.RGBA, err error) {
+	var colors []byte
+
+	switch value := mapNBT["colors"].(type) {
+	case [128 * 128 * 4]byte:
+		colors = value[:]
+	case []byte:
+		colors = value
+	default:
+		return pixels, fmt.Errorf("ParseMapData: Map data have no colors field or its type (%T) is invalid", mapNBT["colors"])
+	}
+
+	pixels, err = ParseMapColors(colors)
+	if err != nil {
+		return pixels, fmt.Errorf("ParseMapData: %v", err)
+	}
+	return pixels, nil
+}
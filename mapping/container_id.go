@@ -43,6 +43,7 @@ var ContainerNeedSlotIDMapping = map[int]bool{
 	protocol.ContainerTypeBlastFurnace:  true,
 	protocol.ContainerTypeSmoker:        true,
 	protocol.ContainerTypeSmithingTable: true,
+	protocol.ContainerTypeCartography:   true,
 }
 
 // ContainerIDMapping 保存了一个 ContainerTypeWithSlot 到容器 ID 的映射。
@@ -83,6 +84,10 @@ var ContainerIDMapping = map[ContainerTypeWithSlot]uint8{
 	{ContainerType: protocol.ContainerTypeSmithingTable, SlotID: 0x34}: protocol.ContainerSmithingTableMaterial, // 4
 	{ContainerType: protocol.ContainerTypeSmithingTable, SlotID: 0x35}: protocol.ContainerSmithingTableTemplate, // 62
 
+	// cartography_table
+	{ContainerType: protocol.ContainerTypeCartography, SlotID: 0x0c}: protocol.ContainerCartographyInput,      // 56
+	{ContainerType: protocol.ContainerTypeCartography, SlotID: 0x0d}: protocol.ContainerCartographyAdditional, // 57
+
 	// blast_furnace (lit_blast_furnace)
 	{ContainerType: protocol.ContainerTypeBlastFurnace, SlotID: 0}: protocol.ContainerBlastFurnaceIngredient, // 46
 	{ContainerType: protocol.ContainerTypeBlastFurnace, SlotID: 1}: protocol.ContainerFurnaceFuel,            // 25
@@ -111,7 +116,6 @@ var ContainerIDMapping = map[ContainerTypeWithSlot]uint8{
 	{ContainerType: protocol.ContainerTypeMaterialReducer}:    ContainerIDUnknown,
 	{ContainerType: protocol.ContainerTypeGrindstone}:         ContainerIDUnknown,
 	{ContainerType: protocol.ContainerTypeStonecutter}:        ContainerIDUnknown,
-	{ContainerType: protocol.ContainerTypeCartography}:        ContainerIDUnknown,
 	{ContainerType: protocol.ContainerTypeJigsawEditor}:       ContainerIDUnknown,
 	{ContainerType: protocol.ContainerTypeChestBoat}:          ContainerIDUnknown,
 
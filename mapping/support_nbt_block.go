@@ -11,6 +11,12 @@ const (
 	SupportNBTBlockTypeJukeBox
 	SupportNBTBlockTypeBrewingStand
 	SupportNBTBlockTypeCrafter
+	SupportNBTBlockTypeSkull
+	SupportNBTBlockTypeFlowerPot
+	SupportNBTBlockTypeDecoratedPot
+	SupportNBTBlockTypeChiseledBookshelf
+	SupportNBTBlockTypeCampfire
+	SupportNBTBlockTypeMobSpawner
 )
 
 // 此表描述了现阶段已经支持了的方块实体。
@@ -95,4 +101,28 @@ var SupportBlocksPool = map[string]uint8{
 	"minecraft:jukebox":         SupportNBTBlockTypeJukeBox,
 	"minecraft:brewing_stand":   SupportNBTBlockTypeBrewingStand,
 	"minecraft:crafter":         SupportNBTBlockTypeCrafter,
+	// 头颅
+	"minecraft:skull": SupportNBTBlockTypeSkull,
+	// 花盆, 饰纹陶罐 和 雕纹书架
+	"minecraft:flower_pot":         SupportNBTBlockTypeFlowerPot,
+	"minecraft:decorated_pot":      SupportNBTBlockTypeDecoratedPot,
+	"minecraft:chiseled_bookshelf": SupportNBTBlockTypeChiseledBookshelf,
+	// 营火
+	"minecraft:campfire":      SupportNBTBlockTypeCampfire,
+	"minecraft:soul_campfire": SupportNBTBlockTypeCampfire,
+	// 刷怪笼
+	"minecraft:mob_spawner": SupportNBTBlockTypeMobSpawner,
+}
+
+// 此表描述了可以被放置在营火上烹饪的物品
+var CampfireCookableItems = map[string]bool{
+	"minecraft:beef":     true,
+	"minecraft:chicken":  true,
+	"minecraft:cod":      true,
+	"minecraft:salmon":   true,
+	"minecraft:mutton":   true,
+	"minecraft:porkchop": true,
+	"minecraft:rabbit":   true,
+	"minecraft:potato":   true,
+	"minecraft:kelp":     true,
 }
@@ -4,6 +4,7 @@ const (
 	SupportNBTItemTypeBook uint8 = iota
 	SupportNBTItemTypeBanner
 	SupportNBTItemTypeShield
+	SupportNBTItemTypeFilledMap
 )
 
 // 此表描述了现阶段已经支持了的特殊物品，如烟花等物品。
@@ -16,4 +17,6 @@ var SupportItemsPool = map[string]uint8{
 	"minecraft:banner": SupportNBTItemTypeBanner,
 	// 盾牌
 	"minecraft:shield": SupportNBTItemTypeShield,
+	// 已填充地图
+	"minecraft:filled_map": SupportNBTItemTypeFilledMap,
 }
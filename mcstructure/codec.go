@@ -0,0 +1,211 @@
+package mcstructure
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// Decode 从 data 解码一个结构文件。
+// data 是小端序 NBT 编码的 .mcstructure 文件内容
+func Decode(data []byte) (*Structure, error) {
+	var structureNBT map[string]any
+
+	err := nbt.UnmarshalEncoding(data, &structureNBT, nbt.LittleEndian)
+	if err != nil {
+		return nil, fmt.Errorf("Decode: %v", err)
+	}
+
+	s, err := FromNBT(structureNBT)
+	if err != nil {
+		return nil, fmt.Errorf("Decode: %v", err)
+	}
+	return s, nil
+}
+
+// Encode 将结构 s 编码为小端序 NBT 编码的 .mcstructure 文件内容
+func (s *Structure) Encode() ([]byte, error) {
+	data, err := nbt.MarshalEncoding(s.ToNBT(), nbt.LittleEndian)
+	if err != nil {
+		return nil, fmt.Errorf("Encode: %v", err)
+	}
+	return data, nil
+}
+
+// FromNBT 从已解码的结构文件 structureNBT 解析一个结构。
+// 它亦可用于解析 StructureTemplateDataResponse 数据包
+// 中的结构模板
+func FromNBT(structureNBT map[string]any) (*Structure, error) {
+	s := &Structure{
+		BlockEntities: make(map[int32]map[string]any),
+	}
+
+	s.FormatVersion, _ = structureNBT["format_version"].(int32)
+	size, err := parseVec3(structureNBT["size"])
+	if err != nil {
+		return nil, fmt.Errorf("FromNBT: Failed to parse size; err = %v", err)
+	}
+	s.Size = size
+	if origin, err := parseVec3(structureNBT["structure_world_origin"]); err == nil {
+		s.Origin = origin
+	}
+
+	structure, ok := structureNBT["structure"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("FromNBT: Structure data not found")
+	}
+
+	// 方块索引
+	layers := utils.NBTListToSlice(structure["block_indices"])
+	if len(layers) > LayerCount {
+		return nil, fmt.Errorf("FromNBT: Too many block layers (%d)", len(layers))
+	}
+	for layer := range LayerCount {
+		s.BlockIndices[layer] = make([]int32, s.Volume())
+		for index := range s.BlockIndices[layer] {
+			s.BlockIndices[layer][index] = EmptyBlockIndex
+		}
+		if layer >= len(layers) {
+			continue
+		}
+		indices := utils.NBTListToSlice(layers[layer])
+		if len(indices) != s.Volume() {
+			return nil, fmt.Errorf(
+				"FromNBT: Block indices of layer %d have %d elements, but the volume of the structure is %d",
+				layer, len(indices), s.Volume(),
+			)
+		}
+		for index, value := range indices {
+			paletteIndex, ok := value.(int32)
+			if !ok {
+				return nil, fmt.Errorf("FromNBT: Invalid block index %#v at layer %d", value, layer)
+			}
+			s.BlockIndices[layer][index] = paletteIndex
+		}
+	}
+
+	// 实体
+	for _, value := range utils.NBTListToSlice(structure["entities"]) {
+		if entity, ok := value.(map[string]any); ok {
+			s.Entities = append(s.Entities, entity)
+		}
+	}
+
+	// 调色板
+	palettes, _ := structure["palette"].(map[string]any)
+	palette, _ := palettes[DefaultPaletteName].(map[string]any)
+
+	for _, value := range utils.NBTListToSlice(palette["block_palette"]) {
+		block, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("FromNBT: Invalid block palette %#v", value)
+		}
+		name, _ := block["name"].(string)
+		states, _ := block["states"].(map[string]any)
+		version, _ := block["version"].(int32)
+		if states == nil {
+			states = make(map[string]any)
+		}
+		s.Palette = append(s.Palette, BlockPalette{
+			Name:    name,
+			States:  states,
+			Version: version,
+		})
+	}
+
+	// 方块实体
+	positionData, _ := palette["block_position_data"].(map[string]any)
+	for key, value := range positionData {
+		index, err := strconv.ParseInt(key, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("FromNBT: Invalid block position data key %#v; err = %v", key, err)
+		}
+		data, _ := value.(map[string]any)
+		blockNBT, ok := data["block_entity_data"].(map[string]any)
+		if !ok {
+			continue
+		}
+		s.BlockEntities[int32(index)] = blockNBT
+	}
+
+	return s, nil
+}
+
+// ToNBT 将结构 s 转换为结构文件的 NBT 表示
+func (s *Structure) ToNBT() map[string]any {
+	layers := make([]any, 0, LayerCount)
+	for layer := range LayerCount {
+		indices := make([]any, s.Volume())
+		for index := range indices {
+			indices[index] = EmptyBlockIndex
+			if index < len(s.BlockIndices[layer]) {
+				indices[index] = s.BlockIndices[layer][index]
+			}
+		}
+		layers = append(layers, indices)
+	}
+
+	entities := make([]any, 0, len(s.Entities))
+	for _, value := range s.Entities {
+		entities = append(entities, value)
+	}
+
+	blockPalette := make([]any, 0, len(s.Palette))
+	for _, value := range s.Palette {
+		states := value.States
+		if states == nil {
+			states = make(map[string]any)
+		}
+		blockPalette = append(blockPalette, map[string]any{
+			"name":    value.Name,
+			"states":  states,
+			"version": value.Version,
+		})
+	}
+
+	positionData := make(map[string]any)
+	for index, blockNBT := range s.BlockEntities {
+		positionData[strconv.FormatInt(int64(index), 10)] = map[string]any{
+			"block_entity_data": blockNBT,
+		}
+	}
+
+	formatVersion := s.FormatVersion
+	if formatVersion == 0 {
+		formatVersion = DefaultFormatVersion
+	}
+
+	return map[string]any{
+		"format_version": formatVersion,
+		"size":           []any{s.Size[0], s.Size[1], s.Size[2]},
+		"structure": map[string]any{
+			"block_indices": layers,
+			"entities":      entities,
+			"palette": map[string]any{
+				DefaultPaletteName: map[string]any{
+					"block_palette":       blockPalette,
+					"block_position_data": positionData,
+				},
+			},
+		},
+		"structure_world_origin": []any{s.Origin[0], s.Origin[1], s.Origin[2]},
+	}
+}
+
+// parseVec3 将由三个 int32 组成的列表 value 解析为数组
+func parseVec3(value any) (result [3]int32, err error) {
+	list := utils.NBTListToSlice(value)
+	if len(list) != 3 {
+		return result, fmt.Errorf("parseVec3: Invalid vector %#v", value)
+	}
+	for index, val := range list {
+		var ok bool
+		result[index], ok = val.(int32)
+		if !ok {
+			return result, fmt.Errorf("parseVec3: Invalid vector %#v", value)
+		}
+	}
+	return result, nil
+}
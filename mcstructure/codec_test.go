@@ -0,0 +1,119 @@
+package mcstructure
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+)
+
+// newFixture 返回一个 2×2×3 的结构。
+// 它含有不同层上的方块、一个方块实体和一个实体
+func newFixture(t *testing.T) *Structure {
+	s := NewStructure([3]int32{2, 2, 3})
+	s.Origin = [3]int32{-8, 64, 1024}
+
+	blocks := []struct {
+		pos   protocol.BlockPos
+		layer int
+		block BlockPalette
+	}{
+		{protocol.BlockPos{0, 0, 0}, LayerNormal, BlockPalette{Name: "minecraft:stone", States: map[string]any{}, Version: DefaultBlockVersion}},
+		{protocol.BlockPos{1, 0, 2}, LayerNormal, BlockPalette{
+			Name:    "minecraft:chest",
+			States:  map[string]any{"minecraft:cardinal_direction": "east"},
+			Version: DefaultBlockVersion,
+		}},
+		{protocol.BlockPos{0, 1, 2}, LayerNormal, BlockPalette{
+			Name:    "minecraft:oak_stairs",
+			States:  map[string]any{"upside_down_bit": byte(1), "weirdo_direction": int32(2)},
+			Version: DefaultBlockVersion,
+		}},
+		{protocol.BlockPos{0, 1, 2}, LayerWaterlogged, BlockPalette{
+			Name:    "minecraft:water",
+			States:  map[string]any{"liquid_depth": int32(0)},
+			Version: DefaultBlockVersion,
+		}},
+	}
+	for _, value := range blocks {
+		if err := s.SetBlock(value.pos, value.layer, value.block); err != nil {
+			t.Fatalf("newFixture: %v", err)
+		}
+	}
+
+	err := s.SetBlockEntity(protocol.BlockPos{1, 0, 2}, map[string]any{
+		"id":         "Chest",
+		"CustomName": "fixture",
+		"x":          int32(-7),
+		"y":          int32(64),
+		"z":          int32(1026),
+	})
+	if err != nil {
+		t.Fatalf("newFixture: %v", err)
+	}
+	s.Entities = append(s.Entities, map[string]any{
+		"identifier": "minecraft:armor_stand",
+		"UniqueID":   int64(-42),
+	})
+
+	return s
+}
+
+func TestEncodeDecode(t *testing.T) {
+	s := newFixture(t)
+
+	data, err := s.Encode()
+	if err != nil {
+		t.Fatalf("TestEncodeDecode: %v", err)
+	}
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("TestEncodeDecode: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, s) {
+		t.Fatalf("TestEncodeDecode: Structure changed after round trip\nexpected = %#v\nactual = %#v", s, decoded)
+	}
+
+	// 解码所得的结构应当能够被再次编码。
+	// 复合标签的字段顺序不固定，因此只比较解码的结果
+	again, err := decoded.Encode()
+	if err != nil {
+		t.Fatalf("TestEncodeDecode: %v", err)
+	}
+	if decoded, err = Decode(again); err != nil || !reflect.DeepEqual(decoded, s) {
+		t.Fatalf("TestEncodeDecode: Structure changed after second round trip (err = %v)", err)
+	}
+}
+
+func TestDecodeLayout(t *testing.T) {
+	data, err := newFixture(t).Encode()
+	if err != nil {
+		t.Fatalf("TestDecodeLayout: %v", err)
+	}
+	s, err := Decode(data)
+	if err != nil {
+		t.Fatalf("TestDecodeLayout: %v", err)
+	}
+
+	if block, found := s.Block(protocol.BlockPos{1, 0, 2}, LayerNormal); !found || block.Name != "minecraft:chest" {
+		t.Fatalf("TestDecodeLayout: Unexpected block %#v", block)
+	}
+	if block, found := s.Block(protocol.BlockPos{0, 1, 2}, LayerWaterlogged); !found || block.Name != "minecraft:water" {
+		t.Fatalf("TestDecodeLayout: Unexpected waterlogged block %#v", block)
+	}
+	if _, found := s.Block(protocol.BlockPos{1, 1, 1}, LayerNormal); found {
+		t.Fatalf("TestDecodeLayout: Expected no block at (1, 1, 1)")
+	}
+	if blockNBT, found := s.BlockEntity(protocol.BlockPos{1, 0, 2}); !found || blockNBT["CustomName"] != "fixture" {
+		t.Fatalf("TestDecodeLayout: Unexpected block entity %#v", blockNBT)
+	}
+
+	// Z 轴变化最快，因此 (1, 0, 2) 的索引为 (1*2+0)*3+2
+	if index := s.Index(protocol.BlockPos{1, 0, 2}); index != 8 || s.Position(index) != (protocol.BlockPos{1, 0, 2}) {
+		t.Fatalf("TestDecodeLayout: Unexpected index %d", index)
+	}
+
+	if _, err = Decode(data[:len(data)/2]); err == nil {
+		t.Fatalf("TestDecodeLayout: Expected an error for truncated data")
+	}
+}
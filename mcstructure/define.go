@@ -0,0 +1,52 @@
+package mcstructure
+
+// DefaultPaletteName 是结构文件默认使用的调色板名称
+const DefaultPaletteName = "default"
+
+// DefaultFormatVersion 是结构文件的格式版本
+const DefaultFormatVersion int32 = 1
+
+// DefaultBlockVersion 是新创建的方块所使用的方块版本
+const DefaultBlockVersion int32 = 18153728
+
+// 结构中的方块层
+const (
+	// LayerNormal 是方块所在的主要层
+	LayerNormal = iota
+	// LayerWaterlogged 是含水方块的水所在的层
+	LayerWaterlogged
+	// LayerCount 是结构中方块层的数量
+	LayerCount
+)
+
+// EmptyBlockIndex 指示某个位置上没有方块，
+// 这通常是结构空位或未被含水的方块
+const EmptyBlockIndex int32 = -1
+
+// BlockPalette 是调色板中的一个方块
+type BlockPalette struct {
+	Name    string
+	States  map[string]any
+	Version int32
+}
+
+// Structure 是 Bedrock 结构文件 (.mcstructure) 的类型化表示
+type Structure struct {
+	// FormatVersion 是结构文件的格式版本
+	FormatVersion int32
+	// Size 是结构在 X, Y, Z 轴上的尺寸
+	Size [3]int32
+	// Origin 是结构被保存时在世界中的原点
+	Origin [3]int32
+
+	// Palette 是结构所使用的方块调色板
+	Palette []BlockPalette
+	// BlockIndices 是每个方块层上各个位置的方块在调色板中的索引。
+	// 如果某个位置没有方块，则其索引为 EmptyBlockIndex
+	BlockIndices [LayerCount][]int32
+	// BlockEntities 是结构中的方块实体数据，
+	// 它的键是方块实体所在位置的索引
+	BlockEntities map[int32]map[string]any
+	// Entities 是结构中的实体数据
+	Entities []map[string]any
+}
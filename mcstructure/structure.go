@@ -0,0 +1,147 @@
+package mcstructure
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+
+	_ "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	_ "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+)
+
+// NewStructure 创建并返回一个尺寸为 size 的空结构。
+// 结构中的所有位置都不含方块
+func NewStructure(size [3]int32) *Structure {
+	volume := int(size[0]) * int(size[1]) * int(size[2])
+	s := &Structure{
+		FormatVersion: DefaultFormatVersion,
+		Size:          size,
+		BlockEntities: make(map[int32]map[string]any),
+	}
+	for layer := range LayerCount {
+		s.BlockIndices[layer] = make([]int32, volume)
+		for index := range volume {
+			s.BlockIndices[layer][index] = EmptyBlockIndex
+		}
+	}
+	return s
+}
+
+// Volume 返回结构的体积
+func (s *Structure) Volume() int {
+	return int(s.Size[0]) * int(s.Size[1]) * int(s.Size[2])
+}
+
+// Contains 检查相对于结构原点的位置 pos 是否在结构内
+func (s *Structure) Contains(pos protocol.BlockPos) bool {
+	for axis := range 3 {
+		if pos[axis] < 0 || pos[axis] >= s.Size[axis] {
+			return false
+		}
+	}
+	return true
+}
+
+// Index 返回相对于结构原点的位置 pos 在结构中的索引。
+// 结构中的方块按照 X, Y, Z 的顺序排列，且 Z 轴变化最快
+func (s *Structure) Index(pos protocol.BlockPos) int32 {
+	return (pos[0]*s.Size[1]+pos[1])*s.Size[2] + pos[2]
+}
+
+// Position 返回索引 index 所对应的相对于结构原点的位置
+func (s *Structure) Position(index int32) protocol.BlockPos {
+	return protocol.BlockPos{
+		index / (s.Size[1] * s.Size[2]),
+		index / s.Size[2] % s.Size[1],
+		index % s.Size[2],
+	}
+}
+
+// Block 返回 pos 处第 layer 层的方块。
+// 如果该位置没有方块，则 found 为假
+func (s *Structure) Block(pos protocol.BlockPos, layer int) (block BlockPalette, found bool) {
+	if !s.Contains(pos) {
+		return BlockPalette{}, false
+	}
+	paletteIndex := s.BlockIndices[layer][s.Index(pos)]
+	if paletteIndex < 0 || int(paletteIndex) >= len(s.Palette) {
+		return BlockPalette{}, false
+	}
+	return s.Palette[paletteIndex], true
+}
+
+// SetBlock 将 pos 处第 layer 层的方块设置为 block。
+// 如果 block 不在调色板中，则它将被追加到调色板
+func (s *Structure) SetBlock(pos protocol.BlockPos, layer int, block BlockPalette) error {
+	if !s.Contains(pos) {
+		return fmt.Errorf("SetBlock: Position %v is out of the structure (size = %v)", pos, s.Size)
+	}
+	s.BlockIndices[layer][s.Index(pos)] = s.paletteIndex(block)
+	return nil
+}
+
+// paletteIndex 返回 block 在调色板中的索引，
+// 如果 block 不在调色板中，则将其追加到调色板
+func (s *Structure) paletteIndex(block BlockPalette) int32 {
+	for index, value := range s.Palette {
+		if value.Name == block.Name && value.Version == block.Version && reflect.DeepEqual(value.States, block.States) {
+			return int32(index)
+		}
+	}
+	s.Palette = append(s.Palette, block)
+	return int32(len(s.Palette) - 1)
+}
+
+// BlockEntity 返回 pos 处的方块实体数据。
+// 如果该位置没有方块实体，则 found 为假
+func (s *Structure) BlockEntity(pos protocol.BlockPos) (blockNBT map[string]any, found bool) {
+	if !s.Contains(pos) {
+		return nil, false
+	}
+	blockNBT, found = s.BlockEntities[s.Index(pos)]
+	return
+}
+
+// SetBlockEntity 将 pos 处的方块实体数据设置为 blockNBT。
+// 如果 blockNBT 为空，则移除该位置的方块实体数据
+func (s *Structure) SetBlockEntity(pos protocol.BlockPos, blockNBT map[string]any) error {
+	if !s.Contains(pos) {
+		return fmt.Errorf("SetBlockEntity: Position %v is out of the structure (size = %v)", pos, s.Size)
+	}
+	if s.BlockEntities == nil {
+		s.BlockEntities = make(map[int32]map[string]any)
+	}
+	if blockNBT == nil {
+		delete(s.BlockEntities, s.Index(pos))
+		return nil
+	}
+	s.BlockEntities[s.Index(pos)] = blockNBT
+	return nil
+}
+
+// ParseBlock 将 pos 处的方块及其方块实体数据解析为 NBT 方块。
+// nameChecker 的含义与 nbt_parser_interface.ParseBlock 相同。
+//
+// 如果该位置没有方块实体，则 found 为假
+func (s *Structure) ParseBlock(nameChecker func(name string) bool, pos protocol.BlockPos) (
+	block nbt_parser_interface.Block,
+	found bool,
+	err error,
+) {
+	blockNBT, found := s.BlockEntity(pos)
+	if !found {
+		return nil, false, nil
+	}
+	palette, found := s.Block(pos, LayerNormal)
+	if !found {
+		return nil, false, nil
+	}
+
+	block, err = nbt_parser_interface.ParseBlock(nameChecker, palette.Name, palette.States, blockNBT)
+	if err != nil {
+		return nil, false, fmt.Errorf("ParseBlock: %v", err)
+	}
+	return block, true, nil
+}
@@ -0,0 +1,123 @@
+package mock_server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/bunker/auth"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/login"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/google/uuid"
+)
+
+// Authenticator 是模拟租赁服的验证器。
+// 它实现了 minecraft.Authenticator，
+// 并在本地签发一条自签名的登录链，
+// 这使得客户端无需连接验证服务器即可登录模拟租赁服
+type Authenticator struct {
+	address  string
+	identity login.IdentityData
+}
+
+// chainClaims 是登录链中除最后一个以外的声明
+type chainClaims struct {
+	jwt.Claims
+	IdentityPublicKey    string `json:"identityPublicKey"`
+	CertificateAuthority bool   `json:"certificateAuthority,omitempty"`
+}
+
+// identityClaims 是登录链中最后一个声明，
+// 它持有机器人的身份数据
+type identityClaims struct {
+	jwt.Claims
+	ExtraData         login.IdentityData `json:"extraData"`
+	IdentityPublicKey string             `json:"identityPublicKey"`
+}
+
+// GetAccess 签发一条包含 publicKey 的登录链。
+// publicKey 是客户端以 PKIX 格式编码的公钥
+func (a *Authenticator) GetAccess(ctx context.Context, publicKey []byte) (auth.AuthResponse, error) {
+	chain, err := a.makeChain(base64.StdEncoding.EncodeToString(publicKey))
+	if err != nil {
+		return auth.AuthResponse{}, fmt.Errorf("GetAccess: %v", err)
+	}
+	return auth.AuthResponse{
+		SuccessStates:  true,
+		RentalServerIP: a.address,
+		ChainInfo:      chain,
+	}, nil
+}
+
+// makeChain 签发一条以 clientKey 为终点的登录链。
+//
+// 客户端在登录时会在登录链的开头插入一个声明，
+// 因此这里签发的链包含两个声明，使得最终的链满足
+// 网易租赁服所使用的 3 个声明的格式
+func (a *Authenticator) makeChain(clientKey string) (string, error) {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("makeChain: %v", err)
+	}
+	identityKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("makeChain: %v", err)
+	}
+
+	claims := jwt.Claims{
+		Issuer:    "NetEase",
+		Expiry:    jwt.NewNumericDate(time.Now().Add(time.Hour * 6)),
+		NotBefore: jwt.NewNumericDate(time.Now().Add(-time.Hour * 6)),
+	}
+
+	rootSigner, err := jose.NewSigner(
+		jose.SigningKey{Key: rootKey, Algorithm: jose.ES384},
+		&jose.SignerOptions{ExtraHeaders: map[jose.HeaderKey]any{"x5u": login.MarshalPublicKey(&rootKey.PublicKey)}},
+	)
+	if err != nil {
+		return "", fmt.Errorf("makeChain: %v", err)
+	}
+	identitySigner, err := jose.NewSigner(
+		jose.SigningKey{Key: identityKey, Algorithm: jose.ES384},
+		&jose.SignerOptions{ExtraHeaders: map[jose.HeaderKey]any{"x5u": login.MarshalPublicKey(&identityKey.PublicKey)}},
+	)
+	if err != nil {
+		return "", fmt.Errorf("makeChain: %v", err)
+	}
+
+	first, err := jwt.Signed(rootSigner).Claims(chainClaims{
+		Claims:               claims,
+		IdentityPublicKey:    login.MarshalPublicKey(&identityKey.PublicKey),
+		CertificateAuthority: true,
+	}).CompactSerialize()
+	if err != nil {
+		return "", fmt.Errorf("makeChain: %v", err)
+	}
+	second, err := jwt.Signed(identitySigner).Claims(identityClaims{
+		Claims:            claims,
+		ExtraData:         a.identity,
+		IdentityPublicKey: clientKey,
+	}).CompactSerialize()
+	if err != nil {
+		return "", fmt.Errorf("makeChain: %v", err)
+	}
+
+	chain, _ := json.Marshal(map[string]any{"chain": []string{first, second}})
+	return string(chain), nil
+}
+
+// newIdentity 返回名为 botName 的机器人的身份数据
+func newIdentity(botName string, uid int64) login.IdentityData {
+	return login.IdentityData{
+		Identity:    uuid.NewString(),
+		DisplayName: botName,
+		Uid:         uid,
+	}
+}
@@ -0,0 +1,179 @@
+package mock_server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+
+	"github.com/TriM-Organization/bedrock-world-operator/block"
+)
+
+const (
+	// chunkRadius 是模拟租赁服向机器人发送的区块的半径 (以区块为单位)
+	chunkRadius = 4
+	// worldMinY 和 worldMaxY 是主世界的高度范围
+	worldMinY, worldMaxY = -64, 319
+	// subChunkCount 是主世界中每个区块的子区块数量
+	subChunkCount = (worldMaxY-worldMinY)>>4 + 1
+	// storageHeaderCopyLast 是生物群系储存的头部，
+	// 它指示该储存与上一个储存完全相同
+	storageHeaderCopyLast = 0x7f<<1 | 1
+)
+
+// chunkPos 是区块的坐标
+type chunkPos [2]int32
+
+// chunkPosOf 返回方块坐标 pos 所在区块的坐标
+func chunkPosOf(pos protocol.BlockPos) chunkPos {
+	return chunkPos{pos[0] >> 4, pos[2] >> 4}
+}
+
+// blockRuntimeID 返回方块 b 的运行时 ID。
+// 如果该方块无法在方块注册表中被找到，则返回空气的运行时 ID
+func blockRuntimeID(b Block) uint32 {
+	runtimeID, found := block.StateToRuntimeID(b.Name, b.States)
+	if !found {
+		return block.AirRuntimeID
+	}
+	return runtimeID
+}
+
+// writeVarint32 将 value 以 varint32 写入 buf
+func writeVarint32(buf *bytes.Buffer, value int32) {
+	buf.Write(binary.AppendVarint(nil, int64(value)))
+}
+
+// encodeStorage 将子区块中按索引排列的方块运行时 ID
+// runtimeIDs 以网络格式的调色板储存写入 buf
+func encodeStorage(buf *bytes.Buffer, runtimeIDs []uint32) {
+	palette := make([]uint32, 0)
+	paletteIndex := make(map[uint32]uint32)
+	indices := make([]uint32, len(runtimeIDs))
+	for index, runtimeID := range runtimeIDs {
+		value, ok := paletteIndex[runtimeID]
+		if !ok {
+			value = uint32(len(palette))
+			paletteIndex[runtimeID] = value
+			palette = append(palette, runtimeID)
+		}
+		indices[index] = value
+	}
+
+	if len(palette) == 1 {
+		buf.WriteByte(1)
+		writeVarint32(buf, int32(palette[0]))
+		return
+	}
+
+	bitsPerBlock := 1
+	for _, bits := range []int{1, 2, 3, 4, 5, 6, 8, 16} {
+		if len(palette) <= 1<<bits {
+			bitsPerBlock = bits
+			break
+		}
+	}
+	blocksPerWord := 32 / bitsPerBlock
+	words := make([]uint32, (len(indices)+blocksPerWord-1)/blocksPerWord)
+	for index, value := range indices {
+		words[index/blocksPerWord] |= value << (uint(index%blocksPerWord) * uint(bitsPerBlock))
+	}
+
+	buf.WriteByte(byte(bitsPerBlock<<1 | 1))
+	_ = binary.Write(buf, binary.LittleEndian, words)
+	writeVarint32(buf, int32(len(palette)))
+	for _, runtimeID := range palette {
+		writeVarint32(buf, int32(runtimeID))
+	}
+}
+
+// levelChunk 返回区块 pos 的区块数据包。
+// 区块中的所有子区块都被包含在其中，
+// 但方块实体数据不会被发送
+func (w *World) levelChunk(pos chunkPos) *packet.LevelChunk {
+	w.mu.Lock()
+	subChunks := make(map[int32][]uint32)
+	for blockPos, b := range w.blocks {
+		if chunkPosOf(blockPos) != pos || blockPos[1] < worldMinY || blockPos[1] > worldMaxY {
+			continue
+		}
+		subY := blockPos[1] >> 4
+		if _, ok := subChunks[subY]; !ok {
+			subChunks[subY] = make([]uint32, 4096)
+			for index := range subChunks[subY] {
+				subChunks[subY][index] = block.AirRuntimeID
+			}
+		}
+		index := (blockPos[0]&15)<<8 | (blockPos[2]&15)<<4 | blockPos[1]&15
+		subChunks[subY][index] = blockRuntimeID(b)
+	}
+	w.mu.Unlock()
+
+	buf := bytes.NewBuffer(nil)
+	for index := range int32(subChunkCount) {
+		subY := worldMinY>>4 + index
+		buf.Write([]byte{9, 1, byte(int8(subY))})
+		runtimeIDs, ok := subChunks[subY]
+		if !ok {
+			runtimeIDs = []uint32{block.AirRuntimeID}
+		}
+		encodeStorage(buf, runtimeIDs)
+	}
+	// 所有子区块都使用同一个生物群系
+	encodeStorage(buf, []uint32{0})
+	for range subChunkCount - 1 {
+		buf.WriteByte(storageHeaderCopyLast)
+	}
+	// 边界方块的数量
+	buf.WriteByte(0)
+
+	return &packet.LevelChunk{
+		Position:      protocol.ChunkPos{pos[0], pos[1]},
+		SubChunkCount: subChunkCount,
+		RawPayload:    buf.Bytes(),
+	}
+}
+
+// sendChunks 向客户端发送以机器人所在的区块为中心，
+// 半径为 chunkRadius 的所有尚未发送的区块
+func (s *session) sendChunks() {
+	center := chunkPosOf(protocol.BlockPos{
+		int32(math.Floor(float64(s.position[0]))),
+		0,
+		int32(math.Floor(float64(s.position[2]))),
+	})
+	_ = s.conn.WritePacket(&packet.NetworkChunkPublisherUpdate{
+		Position: protocol.BlockPos{center[0] << 4, 0, center[1] << 4},
+		Radius:   chunkRadius << 4,
+	})
+
+	for pos := range s.sentChunks {
+		dx, dz := pos[0]-center[0], pos[1]-center[1]
+		if dx*dx+dz*dz > chunkRadius*chunkRadius {
+			delete(s.sentChunks, pos)
+		}
+	}
+	for dx := int32(-chunkRadius); dx <= chunkRadius; dx++ {
+		for dz := int32(-chunkRadius); dz <= chunkRadius; dz++ {
+			pos := chunkPos{center[0] + dx, center[1] + dz}
+			if dx*dx+dz*dz > chunkRadius*chunkRadius || s.sentChunks[pos] {
+				continue
+			}
+			_ = s.conn.WritePacket(s.world.levelChunk(pos))
+			s.sentChunks[pos] = true
+		}
+	}
+}
+
+// onBlockChange 在世界中 pos 处的方块变为 b 时
+// 向客户端发送方块更新
+func (s *session) onBlockChange(pos protocol.BlockPos, b Block) {
+	_ = s.conn.WritePacket(&packet.UpdateBlock{
+		Position:          pos,
+		NewBlockRuntimeID: blockRuntimeID(b),
+		Flags:             packet.BlockUpdateNetwork,
+		Layer:             0,
+	})
+}
@@ -0,0 +1,1011 @@
+package mock_server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+
+	"github.com/df-mc/worldupgrader/blockupgrader"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// commandResult 是单条命令的执行结果
+type commandResult struct {
+	success    bool
+	message    string
+	parameters []string
+}
+
+// commandFailed 返回以 message 为消息的失败结果
+func commandFailed(message string, parameters ...string) commandResult {
+	return commandResult{success: false, message: message, parameters: parameters}
+}
+
+// commandSucceed 返回以 message 为消息的成功结果
+func commandSucceed(message string, parameters ...string) commandResult {
+	return commandResult{success: true, message: message, parameters: parameters}
+}
+
+// handleCommandRequest 执行客户端请求的命令，
+// 并以命令输出数据包返回执行结果。
+//
+// 与租赁服一致，以玩家身份执行成功的命令不会得到命令输出，
+// 只有失败的命令才会得到响应
+func (s *session) handleCommandRequest(p *packet.CommandRequest) {
+	result := s.executeCommand(p.CommandLine)
+	if result.success && p.CommandOrigin.Origin == protocol.CommandOriginPlayer {
+		return
+	}
+	if drop := s.server.cfg.DropCommandOutput; drop != nil && drop(p.CommandLine) {
+		return
+	}
+
+	var successCount uint32
+	if result.success {
+		successCount = 1
+	}
+	_ = s.conn.WritePacket(&packet.CommandOutput{
+		CommandOrigin: p.CommandOrigin,
+		OutputType:    packet.CommandOutputTypeAllOutput,
+		SuccessCount:  successCount,
+		OutputMessages: []protocol.CommandOutputMessage{
+			{
+				Success:    result.success,
+				Message:    result.message,
+				Parameters: result.parameters,
+			},
+		},
+	})
+}
+
+// splitCommand 将命令 line 按空格切分。
+// 位于引号、方括号或花括号中的空格不会被用于切分
+func splitCommand(line string) []string {
+	var (
+		result  []string
+		current strings.Builder
+		depth   int
+		quoted  bool
+		escaped bool
+	)
+
+	for _, char := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && char == '\\':
+			escaped = true
+		case char == '"':
+			quoted = !quoted
+		case !quoted && (char == '[' || char == '{'):
+			depth++
+		case !quoted && (char == ']' || char == '}'):
+			depth--
+		case !quoted && depth <= 0 && (char == ' ' || char == '\t'):
+			if current.Len() > 0 {
+				result = append(result, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(char)
+	}
+	if current.Len() > 0 {
+		result = append(result, current.String())
+	}
+
+	return result
+}
+
+// unquote 去除 str 两侧的引号
+func unquote(str string) string {
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
+		if result, err := strconv.Unquote(str); err == nil {
+			return result
+		}
+		return str[1 : len(str)-1]
+	}
+	return str
+}
+
+// parseCoordinate 以 origin 为参照解析单个坐标分量
+func parseCoordinate(arg string, origin float32) (float32, error) {
+	if strings.HasPrefix(arg, "~") || strings.HasPrefix(arg, "^") {
+		if len(arg) == 1 {
+			return origin, nil
+		}
+		offset, err := strconv.ParseFloat(arg[1:], 32)
+		if err != nil {
+			return 0, fmt.Errorf("parseCoordinate: %v", err)
+		}
+		return origin + float32(offset), nil
+	}
+
+	value, err := strconv.ParseFloat(arg, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parseCoordinate: %v", err)
+	}
+	return float32(value), nil
+}
+
+// parsePosition 以 origin 为参照解析由 args 表示的坐标
+func parsePosition(args []string, origin mgl32.Vec3) (result mgl32.Vec3, err error) {
+	if len(args) < 3 {
+		return mgl32.Vec3{}, fmt.Errorf("parsePosition: Position is incomplete")
+	}
+	for index := range 3 {
+		result[index], err = parseCoordinate(args[index], origin[index])
+		if err != nil {
+			return mgl32.Vec3{}, fmt.Errorf("parsePosition: %v", err)
+		}
+	}
+	return
+}
+
+// parseBlockPos 以 origin 为参照解析由 args 表示的方块坐标
+func parseBlockPos(args []string, origin mgl32.Vec3) (protocol.BlockPos, error) {
+	pos, err := parsePosition(args, origin)
+	if err != nil {
+		return protocol.BlockPos{}, fmt.Errorf("parseBlockPos: %v", err)
+	}
+	return protocol.BlockPos{
+		int32(math.Floor(float64(pos[0]))),
+		int32(math.Floor(float64(pos[1]))),
+		int32(math.Floor(float64(pos[2]))),
+	}, nil
+}
+
+// parseBlockStates 解析形如 ["a"="b",c=1,d=true] 的方块状态
+func parseBlockStates(str string) (map[string]any, error) {
+	result := make(map[string]any)
+
+	str = strings.TrimSpace(str)
+	if !strings.HasPrefix(str, "[") || !strings.HasSuffix(str, "]") {
+		return nil, fmt.Errorf("parseBlockStates: Invalid block states %#v", str)
+	}
+	str = strings.TrimSpace(str[1 : len(str)-1])
+	if len(str) == 0 {
+		return result, nil
+	}
+
+	for _, pair := range strings.Split(str, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			key, value, found = strings.Cut(pair, ":")
+		}
+		if !found {
+			return nil, fmt.Errorf("parseBlockStates: Invalid block state %#v", pair)
+		}
+		key, value = unquote(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, `"`):
+			result[key] = unquote(value)
+		case value == "true":
+			result[key] = byte(1)
+		case value == "false":
+			result[key] = byte(0)
+		default:
+			number, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("parseBlockStates: %v", err)
+			}
+			result[key] = int32(number)
+		}
+	}
+
+	return result, nil
+}
+
+// executeCommand 执行命令 line 并返回执行结果
+func (s *session) executeCommand(line string) commandResult {
+	args := splitCommand(strings.TrimPrefix(strings.TrimSpace(line), "/"))
+	if len(args) == 0 {
+		return commandFailed("commands.generic.unknown", "")
+	}
+	return s.runCommand(args, s.position)
+}
+
+// runCommand 以 origin 为执行位置运行由 args 表示的命令
+func (s *session) runCommand(args []string, origin mgl32.Vec3) commandResult {
+	switch strings.ToLower(args[0]) {
+	case "execute":
+		return s.commandExecute(args[1:], origin)
+	case "setblock":
+		return s.commandSetblock(args[1:], origin)
+	case "fill":
+		return s.commandFill(args[1:], origin)
+	case "clone":
+		return s.commandClone(args[1:], origin)
+	case "testforblocks":
+		return s.commandTestForBlocks(args[1:], origin)
+	case "testforblock":
+		return s.commandTestForBlock(args[1:], origin)
+	case "structure":
+		return s.commandStructure(args[1:], origin)
+	case "replaceitem":
+		return s.commandReplaceitem(args[1:], origin)
+	case "give":
+		return s.commandGive(args[1:])
+	case "clear":
+		return s.commandClear()
+	case "querytarget":
+		return s.commandQuerytarget()
+	case "tp", "teleport":
+		return s.commandTeleport(args[1:], origin)
+	case "gamemode":
+		return s.commandGamemode(args[1:])
+	case "say":
+		return s.commandMessage(packet.TextTypeAnnouncement, args[1:])
+	case "tell", "msg", "w":
+		if len(args) < 2 {
+			return commandFailed("commands.generic.syntax", args[0])
+		}
+		return s.commandMessage(packet.TextTypeWhisper, args[2:])
+	case "titleraw":
+		return s.commandTitleraw(args[1:])
+	case "kick":
+		return s.commandKick(args[1:])
+	case "gamerule":
+		return s.commandGamerule(args[1:])
+	case "list":
+		return commandSucceed("commands.players.list", "1", "1")
+	}
+	return commandFailed("commands.generic.unknown", args[0])
+}
+
+// commandExecute 实现 execute 命令。
+// 由于世界中只有机器人一个实体，因此目标选择器总被视为机器人自身
+func (s *session) commandExecute(args []string, origin mgl32.Vec3) commandResult {
+	for len(args) > 0 {
+		switch args[0] {
+		case "as", "in":
+			if len(args) < 2 {
+				return commandFailed("commands.generic.syntax", args[0])
+			}
+			args = args[2:]
+		case "at":
+			if len(args) < 2 {
+				return commandFailed("commands.generic.syntax", args[0])
+			}
+			origin, args = s.position, args[2:]
+		case "positioned":
+			pos, err := parsePosition(args[1:], origin)
+			if err != nil {
+				return commandFailed("commands.generic.syntax", args[0])
+			}
+			origin, args = pos, args[4:]
+		case "run":
+			if len(args) < 2 {
+				return commandFailed("commands.generic.syntax", args[0])
+			}
+			return s.runCommand(args[1:], origin)
+		default:
+			return commandFailed("commands.generic.syntax", args[0])
+		}
+	}
+	return commandFailed("commands.generic.syntax", "execute")
+}
+
+// parseBlock 解析由 args 表示的方块名称和方块状态。
+// rest 是方块状态之后的剩余参数
+func parseBlock(args []string) (block Block, rest []string, err error) {
+	if len(args) == 0 {
+		return Block{}, nil, fmt.Errorf("parseBlock: Block name is missing")
+	}
+
+	block = Block{Name: normalizeName(args[0]), States: make(map[string]any)}
+	rest = args[1:]
+	if len(rest) > 0 && strings.HasPrefix(rest[0], "[") {
+		block.States, err = parseBlockStates(rest[0])
+		if err != nil {
+			return Block{}, nil, fmt.Errorf("parseBlock: %v", err)
+		}
+		rest = rest[1:]
+	}
+
+	newBlock := blockupgrader.Upgrade(blockupgrader.BlockState{
+		Name:       block.Name,
+		Properties: block.States,
+	})
+	block.Name, block.States = newBlock.Name, newBlock.Properties
+	block.NBT = defaultBlockNBT(block.Name)
+
+	return block, rest, nil
+}
+
+// checkBlockName 检查名为 name 的方块是否可被放置。
+// 只有在配置中指定了方块列表时，该检查才会生效
+func (s *session) checkBlockName(name string) bool {
+	if name == AirBlock || len(s.server.cfg.Blocks) == 0 {
+		return true
+	}
+	for _, value := range s.server.cfg.Blocks {
+		if normalizeName(value) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// commandSetblock 实现 setblock 命令
+func (s *session) commandSetblock(args []string, origin mgl32.Vec3) commandResult {
+	pos, err := parseBlockPos(args, origin)
+	if err != nil || len(args) < 4 {
+		return commandFailed("commands.generic.syntax", "setblock")
+	}
+	block, rest, err := parseBlock(args[3:])
+	if err != nil || !s.checkBlockName(block.Name) {
+		return commandFailed("commands.setblock.failed")
+	}
+	if len(rest) > 0 && rest[0] == "keep" && s.world.Block(pos).Name != AirBlock {
+		return commandFailed("commands.setblock.noChange")
+	}
+
+	s.world.SetBlock(pos, block)
+	return commandSucceed("commands.setblock.success")
+}
+
+// maxRegionBlocks 是 fill, clone 和 testforblocks 命令所能操作的最大方块数量
+const maxRegionBlocks = 32768
+
+// parseRegion 解析由 args 表示的两个角，
+// 并返回它们所围成的区域的最小坐标和最大坐标
+func parseRegion(args []string, origin mgl32.Vec3) (minPos protocol.BlockPos, maxPos protocol.BlockPos, err error) {
+	start, err := parseBlockPos(args[0:3], origin)
+	if err != nil {
+		return minPos, maxPos, fmt.Errorf("parseRegion: %v", err)
+	}
+	end, err := parseBlockPos(args[3:6], origin)
+	if err != nil {
+		return minPos, maxPos, fmt.Errorf("parseRegion: %v", err)
+	}
+	for index := range 3 {
+		minPos[index] = min(start[index], end[index])
+		maxPos[index] = max(start[index], end[index])
+	}
+	return minPos, maxPos, nil
+}
+
+// regionVolume 返回由 minPos 和 maxPos 所围成的区域的方块数量
+func regionVolume(minPos protocol.BlockPos, maxPos protocol.BlockPos) int {
+	return int(maxPos[0]-minPos[0]+1) * int(maxPos[1]-minPos[1]+1) * int(maxPos[2]-minPos[2]+1)
+}
+
+// regionLoaded 检查由 minPos 和 maxPos 所围成的区域
+// 所在的区块是否都已被发送给客户端。
+// 与租赁服相同，区域命令无法操作未被加载的区块
+func (s *session) regionLoaded(minPos protocol.BlockPos, maxPos protocol.BlockPos) bool {
+	start, end := chunkPosOf(minPos), chunkPosOf(maxPos)
+	for x := start[0]; x <= end[0]; x++ {
+		for z := start[1]; z <= end[1]; z++ {
+			if !s.sentChunks[chunkPos{x, z}] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// offsetRegion 返回以 dest 为起点且与由 minPos
+// 和 maxPos 所围成的区域等大的区域的最大坐标
+func offsetRegion(minPos protocol.BlockPos, maxPos protocol.BlockPos, dest protocol.BlockPos) protocol.BlockPos {
+	return protocol.BlockPos{
+		dest[0] + maxPos[0] - minPos[0],
+		dest[1] + maxPos[1] - minPos[1],
+		dest[2] + maxPos[2] - minPos[2],
+	}
+}
+
+// sameBlock 检查 a 和 b 是否是相同的方块
+func sameBlock(a Block, b Block) bool {
+	return a.Name == b.Name && reflect.DeepEqual(a.States, b.States)
+}
+
+// commandFill 实现 fill 命令
+func (s *session) commandFill(args []string, origin mgl32.Vec3) commandResult {
+	if len(args) < 7 {
+		return commandFailed("commands.generic.syntax", "fill")
+	}
+	minPos, maxPos, err := parseRegion(args, origin)
+	if err != nil {
+		return commandFailed("commands.generic.syntax", "fill")
+	}
+	if volume := regionVolume(minPos, maxPos); volume > maxRegionBlocks {
+		return commandFailed("commands.fill.tooManyBlocks", strconv.Itoa(volume), strconv.Itoa(maxRegionBlocks))
+	}
+	if !s.regionLoaded(minPos, maxPos) {
+		return commandFailed("commands.fill.outOfWorld")
+	}
+	block, rest, err := parseBlock(args[6:])
+	if err != nil || !s.checkBlockName(block.Name) {
+		return commandFailed("commands.fill.failed")
+	}
+
+	var filter *Block
+	if len(rest) > 1 && rest[0] == "replace" {
+		filterBlock, _, err := parseBlock(rest[1:])
+		if err != nil {
+			return commandFailed("commands.generic.syntax", "fill")
+		}
+		filter = &filterBlock
+	}
+
+	var count int
+	for x := minPos[0]; x <= maxPos[0]; x++ {
+		for y := minPos[1]; y <= maxPos[1]; y++ {
+			for z := minPos[2]; z <= maxPos[2]; z++ {
+				pos := protocol.BlockPos{x, y, z}
+				current := s.world.Block(pos)
+				if filter != nil && current.Name != filter.Name {
+					continue
+				}
+				// 与租赁服相同，未被更改的方块不计入填充的数量
+				if sameBlock(current, block) {
+					continue
+				}
+				s.world.SetBlock(pos, block)
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return commandFailed("commands.fill.failed")
+	}
+	return commandSucceed("commands.fill.success", strconv.Itoa(count))
+}
+
+// commandClone 实现 clone 命令
+func (s *session) commandClone(args []string, origin mgl32.Vec3) commandResult {
+	if len(args) < 9 {
+		return commandFailed("commands.generic.syntax", "clone")
+	}
+	minPos, maxPos, err := parseRegion(args, origin)
+	if err != nil {
+		return commandFailed("commands.generic.syntax", "clone")
+	}
+	dest, err := parseBlockPos(args[6:9], origin)
+	if err != nil {
+		return commandFailed("commands.generic.syntax", "clone")
+	}
+	if volume := regionVolume(minPos, maxPos); volume > maxRegionBlocks {
+		return commandFailed("commands.clone.tooManyBlocks", strconv.Itoa(volume), strconv.Itoa(maxRegionBlocks))
+	}
+	if !s.regionLoaded(minPos, maxPos) || !s.regionLoaded(dest, offsetRegion(minPos, maxPos, dest)) {
+		return commandFailed("commands.clone.outOfWorld")
+	}
+
+	var blocks []Block
+	for x := minPos[0]; x <= maxPos[0]; x++ {
+		for y := minPos[1]; y <= maxPos[1]; y++ {
+			for z := minPos[2]; z <= maxPos[2]; z++ {
+				blocks = append(blocks, s.world.Block(protocol.BlockPos{x, y, z}))
+			}
+		}
+	}
+	var count int
+	for x := range maxPos[0] - minPos[0] + 1 {
+		for y := range maxPos[1] - minPos[1] + 1 {
+			for z := range maxPos[2] - minPos[2] + 1 {
+				pos := protocol.BlockPos{dest[0] + x, dest[1] + y, dest[2] + z}
+				if !sameBlock(s.world.Block(pos), blocks[0]) {
+					s.world.SetBlock(pos, blocks[0])
+					count++
+				}
+				blocks = blocks[1:]
+			}
+		}
+	}
+	if count == 0 {
+		return commandFailed("commands.clone.failed")
+	}
+
+	return commandSucceed("commands.clone.success", strconv.Itoa(count))
+}
+
+// commandTestForBlock 实现 testforblock 命令
+func (s *session) commandTestForBlock(args []string, origin mgl32.Vec3) commandResult {
+	pos, err := parseBlockPos(args, origin)
+	if err != nil || len(args) < 4 {
+		return commandFailed("commands.generic.syntax", "testforblock")
+	}
+	if s.world.Block(pos).Name != normalizeName(args[3]) {
+		return commandFailed("commands.testforblock.failed.tile")
+	}
+	return commandSucceed("commands.testforblock.success")
+}
+
+// commandTestForBlocks 实现 testforblocks 命令
+func (s *session) commandTestForBlocks(args []string, origin mgl32.Vec3) commandResult {
+	if len(args) < 9 {
+		return commandFailed("commands.generic.syntax", "testforblocks")
+	}
+	minPos, maxPos, err := parseRegion(args, origin)
+	if err != nil {
+		return commandFailed("commands.generic.syntax", "testforblocks")
+	}
+	dest, err := parseBlockPos(args[6:9], origin)
+	if err != nil {
+		return commandFailed("commands.generic.syntax", "testforblocks")
+	}
+	volume := regionVolume(minPos, maxPos)
+	if volume > maxRegionBlocks {
+		return commandFailed("commands.compare.tooManyBlocks", strconv.Itoa(volume), strconv.Itoa(maxRegionBlocks))
+	}
+	if !s.regionLoaded(minPos, maxPos) || !s.regionLoaded(dest, offsetRegion(minPos, maxPos, dest)) {
+		return commandFailed("commands.compare.outOfWorld")
+	}
+
+	for x := range maxPos[0] - minPos[0] + 1 {
+		for y := range maxPos[1] - minPos[1] + 1 {
+			for z := range maxPos[2] - minPos[2] + 1 {
+				src := s.world.Block(protocol.BlockPos{minPos[0] + x, minPos[1] + y, minPos[2] + z})
+				dst := s.world.Block(protocol.BlockPos{dest[0] + x, dest[1] + y, dest[2] + z})
+				if !sameBlock(src, dst) {
+					return commandFailed("commands.compare.failed")
+				}
+			}
+		}
+	}
+
+	return commandSucceed("commands.compare.success", strconv.Itoa(volume))
+}
+
+// commandStructure 实现 structure save、load 和 delete 命令
+func (s *session) commandStructure(args []string, origin mgl32.Vec3) commandResult {
+	if len(args) < 2 {
+		return commandFailed("commands.generic.syntax", "structure")
+	}
+	name := unquote(args[1])
+
+	switch args[0] {
+	case "save":
+		if len(args) < 8 {
+			return commandFailed("commands.generic.syntax", "structure")
+		}
+		start, err := parseBlockPos(args[2:5], origin)
+		if err != nil {
+			return commandFailed("commands.generic.syntax", "structure")
+		}
+		end, err := parseBlockPos(args[5:8], origin)
+		if err != nil {
+			return commandFailed("commands.generic.syntax", "structure")
+		}
+		s.world.SaveStructure(name, start, end)
+		return commandSucceed("commands.structure.save.success", name)
+	case "load":
+		pos, err := parseBlockPos(args[2:], origin)
+		if err != nil {
+			return commandFailed("commands.generic.syntax", "structure")
+		}
+		if !s.world.LoadStructure(name, pos) {
+			return commandFailed("commands.structure.load.notFound", name)
+		}
+		return commandSucceed("commands.structure.load.success", name)
+	case "delete":
+		if !s.world.DeleteStructure(name) {
+			return commandFailed("commands.structure.delete.notFound", name)
+		}
+		return commandSucceed("commands.structure.delete.success", name)
+	}
+
+	return commandFailed("commands.generic.syntax", "structure")
+}
+
+// upgradeBlockName 返回名为 name 的方块在当前版本下的名称
+func upgradeBlockName(name string) string {
+	return blockupgrader.Upgrade(blockupgrader.BlockState{
+		Name:       normalizeName(name),
+		Properties: make(map[string]any),
+	}).Name
+}
+
+// parseItemComponent 解析命令中的物品组件，
+// 并将其应用到 stack 上
+func parseItemComponent(str string, stack *protocol.ItemStack) error {
+	var component struct {
+		CanPlaceOn *struct {
+			Blocks []string `json:"blocks"`
+		} `json:"can_place_on"`
+		CanDestroy *struct {
+			Blocks []string `json:"blocks"`
+		} `json:"can_destroy"`
+		ItemLock *struct {
+			Mode string `json:"mode"`
+		} `json:"item_lock"`
+		KeepOnDeath *struct{} `json:"keep_on_death"`
+	}
+
+	if len(str) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(str), &component); err != nil {
+		return fmt.Errorf("parseItemComponent: %v", err)
+	}
+
+	if component.CanPlaceOn != nil {
+		for _, name := range component.CanPlaceOn.Blocks {
+			stack.CanBePlacedOn = append(stack.CanBePlacedOn, upgradeBlockName(name))
+		}
+	}
+	if component.CanDestroy != nil {
+		for _, name := range component.CanDestroy.Blocks {
+			stack.CanBreak = append(stack.CanBreak, upgradeBlockName(name))
+		}
+	}
+	if component.ItemLock != nil {
+		switch component.ItemLock.Mode {
+		case "lock_in_slot":
+			stack.NBTData["minecraft:item_lock"] = byte(1)
+		case "lock_in_inventory":
+			stack.NBTData["minecraft:item_lock"] = byte(2)
+		}
+	}
+	if component.KeepOnDeath != nil {
+		stack.NBTData["minecraft:keep_on_death"] = byte(1)
+	}
+
+	return nil
+}
+
+// parseItem 解析由 args 表示的物品名称、数量、数据值和物品组件。
+// maxCount 是物品数量的上限
+func (s *session) parseItem(args []string, maxCount int) (stack protocol.ItemStack, err error) {
+	if len(args) == 0 {
+		return protocol.ItemStack{}, fmt.Errorf("parseItem: Item name is missing")
+	}
+
+	count, metadata := 1, 0
+	if len(args) > 1 {
+		if count, err = strconv.Atoi(args[1]); err != nil {
+			return protocol.ItemStack{}, fmt.Errorf("parseItem: %v", err)
+		}
+	}
+	if len(args) > 2 {
+		if metadata, err = strconv.Atoi(args[2]); err != nil {
+			return protocol.ItemStack{}, fmt.Errorf("parseItem: %v", err)
+		}
+	}
+	if count <= 0 || count > maxCount || metadata < 0 {
+		return protocol.ItemStack{}, fmt.Errorf("parseItem: Invalid count or metadata")
+	}
+
+	stack, found := s.items.newItemStack(args[0], uint16(count), uint32(metadata))
+	if !found {
+		return protocol.ItemStack{}, fmt.Errorf("parseItem: Item %#v is not registered", args[0])
+	}
+	if len(args) > 3 {
+		if err = parseItemComponent(strings.Join(args[3:], " "), &stack); err != nil {
+			return protocol.ItemStack{}, fmt.Errorf("parseItem: %v", err)
+		}
+	}
+
+	return stack, nil
+}
+
+// parseReplaceitemItem 解析 replaceitem 命令中的物品。
+// 与 parseItem 不同，它允许使用空气清空槽位
+func (s *session) parseReplaceitemItem(args []string) (stack protocol.ItemStack, err error) {
+	if len(args) > 0 && normalizeName(args[0]) == "minecraft:air" {
+		return protocol.ItemStack{NBTData: make(map[string]any)}, nil
+	}
+	return s.parseItem(args, DefaultMaxStackSize)
+}
+
+// commandReplaceitem 实现 replaceitem entity 和 replaceitem block 命令
+func (s *session) commandReplaceitem(args []string, origin mgl32.Vec3) commandResult {
+	if len(args) == 0 {
+		return commandFailed("commands.generic.syntax", "replaceitem")
+	}
+
+	switch args[0] {
+	case "entity":
+		if len(args) < 5 {
+			return commandFailed("commands.generic.syntax", "replaceitem")
+		}
+		slot, err := strconv.Atoi(args[3])
+		if err != nil || slot < 0 {
+			return commandFailed("commands.generic.syntax", "replaceitem")
+		}
+
+		var w *window
+		switch args[2] {
+		case "slot.hotbar":
+			w = s.inventory
+			if slot >= botHotbarSize {
+				return commandFailed("commands.replaceitem.badSlotNumber", args[2])
+			}
+		case "slot.inventory":
+			w, slot = s.inventory, slot+botHotbarSize
+		case "slot.weapon.offhand":
+			w = s.offHand
+		case "slot.armor.head", "slot.armor.chest", "slot.armor.legs", "slot.armor.feet":
+			w = s.armour
+			slot = map[string]int{"slot.armor.head": 0, "slot.armor.chest": 1, "slot.armor.legs": 2, "slot.armor.feet": 3}[args[2]]
+		default:
+			return commandFailed("commands.replaceitem.badSlotNumber", args[2])
+		}
+		if slot >= w.size {
+			return commandFailed("commands.replaceitem.badSlotNumber", args[2])
+		}
+
+		stack, err := s.parseReplaceitemItem(args[4:])
+		if err != nil {
+			return commandFailed("commands.replaceitem.failed")
+		}
+		w.set(byte(slot), s.newItemInstance(stack))
+		s.sendSlot(w, byte(slot))
+		return commandSucceed("commands.replaceitem.success.entity")
+	case "block":
+		if len(args) < 7 || args[4] != "slot.container" {
+			return commandFailed("commands.generic.syntax", "replaceitem")
+		}
+		pos, err := parseBlockPos(args[1:4], origin)
+		if err != nil {
+			return commandFailed("commands.generic.syntax", "replaceitem")
+		}
+		slot, err := strconv.Atoi(args[5])
+		if err != nil || slot < 0 || slot > math.MaxUint8 {
+			return commandFailed("commands.generic.syntax", "replaceitem")
+		}
+		stack, err := s.parseReplaceitemItem(args[6:])
+		if err != nil {
+			return commandFailed("commands.replaceitem.failed")
+		}
+		if s.opened != nil && len(s.opened.storageKey) > 0 && s.opened.blockPos == pos {
+			return commandFailed("commands.replaceitem.failed")
+		}
+
+		key := s.storageKeyOf(pos)
+		if len(key) == 0 {
+			return commandFailed("commands.replaceitem.noContainer", args[1], args[2], args[3])
+		}
+		updated := s.world.UpdateBlockNBT(pos, func(blockNBT map[string]any) {
+			items, _ := blockNBT[key].([]any)
+			newItems := make([]any, 0, len(items)+1)
+			for _, value := range items {
+				itemNBT, ok := value.(map[string]any)
+				if !ok {
+					continue
+				}
+				if itemSlot, _ := toInt(itemNBT["Slot"]); itemSlot == slot {
+					continue
+				}
+				newItems = append(newItems, itemNBT)
+			}
+			blockNBT[key] = append(newItems, s.itemToNBT(stack, byte(slot)))
+		})
+		if !updated {
+			return commandFailed("commands.replaceitem.noContainer", args[1], args[2], args[3])
+		}
+		return commandSucceed("commands.replaceitem.success")
+	}
+
+	return commandFailed("commands.generic.syntax", "replaceitem")
+}
+
+// storageKeyOf 返回 pos 处容器存放物品的字段名。
+// 如果该方块不是容器，则返回空字符串
+func (s *session) storageKeyOf(pos protocol.BlockPos) string {
+	return mapping.ContainerStorageKey[s.world.Block(pos).Name]
+}
+
+// commandGive 实现 give 命令。
+// 物品将优先与背包中相同的物品堆叠，然后再被放入空槽位
+func (s *session) commandGive(args []string) commandResult {
+	if len(args) < 2 {
+		return commandFailed("commands.generic.syntax", "give")
+	}
+	stack, err := s.parseItem(args[1:], DefaultMaxStackSize*botInventorySize)
+	if err != nil {
+		return commandFailed("commands.give.item.notFound", args[1])
+	}
+
+	remain := stack.Count
+	changed := make([]byte, 0)
+	for _, mergeOnly := range []bool{true, false} {
+		for slot := range byte(botInventorySize) {
+			if remain == 0 {
+				break
+			}
+
+			item := s.inventory.get(slot)
+			if mergeOnly != (item.Stack.NetworkID != 0) {
+				continue
+			}
+			if mergeOnly && !sameItem(item.Stack, stack) {
+				continue
+			}
+
+			delta := min(remain, DefaultMaxStackSize-item.Stack.Count)
+			if delta == 0 {
+				continue
+			}
+			newStack := stack
+			newStack.Count = item.Stack.Count + delta
+			s.inventory.set(slot, s.newItemInstance(newStack))
+			changed = append(changed, slot)
+			remain -= delta
+		}
+	}
+
+	for _, slot := range changed {
+		s.sendSlot(s.inventory, slot)
+	}
+	if remain == stack.Count {
+		return commandFailed("commands.give.failed")
+	}
+	return commandSucceed("commands.give.success")
+}
+
+// commandClear 实现 clear 命令
+func (s *session) commandClear() commandResult {
+	for _, w := range []*window{s.inventory, s.offHand, s.armour} {
+		clear(w.slots)
+		s.sendWindowContent(w)
+	}
+	return commandSucceed("commands.clear.success")
+}
+
+// commandQuerytarget 实现 querytarget 命令
+func (s *session) commandQuerytarget() commandResult {
+	type position struct {
+		X float32 `json:"x"`
+		Y float32 `json:"y"`
+		Z float32 `json:"z"`
+	}
+	type target struct {
+		Dimension byte     `json:"dimension"`
+		ID        int64    `json:"id"`
+		Position  position `json:"position"`
+		UniqueID  string   `json:"uniqueId"`
+		YRot      float32  `json:"yRot"`
+	}
+
+	result, _ := json.Marshal([]target{
+		{
+			ID:       botEntityUniqueID,
+			Position: position{X: s.position[0], Y: s.position[1], Z: s.position[2]},
+			UniqueID: s.conn.IdentityData().Identity,
+		},
+	})
+	return commandSucceed("commands.querytarget.success", string(result))
+}
+
+// commandTeleport 实现 tp 命令。
+// 只支持传送到坐标，并且目标总被视为机器人自身
+func (s *session) commandTeleport(args []string, origin mgl32.Vec3) commandResult {
+	if len(args) > 0 && strings.HasPrefix(args[0], "@") {
+		args = args[1:]
+	}
+	pos, err := parsePosition(args, origin)
+	if err != nil {
+		return commandFailed("commands.generic.syntax", "tp")
+	}
+
+	s.position = pos
+	_ = s.conn.WritePacket(&packet.MovePlayer{
+		EntityRuntimeID: botEntityRuntimeID,
+		Position:        pos,
+		Mode:            packet.MoveModeTeleport,
+		OnGround:        true,
+		TeleportCause:   packet.TeleportCauseCommand,
+	})
+	s.sendChunks()
+	return commandSucceed("commands.tp.success")
+}
+
+// commandGamemode 实现 gamemode 命令
+func (s *session) commandGamemode(args []string) commandResult {
+	if len(args) == 0 {
+		return commandFailed("commands.generic.syntax", "gamemode")
+	}
+
+	var gameMode int32
+	switch args[0] {
+	case "0", "s", "survival":
+		gameMode = packet.GameTypeSurvival
+	case "1", "c", "creative":
+		gameMode = packet.GameTypeCreative
+	case "2", "a", "adventure":
+		gameMode = packet.GameTypeAdventure
+	default:
+		return commandFailed("commands.gamemode.fail.invalid", args[0])
+	}
+
+	s.gameMode = gameMode
+	_ = s.conn.WritePacket(&packet.SetPlayerGameType{GameType: gameMode})
+	return commandSucceed("commands.gamemode.success.self")
+}
+
+// commandMessage 实现 say 和 msg 命令。
+// 由于世界中只有机器人一个玩家，因此消息总是被发送给机器人
+func (s *session) commandMessage(textType byte, args []string) commandResult {
+	_ = s.conn.WritePacket(&packet.Text{
+		TextType:   textType,
+		SourceName: s.server.cfg.BotName,
+		Message:    strings.Join(args, " "),
+	})
+	return commandSucceed("commands.message.success")
+}
+
+// commandKick 实现 kick 命令。
+// 由于世界中只有机器人一个玩家，因此被踢出的总是机器人
+func (s *session) commandKick(args []string) commandResult {
+	if len(args) == 0 {
+		return commandFailed("commands.generic.syntax", "kick")
+	}
+	message := "§c您已被踢出游戏"
+	if len(args) > 1 {
+		message += "，原因：" + strings.Join(args[1:], " ")
+	}
+	_ = s.server.listener.Disconnect(s.conn, message)
+	return commandSucceed("commands.kick.success", args[0])
+}
+
+// commandTitleraw 实现 titleraw 命令。
+// 只有原始 JSON 文本中的 text 字段会被使用
+func (s *session) commandTitleraw(args []string) commandResult {
+	if len(args) < 3 {
+		return commandFailed("commands.generic.syntax", "titleraw")
+	}
+
+	var actionType int32
+	switch args[1] {
+	case "title":
+		actionType = packet.TitleActionSetTitle
+	case "subtitle":
+		actionType = packet.TitleActionSetSubtitle
+	case "actionbar":
+		actionType = packet.TitleActionSetActionBar
+	default:
+		return commandFailed("commands.generic.syntax", "titleraw")
+	}
+
+	var rawText struct {
+		RawText []struct {
+			Text string `json:"text"`
+		} `json:"rawtext"`
+	}
+	if err := json.Unmarshal([]byte(strings.Join(args[2:], " ")), &rawText); err != nil {
+		return commandFailed("commands.tellraw.jsonException", err.Error())
+	}
+
+	var text strings.Builder
+	for _, value := range rawText.RawText {
+		text.WriteString(value.Text)
+	}
+	_ = s.conn.WritePacket(&packet.SetTitle{
+		ActionType: actionType,
+		Text:       text.String(),
+	})
+	return commandSucceed("commands.title.success")
+}
+
+// commandGamerule 实现设置游戏规则的 gamerule 命令
+func (s *session) commandGamerule(args []string) commandResult {
+	if len(args) < 2 {
+		return commandFailed("commands.generic.syntax", "gamerule")
+	}
+
+	var value any
+	if boolValue, err := strconv.ParseBool(args[1]); err == nil {
+		value = boolValue
+	} else if intValue, err := strconv.ParseUint(args[1], 10, 32); err == nil {
+		value = uint32(intValue)
+	} else {
+		return commandFailed("commands.gamerule.type.invalid", args[1])
+	}
+
+	_ = s.conn.WritePacket(&packet.GameRulesChanged{
+		GameRules: []protocol.GameRule{{Name: args[0], Value: value}},
+	})
+	return commandSucceed("commands.gamerule.success", args[0], args[1])
+}
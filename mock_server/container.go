@@ -0,0 +1,441 @@
+package mock_server
+
+import (
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// window 是机器人拥有或打开的单个库存窗口
+type window struct {
+	id            byte
+	containerType byte
+	size          int
+	slots         map[byte]protocol.ItemInstance
+
+	// 以下字段仅对打开的方块容器有效。
+	// 如果 storageKey 非空，则窗口中的物品
+	// 将被同步到该方块的方块实体数据中
+	blockPos   protocol.BlockPos
+	storageKey string
+}
+
+// newWindow 创建并返回一个新的空窗口
+func newWindow(id byte, containerType byte, size int) *window {
+	return &window{
+		id:            id,
+		containerType: containerType,
+		size:          size,
+		slots:         make(map[byte]protocol.ItemInstance),
+	}
+}
+
+// airItemInstance 返回空气的物品实例
+func airItemInstance() protocol.ItemInstance {
+	return protocol.ItemInstance{Stack: protocol.ItemStack{NBTData: make(map[string]any)}}
+}
+
+// get 返回窗口中位于 slot 的物品
+func (w *window) get(slot byte) protocol.ItemInstance {
+	item, ok := w.slots[slot]
+	if !ok {
+		return airItemInstance()
+	}
+	return item
+}
+
+// set 将窗口中位于 slot 的物品设置为 item
+func (w *window) set(slot byte, item protocol.ItemInstance) {
+	if item.Stack.NetworkID == 0 || item.Stack.Count == 0 {
+		delete(w.slots, slot)
+		return
+	}
+	w.slots[slot] = item
+}
+
+// clone 返回窗口的拷贝。
+// 物品的 NBT 数据不会被深拷贝，
+// 因此修改物品时应当总是替换整个物品实例
+func (w *window) clone() *window {
+	result := *w
+	result.slots = make(map[byte]protocol.ItemInstance, len(w.slots))
+	for slot, item := range w.slots {
+		result.slots[slot] = item
+	}
+	return &result
+}
+
+// containerInfo 描述可被打开的方块的容器信息
+type containerInfo struct {
+	containerType byte
+	size          int
+}
+
+// blockContainer 返回名为 name 的方块被打开时的容器信息。
+// 如果该方块不是可以被打开的容器，则 found 为假
+func blockContainer(name string) (info containerInfo, found bool) {
+	name = normalizeName(name)
+	switch {
+	case name == "minecraft:chest", name == "minecraft:trapped_chest", name == "minecraft:barrel":
+		return containerInfo{protocol.ContainerTypeContainer, 27}, true
+	case strings.HasSuffix(name, "shulker_box"):
+		return containerInfo{protocol.ContainerTypeContainer, 27}, true
+	case name == "minecraft:hopper":
+		return containerInfo{protocol.ContainerTypeHopper, 5}, true
+	case name == "minecraft:dispenser":
+		return containerInfo{protocol.ContainerTypeDispenser, 9}, true
+	case name == "minecraft:dropper":
+		return containerInfo{protocol.ContainerTypeDropper, 9}, true
+	case name == "minecraft:crafter":
+		return containerInfo{protocol.ContainerTypeCrafter, 9}, true
+	case strings.HasSuffix(name, "blast_furnace"):
+		return containerInfo{protocol.ContainerTypeBlastFurnace, 3}, true
+	case strings.HasSuffix(name, "smoker"):
+		return containerInfo{protocol.ContainerTypeSmoker, 3}, true
+	case strings.HasSuffix(name, "furnace"):
+		return containerInfo{protocol.ContainerTypeFurnace, 3}, true
+	case name == "minecraft:brewing_stand":
+		return containerInfo{protocol.ContainerTypeBrewingStand, 5}, true
+	case strings.HasSuffix(name, "anvil"):
+		return containerInfo{protocol.ContainerTypeAnvil, 3}, true
+	case name == "minecraft:loom":
+		return containerInfo{protocol.ContainerTypeLoom, 12}, true
+	}
+	return containerInfo{}, false
+}
+
+// handleInteract 处理打开背包的请求
+func (s *session) handleInteract(p *packet.Interact) {
+	if p.ActionType != packet.InteractActionOpenInventory || s.opened != nil {
+		return
+	}
+	s.opened = newWindow(protocol.WindowIDInventory, containerTypeInventory, 0)
+	_ = s.conn.WritePacket(&packet.ContainerOpen{
+		WindowID:                protocol.WindowIDInventory,
+		ContainerType:           containerTypeInventory,
+		ContainerPosition:       protocol.BlockPos{int32(s.position[0]), int32(s.position[1]), int32(s.position[2])},
+		ContainerEntityUniqueID: botEntityUniqueID,
+	})
+}
+
+// openContainer 打开位于 pos 的方块容器
+func (s *session) openContainer(pos protocol.BlockPos, block Block, info containerInfo) {
+	windowID := s.nextWindowID
+	s.nextWindowID = s.nextWindowID%99 + 1
+
+	w := newWindow(windowID, info.containerType, info.size)
+	w.blockPos = pos
+	w.storageKey = mapping.ContainerStorageKey[block.Name]
+	if len(w.storageKey) > 0 && block.NBT != nil {
+		items, _ := block.NBT[w.storageKey].([]any)
+		for _, value := range items {
+			itemNBT, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			slot, stack, ok := s.itemFromNBT(itemNBT)
+			if !ok {
+				continue
+			}
+			w.set(slot, s.newItemInstance(stack))
+		}
+	}
+
+	s.opened = w
+	_ = s.conn.WritePacket(&packet.ContainerOpen{
+		WindowID:                windowID,
+		ContainerType:           info.containerType,
+		ContainerPosition:       pos,
+		ContainerEntityUniqueID: -1,
+	})
+	if len(w.storageKey) > 0 {
+		s.sendWindowContent(w)
+	}
+}
+
+// syncContainer 将打开的方块容器中的物品同步到其方块实体数据中
+func (s *session) syncContainer() {
+	w := s.opened
+	if w == nil || len(w.storageKey) == 0 {
+		return
+	}
+
+	items := make([]any, 0, len(w.slots))
+	for slot := range w.size {
+		item, ok := w.slots[byte(slot)]
+		if !ok {
+			continue
+		}
+		items = append(items, s.itemToNBT(item.Stack, byte(slot)))
+	}
+	s.world.UpdateBlockNBT(w.blockPos, func(blockNBT map[string]any) {
+		blockNBT[w.storageKey] = items
+	})
+}
+
+// handleContainerClose 处理关闭容器的请求。
+// 铁砧和织布机等非方块容器中遗留的物品将被丢弃
+func (s *session) handleContainerClose(p *packet.ContainerClose) {
+	if s.opened == nil || s.opened.id != p.WindowID {
+		_ = s.conn.WritePacket(&packet.ContainerClose{WindowID: p.WindowID})
+		return
+	}
+
+	s.syncContainer()
+	_ = s.conn.WritePacket(&packet.ContainerClose{
+		WindowID:      p.WindowID,
+		ContainerType: s.opened.containerType,
+	})
+	s.opened = nil
+}
+
+// handleInventoryTransaction 处理点击方块的请求。
+// 点击容器将打开该容器，
+// 点击其他方块则尝试放置手持的方块物品
+func (s *session) handleInventoryTransaction(p *packet.InventoryTransaction) {
+	data, ok := p.TransactionData.(*protocol.UseItemTransactionData)
+	if !ok || data.ActionType != protocol.UseItemActionClickBlock {
+		return
+	}
+
+	block := s.world.Block(data.BlockPosition)
+	if info, ok := blockContainer(block.Name); ok {
+		if s.opened == nil {
+			s.openContainer(data.BlockPosition, block, info)
+		}
+		return
+	}
+	if block.Name == AirBlock {
+		return
+	}
+
+	slot := byte(data.HotBarSlot)
+	held := s.inventory.get(slot)
+	if held.Stack.NetworkID == 0 {
+		return
+	}
+
+	var used bool
+	if strings.HasSuffix(block.Name, "frame") {
+		used = s.putIntoFrame(data.BlockPosition, held.Stack)
+	} else {
+		used = s.placeItem(faceOffset(data.BlockPosition, data.BlockFace), held.Stack)
+	}
+	if used && s.gameMode != packet.GameTypeCreative {
+		held.Stack.Count--
+		if held.Stack.Count == 0 {
+			held = airItemInstance()
+		}
+		s.inventory.set(slot, held)
+		s.sendSlot(s.inventory, slot)
+	}
+}
+
+// putIntoFrame 将物品 stack 中的一个物品放入 pos 处的物品展示框。
+// 如果展示框中已有物品，则返回假
+func (s *session) putIntoFrame(pos protocol.BlockPos, stack protocol.ItemStack) bool {
+	var used bool
+	stack.Count = 1
+	s.world.UpdateBlockNBT(pos, func(blockNBT map[string]any) {
+		if _, ok := blockNBT["Item"]; ok {
+			return
+		}
+		itemNBT := s.itemToNBT(stack, 0)
+		delete(itemNBT, "Slot")
+		blockNBT["Item"] = itemNBT
+		blockNBT["ItemRotation"] = float32(0)
+		blockNBT["ItemDropChance"] = float32(1)
+		used = true
+	})
+	return used
+}
+
+// faceOffset 返回 pos 处方块的 face 面所相邻的方块的位置
+func faceOffset(pos protocol.BlockPos, face int32) protocol.BlockPos {
+	switch face {
+	case 0:
+		pos[1]--
+	case 1:
+		pos[1]++
+	case 2:
+		pos[2]--
+	case 3:
+		pos[2]++
+	case 4:
+		pos[0]--
+	case 5:
+		pos[0]++
+	}
+	return pos
+}
+
+// placeItem 将方块物品 stack 放置在 pos 处，并返回该物品是否被使用。
+// 物品的 NBT 数据 (显示名称和魔咒除外) 将成为方块的方块实体数据
+func (s *session) placeItem(pos protocol.BlockPos, stack protocol.ItemStack) bool {
+	name := s.items.name(stack.NetworkID)
+	if blockName, ok := mapping.ItemNameToBlockName[name]; ok {
+		name = blockName
+	}
+	if !s.isBlockItem(name) || s.world.Block(pos).Name != AirBlock {
+		return false
+	}
+
+	blockNBT := defaultBlockNBT(name)
+	if len(stack.NBTData) > 0 {
+		if blockNBT == nil {
+			blockNBT = make(map[string]any)
+		}
+		for key, value := range utils.DeepCopyNBT(stack.NBTData) {
+			switch key {
+			case "display", "ench", "RepairCost":
+			default:
+				blockNBT[key] = value
+			}
+		}
+	}
+	s.world.SetBlock(pos, Block{Name: name, NBT: blockNBT})
+	return true
+}
+
+// isBlockItem 检查名为 name 的物品是否可以作为方块被放置。
+// 模拟租赁服没有完整的方块注册表，
+// 因此只有具有方块实体或在配置中被指定的方块才被视为方块物品
+func (s *session) isBlockItem(name string) bool {
+	if _, ok := mapping.SupportBlocksPool[name]; ok {
+		return true
+	}
+	if _, ok := mapping.ContainerStorageKey[name]; ok {
+		return true
+	}
+	for _, value := range s.server.cfg.Blocks {
+		if normalizeName(value) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// handleBlockPickRequest 处理选取方块的请求。
+// 被选取的方块将被放入快捷栏的空槽位中，
+// 如果快捷栏已满，则替换当前选中的槽位
+func (s *session) handleBlockPickRequest(p *packet.BlockPickRequest) {
+	block := s.world.Block(p.Position)
+	if block.Name == AirBlock {
+		return
+	}
+	stack, found := s.items.newItemStack(block.Name, 1, 0)
+	if !found {
+		return
+	}
+	if p.AddBlockNBT && block.NBT != nil {
+		// 与租赁服一致，带有方块实体数据的物品
+		// 会被添加一行 (+DATA) 的物品描述
+		stack.NBTData = block.NBT
+		stack.NBTData["display"] = map[string]any{"Lore": []any{"(+DATA)"}}
+	}
+
+	slot := s.selectedSlot
+	for index := range byte(botHotbarSize) {
+		if s.inventory.get(index).Stack.NetworkID == 0 {
+			slot = index
+			break
+		}
+	}
+
+	s.inventory.set(slot, s.newItemInstance(stack))
+	s.selectedSlot = slot
+	s.sendSlot(s.inventory, slot)
+	_ = s.conn.WritePacket(&packet.PlayerHotBar{
+		SelectedHotBarSlot: uint32(slot),
+		WindowID:           protocol.WindowIDInventory,
+		SelectHotBarSlot:   true,
+	})
+}
+
+// itemToNBT 将位于 slot 的物品堆栈 stack 转换为容器中的物品 NBT
+func (s *session) itemToNBT(stack protocol.ItemStack, slot byte) map[string]any {
+	result := map[string]any{
+		"Name":        s.items.name(stack.NetworkID),
+		"Count":       byte(stack.Count),
+		"Damage":      int16(stack.MetadataValue),
+		"Slot":        slot,
+		"WasPickedUp": byte(0),
+	}
+	if len(stack.NBTData) > 0 {
+		result["tag"] = utils.DeepCopyNBT(stack.NBTData)
+	}
+	if len(stack.CanBePlacedOn) > 0 {
+		result["CanPlaceOn"] = stringsToList(stack.CanBePlacedOn)
+	}
+	if len(stack.CanBreak) > 0 {
+		result["CanDestroy"] = stringsToList(stack.CanBreak)
+	}
+	return result
+}
+
+// itemFromNBT 将容器中的物品 NBT 转换为物品堆栈。
+// 如果该物品未被注册，则 ok 为假
+func (s *session) itemFromNBT(itemNBT map[string]any) (slot byte, stack protocol.ItemStack, ok bool) {
+	name, _ := itemNBT["Name"].(string)
+	count, _ := toInt(itemNBT["Count"])
+	damage, _ := toInt(itemNBT["Damage"])
+	slotValue, _ := toInt(itemNBT["Slot"])
+
+	stack, ok = s.items.newItemStack(name, uint16(count), uint32(damage))
+	if !ok || count <= 0 {
+		return 0, protocol.ItemStack{}, false
+	}
+	if tag, ok := itemNBT["tag"].(map[string]any); ok {
+		stack.NBTData = utils.DeepCopyNBT(tag)
+	}
+	stack.CanBePlacedOn = listToStrings(itemNBT["CanPlaceOn"])
+	stack.CanBreak = listToStrings(itemNBT["CanDestroy"])
+
+	return byte(slotValue), stack, true
+}
+
+// toInt 将 NBT 中的整数 value 转换为 int
+func toInt(value any) (result int, ok bool) {
+	switch v := value.(type) {
+	case byte:
+		return int(v), true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case int:
+		return v, true
+	}
+	return 0, false
+}
+
+// stringsToList 将字符串切片转换为 NBT 列表
+func stringsToList(values []string) []any {
+	result := make([]any, len(values))
+	for index, value := range values {
+		result[index] = value
+	}
+	return result
+}
+
+// listToStrings 将 NBT 列表转换为字符串切片
+func listToStrings(value any) []string {
+	list, _ := value.([]any)
+	if len(list) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(list))
+	for _, elem := range list {
+		if str, ok := elem.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package mock_server
+
+import (
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+)
+
+const (
+	// DefaultAddress 是模拟租赁服默认的监听地址，
+	// 它指示监听本地回环地址上的随机端口
+	DefaultAddress = "127.0.0.1:0"
+	// DefaultBotName 是机器人默认的名称
+	DefaultBotName = "mock_bot"
+	// DefaultMaxStackSize 是每个物品堆栈最多可以容纳的物品数量
+	DefaultMaxStackSize = 64
+)
+
+// 以下是模拟租赁服中机器人的常量数据
+const (
+	// botEntityUniqueID 是机器人的唯一 ID
+	botEntityUniqueID int64 = 1
+	// botEntityRuntimeID 是机器人的运行时 ID
+	botEntityRuntimeID uint64 = 1
+	// botInventorySize 是机器人背包的大小 (含快捷栏)
+	botInventorySize = 36
+	// botHotbarSize 是机器人快捷栏的大小
+	botHotbarSize = 9
+	// containerTypeInventory 是背包的容器类型。
+	// protocol.ContainerTypeInventory 为 -1，
+	// 它在数据包中被编码为 0xff
+	containerTypeInventory byte = 0xff
+)
+
+// Config 是模拟租赁服的配置
+type Config struct {
+	// Address 是模拟租赁服的监听地址。
+	// 如果为空，则使用 DefaultAddress
+	Address string
+	// BotName 是登录模拟租赁服的机器人的名称。
+	// 如果为空，则使用 DefaultBotName
+	BotName string
+	// Items 是除默认物品以外，模拟租赁服额外注册的物品。
+	// 这些物品都可以通过命令获取，并且会出现在创造物品栏中
+	Items []string
+	// Blocks 是可以通过命令放置的方块。
+	// 如果为空，则不发送方块的命令枚举，
+	// 这意味着客户端将认为所有方块都可以通过命令放置
+	Blocks []string
+	// DropCommandOutput 用于模拟命令请求超时。
+	// 如果它不为空且对某条命令返回真，则模拟租赁服
+	// 仍然执行该命令，但不会返回该命令的输出
+	DropCommandOutput func(commandLine string) bool
+}
+
+// Block 是模拟租赁服中的单个方块
+type Block struct {
+	// Name 是方块的名称，例如 minecraft:chest
+	Name string
+	// States 是方块的方块状态
+	States map[string]any
+	// NBT 是方块的方块实体数据。
+	// 对于没有方块实体的方块，NBT 为 nil
+	NBT map[string]any
+}
+
+// Structure 是通过 structure save 命令保存的结构
+type Structure struct {
+	// Size 是结构的尺寸
+	Size protocol.BlockPos
+	// Blocks 是结构中的所有方块，
+	// 其键是方块相对于结构原点的偏移量。
+	// 不在其中的位置是空气
+	Blocks map[protocol.BlockPos]Block
+}
@@ -0,0 +1,386 @@
+package mock_server
+
+import (
+	"reflect"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// createdOutputSlot 是合成结果所在的槽位
+const createdOutputSlot byte = 0x32
+
+// stackSlot 是物品堆栈请求中所引用的槽位
+type stackSlot struct {
+	containerID byte
+	slot        byte
+}
+
+// stackSandbox 是处理单个物品堆栈请求时的库存副本。
+// 请求中的操作总是先在副本上进行，
+// 只有在所有操作都成功后，副本才会被提交
+type stackSandbox struct {
+	inventory *window
+	offHand   *window
+	armour    *window
+	opened    *window
+	created   *window
+
+	touched []stackSlot
+}
+
+// newStackSandbox 基于会话当前的库存创建一个副本
+func (s *session) newStackSandbox() *stackSandbox {
+	sandbox := &stackSandbox{
+		inventory: s.inventory.clone(),
+		offHand:   s.offHand.clone(),
+		armour:    s.armour.clone(),
+		created:   newWindow(0, 0, int(createdOutputSlot)+1),
+	}
+	if s.opened != nil {
+		sandbox.opened = s.opened.clone()
+	}
+	return sandbox
+}
+
+// window 返回 containerID 所对应的窗口
+func (sb *stackSandbox) window(containerID byte) (w *window, found bool) {
+	switch containerID {
+	case protocol.ContainerCombinedHotBarAndInventory, protocol.ContainerHotBar, protocol.ContainerInventory:
+		return sb.inventory, true
+	case protocol.ContainerOffhand:
+		return sb.offHand, true
+	case protocol.ContainerArmor:
+		return sb.armour, true
+	case protocol.ContainerCreatedOutput:
+		return sb.created, true
+	}
+	if sb.opened == nil {
+		return nil, false
+	}
+	return sb.opened, true
+}
+
+// get 返回 info 所指示的槽位中的物品，并将该槽位标记为已改变
+func (sb *stackSandbox) get(info protocol.StackRequestSlotInfo) (item protocol.ItemInstance, found bool) {
+	w, found := sb.window(info.ContainerID)
+	if !found {
+		return protocol.ItemInstance{}, false
+	}
+
+	location := stackSlot{containerID: info.ContainerID, slot: info.Slot}
+	if info.ContainerID != protocol.ContainerCreatedOutput {
+		var existed bool
+		for _, value := range sb.touched {
+			if value == location {
+				existed = true
+				break
+			}
+		}
+		if !existed {
+			sb.touched = append(sb.touched, location)
+		}
+	}
+
+	return w.get(info.Slot), true
+}
+
+// set 将 info 所指示的槽位中的物品设置为 item
+func (sb *stackSandbox) set(info protocol.StackRequestSlotInfo, item protocol.ItemInstance) {
+	w, found := sb.window(info.ContainerID)
+	if !found {
+		panic("set: Should never happened")
+	}
+	w.set(info.Slot, item)
+}
+
+// sameItem 检查 a 和 b 是否是可以堆叠在一起的物品
+func sameItem(a protocol.ItemStack, b protocol.ItemStack) bool {
+	if a.NetworkID != b.NetworkID || a.MetadataValue != b.MetadataValue {
+		return false
+	}
+	if len(a.NBTData) == 0 && len(b.NBTData) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.NBTData, b.NBTData)
+}
+
+// transfer 从 src 中移动 count 个物品到 dst
+func (sb *stackSandbox) transfer(count byte, src protocol.StackRequestSlotInfo, dst protocol.StackRequestSlotInfo) uint8 {
+	srcItem, found := sb.get(src)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	dstItem, found := sb.get(dst)
+	if !found {
+		return protocol.ItemStackResponseStatusFailedToValidateDstSlot
+	}
+
+	if count == 0 || srcItem.Stack.NetworkID == 0 || srcItem.Stack.Count < uint16(count) {
+		return protocol.ItemStackResponseStatusInvalidTransferAmount
+	}
+	if dstItem.Stack.NetworkID != 0 {
+		if !sameItem(srcItem.Stack, dstItem.Stack) {
+			return protocol.ItemStackResponseStatusCannotPlaceItem
+		}
+		if dstItem.Stack.Count+uint16(count) > DefaultMaxStackSize {
+			return protocol.ItemStackResponseStatusInvalidTransferAmount
+		}
+	}
+
+	if dstItem.Stack.NetworkID == 0 {
+		dstItem = srcItem
+		dstItem.Stack.Count = uint16(count)
+	} else {
+		dstItem.Stack.Count += uint16(count)
+	}
+	srcItem.Stack.Count -= uint16(count)
+
+	sb.set(src, srcItem)
+	sb.set(dst, dstItem)
+	return protocol.ItemStackResponseStatusOK
+}
+
+// remove 从 src 中移除 count 个物品
+func (sb *stackSandbox) remove(count byte, src protocol.StackRequestSlotInfo) uint8 {
+	item, found := sb.get(src)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	if count == 0 || item.Stack.NetworkID == 0 || item.Stack.Count < uint16(count) {
+		return protocol.ItemStackResponseStatusInvalidRemovedAmount
+	}
+	item.Stack.Count -= uint16(count)
+	sb.set(src, item)
+	return protocol.ItemStackResponseStatusOK
+}
+
+// swap 交换 src 和 dst 中的物品
+func (sb *stackSandbox) swap(src protocol.StackRequestSlotInfo, dst protocol.StackRequestSlotInfo) uint8 {
+	srcItem, found := sb.get(src)
+	if !found {
+		return protocol.ItemStackResponseStatusInvalidSourceContainer
+	}
+	dstItem, found := sb.get(dst)
+	if !found {
+		return protocol.ItemStackResponseStatusFailedToValidateDstSlot
+	}
+	sb.set(src, dstItem)
+	sb.set(dst, srcItem)
+	return protocol.ItemStackResponseStatusOK
+}
+
+// handleItemStackRequest 处理客户端的物品堆栈请求。
+// 与租赁服相同，如果存在被拒绝的请求，则在响应后
+// 重新发送背包和已打开容器中的所有物品
+func (s *session) handleItemStackRequest(p *packet.ItemStackRequest) {
+	rejected := false
+	responses := make([]protocol.ItemStackResponse, 0, len(p.Requests))
+	for _, request := range p.Requests {
+		response := s.processItemStackRequest(request)
+		if response.Status != protocol.ItemStackResponseStatusOK {
+			rejected = true
+		}
+		responses = append(responses, response)
+	}
+	_ = s.conn.WritePacket(&packet.ItemStackResponse{Responses: responses})
+
+	if rejected {
+		s.sendWindowContent(s.inventory)
+		if s.opened != nil {
+			s.sendWindowContent(s.opened)
+		}
+	}
+}
+
+// processItemStackRequest 处理单个物品堆栈请求。
+// 如果请求中的任何一个操作失败，则整个请求都不会生效。
+//
+// 请求中的物品堆栈网络 ID 不会被校验，
+// 因为客户端可能使用请求 ID 来引用同一请求中刚被改变的物品
+func (s *session) processItemStackRequest(request protocol.ItemStackRequest) protocol.ItemStackResponse {
+	sandbox := s.newStackSandbox()
+
+	for _, action := range request.Actions {
+		status := s.processStackAction(sandbox, request, action)
+		if status != protocol.ItemStackResponseStatusOK {
+			return protocol.ItemStackResponse{Status: status, RequestID: request.RequestID}
+		}
+	}
+
+	// 为所有被改变的物品分配新的物品堆栈网络 ID
+	for _, location := range sandbox.touched {
+		w, _ := sandbox.window(location.containerID)
+		item := w.get(location.slot)
+		if item.Stack.NetworkID != 0 {
+			item.StackNetworkID = s.newStackNetworkID()
+			w.set(location.slot, item)
+		}
+	}
+
+	s.inventory, s.offHand, s.armour = sandbox.inventory, sandbox.offHand, sandbox.armour
+	if sandbox.opened != nil {
+		s.opened = sandbox.opened
+		s.syncContainer()
+	}
+
+	return protocol.ItemStackResponse{
+		Status:        protocol.ItemStackResponseStatusOK,
+		RequestID:     request.RequestID,
+		ContainerInfo: sandbox.containerInfo(),
+	}
+}
+
+// processStackAction 在 sandbox 上执行单个物品堆栈操作
+func (s *session) processStackAction(
+	sandbox *stackSandbox,
+	request protocol.ItemStackRequest,
+	action protocol.StackRequestAction,
+) uint8 {
+	switch a := action.(type) {
+	case *protocol.TakeStackRequestAction:
+		return sandbox.transfer(a.Count, a.Source, a.Destination)
+	case *protocol.PlaceStackRequestAction:
+		return sandbox.transfer(a.Count, a.Source, a.Destination)
+	case *protocol.PlaceInContainerStackRequestAction:
+		return sandbox.transfer(a.Count, a.Source, a.Destination)
+	case *protocol.TakeOutContainerStackRequestAction:
+		return sandbox.transfer(a.Count, a.Source, a.Destination)
+	case *protocol.SwapStackRequestAction:
+		return sandbox.swap(a.Source, a.Destination)
+	case *protocol.DropStackRequestAction:
+		return sandbox.remove(a.Count, a.Source)
+	case *protocol.DestroyStackRequestAction:
+		return sandbox.remove(a.Count, a.Source)
+	case *protocol.ConsumeStackRequestAction:
+		return sandbox.remove(a.Count, a.Source)
+	case *protocol.CraftCreativeStackRequestAction:
+		return s.craftCreative(sandbox, a)
+	case *protocol.CraftRecipeOptionalStackRequestAction:
+		return s.craftRenaming(sandbox, request, a)
+	case *protocol.CraftLoomRecipeStackRequestAction:
+		return s.craftLooming(sandbox, a)
+	}
+	return protocol.ItemStackResponseStatusInvalidRequestActionType
+}
+
+// craftCreative 从创造物品栏中取出物品并放入合成结果槽位
+func (s *session) craftCreative(sandbox *stackSandbox, a *protocol.CraftCreativeStackRequestAction) uint8 {
+	name := s.items.name(int32(a.CreativeItemNetworkID))
+	if len(name) == 0 {
+		return protocol.ItemStackResponseStatusFailedToCraftCreative
+	}
+	if s.gameMode != packet.GameTypeCreative {
+		return protocol.ItemStackResponseStatusPlayerNotInCreativeMode
+	}
+
+	stack, _ := s.items.newItemStack(name, DefaultMaxStackSize, 0)
+	sandbox.created.set(createdOutputSlot, protocol.ItemInstance{Stack: stack})
+	return protocol.ItemStackResponseStatusOK
+}
+
+// craftRenaming 使用铁砧重命名位于铁砧输入槽位的物品
+func (s *session) craftRenaming(
+	sandbox *stackSandbox,
+	request protocol.ItemStackRequest,
+	a *protocol.CraftRecipeOptionalStackRequestAction,
+) uint8 {
+	if sandbox.opened == nil || sandbox.opened.containerType != protocol.ContainerTypeAnvil {
+		return protocol.ItemStackResponseStatusInvalidCraftRequestScreen
+	}
+	if a.FilterStringIndex < 0 || int(a.FilterStringIndex) >= len(request.FilterStrings) {
+		return protocol.ItemStackResponseStatusInvalidCraftRequest
+	}
+
+	item := sandbox.opened.get(1)
+	if item.Stack.NetworkID == 0 {
+		return protocol.ItemStackResponseStatusMissingInputItem
+	}
+
+	nbtData := utils.DeepCopyNBT(item.Stack.NBTData)
+	display, _ := nbtData["display"].(map[string]any)
+	if display == nil {
+		display = make(map[string]any)
+	}
+	display["Name"] = request.FilterStrings[a.FilterStringIndex]
+	nbtData["display"] = display
+
+	item.Stack.NBTData = nbtData
+	sandbox.created.set(createdOutputSlot, item)
+	return protocol.ItemStackResponseStatusOK
+}
+
+// craftLooming 使用织布机为位于织布机输入槽位的旗帜添加图案
+func (s *session) craftLooming(sandbox *stackSandbox, a *protocol.CraftLoomRecipeStackRequestAction) uint8 {
+	if sandbox.opened == nil || sandbox.opened.containerType != protocol.ContainerTypeLoom {
+		return protocol.ItemStackResponseStatusInvalidCraftRequestScreen
+	}
+
+	banner := sandbox.opened.get(9)
+	dye := sandbox.opened.get(10)
+	if banner.Stack.NetworkID == 0 {
+		return protocol.ItemStackResponseStatusMissingInputItem
+	}
+	if dye.Stack.NetworkID == 0 {
+		return protocol.ItemStackResponseStatusMissingMaterialItem
+	}
+
+	color, found := int32(0), false
+	dyeName := s.items.name(dye.Stack.NetworkID)
+	for key, value := range mapping.BannerColorToDyeName {
+		if normalizeName(value) == dyeName {
+			color, found = key, true
+			break
+		}
+	}
+	if !found {
+		return protocol.ItemStackResponseStatusMismatchedRecipeForInputGridItems
+	}
+
+	nbtData := utils.DeepCopyNBT(banner.Stack.NBTData)
+	patterns, _ := nbtData["Patterns"].([]any)
+	nbtData["Patterns"] = append(patterns, map[string]any{
+		"Color":   color,
+		"Pattern": a.Pattern,
+	})
+
+	banner.Stack.NBTData = nbtData
+	banner.Stack.Count = 1
+	sandbox.created.set(createdOutputSlot, banner)
+	return protocol.ItemStackResponseStatusOK
+}
+
+// containerInfo 返回被改变的槽位的容器信息。
+// 合成结果槽位不会被包含在内
+func (sb *stackSandbox) containerInfo() []protocol.StackResponseContainerInfo {
+	result := make([]protocol.StackResponseContainerInfo, 0)
+	indexes := make(map[byte]int)
+
+	for _, location := range sb.touched {
+		w, _ := sb.window(location.containerID)
+		item := w.get(location.slot)
+
+		var customName string
+		if display, ok := item.Stack.NBTData["display"].(map[string]any); ok {
+			customName, _ = display["Name"].(string)
+		}
+
+		index, ok := indexes[location.containerID]
+		if !ok {
+			index = len(result)
+			indexes[location.containerID] = index
+			result = append(result, protocol.StackResponseContainerInfo{ContainerID: location.containerID})
+		}
+		result[index].SlotInfo = append(result[index].SlotInfo, protocol.StackResponseSlotInfo{
+			Slot:           location.slot,
+			HotbarSlot:     location.slot,
+			Count:          byte(item.Stack.Count),
+			StackNetworkID: item.StackNetworkID,
+			CustomName:     customName,
+		})
+	}
+
+	return result
+}
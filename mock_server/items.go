@@ -0,0 +1,142 @@
+package mock_server
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+)
+
+// basicItems 是除了可从 mapping 包中得到的物品以外，
+// 模拟租赁服默认注册的物品
+var basicItems = []string{
+	"minecraft:allow",
+	"minecraft:apple",
+	"minecraft:anvil",
+	"minecraft:book",
+	"minecraft:border_block",
+	"minecraft:bread",
+	"minecraft:cobblestone",
+	"minecraft:deny",
+	"minecraft:diamond",
+	"minecraft:diamond_sword",
+	"minecraft:dirt",
+	"minecraft:emerald_block",
+	"minecraft:empty_map",
+	"minecraft:enchanted_book",
+	"minecraft:enchanted_golden_apple",
+	"minecraft:glass",
+	"minecraft:golden_apple",
+	"minecraft:grass_block",
+	"minecraft:iron_ingot",
+	"minecraft:loom",
+	"minecraft:name_tag",
+	"minecraft:paper",
+	"minecraft:polished_andesite",
+	"minecraft:red_flower",
+	"minecraft:stone",
+	"minecraft:stick",
+	"minecraft:oak_planks",
+	"minecraft:oak_sign",
+	"minecraft:wooden_sword",
+}
+
+// itemRegistry 是模拟租赁服注册的物品
+type itemRegistry struct {
+	entries  []protocol.ItemEntry
+	nameToID map[string]int32
+	idToName map[int32]string
+}
+
+// normalizeName 将 name 转换为带有命名空间的小写名称
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.Contains(name, ":") {
+		name = "minecraft:" + name
+	}
+	return name
+}
+
+// newItemRegistry 创建并返回一个包含默认物品和 extra 的物品注册表
+func newItemRegistry(extra []string) *itemRegistry {
+	names := slices.Clone(basicItems)
+	for name := range mapping.ItemNameToBlockName {
+		names = append(names, name)
+	}
+	for name := range mapping.SupportItemsPool {
+		names = append(names, name)
+	}
+	for _, name := range mapping.BannerColorToDyeName {
+		names = append(names, name)
+	}
+	for _, name := range mapping.BannerPatternToItemName {
+		names = append(names, name)
+	}
+	for _, name := range extra {
+		names = append(names, name)
+	}
+	for index, name := range names {
+		names[index] = normalizeName(name)
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	registry := &itemRegistry{
+		nameToID: make(map[string]int32),
+		idToName: make(map[int32]string),
+	}
+	for index, name := range names {
+		// 数值网络 ID 为 0 的物品是空气，
+		// 因此物品的数值网络 ID 从 1 开始
+		runtimeID := int32(index + 1)
+		registry.entries = append(registry.entries, protocol.ItemEntry{
+			Name:      name,
+			RuntimeID: int16(runtimeID),
+		})
+		registry.nameToID[name] = runtimeID
+		registry.idToName[runtimeID] = name
+	}
+	return registry
+}
+
+// runtimeID 返回名为 name 的物品的数值网络 ID
+func (i *itemRegistry) runtimeID(name string) (runtimeID int32, found bool) {
+	runtimeID, found = i.nameToID[normalizeName(name)]
+	return
+}
+
+// name 返回数值网络 ID 为 runtimeID 的物品的名称
+func (i *itemRegistry) name(runtimeID int32) string {
+	return i.idToName[runtimeID]
+}
+
+// creativeItems 返回所有物品的创造物品数据。
+// 每个物品的创造物品网络 ID 与其数值网络 ID 相同
+func (i *itemRegistry) creativeItems() []protocol.CreativeItem {
+	result := make([]protocol.CreativeItem, 0, len(i.entries))
+	for _, entry := range i.entries {
+		result = append(result, protocol.CreativeItem{
+			CreativeItemNetworkID: uint32(entry.RuntimeID),
+			Item: protocol.ItemStack{
+				ItemType: protocol.ItemType{NetworkID: int32(entry.RuntimeID)},
+				Count:    1,
+				NBTData:  make(map[string]any),
+			},
+		})
+	}
+	return result
+}
+
+// newItemStack 创建一个名为 name 的物品堆栈
+func (i *itemRegistry) newItemStack(name string, count uint16, metadata uint32) (result protocol.ItemStack, found bool) {
+	runtimeID, found := i.runtimeID(name)
+	if !found {
+		return protocol.ItemStack{}, false
+	}
+	return protocol.ItemStack{
+		ItemType: protocol.ItemType{NetworkID: runtimeID, MetadataValue: metadata},
+		Count:    count,
+		NBTData:  make(map[string]any),
+	}, true
+}
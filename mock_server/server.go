@@ -0,0 +1,195 @@
+package mock_server
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Server 是离线的模拟租赁服。
+//
+// 它基于 minecraft.Listener 实现了租赁服一侧的协议，
+// 允许客户端在不经过验证服务器的情况下登录，
+// 并在内存中维护一个简单的世界，使得物品堆栈操作、
+// 容器操作和 NBT 方块导入等逻辑可以在离线环境下被测试。
+//
+// 模拟租赁服只实现了本项目所使用的命令和数据包，
+// 它不是一个完整的 Minecraft 服务器
+type Server struct {
+	cfg      Config
+	listener *minecraft.Listener
+	world    *World
+	items    *itemRegistry
+
+	mu    *sync.Mutex
+	conns map[*minecraft.Conn]bool
+
+	// lastEntityID 是最近一次由 AddActor 生成的实体的 ID
+	lastEntityID atomic.Int64
+}
+
+// NewServer 根据 cfg 创建一个新的模拟租赁服，
+// 并立即开始接受客户端的连接
+func NewServer(cfg Config) (*Server, error) {
+	if len(cfg.Address) == 0 {
+		cfg.Address = DefaultAddress
+	}
+	if len(cfg.BotName) == 0 {
+		cfg.BotName = DefaultBotName
+	}
+
+	listener, err := minecraft.ListenConfig{
+		AuthenticationDisabled: true,
+		AllowUnknownPackets:    true,
+		AllowInvalidPackets:    true,
+	}.Listen("raknet", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("NewServer: %v", err)
+	}
+
+	server := &Server{
+		cfg:      cfg,
+		listener: listener,
+		world:    NewWorld(),
+		items:    newItemRegistry(cfg.Items),
+		mu:       new(sync.Mutex),
+		conns:    make(map[*minecraft.Conn]bool),
+	}
+	go server.accept()
+
+	return server, nil
+}
+
+// Address 返回模拟租赁服实际监听的地址
+func (s *Server) Address() string {
+	return s.listener.Addr().String()
+}
+
+// World 返回模拟租赁服的世界。
+// 它可以用于在测试中检查或预设方块
+func (s *Server) World() *World {
+	return s.world
+}
+
+// Chat 以名为 sender 的玩家的身份向所有客户端发送聊天消息 message。
+// 它可以用于在测试中模拟玩家在聊天栏中发送的命令。
+// 与租赁服相同，聊天消息带有发送者的 XUID，它由 PlayerXUID 给出
+func (s *Server) Chat(sender string, message string) {
+	s.send(&packet.Text{
+		TextType:   packet.TextTypeChat,
+		SourceName: sender,
+		Message:    message,
+		XUID:       PlayerXUID(sender),
+	})
+}
+
+// ChatTranslation 以翻译消息的形式向所有客户端发送聊天消息 message。
+// 与 Chat 不同，翻译消息不带有 XUID，其发送者的名称 sender
+// 只是翻译参数。它可以用于在测试中模拟伪造身份的聊天消息
+func (s *Server) ChatTranslation(sender string, message string) {
+	s.send(&packet.Text{
+		TextType:   packet.TextTypeTranslation,
+		Message:    "chat.type.text",
+		Parameters: []string{sender, message},
+	})
+}
+
+// send 向所有客户端发送数据包 pk
+func (s *Server) send(pk packet.Packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.WritePacket(pk)
+	}
+}
+
+// AddActor 向所有客户端发送在 position 处生成类型为 entityType 的实体的数据包。
+// 模拟租赁服不会模拟实体，因此它只能用于在测试中预设客户端已知的实体
+func (s *Server) AddActor(entityType string, position mgl32.Vec3) {
+	entityID := s.lastEntityID.Add(1) + botEntityUniqueID
+	s.send(&packet.AddActor{
+		EntityUniqueID:  entityID,
+		EntityRuntimeID: uint64(entityID),
+		EntityType:      entityType,
+		Position:        position,
+	})
+}
+
+// PlayerXUID 返回模拟租赁服中名为 name 的玩家的 XUID。
+// 模拟租赁服中的 XUID 由玩家的名称唯一确定
+func PlayerXUID(name string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return strconv.FormatUint(h.Sum64()%1e16, 10)
+}
+
+// Authenticator 返回用于登录这个模拟租赁服的验证器
+func (s *Server) Authenticator() *Authenticator {
+	return &Authenticator{
+		address:  s.Address(),
+		identity: newIdentity(s.cfg.BotName, botEntityUniqueID),
+	}
+}
+
+// Close 关闭模拟租赁服及其所有连接
+func (s *Server) Close() error {
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	if err != nil {
+		return fmt.Errorf("Close: %v", err)
+	}
+	return nil
+}
+
+// accept 不断接受新的连接，直到模拟租赁服被关闭
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+// serve 处理单个客户端连接
+func (s *Server) serve(netConn net.Conn) {
+	conn := netConn.(*minecraft.Conn)
+	s.mu.Lock()
+	s.conns[conn] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	session := newSession(s, conn)
+	if err := session.spawn(); err != nil {
+		return
+	}
+	cancel := s.world.watch(session.onBlockChange)
+	defer cancel()
+
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			return
+		}
+		session.handlePacket(pk)
+	}
+}
@@ -0,0 +1,596 @@
+package mock_server_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpol-studio/flowers-for-machines/client"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface/item_stack_transaction"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/mock_server"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/block_helper"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// login 启动一个新的模拟租赁服并登录，
+// 然后返回模拟租赁服和游戏交互接口
+func login(t *testing.T) (*mock_server.Server, *game_interface.GameInterface) {
+	server, err := mock_server.NewServer(mock_server.Config{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+
+	c, err := client.LoginMockServer(server.Authenticator())
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Conn().Close() })
+
+	return server, game_interface.NewGameInterface(resources_control.NewResourcesControl(c))
+}
+
+func TestCommands(t *testing.T) {
+	server, api := login(t)
+
+	err := api.SetBlock().SetBlock(protocol.BlockPos{1, 2, 3}, "chest", `["minecraft:cardinal_direction"="east"]`)
+	if err != nil {
+		t.Fatalf("SetBlock: %v", err)
+	}
+	block := server.World().Block(protocol.BlockPos{1, 2, 3})
+	if block.Name != "minecraft:chest" || block.States["minecraft:cardinal_direction"] != "east" {
+		t.Fatalf("SetBlock: Unexpected block %#v", block)
+	}
+
+	uniqueID, err := api.StructureBackup().BackupStructure(protocol.BlockPos{1, 2, 3})
+	if err != nil {
+		t.Fatalf("BackupStructure: %v", err)
+	}
+	err = api.SetBlock().SetBlock(protocol.BlockPos{1, 2, 3}, "air", "[]")
+	if err != nil {
+		t.Fatalf("SetBlock: %v", err)
+	}
+	err = api.StructureBackup().RevertStructure(uniqueID, protocol.BlockPos{1, 2, 3})
+	if err != nil {
+		t.Fatalf("RevertStructure: %v", err)
+	}
+	if name := server.World().Block(protocol.BlockPos{1, 2, 3}).Name; name != "minecraft:chest" {
+		t.Fatalf("RevertStructure: Expected chest, but got %s", name)
+	}
+
+	_, err = api.Commands().SendWSCommandWithResp("tp 7 8 9")
+	if err != nil {
+		t.Fatalf("SendWSCommandWithResp: %v", err)
+	}
+	result, err := api.Querytarget().DoQuerytarget("@s")
+	if err != nil {
+		t.Fatalf("DoQuerytarget: %v", err)
+	}
+	if len(result) != 1 || result[0].Position.X != 7 || result[0].Position.Y != 8 || result[0].Position.Z != 9 {
+		t.Fatalf("DoQuerytarget: Unexpected result %#v", result)
+	}
+}
+
+func TestContainer(t *testing.T) {
+	server, api := login(t)
+	chest := game_interface.UseItemOnBlocks{
+		HotbarSlotID: 0,
+		BotPos:       mgl32.Vec3{0, 0, 0},
+		BlockPos:     protocol.BlockPos{0, 0, 0},
+		BlockName:    "chest",
+		BlockStates:  map[string]any{"minecraft:cardinal_direction": "east"},
+	}
+
+	err := api.SetBlock().SetBlock(chest.BlockPos, chest.BlockName, `["minecraft:cardinal_direction"="east"]`)
+	if err != nil {
+		t.Fatalf("SetBlock: %v", err)
+	}
+	err = api.Replaceitem().ReplaceitemInContainerAsync(
+		chest.BlockPos,
+		game_interface.ReplaceitemInfo{Name: "apple", Count: 10, Slot: 3},
+		"",
+	)
+	if err != nil {
+		t.Fatalf("ReplaceitemInContainerAsync: %v", err)
+	}
+	if err = api.Commands().AwaitChangesGeneral(); err != nil {
+		t.Fatalf("AwaitChangesGeneral: %v", err)
+	}
+
+	success, err := api.ContainerOpenAndClose().OpenContainer(chest, false)
+	if err != nil || !success {
+		t.Fatalf("OpenContainer: Failed to open the chest (err = %v)", err)
+	}
+	success, _, _, err = api.ItemStackOperation().OpenTransaction().
+		MoveToInventory(3, 0, 4).
+		MoveToInventory(3, 1, 6).
+		MoveBetweenInventory(1, 0, 6).
+		Commit()
+	if err != nil || !success {
+		t.Fatalf("Commit: Failed to move items (err = %v)", err)
+	}
+	if err = api.ContainerOpenAndClose().CloseContainer(); err != nil {
+		t.Fatalf("CloseContainer: %v", err)
+	}
+
+	item, _ := api.Resources().Inventories().GetItemStack(0, 0)
+	if item.Stack.Count != 10 {
+		t.Fatalf("Commit: Expected 10 apples in slot 0, but got %d", item.Stack.Count)
+	}
+	items, _ := server.World().Block(chest.BlockPos).NBT["Items"].([]any)
+	if len(items) != 0 {
+		t.Fatalf("CloseContainer: Expected an empty chest, but got %#v", items)
+	}
+}
+
+func TestAnvil(t *testing.T) {
+	_, api := login(t)
+
+	_, err := api.Commands().SendWSCommandWithResp("give @s diamond_sword 1")
+	if err != nil {
+		t.Fatalf("SendWSCommandWithResp: %v", err)
+	}
+	states, err := api.SetBlock().SetAnvil(protocol.BlockPos{0, 0, 0}, true)
+	if err != nil {
+		t.Fatalf("SetAnvil: %v", err)
+	}
+
+	success, err := api.ContainerOpenAndClose().OpenContainer(
+		game_interface.UseItemOnBlocks{
+			HotbarSlotID: 0,
+			BotPos:       mgl32.Vec3{0, 0, 0},
+			BlockPos:     protocol.BlockPos{0, 0, 0},
+			BlockName:    "anvil",
+			BlockStates:  states,
+		},
+		false,
+	)
+	if err != nil || !success {
+		t.Fatalf("OpenContainer: Failed to open the anvil (err = %v)", err)
+	}
+	success, _, _, err = api.ItemStackOperation().OpenTransaction().
+		RenameInventoryItem(0, "mock sword").
+		Commit()
+	if err != nil || !success {
+		t.Fatalf("Commit: Failed to rename the item (err = %v)", err)
+	}
+	if err = api.ContainerOpenAndClose().CloseContainer(); err != nil {
+		t.Fatalf("CloseContainer: %v", err)
+	}
+
+	item, _ := api.Resources().Inventories().GetItemStack(0, 0)
+	display, _ := item.Stack.NBTData["display"].(map[string]any)
+	if name, _ := display["Name"].(string); name != "mock sword" {
+		t.Fatalf("Commit: Expected the item to be renamed, but got %#v", item.Stack.NBTData)
+	}
+
+	// replaceitem 命令无法恢复物品的自定义名称，
+	// 因此回滚后的物品只有 NBT 数据与原物品不同
+	if err = api.Commands().SendSettingsCommand("gamemode survival", false); err != nil {
+		t.Fatalf("SendSettingsCommand: %v", err)
+	}
+	_, _, err = api.ItemStackOperation().OpenTransaction().
+		DropInventoryItem(0, 1).
+		GetCreativeItemToInventory(1, 1, 1).
+		CommitWithRollback(api.ItemStackOperation().Restorer())
+
+	var rejected *item_stack_transaction.RejectedError
+	if !errors.As(err, &rejected) || !rejected.RolledBack {
+		t.Fatalf("CommitWithRollback: Unexpected result %v", err)
+	}
+	if len(rejected.NBTNotRestored) != 1 || rejected.NBTNotRestored[0] != (resources_control.SlotLocation{WindowID: 0, SlotID: 0}) {
+		t.Fatalf("CommitWithRollback: Unexpected slots whose NBT is not restored %v", rejected.NBTNotRestored)
+	}
+	item, _ = api.Resources().Inventories().GetItemStack(0, 0)
+	if api.Resources().ConstantPacket().ItemNameByNetworkID(item.Stack.NetworkID) != "minecraft:diamond_sword" {
+		t.Fatalf("CommitWithRollback: Expected the sword to be restored, but got %#v", item.Stack)
+	}
+}
+
+func TestItemStackRollback(t *testing.T) {
+	_, api := login(t)
+
+	for slotID, name := range []string{"stone", "dirt"} {
+		err := api.Replaceitem().ReplaceitemInInventory(
+			"@s",
+			game_interface.ReplacePathInventory,
+			game_interface.ReplaceitemInfo{Name: name, Count: 10, Slot: resources_control.SlotID(slotID)},
+			"",
+			true,
+		)
+		if err != nil {
+			t.Fatalf("ReplaceitemInInventory: %v", err)
+		}
+	}
+	if err := api.Commands().SendSettingsCommand("gamemode survival", false); err != nil {
+		t.Fatalf("SendSettingsCommand: %v", err)
+	}
+	if err := api.Commands().AwaitChangesGeneral(); err != nil {
+		t.Fatalf("AwaitChangesGeneral: %v", err)
+	}
+	creativeItem := api.Resources().ConstantPacket().CreativeItemByName("stone")[0]
+
+	// 第一个请求被接受，而第二个请求将因不在创造模式而被拒绝
+	_, _, err := api.ItemStackOperation().OpenTransaction().
+		MoveBetweenInventory(0, 5, 4).
+		DropInventoryItem(1, 3).
+		GetCreativeItemToInventory(creativeItem.CreativeItemNetworkID, 3, 1).
+		CommitWithRollback(api.ItemStackOperation().Restorer())
+
+	var rejected *item_stack_transaction.RejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("CommitWithRollback: Expected a rejected error, but got %v", err)
+	}
+	if rejected.OperationIndex != 2 || rejected.Status != protocol.ItemStackResponseStatusPlayerNotInCreativeMode {
+		t.Errorf("CommitWithRollback: Unexpected rejected operation %#v", rejected)
+	}
+	if !rejected.RolledBack {
+		t.Fatalf("CommitWithRollback: Rollback failed: %v", rejected.RollbackErr)
+	}
+
+	// 内联在同一个请求中的操作也应当被准确地定位
+	_, _, err = api.ItemStackOperation().OpenTransaction().
+		MoveBetweenInventory(0, 5, 4).
+		MoveBetweenInventory(1, 5, 3).
+		CommitWithRollback(api.ItemStackOperation().Restorer())
+	if !errors.As(err, &rejected) || rejected.OperationIndex != 1 || !rejected.RolledBack {
+		t.Fatalf("CommitWithRollback: Unexpected result %v", err)
+	}
+
+	for slotID, count := range map[resources_control.SlotID]uint16{0: 10, 1: 10, 3: 0, 5: 0} {
+		item, _ := api.Resources().Inventories().GetItemStack(0, slotID)
+		if item.Stack.Count != count {
+			t.Errorf("CommitWithRollback: Expected %d items in slot %d, but got %d", count, slotID, item.Stack.Count)
+		}
+	}
+}
+
+func TestChatCommands(t *testing.T) {
+	_, api := login(t)
+
+	subscription, err := api.Resources().Events().Subscribe(nil, 16, resources_control.EventTypeChat)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer api.Resources().Events().Unsubscribe(subscription.UniqueID())
+
+	// reply 等待下一条由机器人发送的聊天消息
+	reply := func() string {
+		select {
+		case event := <-subscription.Events():
+			return event.(resources_control.ChatEvent).Message
+		case <-time.After(time.Second * 5):
+			t.Fatalf("TestChatCommands: Reply timeout")
+		}
+		return ""
+	}
+
+	var args []string
+	chatCommands := api.ChatCommands()
+	err = chatCommands.Register(game_interface.ChatCommand{
+		Name: "cache stats",
+		Handler: func(request game_interface.ChatCommandRequest) error {
+			args = request.Args
+			return request.Reply("ok")
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	sender := game_interface.ChatCommandSender{Name: "Steve", XUID: mock_server.PlayerXUID("Steve")}
+	handled, err := chatCommands.Dispatch(sender, "hello")
+	if handled || err != nil {
+		t.Fatalf("Dispatch: Unexpected result %v %v", handled, err)
+	}
+	if _, err = chatCommands.Dispatch(sender, "!status"); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if message := reply(); !strings.Contains(message, "没有") {
+		t.Fatalf("Dispatch: Expected permission denied, but got %#v", message)
+	}
+
+	chatCommands.Allow("steve")
+	if _, err = chatCommands.Dispatch(sender, "!Cache  Stats extra"); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if message := reply(); message != "ok" || len(args) != 1 || args[0] != "extra" {
+		t.Fatalf("Dispatch: Unexpected reply %#v with args %#v", message, args)
+	}
+
+	// 无法确认身份的发送者即使名称被允许也不能使用命令
+	if _, err = chatCommands.Dispatch(game_interface.ChatCommandSender{Name: "Steve"}, "!status"); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if message := reply(); !strings.Contains(message, "没有") {
+		t.Fatalf("Dispatch: Expected permission denied without XUID, but got %#v", message)
+	}
+
+	// 私聊中的命令可以省略前缀
+	sender.Whisper = true
+	if _, err = chatCommands.Dispatch(sender, "unknown"); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if message := reply(); !strings.Contains(message, "未知") {
+		t.Fatalf("Dispatch: Expected unknown command, but got %#v", message)
+	}
+}
+
+func TestWorldMirror(t *testing.T) {
+	server, api := login(t)
+	world := api.Resources().World()
+
+	// 登录前就存在的方块通过区块数据被同步
+	server.World().SetBlock(protocol.BlockPos{3, -60, 5}, mock_server.Block{Name: "minecraft:stone"})
+	if err := api.Commands().SendSettingsCommand("tp 200 64 200", true); err != nil {
+		t.Fatalf("SendSettingsCommand: %v", err)
+	}
+	if err := api.Commands().AwaitChangesGeneral(); err != nil {
+		t.Fatalf("AwaitChangesGeneral: %v", err)
+	}
+	if world.ChunkLoaded(protocol.BlockPos{0, 0, 0}) {
+		t.Fatalf("ChunkLoaded: Chunks far away from the bot should be unloaded")
+	}
+	if err := api.Commands().SendSettingsCommand("tp 0 64 0", true); err != nil {
+		t.Fatalf("SendSettingsCommand: %v", err)
+	}
+	if err := api.Commands().AwaitChangesGeneral(); err != nil {
+		t.Fatalf("AwaitChangesGeneral: %v", err)
+	}
+	if name, _, found := world.BlockAt(protocol.BlockPos{3, -60, 5}); !found || name != "minecraft:stone" {
+		t.Fatalf("BlockAt: Expected stone from chunk data, but got %#v (found = %v)", name, found)
+	}
+
+	// 命令对方块的更改通过方块更新被同步
+	err := api.SetBlock().SetBlock(protocol.BlockPos{1, 2, 3}, "chest", `["minecraft:cardinal_direction"="east"]`)
+	if err != nil {
+		t.Fatalf("SetBlock: %v", err)
+	}
+	name, states, found := world.BlockAt(protocol.BlockPos{1, 2, 3})
+	if !found || name != "minecraft:chest" || states["minecraft:cardinal_direction"] != "east" {
+		t.Fatalf("BlockAt: Unexpected block %#v %#v (found = %v)", name, states, found)
+	}
+	if name, _, _ = world.BlockAt(protocol.BlockPos{3, -60, 5}); name != "minecraft:stone" {
+		t.Fatalf("BlockAt: Unrelated block is changed to %#v", name)
+	}
+}
+
+func TestConsoleVerifyBlock(t *testing.T) {
+	_, api := login(t)
+
+	console, err := nbt_console.NewConsole(api, 0, protocol.BlockPos{100, 64, 100})
+	if err != nil {
+		t.Fatalf("NewConsole: %v", err)
+	}
+	index, err := console.FindOrGenerateNewLoom()
+	if err != nil {
+		t.Fatalf("FindOrGenerateNewLoom: %v", err)
+	}
+
+	loom := block_helper.LoomBlockHelper{}
+	matched, known := console.VerifyBlockByIndex(index, loom.BlockName(), loom.BlockStates())
+	if !matched || !known {
+		t.Fatalf("VerifyBlockByIndex: Expected the loom to be verified (matched = %v, known = %v)", matched, known)
+	}
+	matched, known = console.VerifyBlockByIndex(index, "minecraft:stone", map[string]any{})
+	if matched || !known {
+		t.Fatalf("VerifyBlockByIndex: Expected a mismatch (matched = %v, known = %v)", matched, known)
+	}
+	// 未被加载的方块无法通过世界镜像验证
+	_, known = console.VerifyBlock(protocol.BlockPos{10000, 64, 10000}, "minecraft:air", map[string]any{})
+	if known {
+		t.Fatalf("VerifyBlock: Blocks in unloaded chunks should be unknown")
+	}
+}
+
+func TestConsoleEntities(t *testing.T) {
+	server, api := login(t)
+
+	// 操作台区域之外的实体不影响操作台的初始化
+	server.AddActor("minecraft:zombie", mgl32.Vec3{120.5, 64, 100.5})
+	console, err := nbt_console.NewConsole(api, 0, protocol.BlockPos{100, 64, 100})
+	if err != nil {
+		t.Fatalf("NewConsole: %v", err)
+	}
+
+	server.AddActor("minecraft:armor_stand", mgl32.Vec3{203.5, 65, 200.5})
+	err = console.ChangeConsolePosition(0, protocol.BlockPos{200, 64, 200})
+	entitiesErr, ok := err.(*nbt_console.EntitiesError)
+	if !ok {
+		t.Fatalf("ChangeConsolePosition: Expected *EntitiesError, but got %v", err)
+	}
+	if len(entitiesErr.Entities) != 1 || entitiesErr.Entities[0].EntityType != "minecraft:armor_stand" {
+		t.Fatalf("ChangeConsolePosition: Unexpected entities %#v", entitiesErr.Entities)
+	}
+	// 操作台在存在实体时仍然被成功移动
+	if console.Center() != (protocol.BlockPos{200, 64, 200}) {
+		t.Fatalf("ChangeConsolePosition: Console is not moved, center = %v", console.Center())
+	}
+}
+
+func TestChatCommandsConcurrent(t *testing.T) {
+	server, api := login(t)
+
+	subscription, err := api.Resources().Events().Subscribe(nil, 16, resources_control.EventTypeChat)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer api.Resources().Events().Unsubscribe(subscription.UniqueID())
+
+	// reply 等待下一条由机器人发送的聊天消息
+	reply := func() string {
+		for {
+			select {
+			case event := <-subscription.Events():
+				chat := event.(resources_control.ChatEvent)
+				if chat.Sender == mock_server.DefaultBotName {
+					return chat.Message
+				}
+			case <-time.After(time.Second * 5):
+				t.Fatalf("TestChatCommandsConcurrent: Reply timeout")
+				return ""
+			}
+		}
+	}
+
+	release := make(chan struct{})
+	chatCommands := api.ChatCommands()
+	for _, command := range []game_interface.ChatCommand{
+		{
+			Name: "slow",
+			Handler: func(request game_interface.ChatCommandRequest) error {
+				<-release
+				return request.Reply("slow")
+			},
+		},
+		{
+			Name: "fast",
+			Handler: func(request game_interface.ChatCommandRequest) error {
+				return request.Reply("fast")
+			},
+		},
+	} {
+		if err = chatCommands.Register(command); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+	chatCommands.Allow("Steve")
+	if err = chatCommands.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer chatCommands.Stop()
+
+	// 伪造身份的翻译消息不能调用命令
+	server.ChatTranslation("Steve", "!fast")
+	if message := reply(); !strings.Contains(message, "没有") {
+		t.Fatalf("TestChatCommandsConcurrent: Expected permission denied, but got %#v", message)
+	}
+
+	// 耗时较长的命令不应阻塞之后到达的命令
+	server.Chat("Steve", "!slow")
+	server.Chat("Steve", "!fast")
+	if message := reply(); message != "fast" {
+		t.Fatalf("TestChatCommandsConcurrent: Expected fast, but got %#v", message)
+	}
+	close(release)
+	if message := reply(); message != "slow" {
+		t.Fatalf("TestChatCommandsConcurrent: Expected slow, but got %#v", message)
+	}
+}
+
+func TestKick(t *testing.T) {
+	server, err := mock_server.NewServer(mock_server.Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	defer server.Close()
+	c, err := client.LoginMockServer(server.Authenticator())
+	if err != nil {
+		t.Fatalf("LoginMockServer: %v", err)
+	}
+	defer c.Conn().Close()
+	api := game_interface.NewGameInterface(resources_control.NewResourcesControl(c))
+
+	subscription, err := api.Resources().Events().Subscribe(nil, 16, resources_control.EventTypeKick)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err = api.Commands().SendWSCommand("kick @s 刷屏"); err != nil {
+		t.Fatalf("SendWSCommand: %v", err)
+	}
+
+	select {
+	case event := <-subscription.Events():
+		kick := event.(resources_control.KickEvent)
+		if kick.Reason != resources_control.KickReasonKicked || kick.Detail != "刷屏" {
+			t.Fatalf("TestKick: Unexpected kick event %#v", kick)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("TestKick: Kick event timeout")
+	}
+
+	// 断开连接的原因被包装在 net.OpError 中
+	_, err = c.Conn().ReadPacket()
+	var opErr *net.OpError
+	var disconnectErr minecraft.DisconnectError
+	if !errors.As(err, &opErr) || !errors.As(err, &disconnectErr) || !strings.Contains(string(disconnectErr), "刷屏") {
+		t.Fatalf("TestKick: Unexpected error %#v", err)
+	}
+}
+
+func TestNBTAssignerContainer(t *testing.T) {
+	server, api := login(t)
+
+	console, err := nbt_console.NewConsole(api, 0, protocol.BlockPos{100, 64, 100})
+	if err != nil {
+		t.Fatalf("NewConsole: %v", err)
+	}
+	assigner := nbt_assigner.NewNBTAssigner(console, nbt_cache.NewNBTCacheSystem(console))
+
+	// 带有自定义名称的物品需要在铁砧中制作，
+	// 然后被移动到箱子中
+	canFast, uniqueID, _, err := assigner.PlaceNBTBlock(
+		"minecraft:chest",
+		map[string]any{"minecraft:cardinal_direction": "north"},
+		map[string]any{
+			"id": "Chest",
+			"Items": []any{
+				map[string]any{
+					"Name":   "minecraft:diamond_sword",
+					"Count":  byte(1),
+					"Damage": int16(0),
+					"Slot":   byte(3),
+					"tag": map[string]any{
+						"display": map[string]any{"Name": "mock sword"},
+					},
+				},
+				map[string]any{
+					"Name":   "minecraft:apple",
+					"Count":  byte(5),
+					"Damage": int16(0),
+					"Slot":   byte(7),
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("PlaceNBTBlock: %v", err)
+	}
+	if canFast {
+		t.Fatalf("PlaceNBTBlock: The chest should not be placed by command")
+	}
+
+	structure, found := server.World().Structure(utils.MakeUUIDSafeString(uniqueID))
+	if !found {
+		t.Fatalf("PlaceNBTBlock: Structure of the chest is not saved")
+	}
+	chest := structure.Blocks[protocol.BlockPos{0, 0, 0}]
+	if chest.Name != "minecraft:chest" {
+		t.Fatalf("PlaceNBTBlock: Expected a chest in the structure, but got %#v", chest)
+	}
+
+	items := make(map[byte]map[string]any)
+	for _, value := range chest.NBT["Items"].([]any) {
+		item := value.(map[string]any)
+		items[item["Slot"].(byte)] = item
+	}
+	if len(items) != 2 || items[7]["Name"] != "minecraft:apple" || items[7]["Count"] != byte(5) {
+		t.Fatalf("PlaceNBTBlock: Unexpected items in the chest %#v", items)
+	}
+	tag, _ := items[3]["tag"].(map[string]any)
+	display, _ := tag["display"].(map[string]any)
+	if items[3]["Name"] != "minecraft:diamond_sword" || display["Name"] != "mock sword" {
+		t.Fatalf("PlaceNBTBlock: Expected a renamed sword in slot 3, but got %#v", items[3])
+	}
+}
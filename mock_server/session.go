@@ -0,0 +1,188 @@
+package mock_server
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// session 是单个客户端连接的状态。
+// 它的所有方法都只在处理该连接的协程中被调用，
+// 这使得数据包总是按照其到达的顺序被处理
+type session struct {
+	server *Server
+	conn   *minecraft.Conn
+	world  *World
+	items  *itemRegistry
+
+	position     mgl32.Vec3
+	gameMode     int32
+	selectedSlot byte
+
+	inventory *window
+	offHand   *window
+	armour    *window
+	opened    *window
+
+	nextWindowID       byte
+	nextStackNetworkID int32
+
+	// sentChunks 是已被发送给客户端的区块
+	sentChunks map[chunkPos]bool
+}
+
+// newSession 创建并返回 conn 的连接状态
+func newSession(server *Server, conn *minecraft.Conn) *session {
+	return &session{
+		server:             server,
+		conn:               conn,
+		world:              server.world,
+		items:              server.items,
+		position:           mgl32.Vec3{0, 64, 0},
+		gameMode:           packet.GameTypeCreative,
+		inventory:          newWindow(protocol.WindowIDInventory, containerTypeInventory, botInventorySize),
+		offHand:            newWindow(protocol.WindowIDOffHand, containerTypeInventory, 1),
+		armour:             newWindow(protocol.WindowIDArmour, protocol.ContainerTypeArmour, 4),
+		nextWindowID:       1,
+		nextStackNetworkID: 1,
+		sentChunks:         make(map[chunkPos]bool),
+	}
+}
+
+// spawn 完成登录序列，
+// 并发送客户端在登录后所需的常量数据包
+func (s *session) spawn() error {
+	err := s.conn.StartGame(minecraft.GameData{
+		WorldName:                    "mock_server",
+		EntityUniqueID:               botEntityUniqueID,
+		EntityRuntimeID:              botEntityRuntimeID,
+		PlayerGameMode:               s.gameMode,
+		PlayerPosition:               s.position,
+		WorldGameMode:                s.gameMode,
+		Items:                        s.items.entries,
+		ServerAuthoritativeInventory: true,
+		PlayerPermissions:            packet.PermissionLevelOperator,
+		ChunkRadius:                  4,
+	})
+	if err != nil {
+		return fmt.Errorf("spawn: %v", err)
+	}
+
+	_ = s.conn.WritePacket(&packet.CreativeContent{Items: s.items.creativeItems()})
+	_ = s.conn.WritePacket(&packet.CraftingData{ClearRecipes: true})
+	for _, w := range []*window{s.inventory, s.offHand, s.armour} {
+		s.sendWindowContent(w)
+	}
+	s.sendChunks()
+	// AvailableCommands 总是最后被发送，
+	// 客户端可以以此判断登录后的常量数据包都已被收到
+	_ = s.conn.WritePacket(s.availableCommands())
+
+	return nil
+}
+
+// availableCommands 返回可用命令数据包。
+// 它只包含客户端所使用的物品和方块的命令枚举
+func (s *session) availableCommands() *packet.AvailableCommands {
+	pk := new(packet.AvailableCommands)
+
+	itemEnum := protocol.CommandEnum{Type: "Item"}
+	for _, entry := range s.items.entries {
+		itemEnum.ValueIndices = append(itemEnum.ValueIndices, uint(len(pk.EnumValues)))
+		pk.EnumValues = append(pk.EnumValues, entry.Name)
+	}
+	pk.Enums = append(pk.Enums, itemEnum)
+
+	if len(s.server.cfg.Blocks) > 0 {
+		blockEnum := protocol.CommandEnum{Type: "Block"}
+		for _, name := range s.server.cfg.Blocks {
+			blockEnum.ValueIndices = append(blockEnum.ValueIndices, uint(len(pk.EnumValues)))
+			pk.EnumValues = append(pk.EnumValues, normalizeName(name))
+		}
+		pk.Enums = append(pk.Enums, blockEnum)
+	}
+
+	return pk
+}
+
+// handlePacket 处理客户端发送的数据包 pk
+func (s *session) handlePacket(pk packet.Packet) {
+	switch p := pk.(type) {
+	case *packet.CommandRequest:
+		s.handleCommandRequest(p)
+	case *packet.SettingsCommand:
+		s.executeCommand(p.CommandLine)
+	case *packet.ItemStackRequest:
+		s.handleItemStackRequest(p)
+	case *packet.InventoryTransaction:
+		s.handleInventoryTransaction(p)
+	case *packet.Interact:
+		s.handleInteract(p)
+	case *packet.ContainerClose:
+		s.handleContainerClose(p)
+	case *packet.PlayerHotBar:
+		if p.SelectHotBarSlot && p.SelectedHotBarSlot < botHotbarSize {
+			s.selectedSlot = byte(p.SelectedHotBarSlot)
+		}
+	case *packet.BlockPickRequest:
+		s.handleBlockPickRequest(p)
+	case *packet.StructureTemplateDataRequest:
+		s.handleStructureTemplateDataRequest(p)
+	case *packet.Text:
+		if p.TextType == packet.TextTypeChat {
+			_ = s.conn.WritePacket(&packet.Text{
+				TextType:   packet.TextTypeChat,
+				SourceName: s.server.cfg.BotName,
+				Message:    p.Message,
+			})
+		}
+	case *packet.PlayerAuthInput:
+		s.position = p.Position
+	}
+}
+
+// newStackNetworkID 返回一个新的物品堆栈网络 ID
+func (s *session) newStackNetworkID() int32 {
+	s.nextStackNetworkID++
+	return s.nextStackNetworkID
+}
+
+// newItemInstance 为物品堆栈 stack 分配一个新的物品堆栈网络 ID。
+// 如果 stack 是空气，则返回空气的物品实例
+func (s *session) newItemInstance(stack protocol.ItemStack) protocol.ItemInstance {
+	if stack.NetworkID == 0 || stack.Count == 0 {
+		return airItemInstance()
+	}
+	if stack.NBTData == nil {
+		stack.NBTData = make(map[string]any)
+	}
+	return protocol.ItemInstance{
+		StackNetworkID: s.newStackNetworkID(),
+		Stack:          stack,
+	}
+}
+
+// sendWindowContent 向客户端发送窗口 w 中的所有物品
+func (s *session) sendWindowContent(w *window) {
+	content := make([]protocol.ItemInstance, w.size)
+	for index := range w.size {
+		content[index] = w.get(byte(index))
+	}
+	_ = s.conn.WritePacket(&packet.InventoryContent{
+		WindowID: uint32(w.id),
+		Content:  content,
+	})
+}
+
+// sendSlot 向客户端发送窗口 w 中位于 slot 的物品
+func (s *session) sendSlot(w *window, slot byte) {
+	_ = s.conn.WritePacket(&packet.InventorySlot{
+		WindowID: uint32(w.id),
+		Slot:     uint32(slot),
+		NewItem:  w.get(slot),
+	})
+}
@@ -0,0 +1,63 @@
+package mock_server
+
+import (
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
+)
+
+// ExportStructure 将以 origin 为原点且尺寸为 size 的区域导出为结构文件。
+// 与租赁服一致，区域中的空气也会被导出
+func (w *World) ExportStructure(origin protocol.BlockPos, size protocol.BlockPos) *mcstructure.Structure {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	structure := mcstructure.NewStructure([3]int32{size[0], size[1], size[2]})
+	structure.Origin = [3]int32{origin[0], origin[1], origin[2]}
+
+	for x := range size[0] {
+		for y := range size[1] {
+			for z := range size[2] {
+				offset := protocol.BlockPos{x, y, z}
+				block, ok := w.blocks[protocol.BlockPos{origin[0] + x, origin[1] + y, origin[2] + z}]
+				if !ok {
+					block = Block{Name: AirBlock}
+				}
+				block = copyBlock(block)
+
+				_ = structure.SetBlock(offset, mcstructure.LayerNormal, mcstructure.BlockPalette{
+					Name:    block.Name,
+					States:  block.States,
+					Version: mcstructure.DefaultBlockVersion,
+				})
+				if block.NBT != nil {
+					_ = structure.SetBlockEntity(offset, block.NBT)
+				}
+			}
+		}
+	}
+
+	return structure
+}
+
+// handleStructureTemplateDataRequest 处理结构模板数据请求。
+// 模拟租赁服只支持从世界导出结构
+func (s *session) handleStructureTemplateDataRequest(p *packet.StructureTemplateDataRequest) {
+	resp := &packet.StructureTemplateDataResponse{
+		StructureName: p.StructureName,
+		ResponseType:  packet.StructureTemplateResponseExport,
+	}
+
+	size := p.Settings.Size
+	if p.RequestType == packet.StructureTemplateRequestExportFromSave && size[0] > 0 && size[1] > 0 && size[2] > 0 {
+		origin := protocol.BlockPos{
+			p.Position[0] + p.Settings.Offset[0],
+			p.Position[1] + p.Settings.Offset[1],
+			p.Position[2] + p.Settings.Offset[2],
+		}
+		resp.Success = true
+		resp.StructureTemplate = s.world.ExportStructure(origin, size).ToNBT()
+	}
+
+	_ = s.conn.WritePacket(resp)
+}
@@ -0,0 +1,216 @@
+package mock_server
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// AirBlock 是空气方块的名称
+const AirBlock = "minecraft:air"
+
+// World 是模拟租赁服的内存世界。
+// 它只记录被放置过的方块，其余位置都是空气
+type World struct {
+	mu         *sync.Mutex
+	blocks     map[protocol.BlockPos]Block
+	structures map[string]Structure
+
+	nextWatcherID int
+	watchers      map[int]func(pos protocol.BlockPos, block Block)
+}
+
+// NewWorld 创建并返回一个新的空世界
+func NewWorld() *World {
+	return &World{
+		mu:         new(sync.Mutex),
+		blocks:     make(map[protocol.BlockPos]Block),
+		structures: make(map[string]Structure),
+		watchers:   make(map[int]func(pos protocol.BlockPos, block Block)),
+	}
+}
+
+// watch 使得 f 在世界中的任何方块被更改时被调用。
+// f 在持有世界的锁时被调用，因此它不能访问世界。
+// 返回的 cancel 用于取消监听
+func (w *World) watch(f func(pos protocol.BlockPos, block Block)) (cancel func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextWatcherID
+	w.nextWatcherID++
+	w.watchers[id] = f
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.watchers, id)
+	}
+}
+
+// copyBlock 返回 block 的深拷贝
+func copyBlock(block Block) Block {
+	result := Block{Name: block.Name, States: maps.Clone(block.States)}
+	if result.States == nil {
+		result.States = make(map[string]any)
+	}
+	if block.NBT != nil {
+		result.NBT = utils.DeepCopyNBT(block.NBT)
+	}
+	return result
+}
+
+// defaultBlockNBT 返回名为 name 的方块在刚被放置时的方块实体数据。
+// 如果该方块没有方块实体，则返回 nil
+func defaultBlockNBT(name string) map[string]any {
+	if key, ok := mapping.ContainerStorageKey[name]; ok {
+		return map[string]any{key: []any{}}
+	}
+	if _, ok := mapping.SupportBlocksPool[name]; ok {
+		return make(map[string]any)
+	}
+	return nil
+}
+
+// Block 返回 pos 处的方块。
+// 返回的方块是深拷贝，对其的修改不会影响世界
+func (w *World) Block(pos protocol.BlockPos) Block {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	block, ok := w.blocks[pos]
+	if !ok {
+		return Block{Name: AirBlock, States: make(map[string]any)}
+	}
+	return copyBlock(block)
+}
+
+// SetBlock 将 pos 处的方块设置为 block。
+// 如果 block 的方块实体数据非空，
+// 则其中的坐标字段将被更新为 pos
+func (w *World) SetBlock(pos protocol.BlockPos, block Block) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.setBlock(pos, block)
+}
+
+// setBlock ..
+func (w *World) setBlock(pos protocol.BlockPos, block Block) {
+	block.Name = normalizeName(block.Name)
+	if block.Name == AirBlock {
+		delete(w.blocks, pos)
+	} else {
+		block = copyBlock(block)
+		if block.NBT != nil {
+			block.NBT["x"], block.NBT["y"], block.NBT["z"] = pos[0], pos[1], pos[2]
+		}
+		w.blocks[pos] = block
+	}
+
+	for _, f := range w.watchers {
+		f(pos, block)
+	}
+}
+
+// UpdateBlockNBT 使用 f 修改 pos 处方块的方块实体数据。
+// 如果该方块没有方块实体，则 f 不会被调用，并且返回假
+func (w *World) UpdateBlockNBT(pos protocol.BlockPos, f func(blockNBT map[string]any)) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	block, ok := w.blocks[pos]
+	if !ok || block.NBT == nil {
+		return false
+	}
+	f(block.NBT)
+	return true
+}
+
+// Structure 返回名为 name 的结构
+func (w *World) Structure(name string) (structure Structure, found bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	structure, found = w.structures[name]
+	return
+}
+
+// Structures 返回所有已保存的结构的名称，它们按名称排序
+func (w *World) Structures() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Sorted(maps.Keys(w.structures))
+}
+
+// SaveStructure 将 start 和 end 围成的区域保存为名为 name 的结构。
+// 如果已存在同名的结构，则它将被覆盖
+func (w *World) SaveStructure(name string, start protocol.BlockPos, end protocol.BlockPos) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	origin := protocol.BlockPos{min(start[0], end[0]), min(start[1], end[1]), min(start[2], end[2])}
+	structure := Structure{
+		Size: protocol.BlockPos{
+			max(start[0], end[0]) - origin[0] + 1,
+			max(start[1], end[1]) - origin[1] + 1,
+			max(start[2], end[2]) - origin[2] + 1,
+		},
+		Blocks: make(map[protocol.BlockPos]Block),
+	}
+
+	for pos, block := range w.blocks {
+		offset := protocol.BlockPos{pos[0] - origin[0], pos[1] - origin[1], pos[2] - origin[2]}
+		if offset[0] < 0 || offset[1] < 0 || offset[2] < 0 {
+			continue
+		}
+		if offset[0] >= structure.Size[0] || offset[1] >= structure.Size[1] || offset[2] >= structure.Size[2] {
+			continue
+		}
+		structure.Blocks[offset] = copyBlock(block)
+	}
+
+	w.structures[name] = structure
+}
+
+// LoadStructure 将名为 name 的结构加载到以 pos 为原点的区域。
+// 结构中的空气也将被加载。如果结构不存在，则返回假
+func (w *World) LoadStructure(name string, pos protocol.BlockPos) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	structure, ok := w.structures[name]
+	if !ok {
+		return false
+	}
+
+	for x := range structure.Size[0] {
+		for y := range structure.Size[1] {
+			for z := range structure.Size[2] {
+				target := protocol.BlockPos{pos[0] + x, pos[1] + y, pos[2] + z}
+				block, ok := structure.Blocks[protocol.BlockPos{x, y, z}]
+				if !ok {
+					block = Block{Name: AirBlock}
+				}
+				w.setBlock(target, block)
+			}
+		}
+	}
+
+	return true
+}
+
+// DeleteStructure 删除名为 name 的结构。
+// 如果结构不存在，则返回假
+func (w *World) DeleteStructure(name string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.structures[name]; !ok {
+		return false
+	}
+	delete(w.structures, name)
+	return true
+}
@@ -0,0 +1,19 @@
+package block_helper
+
+type CartographyTableBlockHelper struct{}
+
+func (CartographyTableBlockHelper) KnownBlockStates() bool {
+	return true
+}
+
+func (CartographyTableBlockHelper) BlockName() string {
+	return "minecraft:cartography_table"
+}
+
+func (CartographyTableBlockHelper) BlockStates() map[string]any {
+	return map[string]any{}
+}
+
+func (CartographyTableBlockHelper) BlockStatesString() string {
+	return `[]`
+}
@@ -5,7 +5,7 @@ package block_helper
 // 块及帮助类方块的相邻方块。
 //
 // 如果表示的是一个帮助类方块，
-// 那么它可以是容器、铁砧或织布机
+// 那么它可以是容器、铁砧、织布机或制图台
 type BlockHelper interface {
 	// KnownBlockStates 指示我们是否已经知晓这个方块的方块状态。
 	// 对于大多数帮助类方块，KnownBlockStates 总是返回真。
@@ -1,10 +1,13 @@
 package nbt_assigner_interface
 
 import (
+	"fmt"
+
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
 	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_diff "github.com/mcpol-studio/flowers-for-machines/nbt_parser/diff"
 	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
 
 	"github.com/google/uuid"
@@ -87,3 +90,25 @@ type Block interface {
 	// 我们需要记录床尾相对于床头的偏移
 	Offset() protocol.BlockPos
 }
+
+// IncompleteBlockError 指示 NBT 方块在多次重新制作后仍未能通过完整性检查。
+// Differences 描述了期望的方块与实际放置的方块之间的每一处差异
+type IncompleteBlockError struct {
+	Expected    nbt_parser_interface.Block
+	Actual      nbt_parser_interface.Block
+	Differences []nbt_diff.Difference
+}
+
+// Error 返回这个错误的字符串表示
+func (e *IncompleteBlockError) Error() string {
+	return fmt.Sprintf(
+		""+
+			"PlaceNBTBlock: Self loop when place NBT block, "+
+			"and result in invalid user input data, "+
+			"and need to correct; "+
+			"differences = %#v; "+
+			"nbtBlock.Format(\"\") = %#v; newBlock.Format(\"\") = %#v",
+		nbt_diff.Strings(e.Differences),
+		e.Expected.Format(""), e.Actual.Format(""),
+	)
+}
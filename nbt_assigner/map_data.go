@@ -0,0 +1,61 @@
+package nbt_assigner
+
+import (
+	"reflect"
+
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache/map_data_cache"
+	nbt_parser_item "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+)
+
+// filledMapType 是已解析的已填充地图的类型
+var filledMapType = reflect.TypeFor[nbt_parser_item.FilledMap]()
+
+// attachMapDataHash 为 value 中 (包括嵌套在容器物品中的)
+// 每个已填充地图设置其像素数据的哈希校验和。
+//
+// 已填充地图的哈希校验和只依赖于地图的 UUID，而 UUID
+// 只在同一个存档中唯一。设置像素数据的哈希校验和后，
+// 使用不同的地图数据 (或没有地图数据) 制作的 NBT 方块
+// 将不会命中彼此的缓存
+func attachMapDataHash(cache *map_data_cache.MapDataCache, value any) {
+	visited := make(map[uintptr]bool)
+
+	var walk func(value reflect.Value)
+	walk = func(value reflect.Value) {
+		switch value.Kind() {
+		case reflect.Interface:
+			if !value.IsNil() {
+				walk(value.Elem())
+			}
+		case reflect.Pointer:
+			if value.IsNil() || visited[value.Pointer()] {
+				return
+			}
+			visited[value.Pointer()] = true
+			walk(value.Elem())
+		case reflect.Struct:
+			if value.Type() == filledMapType {
+				if value.CanAddr() {
+					filledMap := value.Addr().Interface().(*nbt_parser_item.FilledMap)
+					filledMap.NBT.MapDataHash, _ = cache.MapDataHash(filledMap.NBT.MapUUID)
+				}
+				return
+			}
+			for index := range value.NumField() {
+				if value.Type().Field(index).IsExported() {
+					walk(value.Field(index))
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for index := range value.Len() {
+				walk(value.Index(index))
+			}
+		case reflect.Map:
+			for _, key := range value.MapKeys() {
+				walk(value.MapIndex(key))
+			}
+		}
+	}
+
+	walk(reflect.ValueOf(value))
+}
@@ -0,0 +1,79 @@
+package nbt_assigner
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache/map_data_cache"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	nbt_hash "github.com/mcpol-studio/flowers-for-machines/nbt_parser/hash"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+
+	_ "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+)
+
+// filledMapItem 返回 UUID 为 mapUUID 的已填充地图
+func filledMapItem(mapUUID int64) map[string]any {
+	return map[string]any{
+		"Name":   "minecraft:filled_map",
+		"Count":  byte(1),
+		"Damage": int16(0),
+		"tag":    map[string]any{"map_uuid": mapUUID},
+	}
+}
+
+// parseBlock 解析名为 name 的方块
+func parseBlock(t *testing.T, name string, states map[string]any, blockNBT map[string]any) nbt_parser_interface.Block {
+	block, err := nbt_parser_block.ParseBlock(nil, name, states, blockNBT)
+	if err != nil {
+		t.Fatalf("parseBlock: %v", err)
+	}
+	return block
+}
+
+func TestAttachMapDataHash(t *testing.T) {
+	const mapUUID = int64(-42)
+
+	// blockHash 返回含有地图的物品展示框和箱子在设置 cache 中的像素数据后的哈希校验和
+	blockHash := func(cache *map_data_cache.MapDataCache) (frame uint64, chest uint64) {
+		frameBlock := parseBlock(t, "minecraft:frame", map[string]any{"facing_direction": int32(2)}, map[string]any{
+			"id":   "ItemFrame",
+			"Item": filledMapItem(mapUUID),
+		})
+		item := filledMapItem(mapUUID)
+		item["Slot"] = byte(3)
+		chestBlock := parseBlock(t, "minecraft:chest", map[string]any{"minecraft:cardinal_direction": "north"}, map[string]any{
+			"id":    "Chest",
+			"Items": []any{item},
+		})
+
+		attachMapDataHash(cache, frameBlock)
+		attachMapDataHash(cache, chestBlock)
+		return nbt_hash.NBTBlockFullHash(frameBlock), nbt_hash.NBTBlockFullHash(chestBlock)
+	}
+
+	var pixels [128][128]color.RGBA
+	cache := map_data_cache.NewMapDataCache()
+	blankFrame, blankChest := blockHash(cache)
+
+	cache.StoreMapData(mapUUID, pixels)
+	frame, chest := blockHash(cache)
+	if frame == blankFrame || chest == blankChest {
+		t.Fatalf("TestAttachMapDataHash: Blocks with map data have the same hash as those without")
+	}
+
+	pixels[3][5] = color.RGBA{R: 255, A: 255}
+	cache.StoreMapData(mapUUID, pixels)
+	newFrame, newChest := blockHash(cache)
+	if newFrame == frame || newChest == chest {
+		t.Fatalf("TestAttachMapDataHash: Blocks with different map data have the same hash")
+	}
+	if again, _ := blockHash(cache); again != newFrame {
+		t.Fatalf("TestAttachMapDataHash: Hash of the same map data is not stable")
+	}
+
+	cache.CleanMapData()
+	if cleanFrame, cleanChest := blockHash(cache); cleanFrame != blankFrame || cleanChest != blankChest {
+		t.Fatalf("TestAttachMapDataHash: Blocks are not blank after cleaning map data")
+	}
+}
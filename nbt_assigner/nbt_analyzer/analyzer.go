@@ -0,0 +1,331 @@
+package nbt_analyzer
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
+	nbt_assigner_interface "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/interface"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	nbt_hash "github.com/mcpol-studio/flowers-for-machines/nbt_parser/hash"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+	nbt_parser_item "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+
+	_ "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_block"
+	_ "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_item"
+)
+
+// Analyzer 是导入前的可获取性分析器。
+// 它会列举结构中的每个方块和物品，
+// 并按照当前租赁服的物品列表和方块注册表，
+// 检查它们是否可以直接通过命令得到、
+// 是否需要特殊制作，或者根本无法得到
+type Analyzer struct {
+	itemChecker  func(name string) bool
+	blockChecker func(name string) bool
+}
+
+// NewAnalyzer 创建并返回一个新的可获取性分析器。
+//
+// itemChecker 用于检查 name 所指示的物品是否可通过命令获取，
+// blockChecker 用于检查 name 所指示的方块是否可通过命令放置。
+// 它们通常来自 resources_control.ConstantPacket。
+// 如果没有这样的函数，则可以将其简单的置为 nil
+func NewAnalyzer(itemChecker func(name string) bool, blockChecker func(name string) bool) *Analyzer {
+	return &Analyzer{
+		itemChecker:  itemChecker,
+		blockChecker: blockChecker,
+	}
+}
+
+// session 是单次分析的状态
+type session struct {
+	*Analyzer
+	report Report
+	// madeBlocks 是已被制作的 NBT 方块的完整哈希校验和，
+	// 相同的 NBT 方块在其后将从缓存的结构加载
+	madeBlocks map[uint64]bool
+}
+
+// newSession 创建并返回一个新的分析状态
+func (a *Analyzer) newSession() *session {
+	return &session{
+		Analyzer:   a,
+		madeBlocks: make(map[uint64]bool),
+	}
+}
+
+// Analyze 分析 blocks 中的每个方块及其中的物品
+func (a *Analyzer) Analyze(blocks ...Block) Report {
+	s := a.newSession()
+	for _, block := range blocks {
+		s.analyzeBlock(block)
+	}
+	return s.report
+}
+
+// AnalyzeStructure 分析结构 structure 中的每个方块及其中的物品。
+// 空气、结构空位和含水方块的水将被忽略
+func (a *Analyzer) AnalyzeStructure(structure *mcstructure.Structure) Report {
+	return a.Analyze(StructureBlocks(structure)...)
+}
+
+// StructureBlocks 返回结构 structure 中所有需要被分析的方块。
+// 空气、结构空位和含水方块的水将被忽略
+func StructureBlocks(structure *mcstructure.Structure) (result []Block) {
+	for index := range int32(structure.Volume()) {
+		pos := structure.Position(index)
+
+		palette, found := structure.Block(pos, mcstructure.LayerNormal)
+		if !found {
+			continue
+		}
+		if palette.Name == "minecraft:air" || palette.Name == "minecraft:structure_void" {
+			continue
+		}
+
+		blockNBT, _ := structure.BlockEntity(pos)
+		result = append(result, Block{
+			Position: pos,
+			Name:     palette.Name,
+			States:   palette.States,
+			NBT:      blockNBT,
+		})
+	}
+	return
+}
+
+// analyzeBlock 分析单个方块 block 及其中的物品
+func (s *session) analyzeBlock(block Block) {
+	blockNBT := block.NBT
+	if blockNBT == nil {
+		blockNBT = make(map[string]any)
+	}
+
+	// 解析时不使用 itemChecker，
+	// 这使得无法获取的物品不会在解析时被静默丢弃
+	parsed, err := nbt_parser_interface.ParseBlock(nil, block.Name, block.States, blockNBT)
+	if err != nil {
+		s.report.BlockStatistics[ObtainImpossible]++
+		s.report.Entries = append(s.report.Entries, Entry{
+			Position: block.Position,
+			Name:     block.Name,
+			Kind:     ObtainImpossible,
+			Reason:   fmt.Sprintf("方块实体数据无法被解析: %v", err),
+		})
+		return
+	}
+
+	kind, makers, reason, operations, cached := s.classifyBlock(parsed)
+	s.report.BlockStatistics[kind]++
+	s.report.Operations += operations
+	if kind != ObtainByCommand {
+		s.report.Entries = append(s.report.Entries, Entry{
+			Position: block.Position,
+			Name:     parsed.BlockName(),
+			Kind:     kind,
+			Makers:   makers,
+			Reason:   reason,
+		})
+	}
+
+	if kind != ObtainImpossible {
+		s.analyzeItems(block.Position, "", parsed, !cached)
+	}
+}
+
+// classifyBlock 检查已解析的方块 block 的获取方式。
+//
+// operations 是得到这个方块估计所需的操作数 (不含其中的物品)。
+// cached 指示相同的 NBT 方块此前已被制作，
+// 这意味着这个方块将直接从缓存的结构加载
+func (s *session) classifyBlock(block nbt_parser_interface.Block) (
+	kind int,
+	makers []string,
+	reason string,
+	operations int,
+	cached bool,
+) {
+	if s.blockChecker != nil && !s.blockChecker(block.BlockName()) {
+		return ObtainImpossible, nil, "方块未在租赁服的方块注册表中", 0, false
+	}
+	if !block.NeedSpecialHandle() {
+		return ObtainByCommand, nil, "", operationsCommand, false
+	}
+
+	supported := nbt_assigner_interface.NBTBlockIsSupported(block)
+	// NBTBlockIsSupported 没有列出合成器，
+	// 但 PlaceNBTBlock 可以制作合成器
+	if _, ok := block.(*nbt_parser_block.Crafter); ok {
+		supported = true
+	}
+	if !supported {
+		return ObtainImpossible, nil, "不受支持的 NBT 方块", 0, false
+	}
+
+	makers = []string{typeName(block)}
+	hashNumber := nbt_hash.NBTBlockFullHash(block)
+	if s.madeBlocks[hashNumber] {
+		return ObtainBySpecialMake, makers, "", operationsLoadCache, true
+	}
+	s.madeBlocks[hashNumber] = true
+	return ObtainBySpecialMake, makers, "", operationsSpecialBlock, false
+}
+
+// analyzeItems 分析已解析的方块 block 中的每个物品。
+// pos 是这个方块 (或其所在物品的方块) 的位置，
+// prefix 是这个方块在其所在物品中的路径。
+//
+// countOperations 指示是否需要统计制作这些物品的操作数。
+// 对于从缓存加载的方块，其中的物品无需被再次制作
+func (s *session) analyzeItems(
+	pos protocol.BlockPos,
+	prefix string,
+	block nbt_parser_interface.Block,
+	countOperations bool,
+) {
+	paths, items := blockItems(block)
+	for index, item := range items {
+		path := paths[index]
+		if len(prefix) > 0 {
+			path = prefix + "." + path
+		}
+		s.analyzeItem(pos, path, item, countOperations)
+	}
+}
+
+// analyzeItem 分析位于 pos 处方块中路径 path 上的物品 item
+func (s *session) analyzeItem(
+	pos protocol.BlockPos,
+	path string,
+	item nbt_parser_interface.Item,
+	countOperations bool,
+) {
+	var operations int
+	var makers []string
+	var subBlock nbt_parser_interface.Block
+	var subBlockCached bool
+	kind := ObtainByCommand
+
+	addImpossible := func(reason string) {
+		s.report.ItemStatistics[ObtainImpossible]++
+		s.report.Entries = append(s.report.Entries, Entry{
+			Position: pos,
+			IsItem:   true,
+			Path:     path,
+			Name:     item.ItemName(),
+			Kind:     ObtainImpossible,
+			Reason:   reason,
+		})
+	}
+
+	defaultItem, ok := item.UnderlyingItem().(*nbt_parser_item.DefaultItem)
+	if !ok {
+		addImpossible("物品的基本数据无法被解析")
+		return
+	}
+	if s.itemChecker != nil && !s.itemChecker(item.ItemName()) {
+		addImpossible("物品无法通过命令获取")
+		return
+	}
+	operations = operationsCommand
+
+	if item.IsComplex() {
+		switch {
+		case nbt_assigner_interface.NBTItemIsSupported(item):
+			makers = append(makers, typeName(item))
+			operations += operationsSpecialItem
+		case defaultItem.Block.SubBlock != nil:
+			subBlock = defaultItem.Block.SubBlock
+			subKind, subMakers, subReason, subOperations, cached := s.classifyBlock(subBlock)
+			if subKind == ObtainImpossible {
+				addImpossible(fmt.Sprintf("物品中的方块无法被制作: %s", subReason))
+				return
+			}
+			makers = append(makers, MakerSubBlock)
+			makers = append(makers, subMakers...)
+			operations += subOperations + operationsSubBlockItem
+			subBlockCached = cached
+		default:
+			addImpossible("不受支持的复杂物品")
+			return
+		}
+	}
+
+	if len(defaultItem.Enhance.EnchList) > 0 {
+		makers = append(makers, MakerEnch)
+		operations += len(defaultItem.Enhance.EnchList) * operationsEnch
+	}
+	if len(defaultItem.Enhance.DisplayName) > 0 {
+		makers = append(makers, MakerRename)
+		operations += operationsRename
+	}
+
+	if len(makers) > 0 {
+		kind = ObtainBySpecialMake
+		s.report.Entries = append(s.report.Entries, Entry{
+			Position: pos,
+			IsItem:   true,
+			Path:     path,
+			Name:     item.ItemName(),
+			Kind:     kind,
+			Makers:   makers,
+		})
+	}
+	s.report.ItemStatistics[kind]++
+	if countOperations {
+		s.report.Operations += operations
+	}
+
+	if subBlock != nil {
+		s.analyzeItems(pos, path, subBlock, countOperations && !subBlockCached)
+	}
+}
+
+// blockItems 返回已解析的方块 block 中的所有物品，
+// 以及这些物品在方块中的路径
+func blockItems(block nbt_parser_interface.Block) (paths []string, items []nbt_parser_interface.Item) {
+	var itemsWithSlot []nbt_parser_block.ItemWithSlot
+
+	switch b := block.(type) {
+	case *nbt_parser_block.Container:
+		itemsWithSlot = b.NBT.Items
+	case *nbt_parser_block.Crafter:
+		itemsWithSlot = b.NBT.ContainerInfo.Items
+	case *nbt_parser_block.BrewingStand:
+		itemsWithSlot = b.NBT.Items
+	case *nbt_parser_block.ChiseledBookshelf:
+		itemsWithSlot = b.NBT.Items
+	case *nbt_parser_block.Campfire:
+		itemsWithSlot = b.NBT.Items
+	case *nbt_parser_block.Frame:
+		if b.NBT.HaveItem {
+			return []string{"Item"}, []nbt_parser_interface.Item{b.NBT.Item}
+		}
+	case *nbt_parser_block.JukeBox:
+		if b.NBT.HaveDisc {
+			return []string{"RecordItem"}, []nbt_parser_interface.Item{b.NBT.Disc}
+		}
+	case *nbt_parser_block.Lectern:
+		if b.NBT.HaveBook {
+			return []string{"book"}, []nbt_parser_interface.Item{b.NBT.Book}
+		}
+	case *nbt_parser_block.DecoratedPot:
+		if b.NBT.HaveItem {
+			return []string{"item"}, []nbt_parser_interface.Item{b.NBT.Item}
+		}
+	}
+
+	for _, item := range itemsWithSlot {
+		paths = append(paths, fmt.Sprintf("Items[Slot=%d]", item.Slot))
+		items = append(items, item.Item)
+	}
+	return
+}
+
+// typeName 返回已解析的方块或物品 value 的类型名称，
+// 例如 Container 或 Book。它被用作制作器的名称
+func typeName(value any) string {
+	return reflect.Indirect(reflect.ValueOf(value)).Type().Name()
+}
@@ -0,0 +1,88 @@
+package nbt_analyzer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+)
+
+// chestWithItems 返回位于 pos 处且含有 items 的箱子
+func chestWithItems(pos protocol.BlockPos, items ...map[string]any) Block {
+	list := make([]any, 0, len(items))
+	for index, item := range items {
+		item["Slot"] = byte(index)
+		list = append(list, item)
+	}
+	return Block{
+		Position: pos,
+		Name:     "minecraft:chest",
+		States:   map[string]any{"minecraft:cardinal_direction": "north"},
+		NBT:      map[string]any{"Items": list},
+	}
+}
+
+// simpleItem 返回名为 name 且数量为 count 的物品
+func simpleItem(name string, count byte) map[string]any {
+	return map[string]any{"Name": name, "Count": count, "Damage": int16(0), "WasPickedUp": byte(0)}
+}
+
+func TestAnalyze(t *testing.T) {
+	analyzer := NewAnalyzer(
+		func(name string) bool { return name != "minecraft:barrier" },
+		func(name string) bool { return name != "minecraft:unknown_block" },
+	)
+
+	sword := simpleItem("minecraft:diamond_sword", 1)
+	sword["tag"] = map[string]any{
+		"ench": []any{map[string]any{"id": int16(9), "lvl": int16(2)}},
+	}
+	report := analyzer.Analyze(
+		Block{Position: protocol.BlockPos{0, 0, 0}, Name: "minecraft:stone"},
+		Block{Position: protocol.BlockPos{1, 0, 0}, Name: "minecraft:unknown_block"},
+		chestWithItems(protocol.BlockPos{2, 0, 0}, sword, simpleItem("minecraft:apple", 3)),
+		chestWithItems(protocol.BlockPos{3, 0, 0}, simpleItem("minecraft:barrier", 1)),
+	)
+
+	if report.BlockStatistics != [ObtainKindCount]int{1, 2, 1} {
+		t.Fatalf("TestAnalyze: Unexpected block statistics %v", report.BlockStatistics)
+	}
+	if report.ItemStatistics != [ObtainKindCount]int{1, 1, 1} {
+		t.Fatalf("TestAnalyze: Unexpected item statistics %v", report.ItemStatistics)
+	}
+
+	var swordEntry, barrierEntry *Entry
+	for index, entry := range report.Entries {
+		switch {
+		case entry.IsItem && entry.Name == "minecraft:diamond_sword":
+			swordEntry = &report.Entries[index]
+		case entry.IsItem && entry.Name == "minecraft:barrier":
+			barrierEntry = &report.Entries[index]
+		}
+	}
+	if swordEntry == nil || swordEntry.Path != "Items[Slot=0]" || !slices.Equal(swordEntry.Makers, []string{MakerEnch}) {
+		t.Fatalf("TestAnalyze: Unexpected sword entry %#v", swordEntry)
+	}
+	if barrierEntry == nil || barrierEntry.Kind != ObtainImpossible {
+		t.Fatalf("TestAnalyze: Unexpected barrier entry %#v", barrierEntry)
+	}
+}
+
+func TestAnalyzeCachedBlock(t *testing.T) {
+	analyzer := NewAnalyzer(nil, nil)
+	chest := func(pos protocol.BlockPos) Block {
+		return chestWithItems(pos, simpleItem("minecraft:apple", 3))
+	}
+
+	single := analyzer.Analyze(chest(protocol.BlockPos{0, 0, 0}))
+	double := analyzer.Analyze(chest(protocol.BlockPos{0, 0, 0}), chest(protocol.BlockPos{1, 0, 0}))
+
+	// 相同的箱子将从缓存的结构加载，
+	// 因此其中的物品无需被再次制作
+	if double.Operations != single.Operations+operationsLoadCache {
+		t.Fatalf("TestAnalyzeCachedBlock: Unexpected operations %d (single = %d)", double.Operations, single.Operations)
+	}
+	if double.BlockStatistics[ObtainBySpecialMake] != 2 || double.ItemStatistics[ObtainByCommand] != 2 {
+		t.Fatalf("TestAnalyzeCachedBlock: Unexpected statistics %v %v", double.BlockStatistics, double.ItemStatistics)
+	}
+}
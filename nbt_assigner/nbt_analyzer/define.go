@@ -0,0 +1,127 @@
+package nbt_analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+)
+
+// 方块或物品在当前租赁服上的获取方式
+const (
+	// ObtainByCommand 指示可以直接通过命令放置或获取
+	ObtainByCommand = iota
+	// ObtainBySpecialMake 指示需要通过特殊的制作器制作
+	ObtainBySpecialMake
+	// ObtainImpossible 指示无法在当前租赁服上得到，
+	// 这意味着它将在导入时被丢弃
+	ObtainImpossible
+	// ObtainKindCount 是获取方式的种类数
+	ObtainKindCount
+)
+
+// 以下是导入各类方块和物品时估计的操作数。
+// 一次操作是指发送一条命令或一次物品堆栈请求，
+// 它们仅用于在导入前粗略地估计导入所需的时间
+const (
+	// operationsCommand 是通过命令放置方块或获取物品的操作数
+	operationsCommand = 1
+	// operationsLoadCache 是从缓存的结构加载已制作的 NBT 方块的操作数
+	operationsLoadCache = 1
+	// operationsSpecialBlock 是制作单个 NBT 方块的操作数 (不含其中的物品)
+	operationsSpecialBlock = 8
+	// operationsSpecialItem 是制作单个书、旗帜、盾牌或已填充地图的操作数
+	operationsSpecialItem = 6
+	// operationsSubBlockItem 是将已制作的 NBT 方块转换为物品的操作数
+	operationsSubBlockItem = 4
+	// operationsEnch 是为物品添加单个魔咒的操作数
+	operationsEnch = 1
+	// operationsRename 是使用铁砧重命名物品的操作数
+	operationsRename = 4
+)
+
+// 制作器的名称
+const (
+	// MakerEnch 指示物品需要附魔
+	MakerEnch = "Ench"
+	// MakerRename 指示物品需要使用铁砧重命名
+	MakerRename = "Rename"
+	// MakerSubBlock 指示物品需要先制作为 NBT 方块，然后再转换为物品
+	MakerSubBlock = "SubBlock"
+)
+
+// Block 是要分析的单个方块
+type Block struct {
+	// Position 是这个方块所在的位置
+	Position protocol.BlockPos
+	// Name 是这个方块的名称
+	Name string
+	// States 是这个方块的方块状态
+	States map[string]any
+	// NBT 是这个方块的方块实体数据，
+	// 如果这个方块没有方块实体，则可以置为 nil
+	NBT map[string]any
+}
+
+// Entry 描述单个需要特殊制作或无法获取的方块或物品
+type Entry struct {
+	// Position 是这个方块 (或物品所在方块) 的位置
+	Position protocol.BlockPos
+	// IsItem 指示这是否是一个物品
+	IsItem bool
+	// Path 是物品在其所在方块中的路径，例如 Items[Slot=3]。
+	// 对于嵌套在其他物品中的物品，例如潜影盒中的物品，
+	// 路径将形如 Items[Slot=3].Items[Slot=0]。
+	// 对于方块，Path 总是为空
+	Path string
+	// Name 是这个方块或物品的名称
+	Name string
+	// Kind 是这个方块或物品的获取方式，
+	// 它是 ObtainBySpecialMake 或 ObtainImpossible
+	Kind int
+	// Makers 是制作这个方块或物品所使用的制作器，
+	// 例如 Container、Book、Ench 或 Rename。
+	// 它仅在 Kind 为 ObtainBySpecialMake 时有效
+	Makers []string
+	// Reason 是无法得到这个方块或物品的原因。
+	// 它仅在 Kind 为 ObtainImpossible 时有效
+	Reason string
+}
+
+// String 返回 e 的可读描述
+func (e Entry) String() string {
+	target := fmt.Sprintf("方块 %s", e.Name)
+	if e.IsItem {
+		target = fmt.Sprintf("物品 %s (%s)", e.Name, e.Path)
+	}
+
+	if e.Kind == ObtainImpossible {
+		return fmt.Sprintf("%v: %s 无法获取，将被丢弃; %s", e.Position, target, e.Reason)
+	}
+	return fmt.Sprintf("%v: %s 需要特殊制作 (%s)", e.Position, target, strings.Join(e.Makers, ", "))
+}
+
+// Report 是分析多个方块的结果
+type Report struct {
+	// Entries 是所有需要特殊制作或无法获取的方块和物品。
+	// 可以直接通过命令得到的方块和物品仅在统计数据中体现
+	Entries []Entry
+	// BlockStatistics 是每种获取方式的方块的数量
+	BlockStatistics [ObtainKindCount]int
+	// ItemStatistics 是每种获取方式的物品的数量
+	ItemStatistics [ObtainKindCount]int
+	// Operations 是导入所有方块估计所需的操作数。
+	// 相同的 NBT 方块只会被制作一次，
+	// 此后它们将从缓存的结构加载
+	Operations int
+}
+
+// Impossible 返回 r 中所有无法获取的方块和物品
+func (r Report) Impossible() (result []Entry) {
+	for _, entry := range r.Entries {
+		if entry.Kind == ObtainImpossible {
+			result = append(result, entry)
+		}
+	}
+	return
+}
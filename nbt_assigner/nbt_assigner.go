@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/mcpol-studio/flowers-for-machines/block_palette"
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/map_art"
 	nbt_assigner_interface "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_analyzer"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
 	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
@@ -23,6 +26,7 @@ type NBTAssigner struct {
 	mu      *sync.Mutex
 	console *nbt_console.Console
 	cache   *nbt_cache.NBTCacheSystem
+	palette *block_palette.Palette
 }
 
 // NewNBTAssigner 基于操作台和缓存命中系统创建并返回一个新的 NBT 方块放置实现。
@@ -32,13 +36,47 @@ func NewNBTAssigner(
 	console *nbt_console.Console,
 	cache *nbt_cache.NBTCacheSystem,
 ) *NBTAssigner {
+	constantPacket := console.API().Resources().ConstantPacket()
 	return &NBTAssigner{
 		mu:      new(sync.Mutex),
 		console: console,
 		cache:   cache,
+		palette: block_palette.NewPalette(
+			// ConstantPacket 的方法使用值接收者，因此不能直接传递方法值，
+			// 否则在租赁服重新发送命令枚举后，调色板仍会使用旧的数据
+			func(name string) bool { return constantPacket.BlockCanSetByCommand(name) },
+			constantPacket.CustomBlocks(),
+		),
 	}
 }
 
+// Analyzer 返回基于租赁服的物品列表和方块注册表的可获取性分析器。
+// 它可以在导入前分析结构中的每个方块和物品是否可以被得到，
+// 因此它可以在 PlaceNBTBlock 之前被调用
+func (n *NBTAssigner) Analyzer() *nbt_analyzer.Analyzer {
+	constantPacket := n.console.API().Resources().ConstantPacket()
+	return nbt_analyzer.NewAnalyzer(
+		func(name string) bool { return constantPacket.ItemCanGetByCommand(name) },
+		func(name string) bool { return constantPacket.BlockCanSetByCommand(name) },
+	)
+}
+
+// ValidateBlock 根据租赁服的方块注册表和内置的方块调色板校验
+// 名称为 blockName 且方块状态为 blockStates 的方块。
+//
+// 返回的结果包含升级后的方块名称、与原始方块状态最接近的合法方块状态，
+// 以及作出的每一处修正。如果方块在租赁服上不存在，则返回错误。
+//
+// ValidateBlock 不会修改世界中的任何方块，
+// 因此它可以在 PlaceNBTBlock 之前被调用
+func (n *NBTAssigner) ValidateBlock(blockName string, blockStates map[string]any) (block_palette.Result, error) {
+	result, err := n.palette.Validate(blockName, blockStates)
+	if err != nil {
+		return block_palette.Result{}, fmt.Errorf("ValidateBlock: %v", err)
+	}
+	return result, nil
+}
+
 // PlaceNBTBlock 试图制作一个新的 NBT 方块，
 // 制作位置是在操作台的中心方块处。
 //
@@ -73,6 +111,56 @@ func (n *NBTAssigner) PlaceNBTBlock(blockName string, blockStates map[string]any
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
+	attachMapDataHash(n.cache.MapDataCache(), nbtBlock)
 	canFast, uniqueID, offset, err = nbt_assigner_interface.PlaceNBTBlock(n.console, n.cache, nbtBlock)
 	return
 }
+
+// UnreproducibleFields 解析 blockNBT 并返回其中无法在导入时被还原的数据的字段名。
+// blockName 和 blockStates 分别指示这个方块实体的名称和方块状态。
+//
+// 如果这个方块实体的所有数据都可以被还原，则返回的切片为空
+func (n *NBTAssigner) UnreproducibleFields(blockName string, blockStates map[string]any, blockNBT map[string]any) ([]string, error) {
+	nbtBlock, err := nbt_parser_interface.ParseBlock(
+		n.console.API().Resources().ConstantPacket().ItemCanGetByCommand,
+		blockName,
+		blockStates,
+		blockNBT,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("UnreproducibleFields: %v", err)
+	}
+
+	partialBlock, ok := nbtBlock.(nbt_parser_interface.PartialBlock)
+	if !ok {
+		return nil, nil
+	}
+	return partialBlock.UnreproducibleFields(), nil
+}
+
+// StoreMapData 记录 UUID 为 mapUUID 的地图所对应的地图数据 mapNBT，
+// 以便于在导入含有已填充地图的物品展示框或容器时还原地图的像素。
+//
+// mapNBT 是存档中的地图数据，它应当包含 colors 字段。
+// 对于没有提供地图数据的已填充地图，它们将被导入为空白地图
+func (n *NBTAssigner) StoreMapData(mapUUID int64, mapNBT map[string]any) error {
+	pixels, err := map_art.ParseMapData(mapNBT)
+	if err != nil {
+		return fmt.Errorf("StoreMapData: %v", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.cache.MapDataCache().StoreMapData(mapUUID, pixels)
+	return nil
+}
+
+// CleanMapData 清除由 StoreMapData 记录的所有地图数据。
+// 地图 UUID 只在同一个存档中唯一，因此使用者应当在
+// 每次导入结束后调用它，以免地图数据被后续的导入误用
+func (n *NBTAssigner) CleanMapData() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cache.MapDataCache().CleanMapData()
+}
@@ -0,0 +1,118 @@
+package nbt_block
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/block_helper"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_assigner_utils "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/utils"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+)
+
+// 营火
+type Campfire struct {
+	console *nbt_console.Console
+	cache   *nbt_cache.NBTCacheSystem
+	data    nbt_parser_block.Campfire
+}
+
+func (Campfire) Offset() protocol.BlockPos {
+	return protocol.BlockPos{0, 0, 0}
+}
+
+func (c *Campfire) Make() error {
+	api := c.console.API()
+
+	// 物品无法被放入已熄灭的营火，
+	// 因此营火总是先以点燃的状态生成
+	litStates := maps.Clone(c.data.BlockStates())
+	litStates["extinguished"] = byte(0)
+
+	// 生成营火
+	err := nbt_assigner_utils.SpawnNewEmptyBlock(
+		c.console,
+		c.cache,
+		nbt_assigner_utils.EmptyBlockData{
+			Name:               c.data.BlockName(),
+			States:             litStates,
+			IsCanOpenConatiner: false,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 依次放入物品。
+	// 营火中的物品已在解析时按槽位排列，
+	// 而每次点击都会将物品放入第一个空槽位，
+	// 因此前面存在空槽位的物品将被前移
+	for _, value := range c.data.NBT.Items {
+		_, err = prepareHotbarItem(c.console, c.cache, value.Item, 1)
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+
+		err = c.console.CanReachOrMove(c.console.Center())
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+
+		err = api.BotClick().ClickBlock(game_interface.UseItemOnBlocks{
+			HotbarSlotID: c.console.HotbarSlotID(),
+			BotPos:       c.console.Position(),
+			BlockPos:     c.console.Center(),
+			BlockName:    c.data.BlockName(),
+			BlockStates:  litStates,
+		})
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+	}
+
+	// 如果营火原本是熄灭的，则使用锹将其熄灭。
+	// 熄灭营火不会使其中的物品掉落
+	if extinguished, _ := c.data.BlockStates()["extinguished"].(byte); extinguished != 0 {
+		err = api.Replaceitem().ReplaceitemInInventory(
+			"@s",
+			game_interface.ReplacePathHotbarOnly,
+			game_interface.ReplaceitemInfo{
+				Name:     "minecraft:wooden_shovel",
+				Count:    1,
+				MetaData: 0,
+				Slot:     c.console.HotbarSlotID(),
+			},
+			"",
+			true,
+		)
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+		c.console.UseInventorySlot(nbt_console.RequesterUser, c.console.HotbarSlotID(), true)
+
+		err = c.console.CanReachOrMove(c.console.Center())
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+		err = api.BotClick().ClickBlock(game_interface.UseItemOnBlocks{
+			HotbarSlotID: c.console.HotbarSlotID(),
+			BotPos:       c.console.Position(),
+			BlockPos:     c.console.Center(),
+			BlockName:    c.data.BlockName(),
+			BlockStates:  litStates,
+		})
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+		c.console.UseHelperBlock(nbt_console.RequesterUser, nbt_console.ConsoleIndexCenterBlock, block_helper.ComplexBlock{
+			KnownStates: true,
+			Name:        c.data.BlockName(),
+			States:      c.data.BlockStates(),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package nbt_block
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/block_helper"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_assigner_utils "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/utils"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 雕纹书架
+type ChiseledBookshelf struct {
+	console *nbt_console.Console
+	cache   *nbt_cache.NBTCacheSystem
+	data    nbt_parser_block.ChiseledBookshelf
+}
+
+func (ChiseledBookshelf) Offset() protocol.BlockPos {
+	return protocol.BlockPos{0, 0, 0}
+}
+
+// clickTarget 计算放入槽位 slot 时需要点击的面，
+// 以及点击位置相对于书架西北下角的偏移量
+func (c *ChiseledBookshelf) clickTarget(slot uint8) (blockFace int32, clickedPosition mgl32.Vec3) {
+	direction, _ := c.data.BlockStates()["direction"].(int32)
+
+	// u 是从书架正面看去时，槽位中心从左到右的水平偏移
+	u := (float32(slot%3) + 0.5) / 3
+	y := float32(0.75)
+	if slot >= 3 {
+		y = 0.25
+	}
+
+	switch direction {
+	case 1: // 朝西
+		return 4, mgl32.Vec3{0, y, u}
+	case 2: // 朝北
+		return 2, mgl32.Vec3{1 - u, y, 0}
+	case 3: // 朝东
+		return 5, mgl32.Vec3{1, y, 1 - u}
+	default: // 朝南
+		return 3, mgl32.Vec3{u, y, 1}
+	}
+}
+
+func (c *ChiseledBookshelf) Make() error {
+	api := c.console.API()
+
+	// 按槽位顺序将书籍制作并暂存到背包。
+	// 制作书籍可能会占用操作台中心，
+	// 因此这需要在生成书架前完成
+	items := slices.Clone(c.data.NBT.Items)
+	slices.SortStableFunc(items, func(a nbt_parser_block.ItemWithSlot, b nbt_parser_block.ItemWithSlot) int {
+		return cmp.Compare(a.Slot, b.Slot)
+	})
+	books := make([]nbt_parser_interface.Item, 0, len(items))
+	counts := make([]uint8, 0, len(items))
+	for _, value := range items {
+		books = append(books, value.Item)
+		counts = append(counts, 1)
+	}
+	stashed, err := stashItems(c.console, c.cache, books, counts)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 生成空的雕纹书架
+	states := maps.Clone(c.data.BlockStates())
+	states["books_stored"] = int32(0)
+	err = nbt_assigner_utils.SpawnNewEmptyBlock(
+		c.console,
+		c.cache,
+		nbt_assigner_utils.EmptyBlockData{
+			Name:               c.data.BlockName(),
+			States:             states,
+			IsCanOpenConatiner: false,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 依次将书籍移动到快捷栏并放入书架
+	for index, value := range items {
+		err = takeStashedItem(c.console, stashed[index])
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+
+		err = c.console.CanReachOrMove(c.console.Center())
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+
+		blockFace, clickedPosition := c.clickTarget(value.Slot)
+		err = api.BotClick().ClickBlockFace(
+			game_interface.UseItemOnBlocks{
+				HotbarSlotID: c.console.HotbarSlotID(),
+				BotPos:       c.console.Position(),
+				BlockPos:     c.console.Center(),
+				BlockName:    c.data.BlockName(),
+				BlockStates:  states,
+			},
+			blockFace,
+			clickedPosition,
+		)
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+		c.console.UseInventorySlot(nbt_console.RequesterUser, c.console.HotbarSlotID(), false)
+
+		states = maps.Clone(states)
+		states["books_stored"] = states["books_stored"].(int32) | (1 << value.Slot)
+		c.console.UseHelperBlock(nbt_console.RequesterUser, nbt_console.ConsoleIndexCenterBlock, block_helper.ComplexBlock{
+			KnownStates: true,
+			Name:        c.data.BlockName(),
+			States:      states,
+		})
+	}
+
+	return nil
+}
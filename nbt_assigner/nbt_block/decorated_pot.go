@@ -0,0 +1,94 @@
+package nbt_block
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_assigner_utils "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/utils"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+)
+
+// 饰纹陶罐
+type DecoratedPot struct {
+	console *nbt_console.Console
+	cache   *nbt_cache.NBTCacheSystem
+	data    nbt_parser_block.DecoratedPot
+}
+
+func (DecoratedPot) Offset() protocol.BlockPos {
+	return protocol.BlockPos{0, 0, 0}
+}
+
+// putItem 将暂存的物品 item 移动到快捷栏，然后将其放入陶罐。
+// 每次点击都会使得一个物品被放入陶罐
+func (d *DecoratedPot) putItem(item stashedItem) error {
+	api := d.console.API()
+
+	err := takeStashedItem(d.console, item)
+	if err != nil {
+		return fmt.Errorf("putItem: %v", err)
+	}
+
+	err = d.console.CanReachOrMove(d.console.Center())
+	if err != nil {
+		return fmt.Errorf("putItem: %v", err)
+	}
+
+	for range item.Count {
+		err = api.BotClick().ClickBlock(game_interface.UseItemOnBlocks{
+			HotbarSlotID: d.console.HotbarSlotID(),
+			BotPos:       d.console.Position(),
+			BlockPos:     d.console.Center(),
+			BlockName:    d.data.BlockName(),
+			BlockStates:  d.data.BlockStates(),
+		})
+		if err != nil {
+			return fmt.Errorf("putItem: %v", err)
+		}
+	}
+	d.console.UseInventorySlot(nbt_console.RequesterUser, d.console.HotbarSlotID(), false)
+
+	return nil
+}
+
+func (d *DecoratedPot) Make() error {
+	item := d.data.NBT.Item
+
+	// 将目标物品制作并暂存到背包。
+	// 复杂的物品每次只能制作一个，因此只能将一个
+	// 这样的物品放入陶罐，这由 UnreproducibleFields 报告
+	stashed, err := stashItems(
+		d.console, d.cache,
+		[]nbt_parser_interface.Item{item},
+		[]uint8{item.ItemCount()},
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 生成陶罐
+	err = nbt_assigner_utils.SpawnNewEmptyBlock(
+		d.console,
+		d.cache,
+		nbt_assigner_utils.EmptyBlockData{
+			Name:               d.data.BlockName(),
+			States:             d.data.BlockStates(),
+			IsCanOpenConatiner: false,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 放入物品
+	err = d.putItem(stashed[0])
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package nbt_block
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_assigner_utils "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/utils"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+)
+
+// 花盆
+type FlowerPot struct {
+	console *nbt_console.Console
+	cache   *nbt_cache.NBTCacheSystem
+	data    nbt_parser_block.FlowerPot
+}
+
+func (FlowerPot) Offset() protocol.BlockPos {
+	return protocol.BlockPos{0, 0, 0}
+}
+
+func (f *FlowerPot) Make() error {
+	api := f.console.API()
+
+	// 生成花盆
+	err := nbt_assigner_utils.SpawnNewEmptyBlock(
+		f.console,
+		f.cache,
+		nbt_assigner_utils.EmptyBlockData{
+			Name:               f.data.BlockName(),
+			States:             f.data.BlockStates(),
+			IsCanOpenConatiner: false,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 获取植物到物品栏
+	err = api.Replaceitem().ReplaceitemInInventory(
+		"@s",
+		game_interface.ReplacePathHotbarOnly,
+		game_interface.ReplaceitemInfo{
+			Name:     f.data.NBT.PlantName,
+			Count:    1,
+			MetaData: 0,
+			Slot:     f.console.HotbarSlotID(),
+		},
+		"",
+		true,
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+	f.console.UseInventorySlot(nbt_console.RequesterUser, f.console.HotbarSlotID(), true)
+
+	// 传送到操作台中心
+	err = f.console.CanReachOrMove(f.console.Center())
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 将植物放入花盆
+	err = api.BotClick().ClickBlock(game_interface.UseItemOnBlocks{
+		HotbarSlotID: f.console.HotbarSlotID(),
+		BotPos:       f.console.Position(),
+		BlockPos:     f.console.Center(),
+		BlockName:    f.data.BlockName(),
+		BlockStates:  f.data.BlockStates(),
+	})
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	return nil
+}
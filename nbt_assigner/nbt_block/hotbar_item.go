@@ -0,0 +1,252 @@
+package nbt_block
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	nbt_assigner_interface "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_hash "github.com/mcpol-studio/flowers-for-machines/nbt_parser/hash"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+	nbt_parser_item "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// processComplexItem 在背包中制作复杂的物品 item，
+// 并将其移动到操作台所使用的快捷栏。
+//
+// 如果 item 实际上可以直接通过命令获取，
+// 则 canUseCommand 为真，且 resultSlot 无效
+func processComplexItem(
+	console *nbt_console.Console,
+	cache *nbt_cache.NBTCacheSystem,
+	item nbt_parser_interface.Item,
+) (canUseCommand bool, resultSlot resources_control.SlotID, err error) {
+	api := console.API()
+	underlying := item.UnderlyingItem()
+	defaultItem := underlying.(*nbt_parser_item.DefaultItem)
+
+	// 子方块
+	if defaultItem.Block.SubBlock != nil {
+		if !defaultItem.Block.SubBlock.NeedSpecialHandle() {
+			return true, 0, nil
+		}
+		_, _, _, err = nbt_assigner_interface.PlaceNBTBlock(console, cache, defaultItem.Block.SubBlock)
+		if err != nil {
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+
+		_, hit, partHit, err := cache.NBTBlockCache().LoadCache(nbt_hash.CompletelyHashNumber{
+			HashNumber:    nbt_hash.NBTBlockFullHash(defaultItem.Block.SubBlock),
+			SetHashNumber: nbt_hash.ContainerSetHash(defaultItem.Block.SubBlock),
+		})
+		if err != nil {
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+		if !hit || partHit {
+			panic("processComplexItem: Should never happened")
+		}
+
+		_, err = console.API().Commands().SendWSCommandWithResp("clear")
+		if err != nil {
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+		console.CleanInventory()
+
+		success, currentSlot, err := api.BotClick().PickBlock(console.Center(), true)
+		if err != nil || !success {
+			_ = console.ChangeAndUpdateHotbarSlotID(nbt_console.DefaultHotbarSlot)
+		}
+		if err != nil {
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+		if !success {
+			return false, 0, fmt.Errorf("processComplexItem: Failed to pick block due to unknown reason")
+		}
+		console.UpdateHotbarSlotID(currentSlot)
+		console.UseInventorySlot(nbt_console.RequesterUser, currentSlot, true)
+
+		return false, currentSlot, nil
+	}
+
+	// 复杂 NBT 物品制作
+	methods := nbt_assigner_interface.MakeNBTItemMethod(console, cache, item)
+	if len(methods) != 1 {
+		panic("processComplexItem: Should never happened")
+	}
+	resultSlotMapping, err := methods[0].Make()
+	if err != nil {
+		return false, 0, fmt.Errorf("processComplexItem: %v", err)
+	}
+	if len(resultSlotMapping) != 1 {
+		panic("processComplexItem: Should never happened")
+	}
+
+	// 将复杂 NBT 物品移动到快捷栏
+	for _, slotID := range resultSlotMapping {
+		resultSlot = slotID
+	}
+	if resultSlot > 8 {
+		err = api.Replaceitem().ReplaceitemInInventory(
+			"@s",
+			game_interface.ReplacePathHotbarOnly,
+			game_interface.ReplaceitemInfo{
+				Name:     "minecraft:air",
+				Count:    1,
+				MetaData: 0,
+				Slot:     console.HotbarSlotID(),
+			},
+			"",
+			true,
+		)
+		if err != nil {
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+		console.UseInventorySlot(nbt_console.RequesterUser, console.HotbarSlotID(), false)
+
+		success, err := api.ContainerOpenAndClose().OpenInventory()
+		if err != nil {
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+		if !success {
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+
+		success, _, _, err = api.ItemStackOperation().OpenTransaction().
+			MoveBetweenInventory(resultSlot, console.HotbarSlotID(), 1).
+			Commit()
+		if err != nil {
+			_ = api.ContainerOpenAndClose().CloseContainer()
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+		if !success {
+			_ = api.ContainerOpenAndClose().CloseContainer()
+			return false, 0, fmt.Errorf("processComplexItem: The server rejected the stack request action")
+		}
+
+		err = api.ContainerOpenAndClose().CloseContainer()
+		if err != nil {
+			return false, 0, fmt.Errorf("processComplexItem: %v", err)
+		}
+
+		resultSlot = console.HotbarSlotID()
+	}
+
+	return false, resultSlot, nil
+}
+
+// prepareHotbarItem 将物品 item 制作到操作台所使用的快捷栏，
+// 并在必要时为其附魔和重命名。count 指示物品的数量，
+// 但对于复杂的物品，其数量总是为 1。
+//
+// resultSlot 是物品最终所在的快捷栏，
+// 它总是与操作台当前的快捷栏相同
+func prepareHotbarItem(
+	console *nbt_console.Console,
+	cache *nbt_cache.NBTCacheSystem,
+	item nbt_parser_interface.Item,
+	count uint8,
+) (resultSlot resources_control.SlotID, err error) {
+	var canUseCommand bool
+	api := console.API()
+
+	// 如果这是一个复杂的物品
+	if item.IsComplex() {
+		canUseCommand, resultSlot, err = processComplexItem(console, cache, item)
+		if err != nil {
+			return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+		}
+	} else {
+		canUseCommand = true
+	}
+
+	// canUseCommand 指示可以先使用命令获取目标物品
+	if canUseCommand {
+		underlying := item.UnderlyingItem()
+		defaultItem := underlying.(*nbt_parser_item.DefaultItem)
+
+		err = console.API().Replaceitem().ReplaceitemInInventory(
+			"@s",
+			game_interface.ReplacePathHotbarOnly,
+			game_interface.ReplaceitemInfo{
+				Name:     item.ItemName(),
+				Count:    count,
+				MetaData: item.ItemMetadata(),
+				Slot:     console.HotbarSlotID(),
+			},
+			utils.MarshalItemComponent(defaultItem.Enhance.ItemComponent),
+			true,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+		}
+
+		console.UseInventorySlot(nbt_console.RequesterUser, console.HotbarSlotID(), true)
+		resultSlot = console.HotbarSlotID()
+	}
+
+	// 切换物品栏，如果需要的话
+	if resultSlot != console.HotbarSlotID() {
+		err = console.ChangeAndUpdateHotbarSlotID(resultSlot)
+		if err != nil {
+			return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+		}
+	}
+
+	// 如果这个物品需要重命名或附魔
+	if item.NeedEnchOrRename() {
+		underlying := item.UnderlyingItem()
+		defaultItem := underlying.(*nbt_parser_item.DefaultItem)
+
+		// 附魔处理
+		for _, ench := range defaultItem.Enhance.EnchList {
+			err = api.Commands().SendSettingsCommand(fmt.Sprintf("enchant @s %d %d", ench.ID, ench.Level), true)
+			if err != nil {
+				return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+			}
+		}
+		if len(defaultItem.Enhance.EnchList) > 0 {
+			err = api.Commands().AwaitChangesGeneral()
+			if err != nil {
+				return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+			}
+		}
+
+		// 物品改名处理
+		if len(defaultItem.Enhance.DisplayName) > 0 {
+			index, err := console.FindOrGenerateNewAnvil()
+			if err != nil {
+				return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+			}
+
+			success, err := console.OpenContainerByIndex(index)
+			if err != nil {
+				return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+			}
+			if !success {
+				return 0, fmt.Errorf("prepareHotbarItem: Failed to open the anvil who at %#v", console.BlockPosByIndex(index))
+			}
+
+			success, _, _, err = api.ItemStackOperation().OpenTransaction().
+				RenameInventoryItem(resultSlot, defaultItem.Enhance.DisplayName).
+				Commit()
+			if err != nil {
+				_ = api.ContainerOpenAndClose().CloseContainer()
+				return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+			}
+			if !success {
+				_ = api.ContainerOpenAndClose().CloseContainer()
+				return 0, fmt.Errorf("prepareHotbarItem: The server rejected the renaming operation")
+			}
+
+			err = api.ContainerOpenAndClose().CloseContainer()
+			if err != nil {
+				return 0, fmt.Errorf("prepareHotbarItem: %v", err)
+			}
+		}
+	}
+
+	return resultSlot, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
 	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	nbt_diff "github.com/mcpol-studio/flowers-for-machines/nbt_parser/diff"
 	nbt_hash "github.com/mcpol-studio/flowers-for-machines/nbt_parser/hash"
 	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
 
@@ -39,6 +40,12 @@ func NBTBlockIsSupported(block nbt_parser_interface.Block) bool {
 	case *nbt_parser_block.Lectern:
 	case *nbt_parser_block.JukeBox:
 	case *nbt_parser_block.BrewingStand:
+	case *nbt_parser_block.Skull:
+	case *nbt_parser_block.FlowerPot:
+	case *nbt_parser_block.DecoratedPot:
+	case *nbt_parser_block.ChiseledBookshelf:
+	case *nbt_parser_block.Campfire:
+	case *nbt_parser_block.MobSpawner:
 	default:
 		return false
 	}
@@ -155,6 +162,41 @@ func placeNBTBlock(
 			cache:   cache,
 			data:    *block,
 		}
+	case *nbt_parser_block.Skull:
+		method = &Skull{
+			console: console,
+			cache:   cache,
+			data:    *block,
+		}
+	case *nbt_parser_block.FlowerPot:
+		method = &FlowerPot{
+			console: console,
+			cache:   cache,
+			data:    *block,
+		}
+	case *nbt_parser_block.DecoratedPot:
+		method = &DecoratedPot{
+			console: console,
+			cache:   cache,
+			data:    *block,
+		}
+	case *nbt_parser_block.ChiseledBookshelf:
+		method = &ChiseledBookshelf{
+			console: console,
+			cache:   cache,
+			data:    *block,
+		}
+	case *nbt_parser_block.Campfire:
+		method = &Campfire{
+			console: console,
+			cache:   cache,
+			data:    *block,
+		}
+	case *nbt_parser_block.MobSpawner:
+		method = &MobSpawner{
+			console: console,
+			data:    *block,
+		}
 	}
 
 	// 放置相应方块
@@ -178,16 +220,11 @@ func placeNBTBlock(
 		if hashNumber.HashNumber != nbt_hash.NBTBlockFullHash(newBlock) {
 			nextCount := repeatCount + 1
 			if nextCount > MaxRetryPlaceNBTBlock {
-				return false, uuid.UUID{}, protocol.BlockPos{}, fmt.Errorf(
-					""+
-						"PlaceNBTBlock: Self loop when place NBT block, "+
-						"and result in invalid user input data, "+
-						"and need to correct; "+
-						"nbtBlock = %#v; newBlock = %#v; "+
-						"nbtBlock.Format(\"\") = %#v; newBlock.Format(\"\") = %#v",
-					nbtBlock, newBlock,
-					nbtBlock.Format(""), newBlock.Format(""),
-				)
+				return false, uuid.UUID{}, protocol.BlockPos{}, &nbt_assigner_interface.IncompleteBlockError{
+					Expected:    nbtBlock,
+					Actual:      newBlock,
+					Differences: nbt_diff.DiffBlocks(nbtBlock, newBlock),
+				}
 			}
 			return placeNBTBlock(console, cache, nbtBlock, nextCount)
 		}
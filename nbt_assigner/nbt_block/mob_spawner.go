@@ -0,0 +1,74 @@
+package nbt_block
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/block_helper"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+)
+
+// 刷怪笼
+type MobSpawner struct {
+	console *nbt_console.Console
+	data    nbt_parser_block.MobSpawner
+}
+
+func (MobSpawner) Offset() protocol.BlockPos {
+	return protocol.BlockPos{0, 0, 0}
+}
+
+func (m *MobSpawner) Make() error {
+	api := m.console.API()
+
+	// 放置刷怪笼
+	err := api.SetBlock().SetBlock(m.console.Center(), m.data.BlockName(), m.data.BlockStatesString())
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+	m.console.UseHelperBlock(nbt_console.RequesterUser, nbt_console.ConsoleIndexCenterBlock, block_helper.ComplexBlock{
+		KnownStates: true,
+		Name:        m.data.BlockName(),
+		States:      m.data.BlockStates(),
+	})
+
+	// 获取刷怪蛋到物品栏
+	err = api.Replaceitem().ReplaceitemInInventory(
+		"@s",
+		game_interface.ReplacePathHotbarOnly,
+		game_interface.ReplaceitemInfo{
+			Name:     m.data.SpawnEggName(),
+			Count:    1,
+			MetaData: 0,
+			Slot:     m.console.HotbarSlotID(),
+		},
+		"",
+		true,
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+	m.console.UseInventorySlot(nbt_console.RequesterUser, m.console.HotbarSlotID(), true)
+
+	// 传送到操作台中心
+	err = m.console.CanReachOrMove(m.console.Center())
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 对刷怪笼使用刷怪蛋
+	err = api.BotClick().ClickBlock(game_interface.UseItemOnBlocks{
+		HotbarSlotID: m.console.HotbarSlotID(),
+		BotPos:       m.console.Position(),
+		BlockPos:     m.console.Center(),
+		BlockName:    m.data.BlockName(),
+		BlockStates:  m.data.BlockStates(),
+	})
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	return nil
+}
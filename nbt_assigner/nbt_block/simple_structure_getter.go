@@ -7,6 +7,7 @@ import (
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
 	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
 )
 
@@ -72,9 +73,14 @@ func simpleStructureGetter(console *nbt_console.Console) (nbtMap map[string]any,
 		return nil, fmt.Errorf("simpleStructureGetter: %v", terminalErr)
 	}
 
-	m := resp.StructureTemplate
-	m = m["structure"].(map[string]any)["palette"].(map[string]any)["default"].(map[string]any)
-	m = m["block_position_data"].(map[string]any)["0"].(map[string]any)["block_entity_data"].(map[string]any)
+	structure, err := mcstructure.FromNBT(resp.StructureTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("simpleStructureGetter: %v", err)
+	}
+	nbtMap, found := structure.BlockEntity(protocol.BlockPos{0, 0, 0})
+	if !found {
+		return nil, fmt.Errorf("simpleStructureGetter: Block entity data not found")
+	}
 
-	return m, nil
+	return nbtMap, nil
 }
@@ -0,0 +1,102 @@
+package nbt_block
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol/packet"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/block_helper"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+)
+
+// 头颅
+type Skull struct {
+	console *nbt_console.Console
+	cache   *nbt_cache.NBTCacheSystem
+	data    nbt_parser_block.Skull
+}
+
+func (Skull) Offset() protocol.BlockPos {
+	return protocol.BlockPos{0, 0, 0}
+}
+
+func (s *Skull) Make() error {
+	var facing uint8 = 1
+	api := s.console.API()
+
+	// 获取头颅到物品栏
+	err := api.Replaceitem().ReplaceitemInInventory(
+		"@s",
+		game_interface.ReplacePathHotbarOnly,
+		game_interface.ReplaceitemInfo{
+			Name:     "minecraft:skull",
+			Count:    1,
+			MetaData: int16(s.data.NBT.SkullType),
+			Slot:     s.console.HotbarSlotID(),
+		},
+		"",
+		true,
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+	s.console.UseInventorySlot(nbt_console.RequesterUser, s.console.HotbarSlotID(), true)
+
+	// 移动机器人到操作台中心
+	err = s.console.CanReachOrMove(s.console.Center())
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+
+	// 放置在地面上的头颅的旋转取决于机器人的偏航角，
+	// 因此我们需要先转动机器人的视角
+	if s.data.OnFloor() {
+		yaw := s.data.Yaw()
+
+		err = api.Commands().SendSettingsCommand(fmt.Sprintf("tp @s ~ ~ ~ %v 0", yaw), true)
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+		err = api.Commands().AwaitChangesGeneral()
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+
+		err = api.Resources().WritePacket(&packet.PlayerAuthInput{
+			Yaw:       yaw,
+			HeadYaw:   yaw,
+			InputData: packet.InputFlagStartFlying,
+			Position:  s.console.Position(),
+		})
+		if err != nil {
+			return fmt.Errorf("Make: %v", err)
+		}
+	} else {
+		wallFacing, _ := s.data.States["facing_direction"].(int32)
+		facing = uint8(wallFacing)
+	}
+
+	// 放置头颅
+	_, offsetPos, err := api.BotClick().PlaceBlockHighLevel(
+		s.console.Center(),
+		s.console.Position(),
+		s.console.HotbarSlotID(),
+		facing,
+	)
+	if err != nil {
+		return fmt.Errorf("Make: %v", err)
+	}
+	s.console.UseHelperBlock(nbt_console.RequesterUser, nbt_console.ConsoleIndexCenterBlock, block_helper.ComplexBlock{
+		KnownStates: true,
+		Name:        s.data.BlockName(),
+		States:      s.data.BlockStates(),
+	})
+	*s.console.NearBlockByIndex(nbt_console.ConsoleIndexCenterBlock, offsetPos) = block_helper.NearBlock{
+		Name: game_interface.BasePlaceBlock,
+	}
+
+	return nil
+}
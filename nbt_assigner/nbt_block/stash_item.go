@@ -0,0 +1,100 @@
+package nbt_block
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+)
+
+// firstStashSlot 是暂存物品时所使用的第一个背包槽位。
+// 它位于快捷栏之外，因此不会被制作物品的过程所覆盖
+const firstStashSlot resources_control.SlotID = 9
+
+// stashedItem 是暂存于背包中的物品
+type stashedItem struct {
+	Slot  resources_control.SlotID // 物品所在的背包槽位
+	Count uint8                    // 物品的数量
+}
+
+// moveInventoryItem 通过物品状态转移将背包中 src 处的 count 个物品移动到 dst。
+// 应当保证 dst 处是空气
+func moveInventoryItem(console *nbt_console.Console, src resources_control.SlotID, dst resources_control.SlotID, count uint8) error {
+	api := console.API()
+
+	success, err := api.ContainerOpenAndClose().OpenInventory()
+	if err != nil {
+		return fmt.Errorf("moveInventoryItem: %v", err)
+	}
+	if !success {
+		return fmt.Errorf("moveInventoryItem: Failed to open the inventory")
+	}
+
+	success, err = api.ItemTransition().TransitionBetweenInventory(
+		[]game_interface.ItemInfoWithSlot{{Slot: src, ItemInfo: game_interface.ItemInfo{Count: count}}},
+		[]game_interface.ItemInfoWithSlot{{Slot: dst, ItemInfo: game_interface.ItemInfo{Count: count}}},
+	)
+	if err != nil {
+		_ = api.ContainerOpenAndClose().CloseContainer()
+		return fmt.Errorf("moveInventoryItem: %v", err)
+	}
+	if !success {
+		_ = api.ContainerOpenAndClose().CloseContainer()
+		return fmt.Errorf("moveInventoryItem: The server rejected the item transition")
+	}
+
+	err = api.ContainerOpenAndClose().CloseContainer()
+	if err != nil {
+		return fmt.Errorf("moveInventoryItem: %v", err)
+	}
+
+	console.UseInventorySlot(nbt_console.RequesterUser, src, false)
+	console.UseInventorySlot(nbt_console.RequesterUser, dst, true)
+	return nil
+}
+
+// stashItems 依次将 items 中的每个物品制作到操作台所使用的快捷栏，
+// 然后将其暂存到背包中从 firstStashSlot 开始的槽位。counts 指示
+// 每个物品的数量，但对于复杂的物品，其数量总是为 1。
+//
+// 制作复杂的物品可能会占用操作台中心，因此需要通过点击
+// 放入方块的物品应当在生成该方块前被全部暂存。另外，制作
+// 含有子方块的物品会清空背包，因此这样的物品只能被单独暂存
+func stashItems(
+	console *nbt_console.Console,
+	cache *nbt_cache.NBTCacheSystem,
+	items []nbt_parser_interface.Item,
+	counts []uint8,
+) (result []stashedItem, err error) {
+	for index, item := range items {
+		count := counts[index]
+		if item.IsComplex() {
+			count = 1
+		}
+
+		hotbarSlot, err := prepareHotbarItem(console, cache, item, count)
+		if err != nil {
+			return nil, fmt.Errorf("stashItems: %v", err)
+		}
+
+		slot := firstStashSlot + resources_control.SlotID(index)
+		err = moveInventoryItem(console, hotbarSlot, slot, count)
+		if err != nil {
+			return nil, fmt.Errorf("stashItems: %v", err)
+		}
+		result = append(result, stashedItem{Slot: slot, Count: count})
+	}
+	return result, nil
+}
+
+// takeStashedItem 将暂存的物品 item 移动到操作台所使用的快捷栏
+func takeStashedItem(console *nbt_console.Console, item stashedItem) error {
+	err := moveInventoryItem(console, item.Slot, console.HotbarSlotID(), item.Count)
+	if err != nil {
+		return fmt.Errorf("takeStashedItem: %v", err)
+	}
+	return nil
+}
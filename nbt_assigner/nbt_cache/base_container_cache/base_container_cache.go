@@ -24,3 +24,8 @@ func NewBaseContainerCache(console *nbt_console.Console) *BaseContainerCache {
 		cachedBaseContainer: make(map[uint64]StructureBaseContainer),
 	}
 }
+
+// CachedCount 返回已缓存的基容器的数量
+func (b *BaseContainerCache) CachedCount() int {
+	return len(b.cachedBaseContainer)
+}
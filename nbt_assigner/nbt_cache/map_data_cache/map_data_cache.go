@@ -0,0 +1,75 @@
+package map_data_cache
+
+import (
+	"image/color"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// MapDataCache 记载了导入时由使用者提供的地图数据。
+// 由于已填充地图的物品数据只包含地图的 UUID，
+// 因此我们需要依赖这些数据才能还原地图上的像素
+type MapDataCache struct {
+	// pixels 是地图 UUID 到地图像素的映射，
+	// 其中像素以 pixels[x][z] 的形式存放
+	pixels map[int64]*[128][128]color.RGBA
+	// hashes 是地图 UUID 到其像素数据的哈希校验和的映射
+	hashes map[int64]uint64
+}
+
+// NewMapDataCache 创建并返回一个新的地图数据缓存
+func NewMapDataCache() *MapDataCache {
+	return &MapDataCache{
+		pixels: make(map[int64]*[128][128]color.RGBA),
+		hashes: make(map[int64]uint64),
+	}
+}
+
+// StoreMapData 记录 UUID 为 mapUUID 的地图的像素数据 pixels。
+// 如果该地图此前已被记录，则旧的数据将被覆盖
+func (m *MapDataCache) StoreMapData(mapUUID int64, pixels [128][128]color.RGBA) {
+	m.pixels[mapUUID] = &pixels
+	m.hashes[mapUUID] = pixelsHash(&pixels)
+}
+
+// pixelsHash 计算地图像素 pixels 的哈希校验和
+func pixelsHash(pixels *[128][128]color.RGBA) uint64 {
+	digest := xxhash.New()
+	buf := make([]byte, 0, 128*4)
+	for x := range pixels {
+		buf = buf[:0]
+		for _, value := range pixels[x] {
+			buf = append(buf, value.R, value.G, value.B, value.A)
+		}
+		_, _ = digest.Write(buf)
+	}
+	return digest.Sum64()
+}
+
+// LoadMapData 加载 UUID 为 mapUUID 的地图的像素数据。
+// 如果该地图的数据没有被提供，则返回的 existed 为假
+func (m *MapDataCache) LoadMapData(mapUUID int64) (pixels [128][128]color.RGBA, existed bool) {
+	result, ok := m.pixels[mapUUID]
+	if !ok {
+		return pixels, false
+	}
+	return *result, true
+}
+
+// MapDataHash 返回 UUID 为 mapUUID 的地图的像素数据的哈希校验和。
+// 如果该地图的数据没有被提供，则返回的 existed 为假
+func (m *MapDataCache) MapDataHash(mapUUID int64) (hash uint64, existed bool) {
+	hash, existed = m.hashes[mapUUID]
+	return
+}
+
+// Count 返回已记录的地图的数量
+func (m *MapDataCache) Count() int {
+	return len(m.pixels)
+}
+
+// CleanMapData 清除已记录的所有地图数据
+func (m *MapDataCache) CleanMapData() {
+	m.pixels = make(map[int64]*[128][128]color.RGBA)
+	m.hashes = make(map[int64]uint64)
+}
@@ -31,3 +31,9 @@ func NewNBTBlockCache(console *nbt_console.Console) *NBTBlockCache {
 		setHashCache:    make(map[uint64]*StructureNBTBlock),
 	}
 }
+
+// CachedCount 返回已缓存的 NBT 方块的数量。
+// setHashCount 是其中可通过集合哈希校验和命中的数量
+func (n *NBTBlockCache) CachedCount() (completelyCount int, setHashCount int) {
+	return len(n.completelyCache), len(n.setHashCache)
+}
@@ -2,6 +2,7 @@ package nbt_cache
 
 import (
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache/base_container_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache/map_data_cache"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache/nbt_block_cache"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
 )
@@ -10,6 +11,7 @@ import (
 type NBTCacheSystem struct {
 	b *base_container_cache.BaseContainerCache
 	n *nbt_block_cache.NBTBlockCache
+	m *map_data_cache.MapDataCache
 }
 
 // NewNBTCacheSystem 基于操作台 console 创建并返回一个新的 NBT 缓存命中系统
@@ -17,6 +19,7 @@ func NewNBTCacheSystem(console *nbt_console.Console) *NBTCacheSystem {
 	return &NBTCacheSystem{
 		b: base_container_cache.NewBaseContainerCache(console),
 		n: nbt_block_cache.NewNBTBlockCache(console),
+		m: map_data_cache.NewMapDataCache(),
 	}
 }
 
@@ -29,3 +32,8 @@ func (n *NBTCacheSystem) BaseContainerCache() *base_container_cache.BaseContaine
 func (n *NBTCacheSystem) NBTBlockCache() *nbt_block_cache.NBTBlockCache {
 	return n.n
 }
+
+// MapDataCache 返回由使用者提供的地图数据的缓存
+func (n *NBTCacheSystem) MapDataCache() *map_data_cache.MapDataCache {
+	return n.m
+}
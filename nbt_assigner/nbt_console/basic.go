@@ -35,6 +35,43 @@ func (c Console) Position() mgl32.Vec3 {
 	}
 }
 
+// Entities 返回机器人已知的、位于操作台区域中的所有实体。
+// 操作台区域是以中心方块为中心的 11*5*11 的区域。
+//
+// 这些实体可能会妨碍操作台的使用，但它们只能在租赁服
+// 发送相应的实体数据后被得知，因此返回的结果可能不完整
+func (c Console) Entities() []resources_control.Entity {
+	return c.api.Resources().Entities().EntitiesInArea(
+		protocol.BlockPos{c.center[0] - 5, c.center[1] - 2, c.center[2] - 5},
+		protocol.BlockPos{c.center[0] + 5, c.center[1] + 2, c.center[2] + 5},
+	)
+}
+
+// EntitiesError 指示操作台区域中存在实体。
+//
+// 它由 NewConsole 和 ChangeConsolePosition 返回。
+// 与其他错误不同，此时操作台已被成功初始化并可以使用，
+// 但这些实体可能会妨碍操作台的使用 (例如覆盖帮助方块)
+type EntitiesError struct {
+	// Entities 是机器人已知的、位于操作台区域中的实体
+	Entities []resources_control.Entity
+}
+
+// Error 实现 error 接口
+func (e *EntitiesError) Error() string {
+	return fmt.Sprintf(
+		"The console area should have no entity, but found %d entities (e.g. %#v at %v)",
+		len(e.Entities), e.Entities[0].EntityType, e.Entities[0].Position,
+	)
+}
+
+// BlockPosition 返回机器人当前所处的方块坐标。
+// 它是 Position 所对应的方块，并且与 UpdatePosition
+// 所设置的坐标相同
+func (c Console) BlockPosition() protocol.BlockPos {
+	return c.position
+}
+
 // UpdatePosition 设置机器人当前所处的坐标
 func (c *Console) UpdatePosition(blockPos protocol.BlockPos) {
 	c.position = blockPos
@@ -68,9 +105,15 @@ func (c *Console) ChangeAndUpdateHotbarSlotID(slotID resources_control.SlotID) e
 //
 // 如果返回了错误，则在下次成功调用此函数前，
 // 操作台都不应该被使用，否则其他操作的结果
-// 将会是未定义的
+// 将会是未定义的。
+//
+// 与 NewConsole 相同，如果返回的错误是 *EntitiesError，
+// 则操作台已被成功移动，只是新位置中存在实体
 func (c *Console) ChangeConsolePosition(dimensionID uint8, center protocol.BlockPos) error {
 	err := c.initConsole(dimensionID, center)
+	if _, ok := err.(*EntitiesError); ok {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("ChangeConsolePosition: %v", err)
 	}
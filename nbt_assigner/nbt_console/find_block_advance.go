@@ -75,6 +75,27 @@ func (c Console) FindLoom(includeCenter bool) (index int, offset protocol.BlockP
 	return 0, protocol.BlockPos{}, nil
 }
 
+// FindCartographyTable 从操作台的帮助方块中寻找一个制图台方块。
+// includeCenter 指示要查找的方块是否也包括操作台中心处的方块。
+//
+// 返回的 index 可用于 BlockByIndex，
+// 而返回的 offset 可用于 BlockByOffset。
+//
+// 如果返回的 block 不为空，则说明找到，
+// 否则没有找到。找到的方块可以通过修改
+// 其指向的值从而将它变成其他方块
+func (c Console) FindCartographyTable(includeCenter bool) (index int, offset protocol.BlockPos, block *block_helper.BlockHelper) {
+	for index, value := range c.helperBlocks {
+		if !includeCenter && index == 0 {
+			continue
+		}
+		if _, ok := (*value).(block_helper.CartographyTableBlockHelper); ok {
+			return index, helperBlockMapping[index], value
+		}
+	}
+	return 0, protocol.BlockPos{}, nil
+}
+
 // FindNonAnvilAndNonLoom 从操作台的帮助方块
 // 中寻找一个既不是铁砧，也不是织布机的方块。
 //
@@ -201,6 +222,9 @@ func (c *Console) FindOrGenerateNewAnvil() (index int, err error) {
 	}
 
 	anvil := block_helper.AnvilBlockHelper{States: states}
+	if err = c.verifyPlacedBlock(index, anvil); err != nil {
+		return 0, fmt.Errorf("FindOrGenerateNewAnvil: %v", err)
+	}
 	c.UseHelperBlock(RequesterSystemCall, index, anvil)
 	if needFloorBlock {
 		var floorBlock block_helper.BlockHelper = block_helper.NearBlock{
@@ -237,7 +261,43 @@ func (c *Console) FindOrGenerateNewLoom() (index int, err error) {
 	if err != nil {
 		return 0, fmt.Errorf("FindOrGenerateNewLoom: %v", err)
 	}
+	if err = c.verifyPlacedBlock(index, loom); err != nil {
+		return 0, fmt.Errorf("FindOrGenerateNewLoom: %v", err)
+	}
 	c.UseHelperBlock(RequesterSystemCall, index, loom)
 
 	return index, nil
 }
+
+// FindOrGenerateNewCartographyTable 寻找操作台的 8 个帮助方块中
+// 是否有一个是制图台。如果没有，则生成一个新的制图台。
+// index 指示找到或生成的制图台在操作台上的索引
+func (c *Console) FindOrGenerateNewCartographyTable() (index int, err error) {
+	var block *block_helper.BlockHelper
+
+	index, _, block = c.FindCartographyTable(false)
+	if block != nil {
+		return
+	}
+
+	index, _, block = c.FindSpaceToPlaceNewBlock(false)
+	if block == nil {
+		panic("FindOrGenerateNewCartographyTable: Should never happened")
+	}
+
+	cartographyTable := block_helper.CartographyTableBlockHelper{}
+	err = c.api.SetBlock().SetBlock(
+		c.BlockPosByIndex(index),
+		cartographyTable.BlockName(),
+		cartographyTable.BlockStatesString(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("FindOrGenerateNewCartographyTable: %v", err)
+	}
+	if err = c.verifyPlacedBlock(index, cartographyTable); err != nil {
+		return 0, fmt.Errorf("FindOrGenerateNewCartographyTable: %v", err)
+	}
+	c.UseHelperBlock(RequesterSystemCall, index, cartographyTable)
+
+	return index, nil
+}
@@ -70,11 +70,20 @@ type Console struct {
 //
 // NewConsole 的调用者有责任确保操作台位于 dimensionID
 // 所指示的维度上，并且以操作台中心方块为中心处的 11*5*11
-// 的区域全为空气且没有任何实体
+// 的区域全为空气且没有任何实体。
+//
+// 在初始化完成后，NewConsole 会等待租赁服同步实体数据，
+// 然后检查上述区域中的实体。如果存在这样的实体，则返回的
+// 错误是 *EntitiesError，此时操作台已被成功初始化，因此
+// result 仍然可用。由于实体数据可能晚于传送的完成到达，
+// 这一检查只是尽力而为的
 func NewConsole(api *game_interface.GameInterface, dimensionID uint8, center protocol.BlockPos) (result *Console, err error) {
 	c := &Console{api: api}
 
 	err = c.initConsole(dimensionID, center)
+	if _, ok := err.(*EntitiesError); ok {
+		return c, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("NewConsole: %v", err)
 	}
@@ -215,5 +224,14 @@ func (c *Console) initConsole(dimensionID uint8, center protocol.BlockPos) error
 		*c.nearBlocks[index][nearBlockMappingInv[[3]int32{0, -1, 0}]] = floorBlock
 	}
 
+	// Make sure there is no entity in the console area
+	err = api.Commands().AwaitChangesGeneral()
+	if err != nil {
+		return fmt.Errorf("initConsole: %v", err)
+	}
+	if entities := c.Entities(); len(entities) > 0 {
+		return &EntitiesError{Entities: entities}
+	}
+
 	return nil
 }
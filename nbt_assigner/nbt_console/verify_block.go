@@ -0,0 +1,75 @@
+package nbt_console
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/block_helper"
+
+	"github.com/TriM-Organization/bedrock-world-operator/block"
+)
+
+// VerifyBlock 通过客户端的世界镜像检查 pos 处的方块
+// 是否是名为 name 且方块状态为 states 的方块。
+//
+// 如果世界镜像尚未加载 pos 处的方块，或操作台不在
+// 机器人所在的维度，则 known 为假。此时调用者应当
+// 回退到使用命令的方式进行验证
+func (c Console) VerifyBlock(pos protocol.BlockPos, name string, states map[string]any) (matched bool, known bool) {
+	world := c.api.Resources().World()
+	if world.Dimension() != int32(c.dimension) {
+		return false, false
+	}
+
+	actual, found := world.BlockRuntimeIDAt(pos)
+	if !found {
+		return false, false
+	}
+	expected, found := block.StateToRuntimeID(name, states)
+	if !found {
+		return false, true
+	}
+
+	return actual == expected, true
+}
+
+// VerifyBlockByIndex 检查操作台上第 index 个方块是否是名为
+// name 且方块状态为 states 的方块。index 的含义与 BlockPosByIndex
+// 的相同，而返回值的含义与 VerifyBlock 的相同
+func (c Console) VerifyBlockByIndex(index int, name string, states map[string]any) (matched bool, known bool) {
+	return c.VerifyBlock(c.BlockPosByIndex(index), name, states)
+}
+
+// VerifyBlockByOffset 检查操作台上相对于中心的偏移量为 offset 的方块
+// 是否是名为 name 且方块状态为 states 的方块。返回值的含义与 VerifyBlock
+// 的相同
+func (c Console) VerifyBlockByOffset(offset protocol.BlockPos, name string, states map[string]any) (matched bool, known bool) {
+	return c.VerifyBlock(c.BlockPosByOffset(offset), name, states)
+}
+
+// verifyPlacedBlock 通过世界镜像确认操作台上第 index 个方块
+// 已被放置为 helper 所描述的方块。由于方块更新可能晚于命令
+// 的响应到达，因此在首次检查不通过时，会等待租赁服完成已有
+// 的更改后再检查一次。
+//
+// 如果世界镜像无法得知该方块，则认为其已被正确放置
+func (c *Console) verifyPlacedBlock(index int, helper block_helper.BlockHelper) error {
+	matched, known := c.VerifyBlockByIndex(index, helper.BlockName(), helper.BlockStates())
+	if !known || matched {
+		return nil
+	}
+
+	err := c.api.Commands().AwaitChangesGeneral()
+	if err != nil {
+		return fmt.Errorf("verifyPlacedBlock: %v", err)
+	}
+
+	matched, known = c.VerifyBlockByIndex(index, helper.BlockName(), helper.BlockStates())
+	if known && !matched {
+		return fmt.Errorf(
+			"verifyPlacedBlock: Block at %v is not %s%s",
+			c.BlockPosByIndex(index), helper.BlockName(), helper.BlockStatesString(),
+		)
+	}
+	return nil
+}
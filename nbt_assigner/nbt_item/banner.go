@@ -338,13 +338,10 @@ func (b *Banner) makeNormal(
 	}
 
 	// Commit changes
-	success, _, _, err = transaction.Commit()
+	_, _, err = transaction.CommitWithRollback(api.ItemStackOperation().Restorer())
 	if err != nil {
 		return nil, fmt.Errorf("makeNormal: %v", err)
 	}
-	if !success {
-		return nil, fmt.Errorf("makeNormal: Looming operation rejected by the server")
-	}
 
 	// Check hash only
 	for idx, index := range bannerToMake {
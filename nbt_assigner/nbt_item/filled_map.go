@@ -0,0 +1,430 @@
+package nbt_item
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/resources_control"
+	"github.com/mcpol-studio/flowers-for-machines/map_art"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_cache"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_hash "github.com/mcpol-studio/flowers-for-machines/nbt_parser/hash"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+	nbt_parser_item "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+)
+
+const (
+	// FilledMapArtOffsetY 是绘制地图画时，
+	// 草稿区域的最低点相对于操作台中心的高度
+	FilledMapArtOffsetY = 8
+	// FilledMapMaxPosY 是绘制地图画时可以使用的最高 Y 坐标
+	FilledMapMaxPosY = 319
+	// FilledMapRenderDuration 是机器人手持地图后，
+	// 在草稿区域的每个象限中停留以等待地图渲染的时间
+	FilledMapRenderDuration = time.Second * 2
+	// FilledMapMaxPendingFills 是绘制地图画时，
+	// 最多同时等待结果的 fill 命令的数量
+	FilledMapMaxPendingFills = 64
+)
+
+// 已填充地图
+type FilledMap struct {
+	api   *nbt_console.Console
+	cache *nbt_cache.NBTCacheSystem
+	items []nbt_parser_item.FilledMap
+}
+
+// mapArtArea 描述用于绘制地图画的草稿区域
+type mapArtArea struct {
+	// importPos 是地图画结构的起始坐标
+	importPos protocol.BlockPos
+	// blockMatrix 是地图画结构的方块矩阵
+	blockMatrix [128][129]map_art.SingleMapBlock
+	// minPosY 和 maxPosY 是地图画结构的最低和最高 Y 坐标
+	minPosY int32
+	maxPosY int32
+}
+
+// quadrant 返回草稿区域的第 index 个象限。
+// startX 和 endX 是该象限在 blockMatrix 第一维上的范围，
+// startZ 和 endZ 是该象限在 blockMatrix 第二维上的范围，
+// 而 center 是机器人为加载该象限的区块所应前往的位置
+func (m mapArtArea) quadrant(index int) (startX, endX, startZ, endZ int, center protocol.BlockPos) {
+	startX, endX = (index%2)*64, (index%2)*64+64
+	startZ, endZ = 0, 65
+	if index/2 == 1 {
+		startZ, endZ = 65, 129
+	}
+	center = protocol.BlockPos{
+		m.importPos[0] + int32(startX) + 32,
+		m.maxPosY + 2,
+		m.importPos[2] + int32(startZ) + 32,
+	}
+	return
+}
+
+func (f *FilledMap) Append(item ...nbt_parser_interface.Item) {
+	for _, value := range item {
+		val, ok := value.(*nbt_parser_item.FilledMap)
+		if !ok {
+			continue
+		}
+		f.items = append(f.items, *val)
+	}
+}
+
+// teleport 将机器人传送到 pos 处并同步操作台记录的机器人坐标
+func (f *FilledMap) teleport(pos protocol.BlockPos) error {
+	api := f.api.API()
+
+	err := api.Commands().SendSettingsCommand(
+		fmt.Sprintf("execute in overworld run tp %d %d %d", pos[0], pos[1], pos[2]),
+		true,
+	)
+	if err != nil {
+		return fmt.Errorf("teleport: %v", err)
+	}
+	err = api.Commands().AwaitChangesGeneral()
+	if err != nil {
+		return fmt.Errorf("teleport: %v", err)
+	}
+
+	f.api.UpdatePosition(pos)
+	return nil
+}
+
+// generateArea 根据地图像素 pixels 计算用于绘制地图画的草稿区域。
+// 草稿区域位于操作台上方，并与操作台所在的地图网格对齐，
+// 这使得在该区域中心新建的地图恰好覆盖整个地图画
+func (f *FilledMap) generateArea(pixels [128][128]color.RGBA) (area mapArtArea, err error) {
+	center := f.api.Center()
+	gridX := (center[0]+64)>>7<<7 - 64
+	gridZ := (center[2]+64)>>7<<7 - 64
+
+	_, blockMatrix := map_art.GenerateMapArtStructure([3]int32{gridX, 0, gridZ}, pixels)
+	height := int32(0)
+	for x := range 128 {
+		for z := range 129 {
+			height = max(height, int32(blockMatrix[x][z].PosY))
+		}
+	}
+
+	baseY := min(center[1]+FilledMapArtOffsetY, FilledMapMaxPosY-height)
+	if baseY <= center[1]+2 {
+		return area, fmt.Errorf("generateArea: The map art is too high (height = %d) to draw above the console", height)
+	}
+
+	importPos, blockMatrix := map_art.GenerateMapArtStructure([3]int32{gridX, baseY, gridZ}, pixels)
+	return mapArtArea{
+		importPos:   importPos,
+		blockMatrix: blockMatrix,
+		minPosY:     baseY,
+		maxPosY:     baseY + height,
+	}, nil
+}
+
+// drawArea 在草稿区域绘制地图画。
+// 对于每一行中连续的相同方块，我们使用 fill 命令放置它们。
+//
+// 每个象限中的 fill 命令被并发发送，并且在前往下一个象限前
+// 等待它们全部返回。如果有方块未能被放置，则返回错误
+func (f *FilledMap) drawArea(area mapArtArea) error {
+	for index := range 4 {
+		startX, endX, startZ, endZ, center := area.quadrant(index)
+		err := f.teleport(center)
+		if err != nil {
+			return fmt.Errorf("drawArea: %v", err)
+		}
+
+		requests := make([]string, 0)
+		for x := startX; x < endX; x++ {
+			row := area.blockMatrix[x]
+			from := startZ
+
+			for z := startZ + 1; z <= endZ; z++ {
+				if z < endZ && row[z] == row[from] {
+					continue
+				}
+				requests = append(requests, fmt.Sprintf(
+					"fill %d %d %d %d %d %d %s",
+					area.importPos[0]+int32(x), row[from].PosY, area.importPos[2]+int32(from),
+					area.importPos[0]+int32(x), row[from].PosY, area.importPos[2]+int32(z-1),
+					row[from].BlockMode.BlockName,
+				))
+				from = z
+			}
+		}
+
+		err = f.fillAll(requests)
+		if err != nil {
+			return fmt.Errorf("drawArea: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// fillAll 并发地发送 requests 中的每个 fill 命令，
+// 并等待它们全部返回。已被填充的区域会使 fill 命令
+// 因没有方块被更改而失败，但这不被视为错误
+func (f *FilledMap) fillAll(requests []string) error {
+	api := f.api.API()
+
+	mu := new(sync.Mutex)
+	waiter := new(sync.WaitGroup)
+	pending := make(chan struct{}, FilledMapMaxPendingFills)
+	failed := make([]string, 0)
+	var firstErr error
+
+	for _, request := range requests {
+		pending <- struct{}{}
+		waiter.Add(1)
+		go func() {
+			defer func() {
+				<-pending
+				waiter.Done()
+			}()
+
+			resp, err := api.Commands().SendWSCommandWithResp(request)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				if firstErr == nil {
+					firstErr = err
+				}
+			case resp.SuccessCount == 0 && !slices.ContainsFunc(resp.OutputMessages, func(message protocol.CommandOutputMessage) bool {
+				return message.Message == "commands.fill.failed"
+			}):
+				failed = append(failed, request)
+			}
+		}()
+	}
+	waiter.Wait()
+
+	if firstErr != nil {
+		return fmt.Errorf("fillAll: %v", firstErr)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("fillAll: %d fill command(s) failed, and the first one is %#v", len(failed), failed[0])
+	}
+	return nil
+}
+
+// cleanArea 将草稿区域重新置为空气
+func (f *FilledMap) cleanArea(area mapArtArea) error {
+	api := f.api.API()
+
+	for index := range 4 {
+		startX, endX, startZ, endZ, center := area.quadrant(index)
+		err := f.teleport(center)
+		if err != nil {
+			return fmt.Errorf("cleanArea: %v", err)
+		}
+
+		for x := startX; x < endX; x++ {
+			err = api.Commands().SendSettingsCommand(
+				fmt.Sprintf(
+					"fill %d %d %d %d %d %d air",
+					area.importPos[0]+int32(x), area.minPosY, area.importPos[2]+int32(startZ),
+					area.importPos[0]+int32(x), area.maxPosY, area.importPos[2]+int32(endZ-1),
+				),
+				false,
+			)
+			if err != nil {
+				return fmt.Errorf("cleanArea: %v", err)
+			}
+		}
+
+		err = api.Commands().AwaitChangesGeneral()
+		if err != nil {
+			return fmt.Errorf("cleanArea: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// renderMap 使用快捷栏 slotID 处的空地图创建一张新地图，
+// 然后手持该地图经过草稿区域的每个象限以渲染地图画
+func (f *FilledMap) renderMap(area mapArtArea, slotID resources_control.SlotID) error {
+	api := f.api.API()
+	mapCenter := protocol.BlockPos{
+		area.importPos[0] + 64,
+		area.maxPosY + 2,
+		area.importPos[2] + 65,
+	}
+
+	err := f.teleport(mapCenter)
+	if err != nil {
+		return fmt.Errorf("renderMap: %v", err)
+	}
+	err = api.BotClick().ClickAir(slotID, f.api.Position())
+	if err != nil {
+		return fmt.Errorf("renderMap: %v", err)
+	}
+	err = api.Commands().AwaitChangesGeneral()
+	if err != nil {
+		return fmt.Errorf("renderMap: %v", err)
+	}
+
+	item, inventoryExisted := api.Resources().Inventories().GetItemStack(0, slotID)
+	if !inventoryExisted {
+		return fmt.Errorf("renderMap: Inventory of the bot is not found")
+	}
+	if item.Stack.NetworkID != int32(api.Resources().ConstantPacket().ItemByName("minecraft:filled_map").RuntimeID) {
+		return fmt.Errorf("renderMap: Failed to create a new map from the empty map")
+	}
+
+	for index := range 4 {
+		_, _, _, _, center := area.quadrant(index)
+		err = f.teleport(center)
+		if err != nil {
+			return fmt.Errorf("renderMap: %v", err)
+		}
+		time.Sleep(FilledMapRenderDuration)
+	}
+
+	return nil
+}
+
+// lockMap 使用制图台锁定快捷栏 mapSlot 处的地图，
+// 并消耗快捷栏 glassPaneSlot 处的玻璃板
+func (f *FilledMap) lockMap(mapSlot resources_control.SlotID, glassPaneSlot resources_control.SlotID) error {
+	api := f.api.API()
+
+	err := f.api.CanReachOrMove(f.api.Center())
+	if err != nil {
+		return fmt.Errorf("lockMap: %v", err)
+	}
+
+	index, err := f.api.FindOrGenerateNewCartographyTable()
+	if err != nil {
+		return fmt.Errorf("lockMap: %v", err)
+	}
+
+	success, err := f.api.OpenContainerByIndex(index)
+	if err != nil {
+		return fmt.Errorf("lockMap: %v", err)
+	}
+	if !success {
+		return fmt.Errorf("lockMap: Failed to open the cartography table who at %#v", f.api.BlockPosByIndex(index))
+	}
+
+	success, _, _, err = api.ItemStackOperation().OpenTransaction().
+		MapLockingFromInventory(mapSlot, glassPaneSlot, resources_control.ExpectedNewItem{}).
+		Commit()
+	if err != nil {
+		_ = api.ContainerOpenAndClose().CloseContainer()
+		return fmt.Errorf("lockMap: %v", err)
+	}
+	if !success {
+		_ = api.ContainerOpenAndClose().CloseContainer()
+		return fmt.Errorf("lockMap: The server rejected the map locking operation")
+	}
+	f.api.UseInventorySlot(nbt_console.RequesterUser, glassPaneSlot, false)
+
+	err = api.ContainerOpenAndClose().CloseContainer()
+	if err != nil {
+		return fmt.Errorf("lockMap: %v", err)
+	}
+	return nil
+}
+
+// replaceitem 将 count 个 name 物品放置在快捷栏 slotID 处
+func (f *FilledMap) replaceitem(name string, metadata int16, slotID resources_control.SlotID) error {
+	err := f.api.API().Replaceitem().ReplaceitemInInventory(
+		"@s",
+		game_interface.ReplacePathHotbarOnly,
+		game_interface.ReplaceitemInfo{
+			Name:     name,
+			Count:    1,
+			MetaData: metadata,
+			Slot:     slotID,
+		},
+		"",
+		true,
+	)
+	if err != nil {
+		return fmt.Errorf("replaceitem: %v", err)
+	}
+	f.api.UseInventorySlot(nbt_console.RequesterUser, slotID, true)
+	return nil
+}
+
+// Make 制作一个已填充地图。
+//
+// 如果地图的数据已由使用者提供，则我们将在操作台上方的草稿区域中
+// 使用地图画还原地图的像素，然后创建一张新地图并在制图台中锁定它。
+// 否则，该地图将被简单的导入为一张空白的已填充地图。
+//
+// 由于地图画的绘制是独占草稿区域的，因此每次调用 Make 只会制作一个地图
+func (f *FilledMap) Make() (resultSlot map[uint64]resources_control.SlotID, err error) {
+	const mapSlot, glassPaneSlot = resources_control.SlotID(0), resources_control.SlotID(1)
+
+	if len(f.items) == 0 {
+		return nil, nil
+	}
+	item := f.items[0]
+	f.items = f.items[1:]
+	resultSlot = map[uint64]resources_control.SlotID{
+		nbt_hash.NBTItemNBTHash(&item): mapSlot,
+	}
+
+	pixels, existed := f.cache.MapDataCache().LoadMapData(item.NBT.MapUUID)
+	if !existed {
+		err = f.replaceitem(item.ItemName(), item.ItemMetadata(), mapSlot)
+		if err != nil {
+			return nil, fmt.Errorf("Make: %v", err)
+		}
+		return resultSlot, nil
+	}
+
+	area, err := f.generateArea(pixels)
+	if err != nil {
+		return nil, fmt.Errorf("Make: %v", err)
+	}
+
+	err = f.replaceitem("minecraft:empty_map", 0, mapSlot)
+	if err != nil {
+		return nil, fmt.Errorf("Make: %v", err)
+	}
+	err = f.replaceitem("minecraft:glass_pane", 0, glassPaneSlot)
+	if err != nil {
+		return nil, fmt.Errorf("Make: %v", err)
+	}
+	if f.api.HotbarSlotID() != mapSlot {
+		err = f.api.ChangeAndUpdateHotbarSlotID(mapSlot)
+		if err != nil {
+			return nil, fmt.Errorf("Make: %v", err)
+		}
+	}
+
+	err = f.drawArea(area)
+	if err != nil {
+		return nil, fmt.Errorf("Make: %v", err)
+	}
+	err = f.renderMap(area, mapSlot)
+	if err != nil {
+		return nil, fmt.Errorf("Make: %v", err)
+	}
+	err = f.lockMap(mapSlot, glassPaneSlot)
+	if err != nil {
+		return nil, fmt.Errorf("Make: %v", err)
+	}
+	err = f.cleanArea(area)
+	if err != nil {
+		return nil, fmt.Errorf("Make: %v", err)
+	}
+
+	err = f.api.CanReachOrMove(f.api.Center())
+	if err != nil {
+		return nil, fmt.Errorf("Make: %v", err)
+	}
+	return resultSlot, nil
+}
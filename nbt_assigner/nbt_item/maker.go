@@ -12,6 +12,7 @@ import (
 )
 
 func init() {
+	nbt_assigner_interface.NBTItemIsSupported = NBTItemIsSupported
 	nbt_assigner_interface.MakeNBTItemMethod = MakeNBTItemMethod
 	nbt_assigner_interface.EnchMultiple = EnchMultiple
 	nbt_assigner_interface.RenameMultiple = RenameMultiple
@@ -24,6 +25,7 @@ func NBTItemIsSupported(item nbt_parser_interface.Item) bool {
 	case *nbt_parser_item.Book:
 	case *nbt_parser_item.Banner:
 	case *nbt_parser_item.Shield:
+	case *nbt_parser_item.FilledMap:
 	default:
 		return false
 	}
@@ -45,6 +47,7 @@ func MakeNBTItemMethod(
 	books := make([]nbt_parser_interface.Item, 0)
 	banners := make([]nbt_parser_interface.Item, 0)
 	shields := make([]nbt_parser_interface.Item, 0)
+	filledMaps := make([]nbt_parser_interface.Item, 0)
 
 	for _, item := range multipleItems {
 		switch item.(type) {
@@ -54,6 +57,8 @@ func MakeNBTItemMethod(
 			banners = append(banners, item)
 		case *nbt_parser_item.Shield:
 			shields = append(shields, item)
+		case *nbt_parser_item.FilledMap:
+			filledMaps = append(filledMaps, item)
 		}
 	}
 
@@ -76,6 +81,11 @@ func MakeNBTItemMethod(
 		element.Append(shields...)
 		result = append(result, element)
 	}
+	if len(filledMaps) > 0 {
+		element := &FilledMap{api: console, cache: cache}
+		element.Append(filledMaps...)
+		result = append(result, element)
+	}
 
 	return result
 }
@@ -42,6 +42,16 @@ func DeepCopyAndFixStates(blockType uint8, blockName string, blockStates map[str
 	case mapping.SupportNBTBlockTypeCrafter:
 		result["crafting"] = byte(0)
 		result["triggered_bit"] = byte(0)
+	case mapping.SupportNBTBlockTypeCampfire:
+		// 营火需要通过点击放入物品，
+		// 因此其方块状态必须是完整的。
+		// 旧版的 direction 已在升级方块时被转换
+		if _, ok := result["minecraft:cardinal_direction"].(string); !ok {
+			result["minecraft:cardinal_direction"] = "south"
+		}
+		if _, ok := result["extinguished"].(byte); !ok {
+			result["extinguished"] = byte(0)
+		}
 	}
 
 	return result
@@ -0,0 +1,117 @@
+package nbt_parser_block
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+)
+
+// CampfireSlotCount 是营火的槽位数
+const CampfireSlotCount = 4
+
+// CampfireNBT ..
+type CampfireNBT struct {
+	Items []ItemWithSlot
+}
+
+// 营火 或 灵魂营火。
+//
+// 物品的 Slot 是其在营火中的原始槽位 (从 0 开始)。
+// 由于通过点击放入的物品总是占据营火的第一个空槽位，
+// 前面存在空槽位的物品在导入后将被前移
+type Campfire struct {
+	DefaultBlock
+	NBT CampfireNBT
+}
+
+func (c Campfire) NeedSpecialHandle() bool {
+	return len(c.NBT.Items) > 0
+}
+
+func (Campfire) NeedCheckCompletely() bool {
+	return true
+}
+
+// UnreproducibleFields 返回营火中无法被还原的数据的字段名。
+// 如果某个物品之前存在空槽位，则该物品在导入后会被放入更靠前
+// 的槽位，因此其原始槽位无法被还原
+func (c Campfire) UnreproducibleFields() []string {
+	result := make([]string, 0)
+	for index, value := range c.NBT.Items {
+		if int(value.Slot) != index {
+			result = append(result, fmt.Sprintf("Item%d", value.Slot+1))
+		}
+	}
+	return result
+}
+
+func (c Campfire) formatNBT(prefix string) string {
+	result := prefix + "烹饪物品: \n"
+	for _, value := range c.NBT.Items {
+		result += value.Format(prefix + "\t")
+	}
+	if fields := c.UnreproducibleFields(); len(fields) > 0 {
+		result += prefix + fmt.Sprintf("无法还原的字段: %s\n", strings.Join(fields, ", "))
+	}
+	return result
+}
+
+func (c *Campfire) Format(prefix string) string {
+	result := c.DefaultBlock.Format(prefix)
+	if c.NeedSpecialHandle() {
+		result += prefix + "附加数据: \n"
+		result += c.formatNBT(prefix + "\t")
+	}
+	return result
+}
+
+func (c *Campfire) Parse(nbtMap map[string]any) error {
+	for index := range CampfireSlotCount {
+		itemMap, ok := nbtMap[fmt.Sprintf("Item%d", index+1)].(map[string]any)
+		if !ok {
+			continue
+		}
+		if count, _ := itemMap["Count"].(byte); count == 0 {
+			continue
+		}
+
+		item, canGetByCommand, err := nbt_parser_interface.ParseItemNormal(c.NameChecker, itemMap)
+		if err != nil {
+			return fmt.Errorf("Parse: %v", err)
+		}
+		if !canGetByCommand || !mapping.CampfireCookableItems[item.ItemName()] {
+			continue
+		}
+
+		c.NBT.Items = append(c.NBT.Items, ItemWithSlot{
+			Item: item,
+			Slot: uint8(index),
+		})
+	}
+	return nil
+}
+
+func (c Campfire) NBTStableBytes() []byte {
+	buf := bytes.NewBuffer(nil)
+	w := protocol.NewWriter(buf, 0)
+
+	itemCount := uint8(len(c.NBT.Items))
+	w.Uint8(&itemCount)
+	// 物品的原始槽位不被包含在内，只保留物品的顺序，
+	// 否则导入后被前移的物品将使完整性检查失败。
+	// 被前移的物品已通过 UnreproducibleFields 报告
+	for _, value := range c.NBT.Items {
+		itemStableBytes := value.Item.TypeStableBytes()
+		w.ByteSlice(&itemStableBytes)
+	}
+
+	return buf.Bytes()
+}
+
+func (c *Campfire) FullStableBytes() []byte {
+	return append(c.DefaultBlock.FullStableBytes(), c.NBTStableBytes()...)
+}
@@ -0,0 +1,91 @@
+package nbt_parser_block_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	nbt_hash "github.com/mcpol-studio/flowers-for-machines/nbt_parser/hash"
+
+	_ "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+)
+
+// parseCampfire 解析在 slots 处各放有一个牛肉的营火。
+// slots 中的槽位从 0 开始
+func parseCampfire(t *testing.T, slots ...int) *nbt_parser_block.Campfire {
+	blockNBT := map[string]any{"id": "Campfire"}
+	for _, slot := range slots {
+		blockNBT[fmt.Sprintf("Item%d", slot+1)] = map[string]any{
+			"Name":   "minecraft:beef",
+			"Count":  byte(1),
+			"Damage": int16(0),
+		}
+	}
+
+	block, err := nbt_parser_block.ParseBlock(
+		nil,
+		"minecraft:campfire",
+		map[string]any{"minecraft:cardinal_direction": "north", "extinguished": byte(0)},
+		blockNBT,
+	)
+	if err != nil {
+		t.Fatalf("parseCampfire: %v", err)
+	}
+	campfire, ok := block.(*nbt_parser_block.Campfire)
+	if !ok {
+		t.Fatalf("parseCampfire: Expected a campfire, but got %#v", block)
+	}
+	return campfire
+}
+
+func TestCampfireGap(t *testing.T) {
+	tests := []struct {
+		slots          []int
+		compacted      []int
+		unreproducible []string
+	}{
+		{[]int{1}, []int{0}, []string{"Item2"}},
+		{[]int{0, 2}, []int{0, 1}, []string{"Item3"}},
+		{[]int{0, 1}, []int{0, 1}, []string{}},
+	}
+
+	for _, test := range tests {
+		campfire := parseCampfire(t, test.slots...)
+		// 导入后的营火中的物品被前移，
+		// 但它仍然应当通过完整性检查
+		imported := parseCampfire(t, test.compacted...)
+		if nbt_hash.NBTBlockFullHash(campfire) != nbt_hash.NBTBlockFullHash(imported) {
+			t.Errorf("TestCampfireGap: Campfire with items in %v does not match the imported one", test.slots)
+		}
+		if fields := campfire.UnreproducibleFields(); !reflect.DeepEqual(fields, test.unreproducible) {
+			t.Errorf("TestCampfireGap: Expected unreproducible fields %v of %v, but got %v", test.unreproducible, test.slots, fields)
+		}
+	}
+
+	if nbt_hash.NBTBlockFullHash(parseCampfire(t, 0)) == nbt_hash.NBTBlockFullHash(parseCampfire(t, 0, 1)) {
+		t.Errorf("TestCampfireGap: Campfires with different items have the same hash")
+	}
+}
+
+func TestCampfireStates(t *testing.T) {
+	block, err := nbt_parser_block.ParseBlock(nil, "minecraft:soul_campfire", map[string]any{}, map[string]any{"id": "Campfire"})
+	if err != nil {
+		t.Fatalf("TestCampfireStates: %v", err)
+	}
+	states := block.BlockStates()
+	if _, found := states["direction"]; found {
+		t.Errorf("TestCampfireStates: Unexpected legacy state in %#v", states)
+	}
+	if states["minecraft:cardinal_direction"] != "south" || states["extinguished"] != byte(0) {
+		t.Errorf("TestCampfireStates: Missing states are not filled in %#v", states)
+	}
+
+	block, err = nbt_parser_block.ParseBlock(nil, "minecraft:campfire", map[string]any{"direction": int32(1)}, map[string]any{"id": "Campfire"})
+	if err != nil {
+		t.Fatalf("TestCampfireStates: %v", err)
+	}
+	if states := block.BlockStates(); states["minecraft:cardinal_direction"] != "west" {
+		t.Errorf("TestCampfireStates: Legacy direction is not upgraded in %#v", states)
+	}
+}
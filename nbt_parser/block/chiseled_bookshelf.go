@@ -0,0 +1,118 @@
+package nbt_parser_block
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+)
+
+// ChiseledBookshelfSlotCount 是雕纹书架的槽位数
+const ChiseledBookshelfSlotCount = 6
+
+// ChiseledBookshelfNBT ..
+type ChiseledBookshelfNBT struct {
+	Items []ItemWithSlot
+}
+
+// 雕纹书架。
+//
+// 雕纹书架的槽位从书架正面看去，
+// 按照先上后下，从左到右的顺序排列。
+// 其 books_stored 方块状态总是与
+// 书架中实际存放的书相符
+type ChiseledBookshelf struct {
+	DefaultBlock
+	NBT ChiseledBookshelfNBT
+}
+
+func (c ChiseledBookshelf) NeedSpecialHandle() bool {
+	return len(c.NBT.Items) > 0
+}
+
+func (ChiseledBookshelf) NeedCheckCompletely() bool {
+	return true
+}
+
+func (c ChiseledBookshelf) formatNBT(prefix string) string {
+	result := prefix + "书籍数据: \n"
+	for _, value := range c.NBT.Items {
+		result += value.Format(prefix + "\t")
+	}
+	return result
+}
+
+func (c *ChiseledBookshelf) Format(prefix string) string {
+	result := c.DefaultBlock.Format(prefix)
+	if c.NeedSpecialHandle() {
+		result += prefix + "附加数据: \n"
+		result += c.formatNBT(prefix + "\t")
+	}
+	return result
+}
+
+func (c *ChiseledBookshelf) Parse(nbtMap map[string]any) error {
+	var booksStored int32
+
+	itemList, _ := nbtMap["Items"].([]any)
+	for index, value := range itemList {
+		itemMap, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		slotID := uint8(index)
+		if slot, ok := itemMap["Slot"].(byte); ok {
+			slotID = slot
+		}
+		if slotID >= ChiseledBookshelfSlotCount {
+			continue
+		}
+		if count, _ := itemMap["Count"].(byte); count == 0 {
+			continue
+		}
+
+		item, canGetByCommand, err := nbt_parser_interface.ParseItemNormal(c.NameChecker, itemMap)
+		if err != nil {
+			return fmt.Errorf("Parse: %v", err)
+		}
+		if !canGetByCommand {
+			continue
+		}
+
+		c.NBT.Items = append(c.NBT.Items, ItemWithSlot{
+			Item: item,
+			Slot: slotID,
+		})
+		booksStored |= 1 << slotID
+	}
+
+	c.States["books_stored"] = booksStored
+	return nil
+}
+
+func (c ChiseledBookshelf) NBTStableBytes() []byte {
+	buf := bytes.NewBuffer(nil)
+	w := protocol.NewWriter(buf, 0)
+
+	items := make([]*ItemWithSlot, ChiseledBookshelfSlotCount)
+	for index, value := range c.NBT.Items {
+		items[value.Slot] = &c.NBT.Items[index]
+	}
+
+	for _, value := range items {
+		haveItem := (value != nil)
+		w.Bool(&haveItem)
+		if haveItem {
+			itemStableBytes := value.Item.TypeStableBytes()
+			w.ByteSlice(&itemStableBytes)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func (c *ChiseledBookshelf) FullStableBytes() []byte {
+	return append(c.DefaultBlock.FullStableBytes(), c.NBTStableBytes()...)
+}
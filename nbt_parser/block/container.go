@@ -66,6 +66,13 @@ func (c *Container) NeedSpecialHandle() bool {
 }
 
 func (c Container) NeedCheckCompletely() bool {
+	// 还原后的已填充地图总是具有新的地图 UUID，
+	// 因此我们无法通过比对哈希校验其完整性
+	for _, value := range c.NBT.Items {
+		if value.Item.ItemName() == "minecraft:filled_map" && value.Item.IsComplex() {
+			return false
+		}
+	}
 	return true
 }
 
@@ -0,0 +1,129 @@
+package nbt_parser_block
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+)
+
+// DecoratedPotNBT ..
+type DecoratedPotNBT struct {
+	Sherds   []string
+	HaveItem bool
+	Item     nbt_parser_interface.Item
+}
+
+// 饰纹陶罐。
+//
+// 陶罐的纹饰陶片只能通过合成获得，
+// 而目前的合成操作仅支持背包中的合成栏，
+// 因此纹饰陶片无法被还原，且不参与比较。
+// 非默认的纹饰陶片会通过 UnreproducibleFields 报告
+type DecoratedPot struct {
+	DefaultBlock
+	NBT DecoratedPotNBT
+}
+
+func (d DecoratedPot) NeedSpecialHandle() bool {
+	return d.NBT.HaveItem
+}
+
+func (d DecoratedPot) NeedCheckCompletely() bool {
+	// 复杂的物品只能被放入一个，
+	// 因此无法校验多个复杂物品的完整性
+	if d.NBT.HaveItem && d.NBT.Item.IsComplex() && d.NBT.Item.ItemCount() > 1 {
+		return false
+	}
+	return true
+}
+
+// UnreproducibleFields 返回陶罐中无法被还原的数据的字段名。
+// 如果陶罐的某一面不是默认的红砖，则 sherds 会被返回。
+// 如果陶罐中有多个复杂的物品，则只有一个能被放入，
+// 此时物品的数量 item.Count 会被返回
+func (d DecoratedPot) UnreproducibleFields() []string {
+	result := []string{}
+	for _, sherd := range d.NBT.Sherds {
+		if sherd != "" && sherd != "minecraft:brick" {
+			result = append(result, "sherds")
+			break
+		}
+	}
+	if d.NBT.HaveItem && d.NBT.Item.IsComplex() && d.NBT.Item.ItemCount() > 1 {
+		result = append(result, "item.Count")
+	}
+	return result
+}
+
+func (d DecoratedPot) formatNBT(prefix string) string {
+	result := ""
+
+	if len(d.NBT.Sherds) > 0 {
+		result += prefix + fmt.Sprintf("纹饰陶片: %s\n", strings.Join(d.NBT.Sherds, ", "))
+	}
+	if fields := d.UnreproducibleFields(); len(fields) > 0 {
+		result += prefix + fmt.Sprintf("无法还原的字段: %s\n", strings.Join(fields, ", "))
+	}
+	if d.NBT.HaveItem {
+		result += prefix + "物品数据: \n"
+		result += d.NBT.Item.Format(prefix + "\t")
+	}
+
+	return result
+}
+
+func (d *DecoratedPot) Format(prefix string) string {
+	result := d.DefaultBlock.Format(prefix)
+	if d.NeedSpecialHandle() || len(d.NBT.Sherds) > 0 {
+		result += prefix + "附加数据: \n"
+		result += d.formatNBT(prefix + "\t")
+	}
+	return result
+}
+
+func (d *DecoratedPot) Parse(nbtMap map[string]any) error {
+	sherds, _ := nbtMap["sherds"].([]any)
+	for _, value := range sherds {
+		if sherd, ok := value.(string); ok {
+			d.NBT.Sherds = append(d.NBT.Sherds, sherd)
+		}
+	}
+
+	itemMap, ok := nbtMap["item"].(map[string]any)
+	if ok {
+		count, _ := itemMap["Count"].(byte)
+		if count == 0 {
+			return nil
+		}
+		item, canGetByCommand, err := nbt_parser_interface.ParseItemNormal(d.NameChecker, itemMap)
+		if err != nil {
+			return fmt.Errorf("Parse: %v", err)
+		}
+		if canGetByCommand {
+			d.NBT.HaveItem = true
+			d.NBT.Item = item
+		}
+	}
+
+	return nil
+}
+
+func (d DecoratedPot) NBTStableBytes() []byte {
+	buf := bytes.NewBuffer(nil)
+	w := protocol.NewWriter(buf, 0)
+
+	w.Bool(&d.NBT.HaveItem)
+	if d.NBT.HaveItem {
+		itemStableBytes := d.NBT.Item.FullStableBytes()
+		w.ByteSlice(&itemStableBytes)
+	}
+
+	return buf.Bytes()
+}
+
+func (d *DecoratedPot) FullStableBytes() []byte {
+	return append(d.DefaultBlock.FullStableBytes(), d.NBTStableBytes()...)
+}
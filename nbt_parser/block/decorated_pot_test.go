@@ -0,0 +1,61 @@
+package nbt_parser_block_test
+
+import (
+	"reflect"
+	"testing"
+
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+
+	_ "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+)
+
+// parseDecoratedPot 解析放有 item 且纹饰陶片为 sherds 的陶罐
+func parseDecoratedPot(t *testing.T, item map[string]any, sherds ...any) *nbt_parser_block.DecoratedPot {
+	block, err := nbt_parser_block.ParseBlock(
+		nil,
+		"minecraft:decorated_pot",
+		map[string]any{"direction": int32(0)},
+		map[string]any{"id": "DecoratedPot", "item": item, "sherds": sherds},
+	)
+	if err != nil {
+		t.Fatalf("parseDecoratedPot: %v", err)
+	}
+	pot, ok := block.(*nbt_parser_block.DecoratedPot)
+	if !ok {
+		t.Fatalf("parseDecoratedPot: Expected a decorated pot, but got %#v", block)
+	}
+	return pot
+}
+
+func TestDecoratedPotUnreproducibleFields(t *testing.T) {
+	apples := map[string]any{"Name": "minecraft:apple", "Count": byte(5), "Damage": int16(0)}
+	books := map[string]any{
+		"Name":   "minecraft:written_book",
+		"Count":  byte(2),
+		"Damage": int16(0),
+		"tag": map[string]any{
+			"title":  "title",
+			"author": "author",
+			"pages":  []any{map[string]any{"text": "hello"}},
+		},
+	}
+
+	// 简单的物品可以被逐个放入
+	if got := parseDecoratedPot(t, apples).UnreproducibleFields(); len(got) != 0 {
+		t.Errorf("TestDecoratedPotUnreproducibleFields: Unexpected fields %#v", got)
+	}
+
+	// 复杂的物品只能被放入一个
+	pot := parseDecoratedPot(t, books, "minecraft:brick", "minecraft:arms_up_pottery_sherd")
+	if !pot.NBT.Item.IsComplex() {
+		t.Fatalf("TestDecoratedPotUnreproducibleFields: Expected the written book to be complex")
+	}
+	if got := pot.UnreproducibleFields(); !reflect.DeepEqual(got, []string{"sherds", "item.Count"}) {
+		t.Errorf("TestDecoratedPotUnreproducibleFields: Unexpected fields %#v", got)
+	}
+
+	books["Count"] = byte(1)
+	if got := parseDecoratedPot(t, books).UnreproducibleFields(); len(got) != 0 {
+		t.Errorf("TestDecoratedPotUnreproducibleFields: Unexpected fields %#v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package nbt_parser_block
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+
+	"github.com/df-mc/worldupgrader/blockupgrader"
+)
+
+// FlowerPotNBT ..
+type FlowerPotNBT struct {
+	HavePlant   bool
+	PlantName   string
+	PlantStates map[string]any
+}
+
+// 花盆
+type FlowerPot struct {
+	DefaultBlock
+	NBT FlowerPotNBT
+}
+
+func (f FlowerPot) NeedSpecialHandle() bool {
+	return f.NBT.HavePlant
+}
+
+func (FlowerPot) NeedCheckCompletely() bool {
+	return true
+}
+
+func (f FlowerPot) formatNBT(prefix string) string {
+	result := prefix + fmt.Sprintf("植物名称: %s\n", f.NBT.PlantName)
+	result += prefix + fmt.Sprintf("植物状态: %s\n", utils.MarshalBlockStates(f.NBT.PlantStates))
+	return result
+}
+
+func (f *FlowerPot) Format(prefix string) string {
+	result := f.DefaultBlock.Format(prefix)
+	if f.NeedSpecialHandle() {
+		result += prefix + "附加数据: \n"
+		result += f.formatNBT(prefix + "\t")
+	}
+	return result
+}
+
+func (f *FlowerPot) Parse(nbtMap map[string]any) error {
+	plantMap, ok := nbtMap["PlantBlock"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	name, _ := plantMap["name"].(string)
+	states, _ := plantMap["states"].(map[string]any)
+	name = strings.ToLower(name)
+	if len(name) == 0 {
+		return nil
+	}
+	if !strings.HasPrefix(name, "minecraft:") {
+		name = "minecraft:" + name
+	}
+	if name == "minecraft:air" {
+		return nil
+	}
+
+	newBlock := blockupgrader.Upgrade(blockupgrader.BlockState{
+		Name:       name,
+		Properties: states,
+	})
+	if f.NameChecker != nil && !f.NameChecker(newBlock.Name) {
+		return nil
+	}
+
+	f.NBT.HavePlant = true
+	f.NBT.PlantName = newBlock.Name
+	f.NBT.PlantStates = newBlock.Properties
+	return nil
+}
+
+func (f FlowerPot) NBTStableBytes() []byte {
+	buf := bytes.NewBuffer(nil)
+	w := protocol.NewWriter(buf, 0)
+
+	// 植物的方块状态由游戏在放入时决定，
+	// 因此只有植物的名称参与比较
+	w.Bool(&f.NBT.HavePlant)
+	if f.NBT.HavePlant {
+		w.String(&f.NBT.PlantName)
+	}
+
+	return buf.Bytes()
+}
+
+func (f *FlowerPot) FullStableBytes() []byte {
+	return append(f.DefaultBlock.FullStableBytes(), f.NBTStableBytes()...)
+}
@@ -26,6 +26,11 @@ func (f Frame) NeedSpecialHandle() bool {
 }
 
 func (f Frame) NeedCheckCompletely() bool {
+	// 还原后的已填充地图总是具有新的地图 UUID，
+	// 因此我们无法通过比对哈希校验其完整性
+	if f.NBT.HaveItem && f.NBT.Item.ItemName() == "minecraft:filled_map" && f.NBT.Item.IsComplex() {
+		return false
+	}
 	return true
 }
 
@@ -54,10 +59,13 @@ func (f *Frame) Parse(nbtMap map[string]any) error {
 		if err != nil {
 			return fmt.Errorf("Parse: %v", err)
 		}
-		if canGetByCommand && item.ItemName() != "minecraft:filled_map" {
+		if canGetByCommand {
 			f.NBT.HaveItem = true
 			f.NBT.Item = item
 		}
+		if f.NBT.HaveItem && item.ItemName() == "minecraft:filled_map" {
+			f.States["item_frame_map_bit"] = byte(1)
+		}
 	}
 
 	return nil
@@ -0,0 +1,173 @@
+package nbt_parser_block
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+)
+
+// 刷怪笼的默认生成参数
+const (
+	DefaultMobSpawnerDelay               int16 = 20
+	DefaultMobSpawnerMinSpawnDelay       int16 = 200
+	DefaultMobSpawnerMaxSpawnDelay       int16 = 800
+	DefaultMobSpawnerSpawnCount          int16 = 4
+	DefaultMobSpawnerMaxNearbyEntities   int16 = 6
+	DefaultMobSpawnerRequiredPlayerRange int16 = 16
+	DefaultMobSpawnerSpawnRange          int16 = 4
+)
+
+// MobSpawnerNBT ..
+type MobSpawnerNBT struct {
+	EntityIdentifier    string
+	Delay               int16
+	MinSpawnDelay       int16
+	MaxSpawnDelay       int16
+	SpawnCount          int16
+	MaxNearbyEntities   int16
+	RequiredPlayerRange int16
+	SpawnRange          int16
+
+	// CanUseSpawnEgg 指示 EntityIdentifier
+	// 所对应的刷怪蛋是否可以通过命令获取
+	CanUseSpawnEgg bool
+}
+
+// 刷怪笼。
+//
+// 刷怪笼的实体类型可以通过对其使用刷怪蛋还原，
+// 但其余的生成参数均无法在导入时被还原
+type MobSpawner struct {
+	DefaultBlock
+	NBT MobSpawnerNBT
+}
+
+// SpawnEggName 返回刷怪笼实体类型所对应的刷怪蛋的物品名称
+func (m MobSpawner) SpawnEggName() string {
+	return m.NBT.EntityIdentifier + "_spawn_egg"
+}
+
+func (m MobSpawner) NeedSpecialHandle() bool {
+	return len(m.NBT.EntityIdentifier) > 0 && m.NBT.CanUseSpawnEgg
+}
+
+func (MobSpawner) NeedCheckCompletely() bool {
+	return true
+}
+
+// UnreproducibleFields 返回刷怪笼中无法被还原的数据的字段名。
+// 这些字段的值与游戏的默认值不同，但导入后将被重置为默认值。
+//
+// Delay 是距离下次生成的倒计时，它每一刻都在变化，
+// 因此即便与默认值不同也不会被视为无法还原
+func (m MobSpawner) UnreproducibleFields() []string {
+	result := make([]string, 0)
+
+	if len(m.NBT.EntityIdentifier) > 0 && !m.NBT.CanUseSpawnEgg {
+		result = append(result, "EntityIdentifier")
+	}
+	for _, value := range []struct {
+		name         string
+		value        int16
+		defaultValue int16
+	}{
+		{"MinSpawnDelay", m.NBT.MinSpawnDelay, DefaultMobSpawnerMinSpawnDelay},
+		{"MaxSpawnDelay", m.NBT.MaxSpawnDelay, DefaultMobSpawnerMaxSpawnDelay},
+		{"SpawnCount", m.NBT.SpawnCount, DefaultMobSpawnerSpawnCount},
+		{"MaxNearbyEntities", m.NBT.MaxNearbyEntities, DefaultMobSpawnerMaxNearbyEntities},
+		{"RequiredPlayerRange", m.NBT.RequiredPlayerRange, DefaultMobSpawnerRequiredPlayerRange},
+		{"SpawnRange", m.NBT.SpawnRange, DefaultMobSpawnerSpawnRange},
+	} {
+		if value.value != value.defaultValue {
+			result = append(result, value.name)
+		}
+	}
+
+	return result
+}
+
+func (m MobSpawner) formatNBT(prefix string) string {
+	result := prefix + fmt.Sprintf("实体类型: %s\n", m.NBT.EntityIdentifier)
+	result += prefix + fmt.Sprintf("生成延迟: %d (最小 %d, 最大 %d)\n", m.NBT.Delay, m.NBT.MinSpawnDelay, m.NBT.MaxSpawnDelay)
+	result += prefix + fmt.Sprintf("生成数量: %d\n", m.NBT.SpawnCount)
+	result += prefix + fmt.Sprintf("生成范围: %d\n", m.NBT.SpawnRange)
+	result += prefix + fmt.Sprintf("最大附近实体数: %d\n", m.NBT.MaxNearbyEntities)
+	result += prefix + fmt.Sprintf("玩家激活距离: %d\n", m.NBT.RequiredPlayerRange)
+	if fields := m.UnreproducibleFields(); len(fields) > 0 {
+		result += prefix + fmt.Sprintf("无法还原的字段: %s\n", strings.Join(fields, ", "))
+	}
+	return result
+}
+
+func (m *MobSpawner) Format(prefix string) string {
+	result := m.DefaultBlock.Format(prefix)
+	if len(m.NBT.EntityIdentifier) > 0 {
+		result += prefix + "附加数据: \n"
+		result += m.formatNBT(prefix + "\t")
+	}
+	return result
+}
+
+func (m *MobSpawner) Parse(nbtMap map[string]any) error {
+	m.NBT = MobSpawnerNBT{
+		Delay:               DefaultMobSpawnerDelay,
+		MinSpawnDelay:       DefaultMobSpawnerMinSpawnDelay,
+		MaxSpawnDelay:       DefaultMobSpawnerMaxSpawnDelay,
+		SpawnCount:          DefaultMobSpawnerSpawnCount,
+		MaxNearbyEntities:   DefaultMobSpawnerMaxNearbyEntities,
+		RequiredPlayerRange: DefaultMobSpawnerRequiredPlayerRange,
+		SpawnRange:          DefaultMobSpawnerSpawnRange,
+	}
+
+	for key, ptr := range map[string]*int16{
+		"Delay":               &m.NBT.Delay,
+		"MinSpawnDelay":       &m.NBT.MinSpawnDelay,
+		"MaxSpawnDelay":       &m.NBT.MaxSpawnDelay,
+		"SpawnCount":          &m.NBT.SpawnCount,
+		"MaxNearbyEntities":   &m.NBT.MaxNearbyEntities,
+		"RequiredPlayerRange": &m.NBT.RequiredPlayerRange,
+		"SpawnRange":          &m.NBT.SpawnRange,
+	} {
+		if value, ok := nbtMap[key].(int16); ok {
+			*ptr = value
+		}
+	}
+
+	entityIdentifier, _ := nbtMap["EntityIdentifier"].(string)
+	entityIdentifier = strings.ToLower(entityIdentifier)
+	if len(entityIdentifier) == 0 {
+		return nil
+	}
+	if !strings.HasPrefix(entityIdentifier, "minecraft:") {
+		entityIdentifier = "minecraft:" + entityIdentifier
+	}
+
+	m.NBT.EntityIdentifier = entityIdentifier
+	m.NBT.CanUseSpawnEgg = true
+	if m.NameChecker != nil {
+		m.NBT.CanUseSpawnEgg = m.NameChecker(m.SpawnEggName())
+	}
+
+	return nil
+}
+
+func (m MobSpawner) NBTStableBytes() []byte {
+	buf := bytes.NewBuffer(nil)
+	w := protocol.NewWriter(buf, 0)
+
+	// 只有实体类型可以被还原，
+	// 因此其余的生成参数不参与比较
+	entityIdentifier := ""
+	if m.NeedSpecialHandle() {
+		entityIdentifier = m.NBT.EntityIdentifier
+	}
+	w.String(&entityIdentifier)
+
+	return buf.Bytes()
+}
+
+func (m *MobSpawner) FullStableBytes() []byte {
+	return append(m.DefaultBlock.FullStableBytes(), m.NBTStableBytes()...)
+}
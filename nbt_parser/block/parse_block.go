@@ -72,6 +72,18 @@ func ParseBlock(
 		block = &BrewingStand{DefaultBlock: defaultBlock}
 	case mapping.SupportNBTBlockTypeCrafter:
 		block = &Crafter{DefaultBlock: defaultBlock}
+	case mapping.SupportNBTBlockTypeSkull:
+		block = &Skull{DefaultBlock: defaultBlock}
+	case mapping.SupportNBTBlockTypeFlowerPot:
+		block = &FlowerPot{DefaultBlock: defaultBlock}
+	case mapping.SupportNBTBlockTypeDecoratedPot:
+		block = &DecoratedPot{DefaultBlock: defaultBlock}
+	case mapping.SupportNBTBlockTypeChiseledBookshelf:
+		block = &ChiseledBookshelf{DefaultBlock: defaultBlock}
+	case mapping.SupportNBTBlockTypeCampfire:
+		block = &Campfire{DefaultBlock: defaultBlock}
+	case mapping.SupportNBTBlockTypeMobSpawner:
+		block = &MobSpawner{DefaultBlock: defaultBlock}
 	default:
 		panic("ParseNBTBlock: Should never happened")
 	}
@@ -0,0 +1,115 @@
+package nbt_parser_block
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+)
+
+// SkullTypeName 是头颅种类到其名称的映射
+var SkullTypeName = []string{
+	"骷髅头颅",
+	"凋灵骷髅头颅",
+	"僵尸的头",
+	"玩家的头",
+	"苦力怕的头",
+	"龙首",
+	"猪灵的头",
+}
+
+// SkullNBT ..
+type SkullNBT struct {
+	SkullType uint8
+	// Rotation 是头颅被放置在地面上时的旋转，
+	// 它被规范化为 0 到 15 之间的整数，
+	// 且每个单位代表 22.5 度。
+	// 对于挂在墙上的头颅，它总是为 0
+	Rotation uint8
+}
+
+// 头颅。
+// 基岩版的头颅不携带玩家或皮肤数据，
+// 因此玩家的头总是以默认的皮肤被导入
+type Skull struct {
+	DefaultBlock
+	NBT SkullNBT
+}
+
+// OnFloor 指示该头颅是否被放置在地面上
+func (s Skull) OnFloor() bool {
+	facing, _ := s.States["facing_direction"].(int32)
+	return facing <= 1
+}
+
+// Yaw 返回放置该头颅时，机器人应当具有的偏航角
+func (s Skull) Yaw() float32 {
+	yaw := float32(s.NBT.Rotation) * 22.5
+	if yaw > 180 {
+		yaw -= 360
+	}
+	return yaw
+}
+
+func (s Skull) NeedSpecialHandle() bool {
+	return s.NBT.SkullType != 0 || s.NBT.Rotation != 0
+}
+
+func (Skull) NeedCheckCompletely() bool {
+	return true
+}
+
+func (s Skull) formatNBT(prefix string) string {
+	result := ""
+	if int(s.NBT.SkullType) < len(SkullTypeName) {
+		result += prefix + fmt.Sprintf("头颅种类: %s\n", SkullTypeName[s.NBT.SkullType])
+	} else {
+		result += prefix + fmt.Sprintf("头颅种类: 未知 (%d)\n", s.NBT.SkullType)
+	}
+	if s.OnFloor() {
+		result += prefix + fmt.Sprintf("旋转角度: %v 度\n", s.Yaw())
+	}
+	return result
+}
+
+func (s *Skull) Format(prefix string) string {
+	result := s.DefaultBlock.Format(prefix)
+	if s.NeedSpecialHandle() {
+		result += prefix + "附加数据: \n"
+		result += s.formatNBT(prefix + "\t")
+	}
+	return result
+}
+
+func (s *Skull) Parse(nbtMap map[string]any) error {
+	s.NBT.SkullType, _ = nbtMap["SkullType"].(byte)
+	if int(s.NBT.SkullType) >= len(SkullTypeName) {
+		s.NBT.SkullType = 0
+	}
+
+	if s.OnFloor() {
+		rotation, _ := nbtMap["Rotation"].(float32)
+		index := int(math.Round(float64(rotation)/22.5)) % 16
+		if index < 0 {
+			index += 16
+		}
+		s.NBT.Rotation = uint8(index)
+	}
+
+	return nil
+}
+
+func (s Skull) NBTStableBytes() []byte {
+	buf := bytes.NewBuffer(nil)
+	w := protocol.NewWriter(buf, 0)
+
+	w.Uint8(&s.NBT.SkullType)
+	w.Uint8(&s.NBT.Rotation)
+
+	return buf.Bytes()
+}
+
+func (s *Skull) FullStableBytes() []byte {
+	return append(s.DefaultBlock.FullStableBytes(), s.NBTStableBytes()...)
+}
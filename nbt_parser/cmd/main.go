@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	nbt_hash "github.com/mcpol-studio/flowers-for-machines/nbt_parser/hash"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+	nbt_parser_item "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// encodings 是所有受支持的 NBT 编码，
+// 它们的顺序也是自动检测编码时尝试的顺序
+var encodings = []struct {
+	name     string
+	encoding nbt.Encoding
+}{
+	{"le", nbt.LittleEndian},
+	{"network", nbt.NetworkLittleEndian},
+	{"be", nbt.BigEndian},
+}
+
+var (
+	inputPath         *string
+	inputBase64       *string
+	encodingName      *string
+	outputFormat      *string
+	blockName         *string
+	blockStatesString *string
+	isItem            *bool
+)
+
+func init() {
+	inputPath = flag.String("i", "", "The input file path. Files ending with .mcstructure are read as structures. If both -i and -b64 are empty, the input will be read from stdin.")
+	inputBase64 = flag.String("b64", "", "The base64 encoded NBT to inspect. (e.g. block_nbt_base64_string)")
+	encodingName = flag.String("enc", "auto", "The NBT encoding. (auto, le, be, network or snbt)")
+	outputFormat = flag.String("format", "snbt", "The format used to print the NBT. (snbt or json)")
+	blockName = flag.String("block", "", "The block name of the block entity. If empty, the NBT will only be printed, unless -item is set.")
+	blockStatesString = flag.String("states", "[]", `The block states of the block entity. (e.g. ["facing_direction"=2])`)
+	isItem = flag.Bool("item", false, "Whether to parse the NBT as an item instead of a block entity.")
+	flag.Parse()
+}
+
+func main() {
+	data, err := readInput()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if strings.HasSuffix(strings.ToLower(*inputPath), ".mcstructure") {
+		if err = inspectStructure(data); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	nbtMap, name, err := decodeNBT(data)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Printf("== NBT (encoding = %s) ==\n", name)
+	printNBT(nbtMap)
+
+	switch {
+	case *isItem:
+		err = inspectItem(nbtMap)
+	case len(*blockName) > 0:
+		err = inspectBlock(*blockName, utils.ParseBlockStatesString(*blockStatesString), nbtMap)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// readInput 从命令行参数指定的来源读取原始的输入数据
+func readInput() ([]byte, error) {
+	switch {
+	case len(*inputBase64) > 0:
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(*inputBase64))
+	case len(*inputPath) > 0:
+		return os.ReadFile(*inputPath)
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	// 从标准输入读取的二进制 NBT 也可能以 Base64 字符串的形式给出
+	if *encodingName == "snbt" {
+		return data, nil
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data))); err == nil {
+		return decoded, nil
+	}
+	return data, nil
+}
+
+// decodeNBT 使用命令行参数指定的编码解码 data。
+// 如果编码为 auto，则依次尝试所有受支持的二进制编码，
+// 并选用第一个恰好完整读取了 data 的编码；
+// 如果编码为 snbt，则 data 被视为 SNBT 字符串
+func decodeNBT(data []byte) (result map[string]any, encodingUsed string, err error) {
+	if *encodingName == "snbt" {
+		if err = nbt.UnmarshalSNBT(string(data), &result); err != nil {
+			return nil, "", fmt.Errorf("decodeNBT: Failed to decode NBT as snbt; err = %v", err)
+		}
+		return result, "snbt", nil
+	}
+
+	for _, value := range encodings {
+		if *encodingName != "auto" && *encodingName != value.name {
+			continue
+		}
+
+		result = nil
+		buf := bytes.NewBuffer(data)
+		err = nbt.NewDecoderWithEncoding(buf, value.encoding).Decode(&result)
+		if err == nil && buf.Len() == 0 {
+			return result, value.name, nil
+		}
+		if *encodingName != "auto" {
+			if err == nil {
+				err = fmt.Errorf("%d bytes left after decoding", buf.Len())
+			}
+			return nil, "", fmt.Errorf("decodeNBT: Failed to decode NBT as %s; err = %v", value.name, err)
+		}
+	}
+
+	if *encodingName != "auto" {
+		return nil, "", fmt.Errorf("decodeNBT: Unknown encoding %#v", *encodingName)
+	}
+	return nil, "", fmt.Errorf("decodeNBT: The input is not valid NBT in any supported encoding")
+}
+
+// printNBT 按照命令行参数指定的格式打印 nbtMap
+func printNBT(nbtMap map[string]any) {
+	switch *outputFormat {
+	case "json":
+		result, err := json.MarshalIndent(nbtMap, "", "\t")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println(string(result))
+	case "snbt":
+		result, err := nbt.MarshalSNBTIndent(nbtMap, "\t")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println(result)
+	default:
+		log.Fatalf("Unknown output format %#v\n", *outputFormat)
+	}
+}
+
+// inspectBlock 解析并打印名称为 name、方块状态为 states 且方块实体数据为 blockNBT 的 NBT 方块
+func inspectBlock(name string, states map[string]any, blockNBT map[string]any) error {
+	block, err := nbt_parser_block.ParseBlock(nil, name, states, blockNBT)
+	if err != nil {
+		return fmt.Errorf("inspectBlock: %v", err)
+	}
+	printBlock(block)
+	return nil
+}
+
+// printBlock 打印 NBT 方块 block 的解析结果
+func printBlock(block nbt_parser_interface.Block) {
+	fmt.Println("== Parsed block ==")
+	fmt.Printf("Name: %s\n", block.BlockName())
+	fmt.Printf("States: %s\n", block.BlockStatesString())
+	fmt.Printf("Type: %T\n", block)
+	fmt.Printf("NeedSpecialHandle: %v\n", block.NeedSpecialHandle())
+	if block.NeedSpecialHandle() {
+		fmt.Printf("NeedCheckCompletely: %v\n", block.NeedCheckCompletely())
+	}
+	fmt.Printf("Full hash: %#016x\n", nbt_hash.NBTBlockFullHash(block))
+	fmt.Printf("NBT hash: %#016x\n", nbt_hash.NBTBlockNBTHash(block))
+	fmt.Printf("Set hash: %#016x\n", nbt_hash.ContainerSetHash(block))
+	if partialBlock, ok := block.(nbt_parser_interface.PartialBlock); ok {
+		if fields := partialBlock.UnreproducibleFields(); len(fields) > 0 {
+			fmt.Printf("Unreproducible fields: %s\n", strings.Join(fields, ", "))
+		}
+	}
+	fmt.Println("== Format ==")
+	fmt.Print(block.Format(""))
+}
+
+// inspectItem 解析并打印 NBT 物品 itemNBT
+func inspectItem(itemNBT map[string]any) error {
+	item, _, err := nbt_parser_item.ParseItemNormal(nil, itemNBT)
+	if err != nil {
+		return fmt.Errorf("inspectItem: %v", err)
+	}
+
+	fmt.Println("== Parsed item ==")
+	fmt.Printf("Name: %s\n", item.ItemName())
+	fmt.Printf("Count: %d\n", item.ItemCount())
+	fmt.Printf("Metadata: %d\n", item.ItemMetadata())
+	fmt.Printf("Type: %T\n", item)
+	fmt.Printf("NeedEnchOrRename: %v\n", item.NeedEnchOrRename())
+	fmt.Printf("IsComplex: %v\n", item.IsComplex())
+	fmt.Printf("Full hash: %#016x\n", nbt_hash.NBTItemFullHash(item))
+	fmt.Printf("NBT hash: %#016x\n", nbt_hash.NBTItemNBTHash(item))
+	fmt.Printf("Type hash: %#016x\n", nbt_hash.NBTItemTypeHash(item))
+	fmt.Println("== Format ==")
+	fmt.Print(item.Format(""))
+	return nil
+}
+
+// inspectStructure 解析结构文件 data，
+// 并打印其中每个方块实体的解析结果
+func inspectStructure(data []byte) error {
+	structure, err := mcstructure.Decode(data)
+	if err != nil {
+		return fmt.Errorf("inspectStructure: %v", err)
+	}
+	fmt.Printf("== Structure (size = %v, origin = %v, block entities = %d) ==\n",
+		structure.Size, structure.Origin, len(structure.BlockEntities))
+
+	for _, index := range slices.Sorted(maps.Keys(structure.BlockEntities)) {
+		pos := structure.Position(index)
+		fmt.Printf("\n==== Block entity at %v ====\n", pos)
+		printNBT(structure.BlockEntities[index])
+
+		block, found, err := structure.ParseBlock(nil, pos)
+		if err != nil {
+			fmt.Printf("Failed to parse block: %v\n", err)
+			continue
+		}
+		if found {
+			printBlock(block)
+		}
+	}
+
+	return nil
+}
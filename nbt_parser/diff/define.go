@@ -0,0 +1,61 @@
+package nbt_diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Difference 描述两个 NBT 方块 (或两个 NBT 复合标签)
+// 在某一路径上的单个差异
+type Difference struct {
+	// Path 是产生差异的路径，例如 NBT.Items[Slot=5].Item.Basic.Name。
+	// 对于以物品栏或魔咒 ID 为键的列表，其元素将以 [键=值] 的形式给出
+	Path string
+	// Expected 是期望的值。
+	// 如果它为 nil，则说明该路径在实际的值中是多余的
+	Expected any
+	// Actual 是实际的值。
+	// 如果它为 nil，则说明该路径在实际的值中是缺失的
+	Actual any
+}
+
+// String 返回这个差异的可读描述
+func (d Difference) String() string {
+	switch {
+	case d.Expected == nil:
+		return fmt.Sprintf("%s: 多出了 %s", d.Path, describe(d.Actual))
+	case d.Actual == nil:
+		return fmt.Sprintf("%s: 缺少 %s", d.Path, describe(d.Expected))
+	default:
+		return fmt.Sprintf("%s: 期望为 %s，实际为 %s", d.Path, describe(d.Expected), describe(d.Actual))
+	}
+}
+
+// Format 将 diffs 格式化为多行的可读字符串，
+// 其中每一行描述一个差异
+func Format(diffs []Difference) string {
+	result := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		result = append(result, diff.String())
+	}
+	return strings.Join(result, "\n")
+}
+
+// Strings 将 diffs 中的每个差异转换为其可读描述
+func Strings(diffs []Difference) []string {
+	result := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		result = append(result, diff.String())
+	}
+	return result
+}
+
+// describe 返回 value 的单行可读描述。
+// 如果 value 实现了 Format 方法 (例如已解析的物品)，
+// 则使用其结果，否则使用 %#v 格式化
+func describe(value any) string {
+	if formatter, ok := value.(interface{ Format(prefix string) string }); ok {
+		return "{" + strings.Join(strings.Fields(formatter.Format("")), " ") + "}"
+	}
+	return fmt.Sprintf("%#v", value)
+}
@@ -0,0 +1,290 @@
+package nbt_diff
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+)
+
+// keyFields 是已解析的结构体列表中用于匹配元素的字段名，
+// 例如容器中物品的所在物品栏和物品的魔咒 ID
+var keyFields = []string{"Slot", "ID"}
+
+// keyTags 是 NBT 列表中用于匹配复合标签的字段名，
+// 例如容器中物品的所在物品栏和物品的魔咒 ID
+var keyTags = []string{"Slot", "id"}
+
+// differ 是用于比较两个值的差异的比较器
+type differ struct {
+	result []Difference
+}
+
+// DiffBlocks 比较已解析的两个 NBT 方块 expected 和 actual，
+// 并返回它们之间所有路径级别的差异。
+//
+// 对于容器中的物品和物品的魔咒，它们将分别按照所在物品栏和魔咒 ID 进行匹配，
+// 而不是按照在列表中的位置进行匹配。如果两个方块完全相同，则返回的切片为空
+func DiffBlocks(expected nbt_parser_interface.Block, actual nbt_parser_interface.Block) []Difference {
+	d := new(differ)
+	d.compare("", reflect.ValueOf(expected), reflect.ValueOf(actual))
+	return d.result
+}
+
+// DiffNBT 比较两个 NBT 复合标签 expected 和 actual，
+// 并返回它们之间所有路径级别的差异。
+//
+// 对于含有 Slot 或 id 字段的复合标签列表，
+// 其元素将按照该字段的值进行匹配，而不是按照在列表中的位置进行匹配。
+// 如果两个复合标签完全相同，则返回的切片为空
+func DiffNBT(expected map[string]any, actual map[string]any) []Difference {
+	d := new(differ)
+	d.compare("", reflect.ValueOf(expected), reflect.ValueOf(actual))
+	return d.result
+}
+
+// record 记录路径 path 上的一个差异。
+// expected 或 actual 可以是无效的值，
+// 这分别意味着该路径是多余的或缺失的
+func (d *differ) record(path string, expected reflect.Value, actual reflect.Value) {
+	diff := Difference{Path: path}
+	if expected.IsValid() {
+		diff.Expected = expected.Interface()
+	}
+	if actual.IsValid() {
+		diff.Actual = actual.Interface()
+	}
+	if len(diff.Path) == 0 {
+		diff.Path = "."
+	}
+	d.result = append(d.result, diff)
+}
+
+// compare 比较路径 path 上的两个值 expected 和 actual
+func (d *differ) compare(path string, expected reflect.Value, actual reflect.Value) {
+	// 原始的值被用于记录差异，
+	// 这使得指针接收者的 Format 方法仍然可用
+	originExpected, originActual := expected, actual
+	expected, actual = indirect(expected), indirect(actual)
+	if !expected.IsValid() {
+		originExpected = reflect.Value{}
+	}
+	if !actual.IsValid() {
+		originActual = reflect.Value{}
+	}
+
+	switch {
+	case !expected.IsValid() && !actual.IsValid():
+		return
+	case !expected.IsValid() || !actual.IsValid():
+		d.record(path, originExpected, originActual)
+		return
+	case expected.Type() != actual.Type():
+		d.record(path, originExpected, originActual)
+		return
+	}
+
+	switch expected.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return
+	case reflect.Struct:
+		d.compareStruct(path, expected, actual)
+	case reflect.Map:
+		d.compareMap(path, expected, actual)
+	case reflect.Slice, reflect.Array:
+		if !isComposite(expected.Type().Elem()) {
+			if !reflect.DeepEqual(expected.Interface(), actual.Interface()) {
+				d.record(path, expected, actual)
+			}
+			return
+		}
+		d.compareList(path, expected, actual)
+	default:
+		if !reflect.DeepEqual(expected.Interface(), actual.Interface()) {
+			d.record(path, expected, actual)
+		}
+	}
+}
+
+// compareStruct 比较路径 path 上的两个相同类型的结构体。
+// 未导出的字段和函数字段 (例如 NameChecker) 将被忽略
+func (d *differ) compareStruct(path string, expected reflect.Value, actual reflect.Value) {
+	for index := range expected.NumField() {
+		field := expected.Type().Field(index)
+		if !field.IsExported() {
+			continue
+		}
+		d.compare(joinPath(path, field.Name), expected.Field(index), actual.Field(index))
+	}
+}
+
+// compareMap 比较路径 path 上的两个相同类型的映射
+func (d *differ) compareMap(path string, expected reflect.Value, actual reflect.Value) {
+	keys := make(map[string]reflect.Value)
+	for _, key := range expected.MapKeys() {
+		keys[fmt.Sprint(key.Interface())] = key
+	}
+	for _, key := range actual.MapKeys() {
+		keys[fmt.Sprint(key.Interface())] = key
+	}
+
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		key := keys[name]
+		d.compare(joinPath(path, name), expected.MapIndex(key), actual.MapIndex(key))
+	}
+}
+
+// compareList 比较路径 path 上的两个相同类型的列表。
+// 如果列表中的所有元素都具有唯一的键，
+// 则元素按照键进行匹配，否则按照位置进行匹配
+func (d *differ) compareList(path string, expected reflect.Value, actual reflect.Value) {
+	expectedKeys, keyName, ok := listKeys(expected, "")
+	if ok {
+		var actualKeys []string
+		actualKeys, keyName, ok = listKeys(actual, keyName)
+		if ok && len(keyName) > 0 {
+			d.compareKeyedList(path, keyName, expected, actual, expectedKeys, actualKeys)
+			return
+		}
+	}
+
+	for index := range max(expected.Len(), actual.Len()) {
+		var expectedElem, actualElem reflect.Value
+		if index < expected.Len() {
+			expectedElem = expected.Index(index)
+		}
+		if index < actual.Len() {
+			actualElem = actual.Index(index)
+		}
+		d.compare(fmt.Sprintf("%s[%d]", path, index), expectedElem, actualElem)
+	}
+}
+
+// compareKeyedList 比较路径 path 上的两个按照键 keyName 进行匹配的列表。
+// expectedKeys 和 actualKeys 分别是 expected 和 actual 中每个元素的键
+func (d *differ) compareKeyedList(
+	path string,
+	keyName string,
+	expected reflect.Value,
+	actual reflect.Value,
+	expectedKeys []string,
+	actualKeys []string,
+) {
+	for index, key := range expectedKeys {
+		elemPath := fmt.Sprintf("%s[%s=%s]", path, keyName, key)
+		if actualIndex := slices.Index(actualKeys, key); actualIndex != -1 {
+			d.compare(elemPath, expected.Index(index), actual.Index(actualIndex))
+			continue
+		}
+		d.record(elemPath, expected.Index(index), reflect.Value{})
+	}
+	for index, key := range actualKeys {
+		if !slices.Contains(expectedKeys, key) {
+			d.record(fmt.Sprintf("%s[%s=%s]", path, keyName, key), reflect.Value{}, actual.Index(index))
+		}
+	}
+}
+
+// listKeys 返回列表 list 中每个元素的键。
+// 如果 keyName 为空，则自动从 keyFields 或 keyTags 中选取键的名称。
+//
+// 如果存在某个元素不具有该键，或存在重复的键，则 ok 为假。
+// 对于空列表，ok 总是为真
+func listKeys(list reflect.Value, keyName string) (keys []string, usedKeyName string, ok bool) {
+	seen := make(map[string]bool)
+
+	for index := range list.Len() {
+		elem := indirect(list.Index(index))
+		if !elem.IsValid() {
+			return nil, "", false
+		}
+
+		if len(keyName) == 0 {
+			keyName = findKeyName(elem)
+			if len(keyName) == 0 {
+				return nil, "", false
+			}
+		}
+
+		key, found := elemKey(elem, keyName)
+		if !found || seen[key] {
+			return nil, "", false
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	return keys, keyName, true
+}
+
+// findKeyName 返回元素 elem 可用于匹配的键的名称。
+// 如果不存在这样的键，则返回空字符串
+func findKeyName(elem reflect.Value) string {
+	candidates := keyFields
+	if elem.Kind() == reflect.Map {
+		candidates = keyTags
+	}
+	for _, name := range candidates {
+		if _, found := elemKey(elem, name); found {
+			return name
+		}
+	}
+	return ""
+}
+
+// elemKey 返回元素 elem 中名为 keyName 的键的值
+func elemKey(elem reflect.Value, keyName string) (key string, found bool) {
+	var value reflect.Value
+
+	switch elem.Kind() {
+	case reflect.Struct:
+		value = elem.FieldByName(keyName)
+	case reflect.Map:
+		if elem.Type().Key().Kind() != reflect.String {
+			return "", false
+		}
+		value = indirect(elem.MapIndex(reflect.ValueOf(keyName)))
+	}
+
+	if !value.IsValid() || !value.CanInterface() || isComposite(value.Type()) {
+		return "", false
+	}
+	return fmt.Sprint(value.Interface()), true
+}
+
+// indirect 解引用 value 中的所有接口和指针。
+// 如果 value 是 nil 接口或 nil 指针，则返回无效的值
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Interface || value.Kind() == reflect.Pointer) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// isComposite 检查类型 t 是否可能包含需要逐个比较的子元素。
+// 对于基本类型的列表 (例如字节数组)，它们将被视为一个整体进行比较
+func isComposite(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface, reflect.Pointer:
+		return true
+	}
+	return false
+}
+
+// joinPath 将字段名 name 连接到路径 path 之后
+func joinPath(path string, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,168 @@
+package nbt_diff
+
+import (
+	"reflect"
+	"testing"
+
+	nbt_parser_block "github.com/mcpol-studio/flowers-for-machines/nbt_parser/block"
+	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
+
+	_ "github.com/mcpol-studio/flowers-for-machines/nbt_parser/item"
+)
+
+// paths 返回 diffs 中每个差异的路径
+func paths(diffs []Difference) []string {
+	result := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		result = append(result, diff.Path)
+	}
+	return result
+}
+
+// chestItem 返回位于 slot 且名为 name 的箱子物品
+func chestItem(slot byte, name string, count byte) map[string]any {
+	return map[string]any{
+		"Name":   name,
+		"Count":  count,
+		"Damage": int16(0),
+		"Slot":   slot,
+	}
+}
+
+// parseChest 解析含有 items 的箱子
+func parseChest(t *testing.T, items ...any) nbt_parser_interface.Block {
+	block, err := nbt_parser_block.ParseBlock(
+		nil,
+		"minecraft:chest",
+		map[string]any{"minecraft:cardinal_direction": "north"},
+		map[string]any{"id": "Chest", "Items": items},
+	)
+	if err != nil {
+		t.Fatalf("parseChest: %v", err)
+	}
+	return block
+}
+
+func TestDiffNBTNestedCompound(t *testing.T) {
+	expected := map[string]any{
+		"id": "Sign",
+		"FrontText": map[string]any{
+			"Text":          "hello",
+			"TextOwner":     "",
+			"SignTextColor": int32(-16777216),
+		},
+	}
+	actual := map[string]any{
+		"id": "Sign",
+		"FrontText": map[string]any{
+			"Text":              "world",
+			"SignTextColor":     int32(-16777216),
+			"PersistFormatting": byte(1),
+		},
+	}
+
+	diffs := DiffNBT(expected, actual)
+	if got := paths(diffs); !reflect.DeepEqual(got, []string{"FrontText.PersistFormatting", "FrontText.Text", "FrontText.TextOwner"}) {
+		t.Fatalf("TestDiffNBTNestedCompound: Unexpected paths %#v", got)
+	}
+	if diffs[0].Expected != nil || diffs[0].Actual != byte(1) {
+		t.Errorf("TestDiffNBTNestedCompound: Unexpected extra field %#v", diffs[0])
+	}
+	if diffs[1].Expected != "hello" || diffs[1].Actual != "world" {
+		t.Errorf("TestDiffNBTNestedCompound: Unexpected changed field %#v", diffs[1])
+	}
+	if diffs[2].Expected != "" || diffs[2].Actual != nil {
+		t.Errorf("TestDiffNBTNestedCompound: Unexpected missing field %#v", diffs[2])
+	}
+
+	if diffs := DiffNBT(expected, expected); len(diffs) != 0 {
+		t.Errorf("TestDiffNBTNestedCompound: Expected no difference, but got %#v", diffs)
+	}
+}
+
+func TestDiffNBTList(t *testing.T) {
+	// 含有 Slot 的列表按照物品栏匹配，因此元素的顺序不影响结果
+	expected := map[string]any{
+		"Items": []any{chestItem(1, "minecraft:apple", 5), chestItem(4, "minecraft:stone", 1)},
+	}
+	actual := map[string]any{
+		"Items": []any{chestItem(6, "minecraft:dirt", 1), chestItem(4, "minecraft:stone", 1), chestItem(1, "minecraft:apple", 3)},
+	}
+	diffs := DiffNBT(expected, actual)
+	if got := paths(diffs); !reflect.DeepEqual(got, []string{"Items[Slot=1].Count", "Items[Slot=6]"}) {
+		t.Fatalf("TestDiffNBTList: Unexpected paths %#v", got)
+	}
+	if diffs[0].Expected != byte(5) || diffs[0].Actual != byte(3) || diffs[1].Expected != nil {
+		t.Errorf("TestDiffNBTList: Unexpected differences %#v", diffs)
+	}
+
+	// 含有重复的键的列表按照位置匹配
+	expected = map[string]any{"Items": []any{chestItem(1, "minecraft:apple", 1), chestItem(1, "minecraft:stone", 1)}}
+	actual = map[string]any{"Items": []any{chestItem(1, "minecraft:apple", 1)}}
+	if got := paths(DiffNBT(expected, actual)); !reflect.DeepEqual(got, []string{"Items[1]"}) {
+		t.Errorf("TestDiffNBTList: Unexpected paths %#v", got)
+	}
+
+	// 基本类型的列表被视为一个整体
+	expected = map[string]any{"Lore": []any{"a", "b"}, "Colors": []int32{1, 2, 3}}
+	actual = map[string]any{"Lore": []any{"a"}, "Colors": []int32{1, 2, 4}}
+	if got := paths(DiffNBT(expected, actual)); !reflect.DeepEqual(got, []string{"Colors", "Lore[1]"}) {
+		t.Errorf("TestDiffNBTList: Unexpected paths %#v", got)
+	}
+}
+
+func TestDiffNBTTypeChange(t *testing.T) {
+	expected := map[string]any{
+		"Count":   byte(1),
+		"Damage":  int16(0),
+		"display": map[string]any{"Name": "a"},
+	}
+	actual := map[string]any{
+		"Count":   int32(1),
+		"Damage":  int16(0),
+		"display": "a",
+	}
+
+	diffs := DiffNBT(expected, actual)
+	if got := paths(diffs); !reflect.DeepEqual(got, []string{"Count", "display"}) {
+		t.Fatalf("TestDiffNBTTypeChange: Unexpected paths %#v", got)
+	}
+	// 类型不同的值将作为一个整体被记录，而不会被进一步比较
+	if _, ok := diffs[1].Expected.(map[string]any); !ok || diffs[1].Actual != "a" {
+		t.Errorf("TestDiffNBTTypeChange: Unexpected difference %#v", diffs[1])
+	}
+	if got := diffs[0].String(); got != "Count: 期望为 0x1，实际为 1" {
+		t.Errorf("TestDiffNBTTypeChange: Unexpected description %#v", got)
+	}
+}
+
+func TestDiffBlocks(t *testing.T) {
+	expected := parseChest(t, chestItem(1, "minecraft:apple", 5), chestItem(4, "minecraft:stone", 1))
+	actual := parseChest(t, chestItem(4, "minecraft:dirt", 1))
+
+	diffs := DiffBlocks(expected, actual)
+	if len(diffs) == 0 {
+		t.Fatalf("TestDiffBlocks: Expected differences between the chests")
+	}
+	for _, diff := range diffs {
+		switch diff.Path {
+		case "NBT.Items[Slot=1]":
+			if diff.Expected == nil || diff.Actual != nil {
+				t.Errorf("TestDiffBlocks: Unexpected missing item %#v", diff)
+			}
+		case "NBT.Items[Slot=4].Item.Basic.Name":
+			if diff.Expected != "minecraft:stone" || diff.Actual != "minecraft:dirt" {
+				t.Errorf("TestDiffBlocks: Unexpected item name %#v", diff)
+			}
+		default:
+			t.Errorf("TestDiffBlocks: Unexpected difference %s", diff)
+		}
+	}
+	if len(diffs) != 2 {
+		t.Errorf("TestDiffBlocks: Expected 2 differences, but got %s", Format(diffs))
+	}
+
+	if diffs := DiffBlocks(expected, parseChest(t, chestItem(4, "minecraft:stone", 1), chestItem(1, "minecraft:apple", 5))); len(diffs) != 0 {
+		t.Errorf("TestDiffBlocks: Expected no difference, but got %s", Format(diffs))
+	}
+}
@@ -71,6 +71,16 @@ var (
 	SetItemCount func(item Item, count uint8)
 )
 
+// PartialBlock 是可选实现的接口。
+// 实现了它的 NBT 方块可能含有一些
+// 无法在导入时被还原的数据
+type PartialBlock interface {
+	Block
+	// UnreproducibleFields 返回这个方块中
+	// 无法被还原的数据的字段名
+	UnreproducibleFields() []string
+}
+
 // Block 是所有已实现的 NBT 方块的统称
 type Block interface {
 	// BlockName 返回这个方块的名称
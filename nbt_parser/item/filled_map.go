@@ -0,0 +1,86 @@
+package nbt_parser_item
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+)
+
+// FilledMapNBT ..
+type FilledMapNBT struct {
+	MapUUID int64
+	// MapDataHash 是使用者为这张地图提供的像素数据的哈希校验和，
+	// 它不来自物品的 NBT 数据。地图 UUID 只在同一个存档中唯一，
+	// 因此它被用于区分 UUID 相同但像素不同的地图。
+	// 为 0 表示没有提供像素数据
+	MapDataHash uint64
+}
+
+// 已填充地图
+type FilledMap struct {
+	DefaultItem
+	NBT FilledMapNBT
+}
+
+func (f FilledMap) formatNBT(prefix string) string {
+	return prefix + fmt.Sprintf("地图 UUID: %d\n", f.NBT.MapUUID)
+}
+
+func (f *FilledMap) Format(prefix string) string {
+	result := f.DefaultItem.Format(prefix)
+	if f.IsComplex() {
+		result += prefix + "附加数据: \n"
+		result += f.formatNBT(prefix + "\t")
+	}
+	return result
+}
+
+// parse ..
+func (f *FilledMap) parse(tag map[string]any) {
+	f.DefaultItem.Enhance.EnchList = nil
+	f.DefaultItem.Block = ItemBlockData{}
+
+	if len(tag) == 0 {
+		return
+	}
+	f.NBT.MapUUID, _ = tag["map_uuid"].(int64)
+}
+
+func (f *FilledMap) ParseNormal(nbtMap map[string]any) error {
+	tag, _ := nbtMap["tag"].(map[string]any)
+	f.parse(tag)
+	return nil
+}
+
+func (f *FilledMap) ParseNetwork(item protocol.ItemStack, itemName string) error {
+	f.parse(item.NBTData)
+	return nil
+}
+
+// IsComplex 指示这个已填充地图是否指向一张具体的
+// 地图。应当说明的是，即便其为真，也可能因为没有提
+// 供对应的地图数据而无法还原该地图的像素
+func (f *FilledMap) IsComplex() bool {
+	return f.NBT.MapUUID != 0
+}
+
+func (f FilledMap) complexFieldsOnly() []byte {
+	buf := bytes.NewBuffer(nil)
+	w := protocol.NewWriter(buf, 0)
+	w.Varint64(&f.NBT.MapUUID)
+	w.Uint64(&f.NBT.MapDataHash)
+	return buf.Bytes()
+}
+
+func (f *FilledMap) NBTStableBytes() []byte {
+	return append(f.DefaultItem.NBTStableBytes(), f.complexFieldsOnly()...)
+}
+
+func (f *FilledMap) TypeStableBytes() []byte {
+	return append(f.DefaultItem.TypeStableBytes(), f.complexFieldsOnly()...)
+}
+
+func (f *FilledMap) FullStableBytes() []byte {
+	return append(f.TypeStableBytes(), f.Basic.Count)
+}
@@ -51,6 +51,8 @@ func ParseItemNormal(
 		item = &Banner{DefaultItem: defaultItem}
 	case mapping.SupportNBTItemTypeShield:
 		item = &Shield{DefaultItem: defaultItem}
+	case mapping.SupportNBTItemTypeFilledMap:
+		item = &FilledMap{DefaultItem: defaultItem}
 	default:
 		panic("ParseItemNormal: Should never happened")
 	}
@@ -84,6 +86,8 @@ func ParseItemNetwork(itemStack protocol.ItemStack, itemName string) (item nbt_p
 		item = &Banner{DefaultItem: defaultItem}
 	case mapping.SupportNBTItemTypeShield:
 		item = &Shield{DefaultItem: defaultItem}
+	case mapping.SupportNBTItemTypeFilledMap:
+		item = &FilledMap{DefaultItem: defaultItem}
 	default:
 		panic("ParseItemNetwork: Should never happened")
 	}
@@ -0,0 +1,240 @@
+package schematic
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/mapping"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// brewingStandSlots 是 Java 版酿造台的槽位到国际版槽位的映射
+var brewingStandSlots = map[byte]byte{0: 1, 1: 2, 2: 3, 3: 0, 4: 4}
+
+// signTextColor 将 Java 版的颜色名称 color 转换为国际版告示牌的文字颜色
+func signTextColor(color string) int32 {
+	index, ok := javaColorToDyeIndex[color]
+	if !ok {
+		index = javaColorToDyeIndex["black"]
+	}
+	rgb := mapping.DefaultDyeColor[index]
+	return utils.EncodeVarRGBA(rgb[0], rgb[1], rgb[2], 255)
+}
+
+// translateSignText 将 Java 版告示牌的一面 text 转换为国际版的格式
+func translateSignText(text map[string]any) map[string]any {
+	lines := make([]string, 0)
+	for _, value := range utils.NBTListToSlice(text["messages"]) {
+		lines = append(lines, plainText(value))
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	color, _ := text["color"].(string)
+	glowing, _ := toInt64(text["has_glowing_text"])
+	return map[string]any{
+		"Text":           strings.Join(lines, "\n"),
+		"SignTextColor":  signTextColor(color),
+		"IgnoreLighting": byte(glowing),
+	}
+}
+
+// translateSign 将 Java 版告示牌的方块实体数据 javaNBT 转换为国际版的格式
+func translateSign(javaNBT map[string]any) map[string]any {
+	frontText, ok := javaNBT["front_text"].(map[string]any)
+	if !ok {
+		// Java 版 1.20 之前的告示牌
+		messages := make([]any, 0, 4)
+		for index := 1; index <= 4; index++ {
+			messages = append(messages, javaNBT[fmt.Sprintf("Text%d", index)])
+		}
+		frontText = map[string]any{
+			"messages":         messages,
+			"color":            javaNBT["Color"],
+			"has_glowing_text": javaNBT["GlowingText"],
+		}
+	}
+	backText, _ := javaNBT["back_text"].(map[string]any)
+	if backText == nil {
+		backText = make(map[string]any)
+	}
+
+	isWaxed, _ := toInt64(javaNBT["is_waxed"])
+	return map[string]any{
+		"FrontText": translateSignText(frontText),
+		"BackText":  translateSignText(backText),
+		"IsWaxed":   byte(isWaxed),
+	}
+}
+
+// translateBannerPatterns 将 Java 版旗帜的图案 javaNBT 转换为国际版的格式
+func translateBannerPatterns(javaNBT map[string]any) (patterns []any, problems []string) {
+	patterns = make([]any, 0)
+
+	// Java 版 1.20.5 之前的旗帜
+	for _, value := range utils.NBTListToSlice(javaNBT["Patterns"]) {
+		patternMap, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		pattern, _ := patternMap["Pattern"].(string)
+		color, _ := toInt64(patternMap["Color"])
+		patterns = append(patterns, map[string]any{
+			"Pattern": pattern,
+			"Color":   int32(15 - color),
+		})
+	}
+
+	for _, value := range utils.NBTListToSlice(javaNBT["patterns"]) {
+		patternMap, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		patternID, _ := patternMap["pattern"].(string)
+		pattern, ok := javaBannerPatterns[normalizeName(patternID)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("无法转换旗帜图案 %s", patternID))
+			continue
+		}
+		colorName, _ := patternMap["color"].(string)
+		color := slices.Index(javaColors, colorName)
+		if color == -1 {
+			color = 0
+		}
+		patterns = append(patterns, map[string]any{
+			"Pattern": pattern,
+			"Color":   int32(15 - color),
+		})
+	}
+
+	return
+}
+
+// translateBlockEntity 将 Java 版方块 javaBlock 的方块实体数据 javaNBT
+// 转换为国际版方块 bedrock 的方块实体数据。
+// 如果此方块实体无法被转换，则 ok 为假
+func translateBlockEntity(
+	javaBlock JavaBlock,
+	bedrock bedrockBlock,
+	javaNBT map[string]any,
+) (result map[string]any, problems []string, ok bool) {
+	blockType, ok := mapping.SupportBlocksPool[bedrock.Name]
+	if !ok {
+		return nil, nil, false
+	}
+
+	customName := ""
+	if name, ok := javaNBT["CustomName"]; ok {
+		customName = plainText(name)
+	}
+	result = make(map[string]any)
+
+	switch blockType {
+	case mapping.SupportNBTBlockTypeCommandBlock:
+		command, _ := javaNBT["Command"].(string)
+		auto, _ := toInt64(javaNBT["auto"])
+		trackOutput, ok := toInt64(javaNBT["TrackOutput"])
+		if !ok {
+			trackOutput = 1
+		}
+		executeOnFirstTick := byte(0)
+		if bedrock.Name == "minecraft:repeating_command_block" {
+			executeOnFirstTick = 1
+		}
+		result["Command"] = command
+		result["CustomName"] = customName
+		result["auto"] = byte(auto)
+		result["conditionalMode"] = byte(0)
+		if javaBlock.Properties["conditional"] == "true" {
+			result["conditionalMode"] = byte(1)
+		}
+		result["TrackOutput"] = byte(trackOutput)
+		result["TickDelay"] = int32(0)
+		result["ExecuteOnFirstTick"] = executeOnFirstTick
+	case mapping.SupportNBTBlockTypeContainer, mapping.SupportNBTBlockTypeCrafter:
+		key, ok := mapping.ContainerStorageKey[bedrock.Name]
+		if !ok {
+			return nil, nil, false
+		}
+		result[key], problems = translateItems(javaNBT["Items"], nil)
+		result["CustomName"] = customName
+	case mapping.SupportNBTBlockTypeBrewingStand:
+		result["Items"], problems = translateItems(javaNBT["Items"], brewingStandSlots)
+		result["CustomName"] = customName
+	case mapping.SupportNBTBlockTypeSign:
+		result = translateSign(javaNBT)
+	case mapping.SupportNBTBlockTypeBanner:
+		color := 0
+		if baseName, ok := strings.CutSuffix(strings.TrimPrefix(javaBlock.Name, "minecraft:"), "_banner"); ok {
+			color = max(slices.Index(javaColors, strings.TrimSuffix(baseName, "_wall")), 0)
+		}
+		result["Base"] = int32(15 - color)
+		result["Type"] = int32(0)
+		result["Patterns"], problems = translateBannerPatterns(javaNBT)
+	case mapping.SupportNBTBlockTypeLectern:
+		if book, ok := javaNBT["Book"].(map[string]any); ok {
+			item, itemProblems, ok := translateItem(book)
+			problems = append(problems, itemProblems...)
+			if ok {
+				result["book"] = item
+				result["hasBook"] = byte(1)
+			}
+		}
+	case mapping.SupportNBTBlockTypeJukeBox:
+		if record, ok := javaNBT["RecordItem"].(map[string]any); ok {
+			item, itemProblems, ok := translateItem(record)
+			problems = append(problems, itemProblems...)
+			if ok {
+				result["RecordItem"] = item
+			}
+		}
+	case mapping.SupportNBTBlockTypeSkull:
+		rotation := 0
+		if value, ok := javaBlock.Properties["rotation"]; ok {
+			fmt.Sscanf(value, "%d", &rotation)
+		}
+		result["SkullType"] = javaSkullType[javaBlock.Name]
+		result["Rotation"] = float32(rotation) * 22.5
+	case mapping.SupportNBTBlockTypeDecoratedPot:
+		if javaItem, ok := javaNBT["item"].(map[string]any); ok {
+			item, itemProblems, ok := translateItem(javaItem)
+			problems = append(problems, itemProblems...)
+			if ok {
+				result["item"] = item
+			}
+		}
+	case mapping.SupportNBTBlockTypeChiseledBookshelf:
+		result["Items"], problems = translateItems(javaNBT["Items"], nil)
+	case mapping.SupportNBTBlockTypeCampfire:
+		javaItems, _ := translateItems(javaNBT["Items"], nil)
+		for _, value := range javaItems {
+			item := value.(map[string]any)
+			slot, _ := item["Slot"].(byte)
+			name, _ := item["Name"].(string)
+			if slot >= 4 || !mapping.CampfireCookableItems[name] {
+				continue
+			}
+			delete(item, "Slot")
+			item["Count"] = byte(1)
+			result[fmt.Sprintf("Item%d", slot+1)] = item
+		}
+	case mapping.SupportNBTBlockTypeMobSpawner:
+		entityID := ""
+		if spawnData, ok := javaNBT["SpawnData"].(map[string]any); ok {
+			entity, ok := spawnData["entity"].(map[string]any)
+			if !ok {
+				entity = spawnData
+			}
+			entityID, _ = entity["id"].(string)
+		}
+		if len(entityID) > 0 {
+			result["EntityIdentifier"] = normalizeName(entityID)
+		}
+	default:
+		return nil, nil, false
+	}
+
+	return result, problems, true
+}
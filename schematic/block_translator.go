@@ -0,0 +1,580 @@
+package schematic
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/TriM-Organization/bedrock-world-operator/block"
+)
+
+// bedrockBlock 是 Java 版方块转换为国际版后的结果
+type bedrockBlock struct {
+	// Name 和 States 是国际版方块的名称和方块状态
+	Name   string
+	States map[string]any
+	// Waterlogged 指示此方块是否含水
+	Waterlogged bool
+	// Skip 指示此方块不应被放置，
+	// 例如结构空位或国际版中不存在的方块
+	Skip bool
+	// Problems 是转换期间遇到的问题
+	Problems []string
+	// MappedStates 是由 Java 版方块状态转换而来的国际版方块状态的名称。
+	// States 中的其他方块状态都是国际版的默认值
+	MappedStates []string
+}
+
+// stateCandidate 是一个可能的国际版方块状态
+type stateCandidate struct {
+	key   string
+	value string
+}
+
+// directionIndex 是 facing_direction 所使用的方向编号
+var directionIndex = map[string]string{
+	"down":  "0",
+	"up":    "1",
+	"north": "2",
+	"south": "3",
+	"west":  "4",
+	"east":  "5",
+}
+
+// cardinalIndex 是 direction 所使用的方向编号
+var cardinalIndex = map[string]string{
+	"south": "0",
+	"west":  "1",
+	"north": "2",
+	"east":  "3",
+}
+
+// weirdoIndex 是 weirdo_direction 所使用的方向编号，
+// 活板门的 direction 也使用此编号
+var weirdoIndex = map[string]string{
+	"east":  "0",
+	"west":  "1",
+	"south": "2",
+	"north": "3",
+}
+
+// doorIndex 是门的 direction 所使用的方向编号
+var doorIndex = map[string]string{
+	"east":  "0",
+	"south": "1",
+	"west":  "2",
+	"north": "3",
+}
+
+// torchFacing 是 Java 版墙上火把的朝向
+// 到国际版 torch_facing_direction 的映射。
+// 二者的含义恰好相反
+var torchFacing = map[string]string{
+	"north": "south",
+	"south": "north",
+	"west":  "east",
+	"east":  "west",
+}
+
+// railShape 是 Java 版铁轨的形状到国际版 rail_direction 的映射
+var railShape = map[string]string{
+	"north_south":     "0",
+	"east_west":       "1",
+	"ascending_east":  "2",
+	"ascending_west":  "3",
+	"ascending_north": "4",
+	"ascending_south": "5",
+	"south_east":      "6",
+	"south_west":      "7",
+	"north_west":      "8",
+	"north_east":      "9",
+}
+
+// blockTranslator 将 Java 版方块转换为国际版方块，
+// 并缓存每种方块状态的转换结果
+type blockTranslator struct {
+	cache map[string]bedrockBlock
+}
+
+// newBlockTranslator 创建并返回一个新的 blockTranslator
+func newBlockTranslator() *blockTranslator {
+	return &blockTranslator{
+		cache: make(map[string]bedrockBlock),
+	}
+}
+
+// bedrockDefaultStates 返回国际版方块 name 的默认方块状态。
+// 如果国际版中不存在此方块，则 found 为假
+func bedrockDefaultStates(name string) (states map[string]any, found bool) {
+	rid, found := block.StateToRuntimeID(name, map[string]any{})
+	if !found {
+		return nil, false
+	}
+	_, states, found = block.RuntimeIDToState(rid)
+	if !found {
+		return nil, false
+	}
+	return maps.Clone(states), true
+}
+
+// bedrockBlockExists 检查国际版中是否存在名为 name 的方块
+func bedrockBlockExists(name string) bool {
+	_, found := block.StateToRuntimeID(name, map[string]any{})
+	return found
+}
+
+// bedrockStatesValid 检查 states 是否是国际版方块 name 的合法方块状态
+func bedrockStatesValid(name string, states map[string]any) bool {
+	rid, found := block.StateToRuntimeID(name, states)
+	if !found {
+		return false
+	}
+	_, actual, found := block.RuntimeIDToState(rid)
+	if !found {
+		return false
+	}
+	return reflect.DeepEqual(actual, states)
+}
+
+// convertStateValue 按照默认值 defaultValue 的类型将 value 转换为方块状态的值
+func convertStateValue(defaultValue any, value string) (result any, ok bool) {
+	switch defaultValue.(type) {
+	case byte:
+		switch value {
+		case "true", "1":
+			return byte(1), true
+		case "false", "0":
+			return byte(0), true
+		}
+	case int32:
+		val, err := strconv.ParseInt(value, 10, 32)
+		if err == nil {
+			return int32(val), true
+		}
+	case string:
+		return value, true
+	}
+	return nil, false
+}
+
+// offsetNumber 将整数字符串 value 加上 delta
+func offsetNumber(value string, delta int) string {
+	val, err := strconv.Atoi(value)
+	if err != nil {
+		return value
+	}
+	return strconv.Itoa(val + delta)
+}
+
+// invertBool 将布尔字符串 value 取反
+func invertBool(value string) string {
+	if value == "true" {
+		return "false"
+	}
+	return "true"
+}
+
+// Translate 将 Java 版方块 javaBlock 转换为国际版方块
+func (b *blockTranslator) Translate(javaBlock JavaBlock) bedrockBlock {
+	key := javaBlock.String()
+	if result, ok := b.cache[key]; ok {
+		return result
+	}
+	result := b.translate(javaBlock)
+	b.cache[key] = result
+	return result
+}
+
+// bedrockName 返回 Java 版方块 javaBlock 在国际版中的名称。
+// properties 是 javaBlock 的方块状态，
+// 已被用于确定名称的方块状态将从中删除
+func bedrockName(javaBlock JavaBlock, properties map[string]string) string {
+	name := javaBlock.Name
+	baseName := strings.TrimPrefix(name, "minecraft:")
+
+	if newName, ok := javaBlockRenames[name]; ok {
+		name = newName
+	} else {
+		switch {
+		case strings.HasSuffix(baseName, "_wall_hanging_sign"):
+			name = "minecraft:" + strings.TrimSuffix(baseName, "_wall_hanging_sign") + "_hanging_sign"
+		case strings.HasSuffix(baseName, "_hanging_sign"):
+		case strings.HasSuffix(baseName, "_wall_sign"):
+			name = signName(strings.TrimSuffix(baseName, "_wall_sign"), "wall_sign")
+		case strings.HasSuffix(baseName, "_sign"):
+			name = signName(strings.TrimSuffix(baseName, "_sign"), "standing_sign")
+		case strings.HasSuffix(baseName, "_wall_banner"):
+			name = "minecraft:wall_banner"
+		case strings.HasSuffix(baseName, "_banner"):
+			name = "minecraft:standing_banner"
+		case strings.HasSuffix(baseName, "_bed"):
+			name = "minecraft:bed"
+		case baseName == "oak_door":
+			name = "minecraft:wooden_door"
+		case baseName == "oak_trapdoor":
+			name = "minecraft:trapdoor"
+		case baseName == "oak_fence_gate":
+			name = "minecraft:fence_gate"
+		case baseName == "oak_button":
+			name = "minecraft:wooden_button"
+		case baseName == "oak_pressure_plate":
+			name = "minecraft:wooden_pressure_plate"
+		case strings.HasPrefix(baseName, "potted_"):
+			name = "minecraft:flower_pot"
+		case baseName == "stonecutter":
+			name = "minecraft:stonecutter_block"
+		}
+	}
+
+	if properties["type"] == "double" && strings.HasSuffix(name, "_slab") {
+		doubleSlab := strings.TrimSuffix(name, "_slab") + "_double_slab"
+		if bedrockBlockExists(doubleSlab) {
+			name = doubleSlab
+			delete(properties, "type")
+		}
+	}
+
+	if name == "minecraft:daylight_detector" && properties["inverted"] == "true" {
+		name = "minecraft:daylight_detector_inverted"
+	}
+	delete(properties, "inverted")
+
+	if lit, ok := properties["lit"]; ok {
+		litName := "minecraft:lit_" + strings.TrimPrefix(name, "minecraft:")
+		if bedrockBlockExists(litName) {
+			if lit == "true" {
+				name = litName
+			}
+			delete(properties, "lit")
+		}
+	}
+
+	if name == "minecraft:redstone_torch" && properties["lit"] == "false" {
+		name = "minecraft:unlit_redstone_torch"
+	}
+	if name == "minecraft:redstone_torch" || name == "minecraft:unlit_redstone_torch" {
+		delete(properties, "lit")
+	}
+
+	return name
+}
+
+// signName 返回木材种类为 wood 的告示牌在国际版中的名称。
+// kind 为 standing_sign 或 wall_sign
+func signName(wood string, kind string) string {
+	switch wood {
+	case "oak":
+		return "minecraft:" + kind
+	case "dark_oak":
+		return "minecraft:darkoak_" + kind
+	default:
+		return "minecraft:" + wood + "_" + kind
+	}
+}
+
+// translate 将 Java 版方块 javaBlock 转换为国际版方块
+func (b *blockTranslator) translate(javaBlock JavaBlock) (result bedrockBlock) {
+	if javaBlock.Name == "minecraft:structure_void" {
+		result.Skip = true
+		return
+	}
+
+	properties := maps.Clone(javaBlock.Properties)
+	if properties == nil {
+		properties = make(map[string]string)
+	}
+	result.Waterlogged = properties["waterlogged"] == "true"
+	_, isSkull := javaSkullType[javaBlock.Name]
+
+	result.Name = bedrockName(javaBlock, properties)
+	defaultStates, found := bedrockDefaultStates(result.Name)
+	if !found {
+		result.Skip = true
+		result.Problems = append(result.Problems, fmt.Sprintf("国际版中不存在名为 %s 的方块", result.Name))
+		return
+	}
+	result.States = defaultStates
+
+	// 头颅的朝向由 facing_direction 描述，
+	// 放置在地面上的头颅的旋转角度由方块实体描述
+	if isSkull {
+		if _, ok := properties["facing"]; !ok {
+			result.States["facing_direction"] = int32(1)
+		}
+		delete(properties, "rotation")
+		delete(properties, "powered")
+	}
+
+	// 站立的火把没有 facing 属性
+	if _, ok := defaultStates["torch_facing_direction"]; ok {
+		if _, ok := properties["facing"]; !ok {
+			result.States["torch_facing_direction"] = "top"
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		value := properties[key]
+		if key == "shape" {
+			if _, ok := defaultStates["rail_direction"]; !ok {
+				continue
+			}
+		} else if ignoredJavaProperties[key] {
+			continue
+		}
+
+		candidates, ignore := propertyCandidates(result.Name, key, value, properties)
+		if ignore {
+			continue
+		}
+		if !b.applyCandidates(&result, candidates) {
+			result.Problems = append(result.Problems, fmt.Sprintf("无法转换方块状态 %s=%s", key, value))
+		}
+	}
+
+	return
+}
+
+// applyCandidates 依次尝试将 candidates 中的方块状态应用到 result 上，
+// 并返回是否有方块状态被成功应用
+func (b *blockTranslator) applyCandidates(result *bedrockBlock, candidates []stateCandidate) bool {
+	for _, candidate := range candidates {
+		defaultValue, ok := result.States[candidate.key]
+		if !ok {
+			continue
+		}
+		value, ok := convertStateValue(defaultValue, candidate.value)
+		if !ok {
+			continue
+		}
+
+		result.States[candidate.key] = value
+		if bedrockStatesValid(result.Name, result.States) {
+			if !slices.Contains(result.MappedStates, candidate.key) {
+				result.MappedStates = append(result.MappedStates, candidate.key)
+			}
+			return true
+		}
+		result.States[candidate.key] = defaultValue
+	}
+	return false
+}
+
+// propertyCandidates 返回 Java 版方块状态 key=value
+// 在国际版方块 name 中可能的对应方块状态。
+// properties 是该方块的全部 Java 版方块状态。
+// 如果此方块状态应被忽略，则 ignore 为真
+func propertyCandidates(
+	name string,
+	key string,
+	value string,
+	properties map[string]string,
+) (candidates []stateCandidate, ignore bool) {
+	switch key {
+	case "facing":
+		return facingCandidates(name, value, properties["face"]), false
+	case "face":
+		switch value {
+		case "floor":
+			candidates = append(candidates, stateCandidate{"attachment", "standing"})
+		case "ceiling":
+			candidates = append(candidates, stateCandidate{"attachment", "hanging"})
+		case "wall":
+			candidates = append(candidates, stateCandidate{"attachment", "side"})
+		}
+		if len(candidates) > 0 && !strings.HasSuffix(name, "grindstone") && !strings.HasSuffix(name, "bell") {
+			// 按钮和拉杆的 face 已在 facing 中处理
+			return nil, true
+		}
+		return candidates, false
+	case "attachment":
+		switch value {
+		case "floor":
+			return []stateCandidate{{"attachment", "standing"}}, false
+		case "ceiling":
+			return []stateCandidate{{"attachment", "hanging"}}, false
+		case "single_wall":
+			return []stateCandidate{{"attachment", "side"}}, false
+		case "double_wall":
+			return []stateCandidate{{"attachment", "multiple"}}, false
+		}
+	case "half":
+		switch value {
+		case "top", "upper":
+			return []stateCandidate{
+				{"upside_down_bit", "true"},
+				{"upper_block_bit", "true"},
+				{"minecraft:vertical_half", "top"},
+			}, false
+		case "bottom", "lower":
+			return []stateCandidate{
+				{"upside_down_bit", "false"},
+				{"upper_block_bit", "false"},
+				{"minecraft:vertical_half", "bottom"},
+			}, false
+		}
+	case "type":
+		switch value {
+		case "top", "bottom":
+			return []stateCandidate{{"minecraft:vertical_half", value}}, false
+		case "single", "left", "right":
+			// 国际版的大箱子由方块实体描述
+			return nil, true
+		}
+	case "part":
+		return []stateCandidate{{"head_piece_bit", strconv.FormatBool(value == "head")}}, false
+	case "hinge":
+		return []stateCandidate{{"door_hinge_bit", strconv.FormatBool(value == "right")}}, false
+	case "rotation":
+		return []stateCandidate{{"ground_sign_direction", value}}, false
+	case "axis":
+		return []stateCandidate{{"pillar_axis", value}}, false
+	case "shape":
+		if direction, ok := railShape[value]; ok {
+			return []stateCandidate{{"rail_direction", direction}}, false
+		}
+	case "powered":
+		return []stateCandidate{
+			{"powered_bit", value},
+			{"button_pressed_bit", value},
+			{"rail_data_bit", value},
+			{"open_bit", value},
+		}, false
+	case "open", "conditional", "triggered", "attached", "occupied", "in_wall", "persistent", "extended":
+		return []stateCandidate{{key + "_bit", value}}, false
+	case "hanging":
+		return []stateCandidate{{"hanging", value}}, false
+	case "power":
+		return []stateCandidate{{"redstone_signal", value}}, false
+	case "level":
+		return []stateCandidate{
+			{"liquid_depth", value},
+			{"composter_fill_level", value},
+			{"fill_level", value},
+		}, false
+	case "layers":
+		return []stateCandidate{{"height", offsetNumber(value, -1)}}, false
+	case "candles":
+		return []stateCandidate{{"candles", offsetNumber(value, -1)}}, false
+	case "delay":
+		return []stateCandidate{{"repeater_delay", offsetNumber(value, -1)}}, false
+	case "pickles":
+		return []stateCandidate{{"cluster_count", offsetNumber(value, -1)}}, false
+	case "enabled":
+		return []stateCandidate{{"toggle_bit", invertBool(value)}}, false
+	case "lit":
+		return []stateCandidate{
+			{"lit", value},
+			{"extinguished", invertBool(value)},
+		}, false
+	case "age":
+		return []stateCandidate{{"growth", value}, {"age", value}}, false
+	case "bites":
+		return []stateCandidate{{"bite_counter", value}}, false
+	case "moisture":
+		return []stateCandidate{{"moisturized_amount", value}}, false
+	case "charges":
+		return []stateCandidate{{"respawn_anchor_charge", value}}, false
+	case "mode":
+		if strings.HasSuffix(name, "comparator") {
+			return []stateCandidate{{"output_subtract_bit", strconv.FormatBool(value == "subtract")}}, false
+		}
+	case "locked":
+		// 红石中继器的锁定状态由游戏自动计算
+		return nil, true
+	}
+
+	return []stateCandidate{{key, value}}, false
+}
+
+// facingCandidates 返回 Java 版方块状态 facing=value
+// 在国际版方块 name 中可能的对应方块状态。
+// face 是该方块的 face 属性，它可能为空
+func facingCandidates(name string, value string, face string) (candidates []stateCandidate) {
+	if strings.HasSuffix(name, "lever") {
+		axis := "north_south"
+		if value == "east" || value == "west" {
+			axis = "east_west"
+		}
+		switch face {
+		case "floor":
+			return []stateCandidate{{"lever_direction", "up_" + axis}}
+		case "ceiling":
+			return []stateCandidate{{"lever_direction", "down_" + axis}}
+		default:
+			return []stateCandidate{{"lever_direction", value}}
+		}
+	}
+
+	switch face {
+	case "floor":
+		value = "up"
+	case "ceiling":
+		value = "down"
+	}
+
+	if facing, ok := torchFacing[value]; ok {
+		candidates = append(candidates, stateCandidate{"torch_facing_direction", facing})
+	}
+	candidates = append(candidates,
+		stateCandidate{"minecraft:cardinal_direction", value},
+		stateCandidate{"minecraft:facing_direction", value},
+		stateCandidate{"minecraft:block_face", value},
+	)
+	if index, ok := directionIndex[value]; ok {
+		candidates = append(candidates, stateCandidate{"facing_direction", index})
+	}
+
+	switch {
+	case strings.HasSuffix(name, "_door"):
+		if index, ok := doorIndex[value]; ok {
+			candidates = append(candidates, stateCandidate{"direction", index})
+		}
+	case strings.HasSuffix(name, "trapdoor"):
+		if index, ok := weirdoIndex[value]; ok {
+			candidates = append(candidates, stateCandidate{"direction", index})
+		}
+	default:
+		if index, ok := cardinalIndex[value]; ok {
+			candidates = append(candidates, stateCandidate{"direction", index})
+		}
+	}
+	if index, ok := weirdoIndex[value]; ok {
+		candidates = append(candidates, stateCandidate{"weirdo_direction", index})
+	}
+
+	return
+}
+
+// TranslateBlock 将 Java 版方块 javaBlock 转换为国际版方块，
+// 并返回国际版方块的名称和完整的方块状态。
+// problems 是转换期间遇到的问题。
+// 如果国际版中不存在对应的方块，则 found 为假
+func TranslateBlock(javaBlock JavaBlock) (name string, states map[string]any, problems []string, found bool) {
+	result := newBlockTranslator().Translate(javaBlock)
+	if result.Skip {
+		return "", nil, result.Problems, false
+	}
+	return result.Name, result.States, result.Problems, true
+}
+
+// TranslateBlockFilter 将作为筛选条件的 Java 版方块 javaBlock 转换为国际版方块，
+// 例如 execute if block 和 fill 的 replace 模式所使用的方块。
+//
+// 与 TranslateBlock 不同，返回的 states 只包含由 javaBlock 的方块状态转换而来的
+// 国际版方块状态。未被指定的方块状态不会被补全为默认值，否则筛选条件将比 Java 版
+// 更加严格。如果国际版中不存在对应的方块，则 found 为假
+func TranslateBlockFilter(javaBlock JavaBlock) (name string, states map[string]any, problems []string, found bool) {
+	result := newBlockTranslator().Translate(javaBlock)
+	if result.Skip {
+		return "", nil, result.Problems, false
+	}
+
+	states = make(map[string]any)
+	for _, key := range result.MappedStates {
+		states[key] = result.States[key]
+	}
+	return result.Name, states, result.Problems, true
+}
@@ -0,0 +1,174 @@
+package schematic
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
+)
+
+// pottedPlantRenames 描述了名称不能直接由花盆方块名推出的盆栽植物
+var pottedPlantRenames = map[string]string{
+	"minecraft:potted_azalea_bush":           "minecraft:azalea",
+	"minecraft:potted_flowering_azalea_bush": "minecraft:flowering_azalea",
+}
+
+// ignoredBlockEntityKeys 是 Java 版方块实体中不携带实际数据的字段
+var ignoredBlockEntityKeys = map[string]bool{
+	"id":         true,
+	"Id":         true,
+	"x":          true,
+	"y":          true,
+	"z":          true,
+	"Pos":        true,
+	"keepPacked": true,
+}
+
+// Import 根据文件名 fileName 的扩展名读取 Java 版结构文件 data，
+// 并将其转换为国际版结构。
+// 支持的扩展名有 .schem, .schematic, .litematic 和 .nbt。
+//
+// .schem 和 .schematic 文件既可能是 Sponge 格式，也可能是 MCEdit
+// 的旧版格式，因此它们的格式将根据 NBT 根标签的内容确定
+func Import(fileName string, data []byte) (result *mcstructure.Structure, report *Report, err error) {
+	var javaStructure *JavaStructure
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".schem", ".schematic":
+		javaStructure, err = readSchematic(data)
+	case ".litematic":
+		javaStructure, err = ReadLitematic(data)
+	case ".nbt":
+		javaStructure, err = ReadStructureNBT(data)
+	default:
+		return nil, nil, fmt.Errorf("Import: Unsupported file type %#v", fileName)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Import: %v", err)
+	}
+
+	result, report = Convert(javaStructure)
+	return result, report, nil
+}
+
+// readSchematic 读取 Sponge 格式或旧版格式的结构文件 data
+func readSchematic(data []byte) (*JavaStructure, error) {
+	root, err := decodeJavaNBT(data)
+	if err != nil {
+		return nil, fmt.Errorf("readSchematic: %v", err)
+	}
+
+	var structure *JavaStructure
+	switch {
+	case isSponge(root):
+		structure, err = readSponge(root)
+	case isMCEdit(root):
+		structure, err = readMCEdit(root)
+	default:
+		return nil, fmt.Errorf("readSchematic: Unknown schematic format")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("readSchematic: %v", err)
+	}
+	return structure, nil
+}
+
+// Convert 将 Java 版结构 javaStructure 转换为国际版结构。
+// 无法被完整转换的方块将被记录在 report 中。
+//
+// 转换所得结构中的方块实体数据均符合 nbt_parser/block 所期望的格式，
+// 因此可以直接通过 NBTAssigner 放置
+func Convert(javaStructure *JavaStructure) (result *mcstructure.Structure, report *Report) {
+	translator := newBlockTranslator()
+	report = new(Report)
+	result = mcstructure.NewStructure(javaStructure.Size)
+
+	translated := make([]bedrockBlock, len(javaStructure.Palette))
+	for index, javaBlock := range javaStructure.Palette {
+		translated[index] = translator.Translate(javaBlock)
+	}
+
+	for index, paletteIndex := range javaStructure.Blocks {
+		if paletteIndex < 0 || int(paletteIndex) >= len(translated) {
+			continue
+		}
+		javaBlock := javaStructure.Palette[paletteIndex]
+		bedrock := translated[paletteIndex]
+		for _, problem := range bedrock.Problems {
+			report.add(javaBlock.String(), problem)
+		}
+		if bedrock.Skip {
+			continue
+		}
+
+		pos := result.Position(int32(index))
+		_ = result.SetBlock(pos, mcstructure.LayerNormal, mcstructure.BlockPalette{
+			Name:    bedrock.Name,
+			States:  bedrock.States,
+			Version: mcstructure.DefaultBlockVersion,
+		})
+		if bedrock.Waterlogged {
+			_ = result.SetBlock(pos, mcstructure.LayerWaterlogged, mcstructure.BlockPalette{
+				Name:    "minecraft:water",
+				States:  map[string]any{"liquid_depth": int32(0)},
+				Version: mcstructure.DefaultBlockVersion,
+			})
+		}
+
+		blockNBT, problems := convertBlockEntity(translator, javaBlock, bedrock, javaStructure.BlockEntities[int32(index)])
+		for _, problem := range problems {
+			report.add(javaBlock.Name, problem)
+		}
+		if blockNBT != nil {
+			blockNBT["x"], blockNBT["y"], blockNBT["z"] = pos[0], pos[1], pos[2]
+			_ = result.SetBlockEntity(pos, blockNBT)
+		}
+	}
+
+	return
+}
+
+// convertBlockEntity 将 Java 版方块 javaBlock 的方块实体数据 javaNBT
+// 转换为国际版方块 bedrock 的方块实体数据。javaNBT 可以为空
+func convertBlockEntity(
+	translator *blockTranslator,
+	javaBlock JavaBlock,
+	bedrock bedrockBlock,
+	javaNBT map[string]any,
+) (result map[string]any, problems []string) {
+	// Java 版的盆栽是不同的方块，
+	// 而国际版的盆栽由花盆的方块实体描述
+	if strings.HasPrefix(javaBlock.Name, "minecraft:potted_") {
+		plantName, ok := pottedPlantRenames[javaBlock.Name]
+		if !ok {
+			plantName = "minecraft:" + strings.TrimPrefix(javaBlock.Name, "minecraft:potted_")
+		}
+		plant := translator.Translate(JavaBlock{Name: plantName})
+		if plant.Skip {
+			return nil, plant.Problems
+		}
+		return map[string]any{
+			"PlantBlock": map[string]any{
+				"name":    plant.Name,
+				"states":  plant.States,
+				"version": mcstructure.DefaultBlockVersion,
+			},
+		}, nil
+	}
+
+	if javaNBT == nil {
+		javaNBT = make(map[string]any)
+	}
+	result, problems, ok := translateBlockEntity(javaBlock, bedrock, javaNBT)
+	if ok {
+		return result, problems
+	}
+
+	for key := range javaNBT {
+		if !ignoredBlockEntityKeys[key] {
+			return nil, []string{"不支持转换其方块实体数据"}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,151 @@
+package schematic
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// JavaBlock 是 Java 版的一个方块状态
+type JavaBlock struct {
+	Name       string
+	Properties map[string]string
+}
+
+// String 返回 JavaBlock 的字符串表示，
+// 例如 minecraft:oak_stairs[facing=east,half=bottom]
+func (j JavaBlock) String() string {
+	if len(j.Properties) == 0 {
+		return j.Name
+	}
+	keys := slices.Sorted(maps.Keys(j.Properties))
+	properties := make([]string, 0, len(keys))
+	for _, key := range keys {
+		properties = append(properties, key+"="+j.Properties[key])
+	}
+	return fmt.Sprintf("%s[%s]", j.Name, strings.Join(properties, ","))
+}
+
+// ParseJavaBlock 解析形如 minecraft:oak_stairs[facing=east]
+// 的 Java 版方块状态字符串
+func ParseJavaBlock(blockString string) JavaBlock {
+	result := JavaBlock{
+		Properties: make(map[string]string),
+	}
+
+	name, properties, found := strings.Cut(strings.TrimSpace(blockString), "[")
+	result.Name = normalizeName(name)
+	if !found {
+		return result
+	}
+
+	properties = strings.TrimSuffix(properties, "]")
+	for _, value := range strings.Split(properties, ",") {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok {
+			continue
+		}
+		result.Properties[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+
+	return result
+}
+
+// JavaStructure 是从 Java 版结构文件中读取的方块数据。
+// 方块按照 X, Y, Z 的顺序排列，且 Z 轴变化最快
+type JavaStructure struct {
+	// Size 是结构在 X, Y, Z 轴上的尺寸
+	Size [3]int32
+	// Palette 是结构所使用的方块调色板
+	Palette []JavaBlock
+	// Blocks 是每个位置的方块在调色板中的索引，
+	// 如果某个位置没有方块，则其索引为 -1
+	Blocks []int32
+	// BlockEntities 是 Java 版的方块实体数据，
+	// 它的键是方块实体所在位置的索引
+	BlockEntities map[int32]map[string]any
+}
+
+// newJavaStructure 创建一个尺寸为 size 的空结构
+func newJavaStructure(size [3]int32) *JavaStructure {
+	blocks := make([]int32, int(size[0])*int(size[1])*int(size[2]))
+	for index := range blocks {
+		blocks[index] = -1
+	}
+	return &JavaStructure{
+		Size:          size,
+		Blocks:        blocks,
+		BlockEntities: make(map[int32]map[string]any),
+	}
+}
+
+// contains 检查 (x, y, z) 是否在结构内
+func (j *JavaStructure) contains(x int32, y int32, z int32) bool {
+	return x >= 0 && y >= 0 && z >= 0 && x < j.Size[0] && y < j.Size[1] && z < j.Size[2]
+}
+
+// index 返回 (x, y, z) 在结构中的索引
+func (j *JavaStructure) index(x int32, y int32, z int32) int32 {
+	return (x*j.Size[1]+y)*j.Size[2] + z
+}
+
+// UntranslatedBlock 描述了无法完整转换的一类方块
+type UntranslatedBlock struct {
+	// JavaBlock 是 Java 版方块的名称或方块状态
+	JavaBlock string
+	// Reason 是无法完整转换的原因
+	Reason string
+	// Count 是这类方块在结构中出现的次数
+	Count int
+}
+
+// Report 记录了转换期间无法被完整转换的方块
+type Report struct {
+	untranslated map[[2]string]int
+}
+
+// add 记录一个无法完整转换的方块
+func (r *Report) add(javaBlock string, reason string) {
+	if r.untranslated == nil {
+		r.untranslated = make(map[[2]string]int)
+	}
+	r.untranslated[[2]string{javaBlock, reason}]++
+}
+
+// Untranslated 返回所有无法完整转换的方块，
+// 它们按照出现次数从多到少排列
+func (r *Report) Untranslated() []UntranslatedBlock {
+	result := make([]UntranslatedBlock, 0, len(r.untranslated))
+	for key, count := range r.untranslated {
+		result = append(result, UntranslatedBlock{
+			JavaBlock: key[0],
+			Reason:    key[1],
+			Count:     count,
+		})
+	}
+	slices.SortStableFunc(result, func(a UntranslatedBlock, b UntranslatedBlock) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.JavaBlock, b.JavaBlock); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Reason, b.Reason)
+	})
+	return result
+}
+
+// Format 返回 Report 的字符串表示
+func (r *Report) Format(prefix string) string {
+	untranslated := r.Untranslated()
+	if len(untranslated) == 0 {
+		return prefix + "所有方块均已完整转换\n"
+	}
+	result := prefix + fmt.Sprintf("共有 %d 类方块未能完整转换: \n", len(untranslated))
+	for _, value := range untranslated {
+		result += prefix + fmt.Sprintf("\t- %s (%d 个): %s\n", value.JavaBlock, value.Count, value.Reason)
+	}
+	return result
+}
@@ -0,0 +1,309 @@
+package schematic
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// plainText 将 Java 版的 JSON 文本组件 value 转换为纯文本。
+// value 可以是 JSON 字符串，也可以是已解码的 NBT 文本组件
+func plainText(value any) string {
+	switch val := value.(type) {
+	case string:
+		var component any
+		if err := json.Unmarshal([]byte(val), &component); err != nil {
+			return val
+		}
+		if str, ok := component.(string); ok {
+			return str
+		}
+		return componentText(component)
+	default:
+		return componentText(val)
+	}
+}
+
+// componentText 返回已解码的文本组件 component 中的纯文本
+func componentText(component any) string {
+	switch val := component.(type) {
+	case string:
+		return val
+	case map[string]any:
+		result := componentText(val["text"])
+		if translate, ok := val["translate"].(string); ok && len(result) == 0 {
+			result = translate
+		}
+		for _, extra := range utils.NBTListToSlice(val["extra"]) {
+			result += componentText(extra)
+		}
+		return result
+	case nil:
+		return ""
+	}
+
+	if list := utils.NBTListToSlice(component); list != nil {
+		result := ""
+		for _, value := range list {
+			result += componentText(value)
+		}
+		return result
+	}
+	return fmt.Sprintf("%v", component)
+}
+
+// bedrockItemName 返回 Java 版物品 name 在国际版中的名称和特殊值
+func bedrockItemName(name string) (result string, damage int16) {
+	if newName, ok := javaItemRenames[name]; ok {
+		return newName, 0
+	}
+
+	baseName := strings.TrimPrefix(name, "minecraft:")
+	if color, ok := strings.CutSuffix(baseName, "_banner"); ok {
+		if index := slices.Index(javaColors, color); index != -1 {
+			return "minecraft:banner", int16(15 - index)
+		}
+	}
+	if baseName == "oak_door" {
+		return "minecraft:wooden_door", 0
+	}
+
+	return name, 0
+}
+
+// translateItem 将 Java 版物品 javaItem 转换为国际版物品的 NBT。
+// problems 是转换期间遇到的问题
+func translateItem(javaItem map[string]any) (result map[string]any, problems []string, ok bool) {
+	id, _ := javaItem["id"].(string)
+	if len(id) == 0 {
+		return nil, nil, false
+	}
+	id = normalizeName(id)
+
+	count, ok := toInt64(javaItem["Count"])
+	if !ok {
+		count, ok = toInt64(javaItem["count"])
+		if !ok {
+			count = 1
+		}
+	}
+	if count <= 0 {
+		return nil, nil, false
+	}
+
+	name, damage := bedrockItemName(id)
+	result = map[string]any{
+		"Name":        name,
+		"Count":       byte(count),
+		"Damage":      damage,
+		"WasPickedUp": byte(0),
+	}
+	if slot, ok := toInt64(javaItem["Slot"]); ok {
+		result["Slot"] = byte(slot)
+	}
+
+	var tag map[string]any
+	if javaTag, ok := javaItem["tag"].(map[string]any); ok {
+		tag, problems = translateLegacyItemTag(id, javaTag)
+	}
+	if components, ok := javaItem["components"].(map[string]any); ok {
+		tag, problems = translateItemComponents(id, components)
+	}
+	if len(tag) > 0 {
+		result["tag"] = tag
+	}
+
+	return result, problems, true
+}
+
+// translateEnchantments 将 Java 版的附魔 enchantments 转换为国际版的 ench 列表
+func translateEnchantments(enchantments map[string]int64) (ench []any, problems []string) {
+	keys := make([]string, 0, len(enchantments))
+	for key := range enchantments {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		id, ok := javaEnchantmentID[normalizeName(key)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("无法转换附魔 %s", key))
+			continue
+		}
+		ench = append(ench, map[string]any{
+			"id":  id,
+			"lvl": int16(enchantments[key]),
+		})
+	}
+	return
+}
+
+// translateLegacyItemTag 将 Java 版 1.20.5 之前的物品标签 javaTag 转换为国际版的物品标签
+func translateLegacyItemTag(id string, javaTag map[string]any) (tag map[string]any, problems []string) {
+	tag = make(map[string]any)
+
+	if display, ok := javaTag["display"].(map[string]any); ok {
+		if name, ok := display["Name"]; ok {
+			tag["display"] = map[string]any{"Name": plainText(name)}
+		}
+	}
+
+	if damage, ok := toInt64(javaTag["Damage"]); ok && damage > 0 {
+		tag["Damage"] = int32(damage)
+	}
+
+	enchantments := make(map[string]int64)
+	for _, key := range []string{"Enchantments", "StoredEnchantments"} {
+		for _, value := range utils.NBTListToSlice(javaTag[key]) {
+			enchMap, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			enchID, _ := enchMap["id"].(string)
+			level, _ := toInt64(enchMap["lvl"])
+			if len(enchID) > 0 {
+				enchantments[enchID] = level
+			}
+		}
+	}
+	if len(enchantments) > 0 {
+		ench, enchProblems := translateEnchantments(enchantments)
+		problems = append(problems, enchProblems...)
+		if len(ench) > 0 {
+			tag["ench"] = ench
+		}
+	}
+
+	if pages := utils.NBTListToSlice(javaTag["pages"]); pages != nil {
+		bookPages := make([]any, 0, len(pages))
+		for _, page := range pages {
+			text := fmt.Sprintf("%v", page)
+			if id == "minecraft:written_book" {
+				text = plainText(page)
+			}
+			bookPages = append(bookPages, map[string]any{"text": text, "photoname": ""})
+		}
+		tag["pages"] = bookPages
+	}
+	if title, ok := javaTag["title"].(string); ok {
+		tag["title"] = title
+	}
+	if author, ok := javaTag["author"].(string); ok {
+		tag["author"] = author
+	}
+
+	if _, ok := javaTag["BlockEntityTag"]; ok {
+		problems = append(problems, fmt.Sprintf("物品 %s 的方块实体数据未被转换", id))
+	}
+
+	return
+}
+
+// translateItemComponents 将 Java 版 1.20.5 及以后的物品组件 components 转换为国际版的物品标签
+func translateItemComponents(id string, components map[string]any) (tag map[string]any, problems []string) {
+	tag = make(map[string]any)
+
+	if name, ok := components["minecraft:custom_name"]; ok {
+		tag["display"] = map[string]any{"Name": plainText(name)}
+	}
+
+	if damage, ok := toInt64(components["minecraft:damage"]); ok && damage > 0 {
+		tag["Damage"] = int32(damage)
+	}
+
+	enchantments := make(map[string]int64)
+	for _, key := range []string{"minecraft:enchantments", "minecraft:stored_enchantments"} {
+		enchMap, ok := components[key].(map[string]any)
+		if !ok {
+			continue
+		}
+		if levels, ok := enchMap["levels"].(map[string]any); ok {
+			enchMap = levels
+		}
+		for enchID, value := range enchMap {
+			if level, ok := toInt64(value); ok {
+				enchantments[enchID] = level
+			}
+		}
+	}
+	if len(enchantments) > 0 {
+		ench, enchProblems := translateEnchantments(enchantments)
+		problems = append(problems, enchProblems...)
+		if len(ench) > 0 {
+			tag["ench"] = ench
+		}
+	}
+
+	for _, key := range []string{"minecraft:written_book_content", "minecraft:writable_book_content"} {
+		content, ok := components[key].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		pages := utils.NBTListToSlice(content["pages"])
+		bookPages := make([]any, 0, len(pages))
+		for _, page := range pages {
+			if pageMap, ok := page.(map[string]any); ok {
+				page = pageMap["raw"]
+			}
+			bookPages = append(bookPages, map[string]any{"text": plainText(page), "photoname": ""})
+		}
+		tag["pages"] = bookPages
+
+		title := content["title"]
+		if titleMap, ok := title.(map[string]any); ok {
+			title = titleMap["raw"]
+		}
+		if title, ok := title.(string); ok {
+			tag["title"] = title
+		}
+		if author, ok := content["author"].(string); ok {
+			tag["author"] = author
+		}
+	}
+
+	for _, key := range []string{"minecraft:container", "minecraft:block_entity_data"} {
+		if _, ok := components[key]; ok {
+			problems = append(problems, fmt.Sprintf("物品 %s 的方块实体数据未被转换", id))
+			break
+		}
+	}
+
+	return
+}
+
+// translateItems 将 Java 版的物品列表 javaItems 转换为国际版的物品列表。
+// slotMapping 用于将 Java 版的槽位映射为国际版的槽位，它可以为空
+func translateItems(javaItems any, slotMapping map[byte]byte) (items []any, problems []string) {
+	items = make([]any, 0)
+	for _, value := range utils.NBTListToSlice(javaItems) {
+		itemMap, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		item, itemProblems, ok := translateItem(itemMap)
+		problems = append(problems, itemProblems...)
+		if !ok {
+			continue
+		}
+
+		if slot, ok := item["Slot"].(byte); ok && slotMapping != nil {
+			newSlot, ok := slotMapping[slot]
+			if !ok {
+				continue
+			}
+			item["Slot"] = newSlot
+		}
+		items = append(items, item)
+	}
+	return
+}
+
+// TranslateItemName 返回 Java 版物品 name 在国际版中的名称和特殊值
+func TranslateItemName(name string) (result string, damage int16) {
+	return bedrockItemName(normalizeName(name))
+}
@@ -0,0 +1,360 @@
+package schematic
+
+import "strconv"
+
+// legacyWoods 是旧版木材的名称，它们按照旧版的数据值排列
+var legacyWoods = []string{"oak", "spruce", "birch", "jungle", "acacia", "dark_oak"}
+
+// legacyFacing4 是箱子、熔炉和梯子等方块
+// 的数据值 (2 至 5) 所对应的朝向
+var legacyFacing4 = map[byte]string{2: "north", 3: "south", 4: "west", 5: "east"}
+
+// legacyFacing6 是发射器、活塞和潜影盒等方块
+// 的数据值 (0 至 5) 所对应的朝向
+var legacyFacing6 = []string{"down", "up", "north", "south", "west", "east"}
+
+// legacyStairsFacing 是楼梯的数据值的低 2 位所对应的朝向
+var legacyStairsFacing = []string{"east", "west", "south", "north"}
+
+// legacyHorizontalFacing 是南瓜和带釉陶瓦
+// 的数据值的低 2 位所对应的朝向
+var legacyHorizontalFacing = []string{"south", "west", "north", "east"}
+
+// legacyAxis 是原木和柱状方块的数据值的第 3 至 4 位所对应的轴向
+var legacyAxis = []string{"y", "x", "z"}
+
+// legacyBlockNames 是旧版 (1.13 之前) 数字方块 ID
+// 到方块名称的映射。这些名称是 1.13 之后的 Java 版名称。
+// 数据值会影响名称或方块状态的方块由 legacyJavaBlock 另行处理
+var legacyBlockNames = map[uint16]string{
+	0: "air", 2: "grass_block", 4: "cobblestone", 7: "bedrock",
+	8: "water", 9: "water", 10: "lava", 11: "lava",
+	13: "gravel", 14: "gold_ore", 15: "iron_ore", 16: "coal_ore",
+	20: "glass", 21: "lapis_ore", 22: "lapis_block", 23: "dispenser",
+	25: "note_block", 26: "red_bed", 27: "powered_rail", 28: "detector_rail",
+	29: "sticky_piston", 30: "cobweb", 32: "dead_bush", 33: "piston",
+	34: "piston_head", 36: "moving_piston", 37: "dandelion",
+	39: "brown_mushroom", 40: "red_mushroom", 41: "gold_block", 42: "iron_block",
+	45: "bricks", 46: "tnt", 47: "bookshelf", 48: "mossy_cobblestone",
+	49: "obsidian", 51: "fire", 52: "spawner", 53: "oak_stairs",
+	54: "chest", 55: "redstone_wire", 56: "diamond_ore", 57: "diamond_block",
+	58: "crafting_table", 59: "wheat", 60: "farmland", 61: "furnace",
+	62: "furnace", 63: "oak_sign", 64: "oak_door", 65: "ladder",
+	66: "rail", 67: "cobblestone_stairs", 68: "oak_wall_sign", 69: "lever",
+	70: "stone_pressure_plate", 71: "iron_door", 72: "oak_pressure_plate",
+	73: "redstone_ore", 74: "redstone_ore", 77: "stone_button",
+	78: "snow", 79: "ice", 80: "snow_block", 81: "cactus",
+	82: "clay", 83: "sugar_cane", 84: "jukebox", 85: "oak_fence",
+	86: "carved_pumpkin", 87: "netherrack", 88: "soul_sand", 89: "glowstone",
+	90: "nether_portal", 91: "jack_o_lantern", 92: "cake", 93: "repeater",
+	94: "repeater", 96: "oak_trapdoor", 99: "brown_mushroom_block",
+	100: "red_mushroom_block", 101: "iron_bars", 102: "glass_pane", 103: "melon",
+	104: "pumpkin_stem", 105: "melon_stem", 106: "vine", 107: "oak_fence_gate",
+	108: "brick_stairs", 109: "stone_brick_stairs", 110: "mycelium", 111: "lily_pad",
+	112: "nether_bricks", 113: "nether_brick_fence", 114: "nether_brick_stairs",
+	115: "nether_wart", 116: "enchanting_table", 117: "brewing_stand",
+	118: "cauldron", 119: "end_portal", 120: "end_portal_frame", 121: "end_stone",
+	122: "dragon_egg", 123: "redstone_lamp", 124: "redstone_lamp", 127: "cocoa",
+	128: "sandstone_stairs", 129: "emerald_ore", 130: "ender_chest",
+	131: "tripwire_hook", 132: "tripwire", 133: "emerald_block",
+	134: "spruce_stairs", 135: "birch_stairs", 136: "jungle_stairs",
+	137: "command_block", 138: "beacon", 140: "flower_pot", 141: "carrots",
+	142: "potatoes", 143: "oak_button", 144: "skeleton_skull", 146: "trapped_chest",
+	147: "light_weighted_pressure_plate", 148: "heavy_weighted_pressure_plate",
+	149: "comparator", 150: "comparator", 151: "daylight_detector",
+	152: "redstone_block", 153: "nether_quartz_ore", 154: "hopper",
+	156: "quartz_stairs", 157: "activator_rail", 158: "dropper",
+	163: "acacia_stairs", 164: "dark_oak_stairs", 165: "slime_block",
+	166: "barrier", 167: "iron_trapdoor", 169: "sea_lantern", 170: "hay_block",
+	172: "terracotta", 173: "coal_block", 174: "packed_ice",
+	176: "white_banner", 177: "white_wall_banner", 178: "daylight_detector",
+	180: "red_sandstone_stairs", 183: "spruce_fence_gate", 184: "birch_fence_gate",
+	185: "jungle_fence_gate", 186: "dark_oak_fence_gate", 187: "acacia_fence_gate",
+	188: "spruce_fence", 189: "birch_fence", 190: "jungle_fence",
+	191: "dark_oak_fence", 192: "acacia_fence", 193: "spruce_door",
+	194: "birch_door", 195: "jungle_door", 196: "acacia_door", 197: "dark_oak_door",
+	198: "end_rod", 199: "chorus_plant", 200: "chorus_flower", 201: "purpur_block",
+	202: "purpur_pillar", 203: "purpur_stairs", 206: "end_stone_bricks",
+	207: "beetroots", 208: "dirt_path", 209: "end_gateway",
+	210: "repeating_command_block", 211: "chain_command_block", 212: "frosted_ice",
+	213: "magma_block", 214: "nether_wart_block", 215: "red_nether_bricks",
+	216: "bone_block", 217: "structure_void", 218: "observer", 255: "structure_block",
+}
+
+// legacyColoredBlocks 是以数据值区分颜色的旧版方块，
+// 其值是 1.13 之后的名称中颜色之后的部分
+var legacyColoredBlocks = map[uint16]string{
+	35:  "wool",
+	95:  "stained_glass",
+	159: "terracotta",
+	160: "stained_glass_pane",
+	171: "carpet",
+	251: "concrete",
+	252: "concrete_powder",
+}
+
+// legacyVariantBlocks 是以数据值区分种类的旧版方块，
+// 其值是按照数据值排列的 1.13 之后的名称
+var legacyVariantBlocks = map[uint16][]string{
+	1:   {"stone", "granite", "polished_granite", "diorite", "polished_diorite", "andesite", "polished_andesite"},
+	3:   {"dirt", "coarse_dirt", "podzol"},
+	12:  {"sand", "red_sand"},
+	19:  {"sponge", "wet_sponge"},
+	24:  {"sandstone", "chiseled_sandstone", "cut_sandstone"},
+	31:  {"dead_bush", "grass", "fern"},
+	38:  {"poppy", "blue_orchid", "allium", "azure_bluet", "red_tulip", "orange_tulip", "white_tulip", "pink_tulip", "oxeye_daisy"},
+	97:  {"infested_stone", "infested_cobblestone", "infested_stone_bricks", "infested_mossy_stone_bricks", "infested_cracked_stone_bricks", "infested_chiseled_stone_bricks"},
+	98:  {"stone_bricks", "mossy_stone_bricks", "cracked_stone_bricks", "chiseled_stone_bricks"},
+	139: {"cobblestone_wall", "mossy_cobblestone_wall"},
+	168: {"prismarine", "prismarine_bricks", "dark_prismarine"},
+	179: {"red_sandstone", "chiseled_red_sandstone", "cut_red_sandstone"},
+}
+
+// legacyStoneSlabs 是旧版石台阶 (43, 44) 的数据值的低 3 位所对应的台阶
+var legacyStoneSlabs = []string{
+	"smooth_stone_slab", "sandstone_slab", "petrified_oak_slab", "cobblestone_slab",
+	"brick_slab", "stone_brick_slab", "nether_brick_slab", "quartz_slab",
+}
+
+// legacyDoublePlants 是旧版两格高植物 (175) 的数据值的低 3 位所对应的植物
+var legacyDoublePlants = []string{"sunflower", "lilac", "tall_grass", "large_fern", "rose_bush", "peony"}
+
+// legacyJavaBlock 将旧版数字方块 ID id 和数据值 data
+// 转换为 1.13 之后的 Java 版方块。
+//
+// 对于没有被特殊处理的方块，非零的数据值将被保留为
+// legacy_data 方块状态，因此它在转换时会被报告为无法转换
+func legacyJavaBlock(id uint16, data byte) (result JavaBlock, found bool) {
+	result = JavaBlock{Properties: make(map[string]string)}
+	setName := func(name string) {
+		result.Name = "minecraft:" + name
+	}
+	data &= 0xf
+
+	switch {
+	case legacyColoredBlocks[id] != "":
+		setName(javaColors[data] + "_" + legacyColoredBlocks[id])
+		return result, true
+	case id >= 219 && id <= 234:
+		setName(javaColors[id-219] + "_shulker_box")
+		if int(data) < len(legacyFacing6) {
+			result.Properties["facing"] = legacyFacing6[data]
+		}
+		return result, true
+	case id >= 235 && id <= 250:
+		setName(javaColors[id-235] + "_glazed_terracotta")
+		result.Properties["facing"] = legacyHorizontalFacing[data&3]
+		return result, true
+	}
+	if variants, ok := legacyVariantBlocks[id]; ok {
+		if int(data) >= len(variants) {
+			return result, false
+		}
+		setName(variants[data])
+		return result, true
+	}
+
+	switch id {
+	case 5:
+		if int(data) >= len(legacyWoods) {
+			return result, false
+		}
+		setName(legacyWoods[data] + "_planks")
+		return result, true
+	case 6:
+		if int(data&7) >= len(legacyWoods) {
+			return result, false
+		}
+		setName(legacyWoods[data&7] + "_sapling")
+		return result, true
+	case 17, 162:
+		wood := int(data & 3)
+		if id == 162 {
+			wood += 4
+		}
+		if wood >= len(legacyWoods) {
+			return result, false
+		}
+		if data>>2 == 3 {
+			setName(legacyWoods[wood] + "_wood")
+			return result, true
+		}
+		setName(legacyWoods[wood] + "_log")
+		result.Properties["axis"] = legacyAxis[data>>2]
+		return result, true
+	case 18, 161:
+		wood := int(data & 3)
+		if id == 161 {
+			wood += 4
+		}
+		if wood >= len(legacyWoods) {
+			return result, false
+		}
+		setName(legacyWoods[wood] + "_leaves")
+		result.Properties["persistent"] = strconv.FormatBool(data&4 != 0)
+		return result, true
+	case 43, 44:
+		setName(legacyStoneSlabs[data&7])
+		switch {
+		case id == 43 && data == 8:
+			setName("smooth_stone")
+		case id == 43 && data == 9:
+			setName("smooth_sandstone")
+		case id == 43 && data == 15:
+			setName("smooth_quartz")
+		case id == 43:
+			result.Properties["type"] = "double"
+		case data&8 != 0:
+			result.Properties["type"] = "top"
+		default:
+			result.Properties["type"] = "bottom"
+		}
+		return result, true
+	case 125, 126:
+		if int(data&7) >= len(legacyWoods) {
+			return result, false
+		}
+		setName(legacyWoods[data&7] + "_slab")
+		switch {
+		case id == 125:
+			result.Properties["type"] = "double"
+		case data&8 != 0:
+			result.Properties["type"] = "top"
+		default:
+			result.Properties["type"] = "bottom"
+		}
+		return result, true
+	case 181, 182, 204, 205:
+		if id <= 182 {
+			setName("red_sandstone_slab")
+		} else {
+			setName("purpur_slab")
+		}
+		switch {
+		case id == 181 || id == 204:
+			result.Properties["type"] = "double"
+		case data&8 != 0:
+			result.Properties["type"] = "top"
+		default:
+			result.Properties["type"] = "bottom"
+		}
+		return result, true
+	case 145:
+		anvils := []string{"anvil", "chipped_anvil", "damaged_anvil"}
+		if int(data>>2) >= len(anvils) {
+			return result, false
+		}
+		setName(anvils[data>>2])
+		result.Properties["facing"] = legacyHorizontalFacing[data&3]
+		return result, true
+	case 155:
+		switch {
+		case data == 0:
+			setName("quartz_block")
+		case data == 1:
+			setName("chiseled_quartz_block")
+		case data <= 4:
+			setName("quartz_pillar")
+			result.Properties["axis"] = legacyAxis[data-2]
+		default:
+			return result, false
+		}
+		return result, true
+	case 175:
+		// 上半部分的种类由 ReadMCEdit 根据下方的方块确定
+		if int(data&7) >= len(legacyDoublePlants) {
+			return result, false
+		}
+		setName(legacyDoublePlants[data&7])
+		result.Properties["half"] = "lower"
+		if data&8 != 0 {
+			result.Properties["half"] = "upper"
+		}
+		return result, true
+	case 50, 75, 76:
+		standing, wall := "torch", "wall_torch"
+		if id != 50 {
+			standing, wall = "redstone_torch", "redstone_wall_torch"
+			result.Properties["lit"] = strconv.FormatBool(id == 76)
+		}
+		switch data {
+		case 1, 2, 3, 4:
+			setName(wall)
+			result.Properties["facing"] = legacyStairsFacing[data-1]
+		default:
+			setName(standing)
+		}
+		return result, true
+	}
+
+	name, ok := legacyBlockNames[id]
+	if !ok {
+		return result, false
+	}
+	setName(name)
+
+	switch id {
+	case 8, 9, 10, 11:
+		result.Properties["level"] = strconv.Itoa(int(data))
+	case 53, 67, 108, 109, 114, 128, 134, 135, 136, 156, 163, 164, 180, 203:
+		result.Properties["facing"] = legacyStairsFacing[data&3]
+		result.Properties["half"] = "bottom"
+		if data&4 != 0 {
+			result.Properties["half"] = "top"
+		}
+	case 54, 61, 62, 65, 68, 130, 146, 177:
+		facing, ok := legacyFacing4[data]
+		if !ok {
+			facing = "north"
+		}
+		result.Properties["facing"] = facing
+		if id == 61 || id == 62 {
+			result.Properties["lit"] = strconv.FormatBool(id == 62)
+		}
+	case 23, 29, 33, 137, 158, 198, 210, 211, 218:
+		if int(data&7) < len(legacyFacing6) {
+			result.Properties["facing"] = legacyFacing6[data&7]
+		}
+		switch id {
+		case 23, 158:
+			result.Properties["triggered"] = strconv.FormatBool(data&8 != 0)
+		case 29, 33:
+			result.Properties["extended"] = strconv.FormatBool(data&8 != 0)
+		case 137, 210, 211:
+			result.Properties["conditional"] = strconv.FormatBool(data&8 != 0)
+		case 218:
+			result.Properties["powered"] = strconv.FormatBool(data&8 != 0)
+		}
+	case 154:
+		if int(data&7) < len(legacyFacing6) && data&7 != 1 {
+			result.Properties["facing"] = legacyFacing6[data&7]
+		}
+		result.Properties["enabled"] = strconv.FormatBool(data&8 == 0)
+	case 63, 176:
+		result.Properties["rotation"] = strconv.Itoa(int(data))
+	case 86, 91:
+		result.Properties["facing"] = legacyHorizontalFacing[data&3]
+	case 170, 202, 216:
+		if int(data>>2) < len(legacyAxis) {
+			result.Properties["axis"] = legacyAxis[data>>2]
+		}
+	case 78:
+		result.Properties["layers"] = strconv.Itoa(int(data&7) + 1)
+	case 59, 81, 83, 104, 105, 115, 141, 142, 207:
+		result.Properties["age"] = strconv.Itoa(int(data))
+	case 60:
+		result.Properties["moisture"] = strconv.Itoa(int(data))
+	case 74, 124:
+		result.Properties["lit"] = "true"
+	case 94, 150:
+		result.Properties["powered"] = "true"
+	case 178:
+		result.Properties["inverted"] = "true"
+	default:
+		if data != 0 {
+			result.Properties["legacy_data"] = strconv.Itoa(int(data))
+		}
+	}
+
+	return result, true
+}
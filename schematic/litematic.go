@@ -0,0 +1,165 @@
+package schematic
+
+import (
+	"fmt"
+	"maps"
+	"math/bits"
+
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// ReadLitematic 读取 Litematica 格式 (.litematic) 的结构文件 data。
+// 如果结构文件中存在多个区域，则它们将被合并为一个结构
+func ReadLitematic(data []byte) (*JavaStructure, error) {
+	root, err := decodeJavaNBT(data)
+	if err != nil {
+		return nil, fmt.Errorf("ReadLitematic: %v", err)
+	}
+
+	regions, ok := root["Regions"].(map[string]any)
+	if !ok || len(regions) == 0 {
+		return nil, fmt.Errorf("ReadLitematic: Regions not found")
+	}
+
+	type region struct {
+		name   string
+		min    [3]int32
+		size   [3]int32
+		nbtMap map[string]any
+	}
+
+	// 计算每个区域的最小角与整体的包围盒
+	allRegions := make([]region, 0, len(regions))
+	var boundMin, boundMax [3]int32
+	for name, value := range regions {
+		regionMap, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		position, ok1 := xyzVec3(regionMap["Position"])
+		size, ok2 := xyzVec3(regionMap["Size"])
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("ReadLitematic: Position or size of region %#v not found", name)
+		}
+
+		current := region{name: name, nbtMap: regionMap}
+		for axis := range 3 {
+			current.min[axis] = position[axis]
+			current.size[axis] = size[axis]
+			if size[axis] < 0 {
+				current.min[axis] = position[axis] + size[axis] + 1
+				current.size[axis] = -size[axis]
+			}
+			regionMax := current.min[axis] + current.size[axis]
+			if len(allRegions) == 0 || current.min[axis] < boundMin[axis] {
+				boundMin[axis] = current.min[axis]
+			}
+			if len(allRegions) == 0 || regionMax > boundMax[axis] {
+				boundMax[axis] = regionMax
+			}
+		}
+		allRegions = append(allRegions, current)
+	}
+
+	structure := newJavaStructure([3]int32{
+		boundMax[0] - boundMin[0],
+		boundMax[1] - boundMin[1],
+		boundMax[2] - boundMin[2],
+	})
+	paletteMapping := make(map[string]int32)
+
+	for _, current := range allRegions {
+		// 调色板
+		palette := make([]int32, 0)
+		for _, value := range utils.NBTListToSlice(current.nbtMap["BlockStatePalette"]) {
+			blockMap, _ := value.(map[string]any)
+			name, _ := blockMap["Name"].(string)
+			javaBlock := JavaBlock{
+				Name:       normalizeName(name),
+				Properties: make(map[string]string),
+			}
+			properties, _ := blockMap["Properties"].(map[string]any)
+			for key, val := range properties {
+				javaBlock.Properties[key], _ = val.(string)
+			}
+
+			blockString := javaBlock.String()
+			paletteIndex, ok := paletteMapping[blockString]
+			if !ok {
+				paletteIndex = int32(len(structure.Palette))
+				paletteMapping[blockString] = paletteIndex
+				structure.Palette = append(structure.Palette, javaBlock)
+			}
+			palette = append(palette, paletteIndex)
+		}
+		if len(palette) == 0 {
+			return nil, fmt.Errorf("ReadLitematic: Palette of region %#v not found", current.name)
+		}
+
+		// 方块数据以紧密排列的位存储于长整数数组中，
+		// 且单个方块的数据可能跨越两个长整数
+		longs := make([]uint64, 0)
+		for _, value := range utils.NBTListToSlice(current.nbtMap["BlockStates"]) {
+			val, _ := toInt64(value)
+			longs = append(longs, uint64(val))
+		}
+		bitsPerBlock := max(2, bits.Len(uint(len(palette)-1)))
+		mask := uint64(1)<<bitsPerBlock - 1
+		volume := int64(current.size[0]) * int64(current.size[1]) * int64(current.size[2])
+		if int64(len(longs))*64 < volume*int64(bitsPerBlock) {
+			return nil, fmt.Errorf("ReadLitematic: Block states of region %#v is broken", current.name)
+		}
+
+		for index := range volume {
+			startBit := index * int64(bitsPerBlock)
+			startLong := startBit >> 6
+			endLong := (startBit + int64(bitsPerBlock) - 1) >> 6
+			offset := uint(startBit & 63)
+
+			value := longs[startLong] >> offset
+			if startLong != endLong {
+				value |= longs[endLong] << (64 - offset)
+			}
+			value &= mask
+
+			if int(value) >= len(palette) {
+				return nil, fmt.Errorf("ReadLitematic: Unknown palette index %d in region %#v", value, current.name)
+			}
+
+			x := int32(index % int64(current.size[0]))
+			z := int32(index / int64(current.size[0]) % int64(current.size[2]))
+			y := int32(index / (int64(current.size[0]) * int64(current.size[2])))
+			structure.Blocks[structure.index(
+				current.min[0]-boundMin[0]+x,
+				current.min[1]-boundMin[1]+y,
+				current.min[2]-boundMin[2]+z,
+			)] = palette[value]
+		}
+
+		// 方块实体
+		for _, value := range utils.NBTListToSlice(current.nbtMap["TileEntities"]) {
+			blockEntity, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			pos, ok := xyzVec3(blockEntity)
+			if !ok {
+				continue
+			}
+			x := current.min[0] - boundMin[0] + pos[0]
+			y := current.min[1] - boundMin[1] + pos[1]
+			z := current.min[2] - boundMin[2] + pos[2]
+			if !structure.contains(x, y, z) {
+				continue
+			}
+
+			javaNBT := maps.Clone(blockEntity)
+			delete(javaNBT, "x")
+			delete(javaNBT, "y")
+			delete(javaNBT, "z")
+			structure.BlockEntities[structure.index(x, y, z)] = javaNBT
+		}
+	}
+
+	return structure, nil
+}
@@ -0,0 +1,264 @@
+package schematic
+
+// javaColors 是 Java 版染料颜色的名称，
+// 它们按照 Java 版的颜色 ID 排列
+var javaColors = []string{
+	"white", "orange", "magenta", "light_blue",
+	"yellow", "lime", "pink", "gray",
+	"light_gray", "cyan", "purple", "blue",
+	"brown", "green", "red", "black",
+}
+
+// javaColorToDyeIndex 描述了 Java 版染料颜色的名称
+// 到 mapping.DefaultDyeColor 中相应颜色的索引的映射
+var javaColorToDyeIndex = map[string]int{
+	"white":      0,
+	"light_gray": 1,
+	"gray":       2,
+	"black":      3,
+	"brown":      4,
+	"red":        5,
+	"orange":     6,
+	"yellow":     7,
+	"lime":       8,
+	"green":      9,
+	"cyan":       10,
+	"light_blue": 11,
+	"blue":       12,
+	"purple":     13,
+	"magenta":    14,
+	"pink":       15,
+}
+
+// javaBlockRenames 描述了名称与国际版不同的 Java 版方块
+var javaBlockRenames = map[string]string{
+	"minecraft:cave_air":                   "minecraft:air",
+	"minecraft:void_air":                   "minecraft:air",
+	"minecraft:note_block":                 "minecraft:noteblock",
+	"minecraft:spawner":                    "minecraft:mob_spawner",
+	"minecraft:cobweb":                     "minecraft:web",
+	"minecraft:snow_block":                 "minecraft:snow",
+	"minecraft:snow":                       "minecraft:snow_layer",
+	"minecraft:lily_pad":                   "minecraft:waterlily",
+	"minecraft:dead_bush":                  "minecraft:deadbush",
+	"minecraft:magma_block":                "minecraft:magma",
+	"minecraft:nether_bricks":              "minecraft:nether_brick",
+	"minecraft:red_nether_bricks":          "minecraft:red_nether_brick",
+	"minecraft:terracotta":                 "minecraft:hardened_clay",
+	"minecraft:slime_block":                "minecraft:slime",
+	"minecraft:jack_o_lantern":             "minecraft:lit_pumpkin",
+	"minecraft:sugar_cane":                 "minecraft:reeds",
+	"minecraft:grass":                      "minecraft:short_grass",
+	"minecraft:nether_quartz_ore":          "minecraft:quartz_ore",
+	"minecraft:end_stone_bricks":           "minecraft:end_bricks",
+	"minecraft:melon":                      "minecraft:melon_block",
+	"minecraft:powered_rail":               "minecraft:golden_rail",
+	"minecraft:piston_head":                "minecraft:piston_arm_collision",
+	"minecraft:moving_piston":              "minecraft:moving_block",
+	"minecraft:tripwire":                   "minecraft:trip_wire",
+	"minecraft:wall_torch":                 "minecraft:torch",
+	"minecraft:soul_wall_torch":            "minecraft:soul_torch",
+	"minecraft:redstone_wall_torch":        "minecraft:redstone_torch",
+	"minecraft:comparator":                 "minecraft:unpowered_comparator",
+	"minecraft:repeater":                   "minecraft:unpowered_repeater",
+	"minecraft:bricks":                     "minecraft:brick_block",
+	"minecraft:shulker_box":                "minecraft:undyed_shulker_box",
+	"minecraft:stone_stairs":               "minecraft:normal_stone_stairs",
+	"minecraft:cobblestone_stairs":         "minecraft:stone_stairs",
+	"minecraft:end_stone_brick_stairs":     "minecraft:end_brick_stairs",
+	"minecraft:stone_slab":                 "minecraft:normal_stone_slab",
+	"minecraft:dirt_path":                  "minecraft:grass_path",
+	"minecraft:light":                      "minecraft:light_block",
+	"minecraft:attached_pumpkin_stem":      "minecraft:pumpkin_stem",
+	"minecraft:attached_melon_stem":        "minecraft:melon_stem",
+	"minecraft:sign":                       "minecraft:standing_sign",
+	"minecraft:skeleton_skull":             "minecraft:skull",
+	"minecraft:skeleton_wall_skull":        "minecraft:skull",
+	"minecraft:wither_skeleton_skull":      "minecraft:skull",
+	"minecraft:wither_skeleton_wall_skull": "minecraft:skull",
+	"minecraft:zombie_head":                "minecraft:skull",
+	"minecraft:zombie_wall_head":           "minecraft:skull",
+	"minecraft:player_head":                "minecraft:skull",
+	"minecraft:player_wall_head":           "minecraft:skull",
+	"minecraft:creeper_head":               "minecraft:skull",
+	"minecraft:creeper_wall_head":          "minecraft:skull",
+	"minecraft:dragon_head":                "minecraft:skull",
+	"minecraft:dragon_wall_head":           "minecraft:skull",
+	"minecraft:piglin_head":                "minecraft:skull",
+	"minecraft:piglin_wall_head":           "minecraft:skull",
+}
+
+// javaSkullType 描述了 Java 版头颅方块到国际版头颅类型的映射
+var javaSkullType = map[string]byte{
+	"minecraft:skeleton_skull":             0,
+	"minecraft:skeleton_wall_skull":        0,
+	"minecraft:wither_skeleton_skull":      1,
+	"minecraft:wither_skeleton_wall_skull": 1,
+	"minecraft:zombie_head":                2,
+	"minecraft:zombie_wall_head":           2,
+	"minecraft:player_head":                3,
+	"minecraft:player_wall_head":           3,
+	"minecraft:creeper_head":               4,
+	"minecraft:creeper_wall_head":          4,
+	"minecraft:dragon_head":                5,
+	"minecraft:dragon_wall_head":           5,
+	"minecraft:piglin_head":                6,
+	"minecraft:piglin_wall_head":           6,
+}
+
+// javaItemRenames 描述了名称与国际版不同的 Java 版物品
+var javaItemRenames = map[string]string{
+	"minecraft:cobweb":                     "minecraft:web",
+	"minecraft:lily_pad":                   "minecraft:waterlily",
+	"minecraft:dead_bush":                  "minecraft:deadbush",
+	"minecraft:magma_block":                "minecraft:magma",
+	"minecraft:nether_bricks":              "minecraft:nether_brick",
+	"minecraft:nether_brick":               "minecraft:netherbrick",
+	"minecraft:red_nether_bricks":          "minecraft:red_nether_brick",
+	"minecraft:terracotta":                 "minecraft:hardened_clay",
+	"minecraft:slime_block":                "minecraft:slime",
+	"minecraft:jack_o_lantern":             "minecraft:lit_pumpkin",
+	"minecraft:snow_block":                 "minecraft:snow",
+	"minecraft:snow":                       "minecraft:snow_layer",
+	"minecraft:grass":                      "minecraft:short_grass",
+	"minecraft:nether_quartz_ore":          "minecraft:quartz_ore",
+	"minecraft:end_stone_bricks":           "minecraft:end_bricks",
+	"minecraft:melon":                      "minecraft:melon_block",
+	"minecraft:powered_rail":               "minecraft:golden_rail",
+	"minecraft:note_block":                 "minecraft:noteblock",
+	"minecraft:spawner":                    "minecraft:mob_spawner",
+	"minecraft:bricks":                     "minecraft:brick_block",
+	"minecraft:shulker_box":                "minecraft:undyed_shulker_box",
+	"minecraft:dirt_path":                  "minecraft:grass_path",
+	"minecraft:light":                      "minecraft:light_block",
+	"minecraft:map":                        "minecraft:empty_map",
+	"minecraft:zombified_piglin_spawn_egg": "minecraft:zombie_pigman_spawn_egg",
+}
+
+// javaEnchantmentID 描述了 Java 版附魔到国际版附魔 ID 的映射
+var javaEnchantmentID = map[string]int16{
+	"minecraft:protection":            0,
+	"minecraft:fire_protection":       1,
+	"minecraft:feather_falling":       2,
+	"minecraft:blast_protection":      3,
+	"minecraft:projectile_protection": 4,
+	"minecraft:thorns":                5,
+	"minecraft:respiration":           6,
+	"minecraft:depth_strider":         7,
+	"minecraft:aqua_affinity":         8,
+	"minecraft:sharpness":             9,
+	"minecraft:smite":                 10,
+	"minecraft:bane_of_arthropods":    11,
+	"minecraft:knockback":             12,
+	"minecraft:fire_aspect":           13,
+	"minecraft:looting":               14,
+	"minecraft:efficiency":            15,
+	"minecraft:silk_touch":            16,
+	"minecraft:unbreaking":            17,
+	"minecraft:fortune":               18,
+	"minecraft:power":                 19,
+	"minecraft:punch":                 20,
+	"minecraft:flame":                 21,
+	"minecraft:infinity":              22,
+	"minecraft:luck_of_the_sea":       23,
+	"minecraft:lure":                  24,
+	"minecraft:frost_walker":          25,
+	"minecraft:mending":               26,
+	"minecraft:binding_curse":         27,
+	"minecraft:vanishing_curse":       28,
+	"minecraft:impaling":              29,
+	"minecraft:riptide":               30,
+	"minecraft:loyalty":               31,
+	"minecraft:channeling":            32,
+	"minecraft:multishot":             33,
+	"minecraft:piercing":              34,
+	"minecraft:quick_charge":          35,
+	"minecraft:soul_speed":            36,
+	"minecraft:swift_sneak":           37,
+	"minecraft:wind_burst":            38,
+	"minecraft:density":               39,
+	"minecraft:breach":                40,
+}
+
+// javaBannerPatterns 描述了 Java 版旗帜图案 ID
+// 到国际版 (以及旧版 Java 版) 图案简写的映射
+var javaBannerPatterns = map[string]string{
+	"minecraft:base":                   "b",
+	"minecraft:square_bottom_left":     "bl",
+	"minecraft:square_bottom_right":    "br",
+	"minecraft:square_top_left":        "tl",
+	"minecraft:square_top_right":       "tr",
+	"minecraft:stripe_bottom":          "bs",
+	"minecraft:stripe_top":             "ts",
+	"minecraft:stripe_left":            "ls",
+	"minecraft:stripe_right":           "rs",
+	"minecraft:stripe_center":          "cs",
+	"minecraft:stripe_middle":          "ms",
+	"minecraft:stripe_downright":       "drs",
+	"minecraft:stripe_downleft":        "dls",
+	"minecraft:small_stripes":          "ss",
+	"minecraft:cross":                  "cr",
+	"minecraft:straight_cross":         "sc",
+	"minecraft:triangle_bottom":        "bt",
+	"minecraft:triangle_top":           "tt",
+	"minecraft:triangles_bottom":       "bts",
+	"minecraft:triangles_top":          "tts",
+	"minecraft:diagonal_left":          "ld",
+	"minecraft:diagonal_up_right":      "rd",
+	"minecraft:diagonal_up_left":       "lud",
+	"minecraft:diagonal_right":         "rud",
+	"minecraft:circle":                 "mc",
+	"minecraft:rhombus":                "mr",
+	"minecraft:half_vertical":          "vh",
+	"minecraft:half_horizontal":        "hh",
+	"minecraft:half_vertical_right":    "vhr",
+	"minecraft:half_horizontal_bottom": "hhb",
+	"minecraft:border":                 "bo",
+	"minecraft:curly_border":           "cbo",
+	"minecraft:gradient":               "gra",
+	"minecraft:gradient_up":            "gru",
+	"minecraft:bricks":                 "bri",
+	"minecraft:globe":                  "glb",
+	"minecraft:creeper":                "cre",
+	"minecraft:skull":                  "sku",
+	"minecraft:flower":                 "flo",
+	"minecraft:mojang":                 "moj",
+	"minecraft:piglin":                 "pig",
+	"minecraft:flow":                   "flw",
+	"minecraft:guster":                 "gus",
+}
+
+// ignoredJavaProperties 是转换时被忽略的 Java 版方块状态。
+// 它们要么由游戏根据相邻方块自动计算，
+// 要么在国际版中没有对应的概念
+var ignoredJavaProperties = map[string]bool{
+	"north":           true,
+	"south":           true,
+	"east":            true,
+	"west":            true,
+	"up":              true,
+	"down":            true,
+	"snowy":           true,
+	"distance":        true,
+	"instrument":      true,
+	"note":            true,
+	"waterlogged":     true,
+	"shape":           true,
+	"short":           true,
+	"unstable":        true,
+	"bottom":          true,
+	"drag":            true,
+	"extended":        true,
+	"has_book":        true,
+	"has_bottle_0":    true,
+	"has_bottle_1":    true,
+	"has_bottle_2":    true,
+	"has_record":      true,
+	"slot_0_occupied": true,
+	"slot_1_occupied": true,
+	"slot_2_occupied": true,
+	"slot_3_occupied": true,
+	"slot_4_occupied": true,
+	"slot_5_occupied": true,
+	"signal_fire":     true,
+}
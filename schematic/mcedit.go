@@ -0,0 +1,133 @@
+package schematic
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// ReadMCEdit 读取 MCEdit 和旧版 WorldEdit 所使用的
+// 旧版格式 (.schematic) 的结构文件 data。
+//
+// 旧版格式使用数字方块 ID 和数据值描述方块，
+// 它们将被转换为 1.13 之后的 Java 版方块。
+// 无法识别的方块将被命名为 minecraft:legacy_<ID>，
+// 因此它们会在转换时被记录在 Report 中
+func ReadMCEdit(data []byte) (*JavaStructure, error) {
+	root, err := decodeJavaNBT(data)
+	if err != nil {
+		return nil, fmt.Errorf("ReadMCEdit: %v", err)
+	}
+	structure, err := readMCEdit(root)
+	if err != nil {
+		return nil, fmt.Errorf("ReadMCEdit: %v", err)
+	}
+	return structure, nil
+}
+
+// isMCEdit 检查 NBT 根标签 root 是否是旧版格式的结构文件。
+// 旧版格式的方块数据是字节数组，而 Sponge 格式的第 3 版
+// 则使用同名的复合标签
+func isMCEdit(root map[string]any) bool {
+	if _, ok := root["Materials"]; ok {
+		return true
+	}
+	_, hasData := root["Data"]
+	_, isCompound := root["Blocks"].(map[string]any)
+	return hasData && !isCompound && utils.NBTListToSlice(root["Blocks"]) != nil
+}
+
+// readMCEdit 从 NBT 根标签 root 读取旧版格式的结构文件
+func readMCEdit(root map[string]any) (*JavaStructure, error) {
+	if materials, ok := root["Materials"].(string); ok && materials != "Alpha" {
+		return nil, fmt.Errorf("readMCEdit: Unsupported materials %#v", materials)
+	}
+
+	width, ok1 := toInt64(root["Width"])
+	height, ok2 := toInt64(root["Height"])
+	length, ok3 := toInt64(root["Length"])
+	if !ok1 || !ok2 || !ok3 {
+		return nil, fmt.Errorf("readMCEdit: Size of the schematic not found")
+	}
+	// 与 Sponge 相同，旧版格式也使用无符号的 16 位整数存储尺寸
+	width, height, length = width&0xffff, height&0xffff, length&0xffff
+	volume := int(width * height * length)
+
+	blockIDs := utils.NBTListToSlice(root["Blocks"])
+	blockData := utils.NBTListToSlice(root["Data"])
+	addBlocks := utils.NBTListToSlice(root["AddBlocks"])
+	if len(blockIDs) != volume || len(blockData) != volume {
+		return nil, fmt.Errorf(
+			"readMCEdit: Expected %d blocks, but got %d block IDs and %d data values",
+			volume, len(blockIDs), len(blockData),
+		)
+	}
+
+	// legacyID 返回索引为 index 的方块的方块 ID 和数据值。
+	// AddBlocks 以半字节存储大于 255 的方块 ID 的高 4 位
+	legacyID := func(index int) (id uint16, data byte) {
+		low, _ := blockIDs[index].(byte)
+		data, _ = blockData[index].(byte)
+		id = uint16(low)
+		if index>>1 < len(addBlocks) {
+			high, _ := addBlocks[index>>1].(byte)
+			if index&1 == 0 {
+				id |= uint16(high&0x0f) << 8
+			} else {
+				id |= uint16(high&0xf0) << 4
+			}
+		}
+		return id, data & 0xf
+	}
+
+	structure := newJavaStructure([3]int32{int32(width), int32(height), int32(length)})
+	paletteMapping := make(map[[2]uint16]int32)
+	for index := range volume {
+		id, data := legacyID(index)
+		x := int64(index) % width
+		z := int64(index) / width % length
+		y := int64(index) / (width * length)
+
+		// 两格高植物的上半部分不含有种类，
+		// 因此需要使用下半部分的数据值
+		if id == 175 && data&8 != 0 && y > 0 {
+			belowID, belowData := legacyID(index - int(width*length))
+			if belowID == 175 {
+				data = belowData&7 | 8
+			}
+		}
+
+		key := [2]uint16{id, uint16(data)}
+		paletteIndex, ok := paletteMapping[key]
+		if !ok {
+			javaBlock, found := legacyJavaBlock(id, data)
+			if !found {
+				javaBlock = JavaBlock{
+					Name:       fmt.Sprintf("minecraft:legacy_%d", id),
+					Properties: map[string]string{"legacy_data": strconv.Itoa(int(data))},
+				}
+			}
+			paletteIndex = int32(len(structure.Palette))
+			paletteMapping[key] = paletteIndex
+			structure.Palette = append(structure.Palette, javaBlock)
+		}
+		structure.Blocks[structure.index(int32(x), int32(y), int32(z))] = paletteIndex
+	}
+
+	// 方块实体
+	for _, value := range utils.NBTListToSlice(root["TileEntities"]) {
+		blockEntity, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		pos, ok := xyzVec3(blockEntity)
+		if !ok || !structure.contains(pos[0], pos[1], pos[2]) {
+			continue
+		}
+		structure.BlockEntities[structure.index(pos[0], pos[1], pos[2])] = maps.Clone(blockEntity)
+	}
+
+	return structure, nil
+}
@@ -0,0 +1,89 @@
+package schematic
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// decodeJavaNBT 解码 Java 版的大端序 NBT 数据 data。
+// data 可以是经过 gzip 压缩的
+func decodeJavaNBT(data []byte) (result map[string]any, err error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("decodeJavaNBT: %v", err)
+		}
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("decodeJavaNBT: %v", err)
+		}
+	}
+
+	err = nbt.UnmarshalEncoding(data, &result, nbt.BigEndian)
+	if err != nil {
+		return nil, fmt.Errorf("decodeJavaNBT: %v", err)
+	}
+	return result, nil
+}
+
+// normalizeName 将方块或物品的名称 name 转换为带有命名空间的小写形式
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.Contains(name, ":") {
+		name = "minecraft:" + name
+	}
+	return name
+}
+
+// toInt64 将 NBT 中的整数 value 转换为 int64
+func toInt64(value any) (result int64, ok bool) {
+	switch val := value.(type) {
+	case byte:
+		return int64(int8(val)), true
+	case int16:
+		return int64(val), true
+	case int32:
+		return int64(val), true
+	case int64:
+		return val, true
+	}
+	return 0, false
+}
+
+// toInt32Vec3 将由三个整数组成的 NBT 列表或数组 value 转换为数组
+func toInt32Vec3(value any) (result [3]int32, ok bool) {
+	list := utils.NBTListToSlice(value)
+	if len(list) != 3 {
+		return result, false
+	}
+	for index, val := range list {
+		v, ok := toInt64(val)
+		if !ok {
+			return result, false
+		}
+		result[index] = int32(v)
+	}
+	return result, true
+}
+
+// xyzVec3 将形如 {x, y, z} 的 NBT 复合标签 value 转换为数组
+func xyzVec3(value any) (result [3]int32, ok bool) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return result, false
+	}
+	for index, key := range []string{"x", "y", "z"} {
+		v, ok := toInt64(m[key])
+		if !ok {
+			return result, false
+		}
+		result[index] = int32(v)
+	}
+	return result, true
+}
@@ -0,0 +1,162 @@
+package schematic
+
+import (
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+)
+
+// encodeJavaNBT 将 root 编码为 Java 版的大端序 NBT 数据
+func encodeJavaNBT(t *testing.T, root map[string]any) []byte {
+	data, err := nbt.MarshalEncoding(root, nbt.BigEndian)
+	if err != nil {
+		t.Fatalf("encodeJavaNBT: %v", err)
+	}
+	return data
+}
+
+// blockAt 返回 structure 中位于 (x, y, z) 的方块
+func blockAt(structure *JavaStructure, x int32, y int32, z int32) string {
+	paletteIndex := structure.Blocks[structure.index(x, y, z)]
+	if paletteIndex < 0 {
+		return ""
+	}
+	return structure.Palette[paletteIndex].String()
+}
+
+// spongeFixture 返回一个 2×1×2 的 Sponge 第 2 版结构文件。
+// 它在 (1, 0, 0) 处含有一个箱子，其余位置均为石头
+func spongeFixture(t *testing.T) []byte {
+	return encodeJavaNBT(t, map[string]any{
+		"Version": int32(2),
+		"Width":   int16(2),
+		"Height":  int16(1),
+		"Length":  int16(2),
+		"Palette": map[string]any{
+			"minecraft:stone":              int32(0),
+			"minecraft:chest[facing=east]": int32(1),
+		},
+		"BlockData": [4]byte{0, 1, 0, 0},
+		"BlockEntities": []any{
+			map[string]any{
+				"Pos":   [3]int32{1, 0, 0},
+				"Id":    "minecraft:chest",
+				"Items": []any{},
+			},
+		},
+	})
+}
+
+// mcEditFixture 返回一个 2×2×1 的旧版结构文件
+func mcEditFixture(t *testing.T) []byte {
+	return encodeJavaNBT(t, map[string]any{
+		"Materials": "Alpha",
+		"Width":     int16(2),
+		"Height":    int16(2),
+		"Length":    int16(1),
+		// 索引为 2 的方块的 ID 是 300 (256 + 44)，
+		// 其高 4 位存储在 AddBlocks 的第 2 个字节中
+		"Blocks":    [4]byte{1, 175, 44, 175},
+		"AddBlocks": [2]byte{0, 1},
+		"Data":      [4]byte{1, 2, 0, 8},
+		"TileEntities": []any{
+			map[string]any{"id": "Chest", "x": int32(0), "y": int32(1), "z": int32(0)},
+		},
+	})
+}
+
+func TestReadSponge(t *testing.T) {
+	structure, err := ReadSponge(spongeFixture(t))
+	if err != nil {
+		t.Fatalf("TestReadSponge: %v", err)
+	}
+	if structure.Size != [3]int32{2, 1, 2} {
+		t.Fatalf("TestReadSponge: Unexpected size %v", structure.Size)
+	}
+	if block := blockAt(structure, 1, 0, 0); block != "minecraft:chest[facing=east]" {
+		t.Fatalf("TestReadSponge: Unexpected block %s", block)
+	}
+	if block := blockAt(structure, 1, 0, 1); block != "minecraft:stone" {
+		t.Fatalf("TestReadSponge: Unexpected block %s", block)
+	}
+	if blockEntity := structure.BlockEntities[structure.index(1, 0, 0)]; blockEntity["id"] != "minecraft:chest" {
+		t.Fatalf("TestReadSponge: Unexpected block entity %#v", blockEntity)
+	}
+}
+
+func TestReadMCEdit(t *testing.T) {
+	structure, err := ReadMCEdit(mcEditFixture(t))
+	if err != nil {
+		t.Fatalf("TestReadMCEdit: %v", err)
+	}
+
+	expected := map[[3]int32]string{
+		{0, 0, 0}: "minecraft:granite",
+		{1, 0, 0}: "minecraft:tall_grass[half=lower]",
+		{0, 1, 0}: "minecraft:legacy_300[legacy_data=0]",
+		// 上半部分的种类由下方的方块确定
+		{1, 1, 0}: "minecraft:tall_grass[half=upper]",
+	}
+	for pos, name := range expected {
+		if block := blockAt(structure, pos[0], pos[1], pos[2]); block != name {
+			t.Errorf("TestReadMCEdit: Expected %s at %v, but got %s", name, pos, block)
+		}
+	}
+	if blockEntity := structure.BlockEntities[structure.index(0, 1, 0)]; blockEntity["id"] != "Chest" {
+		t.Fatalf("TestReadMCEdit: Unexpected block entity %#v", blockEntity)
+	}
+}
+
+func TestLegacyJavaBlock(t *testing.T) {
+	tests := []struct {
+		id       uint16
+		data     byte
+		expected string
+	}{
+		{35, 14, "minecraft:red_wool"},
+		{53, 6, "minecraft:oak_stairs[facing=south,half=top]"},
+		{17, 10, "minecraft:birch_log[axis=z]"},
+		{44, 12, "minecraft:brick_slab[type=top]"},
+		{50, 3, "minecraft:wall_torch[facing=south]"},
+		{240, 1, "minecraft:lime_glazed_terracotta[facing=west]"},
+		{64, 3, "minecraft:oak_door[legacy_data=3]"},
+	}
+	for _, test := range tests {
+		block, found := legacyJavaBlock(test.id, test.data)
+		if !found || block.String() != test.expected {
+			t.Errorf("TestLegacyJavaBlock: Expected %s for %d:%d, but got %s", test.expected, test.id, test.data, block)
+		}
+	}
+}
+
+func TestImportDetectFormat(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"sponge.schematic": spongeFixture(t),
+		"mcedit.schematic": mcEditFixture(t),
+		"mcedit.schem":     mcEditFixture(t),
+	} {
+		structure, _, err := Import(name, data)
+		if err != nil {
+			t.Fatalf("TestImportDetectFormat: Failed to import %s: %v", name, err)
+		}
+		if structure.Volume() != 4 {
+			t.Fatalf("TestImportDetectFormat: Unexpected volume %d of %s", structure.Volume(), name)
+		}
+	}
+
+	structure, report, err := Import("mcedit.schematic", mcEditFixture(t))
+	if err != nil {
+		t.Fatalf("TestImportDetectFormat: %v", err)
+	}
+	if block, _ := structure.Block(protocol.BlockPos{0, 0, 0}, 0); block.Name != "minecraft:granite" {
+		t.Fatalf("TestImportDetectFormat: Unexpected block %#v", block)
+	}
+	if len(report.Untranslated()) == 0 {
+		t.Fatalf("TestImportDetectFormat: Expected the unknown legacy block to be reported")
+	}
+
+	if _, _, err = Import("unknown.schematic", encodeJavaNBT(t, map[string]any{"Width": int16(1)})); err == nil {
+		t.Fatalf("TestImportDetectFormat: Expected an error for unknown format")
+	}
+}
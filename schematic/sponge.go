@@ -0,0 +1,141 @@
+package schematic
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// ReadSponge 读取 Sponge 格式 (.schem) 的结构文件 data。
+// 它支持 Sponge Schematic 的第 1 至第 3 版
+func ReadSponge(data []byte) (*JavaStructure, error) {
+	root, err := decodeJavaNBT(data)
+	if err != nil {
+		return nil, fmt.Errorf("ReadSponge: %v", err)
+	}
+	structure, err := readSponge(root)
+	if err != nil {
+		return nil, fmt.Errorf("ReadSponge: %v", err)
+	}
+	return structure, nil
+}
+
+// isSponge 检查 NBT 根标签 root 是否是 Sponge 格式的结构文件。
+// 第 3 版的根标签只含有一个名为 Schematic 的复合标签，
+// 而较旧的版本则在根标签中含有 Palette 和 Version
+func isSponge(root map[string]any) bool {
+	if _, ok := root["Schematic"].(map[string]any); ok {
+		return true
+	}
+	_, hasPalette := root["Palette"]
+	_, hasVersion := root["Version"]
+	return hasPalette && hasVersion
+}
+
+// readSponge 从 NBT 根标签 root 读取 Sponge 格式的结构文件
+func readSponge(root map[string]any) (*JavaStructure, error) {
+	if schematic, ok := root["Schematic"].(map[string]any); ok {
+		root = schematic
+	}
+
+	width, ok1 := toInt64(root["Width"])
+	height, ok2 := toInt64(root["Height"])
+	length, ok3 := toInt64(root["Length"])
+	if !ok1 || !ok2 || !ok3 {
+		return nil, fmt.Errorf("readSponge: Size of the schematic not found")
+	}
+	// Sponge 使用无符号的 16 位整数存储尺寸
+	width, height, length = width&0xffff, height&0xffff, length&0xffff
+
+	// 第 3 版将方块数据置于 Blocks 复合标签中
+	blockContainer := root
+	paletteKey, dataKey, blockEntitiesKey := "Palette", "BlockData", "BlockEntities"
+	if blocks, ok := root["Blocks"].(map[string]any); ok {
+		blockContainer = blocks
+		dataKey = "Data"
+	} else if _, ok := root[blockEntitiesKey]; !ok {
+		blockEntitiesKey = "TileEntities"
+	}
+
+	// 调色板
+	palette, ok := blockContainer[paletteKey].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("readSponge: Palette not found")
+	}
+	paletteMapping := make(map[int32]int32)
+	structure := newJavaStructure([3]int32{int32(width), int32(height), int32(length)})
+	for blockString, value := range palette {
+		id, ok := toInt64(value)
+		if !ok {
+			return nil, fmt.Errorf("readSponge: Invalid palette index %#v for %s", value, blockString)
+		}
+		paletteMapping[int32(id)] = int32(len(structure.Palette))
+		structure.Palette = append(structure.Palette, ParseJavaBlock(blockString))
+	}
+
+	// 方块数据使用可变长整数编码
+	blockData := make([]byte, 0)
+	for _, value := range utils.NBTListToSlice(blockContainer[dataKey]) {
+		b, _ := value.(byte)
+		blockData = append(blockData, b)
+	}
+
+	var index int64
+	for reader := 0; reader < len(blockData); index++ {
+		var value, shift int64
+		for {
+			if reader >= len(blockData) {
+				return nil, fmt.Errorf("readSponge: Block data is broken")
+			}
+			b := blockData[reader]
+			reader++
+			value |= int64(b&0x7f) << shift
+			if b&0x80 == 0 {
+				break
+			}
+			shift += 7
+		}
+
+		if index >= width*height*length {
+			return nil, fmt.Errorf("readSponge: Too many blocks in block data")
+		}
+		x := index % width
+		z := index / width % length
+		y := index / (width * length)
+
+		paletteIndex, ok := paletteMapping[int32(value)]
+		if !ok {
+			return nil, fmt.Errorf("readSponge: Unknown palette index %d", value)
+		}
+		structure.Blocks[structure.index(int32(x), int32(y), int32(z))] = paletteIndex
+	}
+
+	// 方块实体
+	for _, value := range utils.NBTListToSlice(blockContainer[blockEntitiesKey]) {
+		blockEntity, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		pos, ok := toInt32Vec3(blockEntity["Pos"])
+		if !ok || !structure.contains(pos[0], pos[1], pos[2]) {
+			continue
+		}
+
+		javaNBT := make(map[string]any)
+		if data, ok := blockEntity["Data"].(map[string]any); ok {
+			maps.Copy(javaNBT, data)
+		} else {
+			maps.Copy(javaNBT, blockEntity)
+			delete(javaNBT, "Pos")
+			delete(javaNBT, "Id")
+		}
+		if id, ok := blockEntity["Id"].(string); ok {
+			javaNBT["id"] = id
+		}
+
+		structure.BlockEntities[structure.index(pos[0], pos[1], pos[2])] = javaNBT
+	}
+
+	return structure, nil
+}
@@ -0,0 +1,71 @@
+package schematic
+
+import (
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// ReadStructureNBT 读取 Java 版原版结构方块
+// 所保存的结构文件 (.nbt) data。
+//
+// 如果结构文件含有多个调色板，
+// 则只有第一个调色板会被使用
+func ReadStructureNBT(data []byte) (*JavaStructure, error) {
+	root, err := decodeJavaNBT(data)
+	if err != nil {
+		return nil, fmt.Errorf("ReadStructureNBT: %v", err)
+	}
+
+	size, ok := toInt32Vec3(root["size"])
+	if !ok {
+		return nil, fmt.Errorf("ReadStructureNBT: Size of the structure not found")
+	}
+	structure := newJavaStructure(size)
+
+	// 调色板
+	paletteList := utils.NBTListToSlice(root["palette"])
+	if palettes := utils.NBTListToSlice(root["palettes"]); len(paletteList) == 0 && len(palettes) > 0 {
+		paletteList = utils.NBTListToSlice(palettes[0])
+	}
+	for _, value := range paletteList {
+		blockMap, _ := value.(map[string]any)
+		name, _ := blockMap["Name"].(string)
+		javaBlock := JavaBlock{
+			Name:       normalizeName(name),
+			Properties: make(map[string]string),
+		}
+		properties, _ := blockMap["Properties"].(map[string]any)
+		for key, val := range properties {
+			javaBlock.Properties[key], _ = val.(string)
+		}
+		structure.Palette = append(structure.Palette, javaBlock)
+	}
+
+	// 方块与方块实体
+	for _, value := range utils.NBTListToSlice(root["blocks"]) {
+		blockMap, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		state, ok1 := toInt64(blockMap["state"])
+		pos, ok2 := toInt32Vec3(blockMap["pos"])
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("ReadStructureNBT: Invalid block %#v", blockMap)
+		}
+		if state < 0 || int(state) >= len(structure.Palette) {
+			return nil, fmt.Errorf("ReadStructureNBT: Unknown palette index %d", state)
+		}
+		if pos[0] < 0 || pos[1] < 0 || pos[2] < 0 || pos[0] >= size[0] || pos[1] >= size[1] || pos[2] >= size[2] {
+			return nil, fmt.Errorf("ReadStructureNBT: Block position %v is out of the structure", pos)
+		}
+
+		index := structure.index(pos[0], pos[1], pos[2])
+		structure.Blocks[index] = int32(state)
+		if javaNBT, ok := blockMap["nbt"].(map[string]any); ok {
+			structure.BlockEntities[index] = javaNBT
+		}
+	}
+
+	return structure, nil
+}
@@ -0,0 +1,47 @@
+package define
+
+// AnalyzeBlock 是要分析的单个方块
+type AnalyzeBlock struct {
+	X                    int32  `json:"x"`
+	Y                    int32  `json:"y"`
+	Z                    int32  `json:"z"`
+	BlockName            string `json:"block_name"`
+	BlockStatesString    string `json:"block_states_string"`
+	BlockNBTBase64String string `json:"block_nbt_base64_string,omitempty"`
+}
+
+type AnalyzeObtainabilityRequest struct {
+	// StructureBase64String 是可选的，它是经过 Base64 编码的结构文件 (.mcstructure)。
+	// 如果它非空，则结构中的每个方块都将被分析
+	StructureBase64String string `json:"structure_base64_string,omitempty"`
+	// Blocks 是可选的，它是要分析的方块列表。
+	// 它可以与 StructureBase64String 同时给出
+	Blocks []AnalyzeBlock `json:"blocks,omitempty"`
+}
+
+// AnalyzeEntry 描述单个需要特殊制作或无法获取的方块或物品
+type AnalyzeEntry struct {
+	X      int32    `json:"x"`
+	Y      int32    `json:"y"`
+	Z      int32    `json:"z"`
+	IsItem bool     `json:"is_item"`
+	Path   string   `json:"path,omitempty"`
+	Name   string   `json:"name"`
+	Kind   int      `json:"kind"`
+	Makers []string `json:"makers,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+type AnalyzeObtainabilityResponse struct {
+	Success   bool   `json:"success"`
+	ErrorInfo string `json:"error_info"`
+
+	// Entries 是所有需要特殊制作或无法获取的方块和物品
+	Entries []AnalyzeEntry `json:"entries,omitempty"`
+	// BlockStatistics 和 ItemStatistics 分别是可以直接通过命令得到、
+	// 需要特殊制作和无法获取的方块和物品的数量
+	BlockStatistics []int `json:"block_statistics"`
+	ItemStatistics  []int `json:"item_statistics"`
+	// Operations 是导入所有方块估计所需的操作数
+	Operations int `json:"operations"`
+}
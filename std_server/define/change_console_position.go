@@ -10,4 +10,8 @@ type ChangeConsolePosRequest struct {
 type ChangeConsolePosResponse struct {
 	Success   bool   `json:"success"`
 	ErrorInfo string `json:"error_info"`
+
+	// EntityWarning 非空时表示操作台已被成功移动，
+	// 但新的操作台区域中存在可能妨碍导入的实体
+	EntityWarning string `json:"entity_warning,omitempty"`
 }
@@ -17,6 +17,7 @@ const (
 	SystemNamePlaceNBTBlock         = "PlaceNBTBlock"
 	SystemNamePlaceLargeChest       = "PlaceLargeChest"
 	SystemNameGetNBTBlockHash       = "GetNBTBlockHash"
+	SystemNameAnalyzeObtainability  = "AnalyzeObtainability"
 )
 
 type LogRecordRequest struct {
@@ -5,10 +5,41 @@ const (
 	ResponseErrorTypeRuntimeError
 )
 
+const (
+	// BlockValidationModeNone 指示不校验要放置的方块
+	BlockValidationModeNone = iota
+	// BlockValidationModeCorrect 指示在放置前校验要放置的方块，
+	// 并将其自动修正为与原始方块最接近的合法方块
+	BlockValidationModeCorrect
+	// BlockValidationModeReject 指示在放置前校验要放置的方块，
+	// 并在方块不合法时拒绝放置。旧版本的方块和缺少部分方块状态的方块
+	// 仍将被升级或补全后放置，只有含有不合法的值的方块会被拒绝
+	BlockValidationModeReject
+)
+
 type PlaceNBTBlockRequest struct {
 	BlockName            string `json:"block_name"`
 	BlockStatesString    string `json:"block_states_string"`
 	BlockNBTBase64String string `json:"block_nbt_base64_string"`
+	// BlockNBTSNBTString 是可选的，它是以 SNBT 形式给出的方块实体数据。
+	// 如果它非空，则 BlockNBTBase64String 将被忽略，
+	// 这使得使用者可以手动编写要放置的方块实体数据
+	BlockNBTSNBTString string `json:"block_nbt_snbt_string,omitempty"`
+	// MapDataBase64String 是可选的，它是地图 UUID 到地图数据的映射。
+	// 地图数据是经过 Base64 编码的小端序 NBT，它应当包含 colors 字段。
+	// 如果要导入的方块含有已填充地图，则可以通过此字段还原地图的像素。
+	// 这些数据只对本次请求有效
+	MapDataBase64String map[int64]string `json:"map_data_base64_string,omitempty"`
+	// TranslateJavaCommand 指示是否需要将命令方块中的命令视为 Java 版命令，
+	// 并在放置前将其转换为国际版命令。无法转换的命令将被原样保留
+	TranslateJavaCommand bool `json:"translate_java_command,omitempty"`
+	// UpgradeLegacyExecute 指示是否需要在放置前将命令方块中
+	// 1.19.50 之前的旧版 execute 语法升级为新版语法。
+	// 无法升级的命令将被原样保留
+	UpgradeLegacyExecute bool `json:"upgrade_legacy_execute,omitempty"`
+	// BlockValidationMode 指示在放置前如何根据租赁服的方块注册表校验方块。
+	// 它是 BlockValidationMode 系列常量之一，默认不进行校验
+	BlockValidationMode int `json:"block_validation_mode,omitempty"`
 }
 
 type PlaceNBTBlockResponse struct {
@@ -23,4 +54,32 @@ type PlaceNBTBlockResponse struct {
 	OffsetX int32 `json:"offset_x"`
 	OffsetY int32 `json:"offset_y"`
 	OffsetZ int32 `json:"offset_z"`
+
+	// UnreproducibleFields 是方块实体中无法被还原的数据的字段名，
+	// 例如刷怪笼的生成参数。这些数据在导入后将被重置为游戏的默认值
+	UnreproducibleFields []string `json:"unreproducible_fields,omitempty"`
+
+	// CommandTranslationWarnings 是转换 Java 版命令时被忽略或近似处理的内容。
+	// CommandTranslationError 非空时表示命令无法被转换，此时原始命令被原样保留
+	CommandTranslationWarnings []string `json:"command_translation_warnings,omitempty"`
+	CommandTranslationError    string   `json:"command_translation_error,omitempty"`
+
+	// LegacyExecuteChanges 描述了升级旧版 execute 语法时所作出的每一处改动。
+	// LegacyExecuteError 非空时表示命令无法被升级，此时原始命令被原样保留
+	LegacyExecuteChanges []string `json:"legacy_execute_changes,omitempty"`
+	LegacyExecuteError   string   `json:"legacy_execute_error,omitempty"`
+
+	// BlockUpgrades 描述了校验方块时将旧版本的方块升级到当前版本所作出的改动，
+	// BlockDefaults 描述了校验方块时被补全为默认值的每一个方块状态。
+	// 它们在 BlockValidationMode 不为 BlockValidationModeNone 时有效
+	BlockUpgrades []string `json:"block_upgrades,omitempty"`
+	BlockDefaults []string `json:"block_defaults,omitempty"`
+
+	// BlockCorrections 描述了校验方块时为得到合法的方块而作出的每一处修正。
+	// 它仅在 BlockValidationMode 为 BlockValidationModeCorrect 时有效
+	BlockCorrections []string `json:"block_corrections,omitempty"`
+
+	// Differences 描述了放置的方块在多次重试后仍未能通过完整性检查时，
+	// 期望的方块与实际放置的方块之间的每一处差异 (例如某个物品栏中物品的名称不同)
+	Differences []string `json:"differences,omitempty"`
 }
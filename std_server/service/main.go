@@ -22,6 +22,7 @@ var (
 	consoleCenterX       *int
 	consoleCenterY       *int
 	consoleCenterZ       *int
+	chatCommandWhitelist *string
 )
 
 func init() {
@@ -34,6 +35,7 @@ func init() {
 	consoleCenterX = flag.Int("ccx", 0, "The X position of the center of the console.")
 	consoleCenterY = flag.Int("ccy", 0, "The Y position of the center of the console.")
 	consoleCenterZ = flag.Int("ccz", 0, "The Z position of the center of the console.")
+	chatCommandWhitelist = flag.String("ccw", "", "The comma separated names, UUIDs or XUIDs of players who can use chat commands. (Chat commands are disabled if empty)")
 
 	flag.Parse()
     if len(*rentalServerCode) == 0 || len(*authServerAddress) == 0 || *standardServerPort == 0 {
@@ -133,5 +135,6 @@ func main() {
         *consoleCenterX,
         *consoleCenterY,
         *consoleCenterZ,
+        *chatCommandWhitelist,
     )
 }
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/game_control/game_interface"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	"github.com/mcpol-studio/flowers-for-machines/schematic"
+	"github.com/mcpol-studio/flowers-for-machines/std_server/define"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+
+	"github.com/google/uuid"
+	"github.com/pterm/pterm"
+)
+
+const (
+	// playerEyeHeight 是 querytarget 返回的玩家坐标相对于玩家脚部的高度
+	playerEyeHeight = 1.62
+	// chatImportAreaSize 是 import here 命令导入结构时每个区域的边长。
+	// 区域中的方块与区域中心的距离不会超过租赁服的模拟距离
+	chatImportAreaSize = 32
+)
+
+// registerChatCommands 注册标准服务器的聊天命令，
+// 并允许 whitelist 中的玩家使用它们。whitelist 是
+// 以逗号分隔的玩家名称、UUID 或 XUID。如果它为空，
+// 则聊天命令不会被启动
+func registerChatCommands(whitelist string) error {
+	if len(strings.TrimSpace(whitelist)) == 0 {
+		return nil
+	}
+
+	chatCommands := gameInterface.ChatCommands()
+	for _, player := range strings.Split(whitelist, ",") {
+		if player = strings.TrimSpace(player); len(player) > 0 {
+			chatCommands.Allow(player)
+		}
+	}
+
+	for _, command := range []game_interface.ChatCommand{
+		{
+			Name:        "import here",
+			Usage:       "<file>",
+			Description: "将结构文件导入到您所在的位置",
+			Handler:     chatCommandImportHere,
+		},
+		{
+			Name:        "console move",
+			Description: "将操作台移动到您所在的位置",
+			Handler:     chatCommandConsoleMove,
+		},
+		{
+			Name:        "cache stats",
+			Description: "显示缓存命中系统的统计信息",
+			Handler:     chatCommandCacheStats,
+		},
+	} {
+		err := chatCommands.Register(command)
+		if err != nil {
+			return fmt.Errorf("registerChatCommands: %v", err)
+		}
+	}
+
+	err := chatCommands.Start()
+	if err != nil {
+		return fmt.Errorf("registerChatCommands: %v", err)
+	}
+	return nil
+}
+
+// senderPosition 返回聊天命令的发送者所在的维度和方块坐标
+func senderPosition(sender game_interface.ChatCommandSender) (dimensionID uint8, pos protocol.BlockPos, err error) {
+	result, err := gameInterface.Querytarget().DoQuerytarget(fmt.Sprintf(`@a[name="%s"]`, sender.Name))
+	if err != nil {
+		return 0, protocol.BlockPos{}, fmt.Errorf("senderPosition: %v", err)
+	}
+	if len(result) == 0 {
+		return 0, protocol.BlockPos{}, fmt.Errorf("senderPosition: Player %#v is not found", sender.Name)
+	}
+
+	position := result[0].Position
+	return result[0].Dimension, protocol.BlockPos{
+		int32(math.Floor(float64(position.X))),
+		int32(math.Floor(float64(position.Y) - playerEyeHeight)),
+		int32(math.Floor(float64(position.Z))),
+	}, nil
+}
+
+// localPath 返回名为 name 的文件相对于工作目录的路径。
+// 返回的路径不会位于工作目录之外
+func localPath(name string) string {
+	return filepath.Join(".", filepath.Clean("/"+name))
+}
+
+// readStructureFile 读取并解析名为 name 的结构文件。
+// 文件总是相对于工作目录查找，且不能位于工作目录之外。
+// 除 .mcstructure 外，Java 版的结构文件也将被转换后返回
+func readStructureFile(name string) (*mcstructure.Structure, error) {
+	path := localPath(name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readStructureFile: %v", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".mcstructure") {
+		structure, err := mcstructure.Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("readStructureFile: %v", err)
+		}
+		return structure, nil
+	}
+
+	structure, _, err := schematic.Import(filepath.Base(path), data)
+	if err != nil {
+		return nil, fmt.Errorf("readStructureFile: %v", err)
+	}
+	return structure, nil
+}
+
+// readMapDataFile 读取名为 name 的地图数据文件。
+// 它是地图 UUID 到地图数据的 JSON 对象，其格式与
+// PlaceNBTBlock 请求中的 map_data_base64_string 相同。
+// 文件的查找方式与 readStructureFile 的相同
+func readMapDataFile(name string) (map[int64]string, error) {
+	data, err := os.ReadFile(localPath(name))
+	if err != nil {
+		return nil, fmt.Errorf("readMapDataFile: %v", err)
+	}
+
+	var result map[int64]string
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("readMapDataFile: %v", err)
+	}
+	return result, nil
+}
+
+// chatCommandImportHere 实现 import here 命令。
+// 它以发送者所在的位置为原点，在发送者所在的维度导入结构文件。
+//
+// 结构被划分为边长为 chatImportAreaSize 的区域，机器人在导入每个
+// 区域前会被传送到该区域的中心。每个方块都经过与 PlaceNBTBlock
+// 相同的校验和放置流程，并且全局锁只在放置单个方块时被持有，
+// 因此导入期间的其他请求不会被长时间阻塞
+func chatCommandImportHere(request game_interface.ChatCommandRequest) error {
+	if len(request.Args) != 1 && len(request.Args) != 2 {
+		return fmt.Errorf("Usage: import here <file> [map data file]")
+	}
+
+	structure, err := readStructureFile(request.Args[0])
+	if err != nil {
+		return err
+	}
+	var mapData map[int64]string
+	if len(request.Args) == 2 {
+		if mapData, err = readMapDataFile(request.Args[1]); err != nil {
+			return err
+		}
+	}
+	dimensionID, origin, err := senderPosition(request.Sender)
+	if err != nil {
+		return err
+	}
+
+	_ = request.Reply(fmt.Sprintf(
+		"开始在维度 %d 的 (%d,%d,%d) 导入 %s，尺寸为 %v",
+		dimensionID, origin[0], origin[1], origin[2], request.Args[0], structure.Size,
+	))
+
+	importer := chatImporter{
+		structure:   structure,
+		mapData:     mapData,
+		dimensionID: dimensionID,
+		origin:      origin,
+	}
+	for x := int32(0); x < structure.Size[0]; x += chatImportAreaSize {
+		for y := int32(0); y < structure.Size[1]; y += chatImportAreaSize {
+			for z := int32(0); z < structure.Size[2]; z += chatImportAreaSize {
+				importer.importArea(protocol.BlockPos{x, y, z})
+			}
+		}
+	}
+
+	return request.Reply(fmt.Sprintf(
+		"导入完成，已放置 %d 个方块，失败 %d 个",
+		importer.placed, importer.failed,
+	))
+}
+
+// chatImporter 是 import here 命令的单次导入
+type chatImporter struct {
+	structure   *mcstructure.Structure
+	mapData     map[int64]string
+	dimensionID uint8
+	origin      protocol.BlockPos
+
+	placed int
+	failed int
+}
+
+// importArea 导入结构中以 start 为起点的区域
+func (i *chatImporter) importArea(start protocol.BlockPos) {
+	end := protocol.BlockPos{
+		min(start[0]+chatImportAreaSize, i.structure.Size[0]),
+		min(start[1]+chatImportAreaSize, i.structure.Size[1]),
+		min(start[2]+chatImportAreaSize, i.structure.Size[2]),
+	}
+	center := protocol.BlockPos{
+		i.origin[0] + (start[0]+end[0])/2,
+		i.origin[1] + (start[1]+end[1])/2,
+		i.origin[2] + (start[2]+end[2])/2,
+	}
+
+	for x := start[0]; x < end[0]; x++ {
+		for y := start[1]; y < end[1]; y++ {
+			for z := start[2]; z < end[2]; z++ {
+				pos := protocol.BlockPos{x, y, z}
+				block, found := i.structure.Block(pos, 0)
+				if !found || strings.TrimPrefix(block.Name, "minecraft:") == "air" {
+					continue
+				}
+
+				err := i.importBlock(pos, block, center)
+				if err != nil {
+					pterm.Warning.Printfln("chatCommandImportHere: Failed to import block at %v; err = %v", pos, err)
+					i.failed++
+					continue
+				}
+				i.placed++
+			}
+		}
+	}
+}
+
+// importBlock 将结构中位于 pos 的方块 block 放置在相应的位置。
+// center 是该方块所在区域的中心
+func (i *chatImporter) importBlock(pos protocol.BlockPos, block mcstructure.BlockPalette, center protocol.BlockPos) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	target := protocol.BlockPos{i.origin[0] + pos[0], i.origin[1] + pos[1], i.origin[2] + pos[2]}
+	result, err := wrapper.ValidateBlock(block.Name, block.States)
+	if err != nil {
+		return fmt.Errorf("importBlock: %v", err)
+	}
+	blockStatesString := utils.MarshalBlockStates(result.States)
+
+	canFast, uniqueID := true, uuid.Nil
+	if blockNBT, ok := i.structure.BlockEntity(pos); ok {
+		blockNBTBytes, err := nbt.MarshalEncoding(blockNBT, nbt.LittleEndian)
+		if err != nil {
+			return fmt.Errorf("importBlock: %v", err)
+		}
+		response, _ := placeNBTBlock(define.PlaceNBTBlockRequest{
+			BlockName:            result.Name,
+			BlockStatesString:    blockStatesString,
+			BlockNBTBase64String: base64.StdEncoding.EncodeToString(blockNBTBytes),
+			MapDataBase64String:  i.mapData,
+		})
+		if !response.Success {
+			return fmt.Errorf("importBlock: %s", response.ErrorInfo)
+		}
+		if canFast = response.CanFast; !canFast {
+			if uniqueID, err = uuid.Parse(response.StructureUniqueID); err != nil {
+				return fmt.Errorf("importBlock: %v", err)
+			}
+		}
+	}
+
+	err = i.moveToArea(center)
+	if err != nil {
+		return fmt.Errorf("importBlock: %v", err)
+	}
+	// 操作台只会在自己所在的维度中移动机器人，
+	// 因此在其他维度导入时需要将机器人送回操作台
+	if i.dimensionID != console.Dimension() {
+		defer i.moveBack()
+	}
+
+	if !canFast {
+		err = gameInterface.StructureBackup().RevertStructure(uniqueID, target)
+		if err != nil {
+			return fmt.Errorf("importBlock: %v", err)
+		}
+		return nil
+	}
+
+	resp, err := gameInterface.Commands().SendWSCommandWithResp(fmt.Sprintf(
+		"setblock %d %d %d %s %s",
+		target[0], target[1], target[2], result.Name, blockStatesString,
+	))
+	if err != nil {
+		return fmt.Errorf("importBlock: %v", err)
+	}
+	if resp.SuccessCount == 0 {
+		// 目标位置已经是相同的方块
+		for _, message := range resp.OutputMessages {
+			if message.Message == "commands.setblock.noChange" {
+				return nil
+			}
+		}
+		return fmt.Errorf("importBlock: Failed to set block at %v; output = %#v", target, resp.OutputMessages)
+	}
+	return nil
+}
+
+// moveToArea 将机器人传送到区域中心 center 并等待其附近的区块被加载。
+// 如果机器人已经位于 center，则不会进行任何操作。调用者应当持有 mu
+func (i *chatImporter) moveToArea(center protocol.BlockPos) error {
+	if i.dimensionID == console.Dimension() && console.BlockPosition() == center {
+		return nil
+	}
+
+	err := gameInterface.Commands().SendSettingsCommand(
+		fmt.Sprintf(
+			"execute in %s run tp %d %d %d",
+			utils.DimensionNameByID(i.dimensionID), center[0], center[1], center[2],
+		),
+		true,
+	)
+	if err != nil {
+		return fmt.Errorf("moveToArea: %v", err)
+	}
+	err = gameInterface.Commands().AwaitChangesGeneral()
+	if err != nil {
+		return fmt.Errorf("moveToArea: %v", err)
+	}
+
+	console.UpdatePosition(center)
+	return nil
+}
+
+// moveBack 将机器人送回操作台的中心。调用者应当持有 mu
+func (i *chatImporter) moveBack() {
+	center := console.Center()
+	err := gameInterface.Commands().SendSettingsCommand(
+		fmt.Sprintf(
+			"execute in %s run tp %d %d %d",
+			utils.DimensionNameByID(console.Dimension()), center[0], center[1], center[2],
+		),
+		true,
+	)
+	if err != nil {
+		pterm.Warning.Printfln("moveBack: %v", err)
+	}
+	console.UpdatePosition(center)
+}
+
+// chatCommandConsoleMove 实现 console move 命令。
+// 它将操作台的中心移动到发送者所在的位置
+func chatCommandConsoleMove(request game_interface.ChatCommandRequest) error {
+	dimensionID, pos, err := senderPosition(request.Sender)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	err = console.ChangeConsolePosition(dimensionID, pos)
+	entitiesErr, hasEntities := err.(*nbt_console.EntitiesError)
+	if err != nil && !hasEntities {
+		return err
+	}
+	message := fmt.Sprintf("操作台已移动到维度 %d 的 (%d,%d,%d)", dimensionID, pos[0], pos[1], pos[2])
+
+	// 实体数据可能晚于传送的完成到达，因此这只是尽力而为的提示
+	if hasEntities {
+		message += fmt.Sprintf("，但操作台区域中有 %d 个实体 (例如位于 %v 的 %s)，它们可能会妨碍导入",
+			len(entitiesErr.Entities), entitiesErr.Entities[0].Position, entitiesErr.Entities[0].EntityType)
+	}
+	return request.Reply(message)
+}
+
+// chatCommandCacheStats 实现 cache stats 命令
+func chatCommandCacheStats(request game_interface.ChatCommandRequest) error {
+	mu.Lock()
+	completelyCount, setHashCount := cache.NBTBlockCache().CachedCount()
+	baseContainerCount := cache.BaseContainerCache().CachedCount()
+	mapDataCount := cache.MapDataCache().Count()
+	mu.Unlock()
+
+	return request.Reply(fmt.Sprintf(
+		"NBT 方块缓存 %d 个 (其中可按集合哈希命中 %d 个)，基容器缓存 %d 个，地图数据 %d 个",
+		completelyCount, setHashCount, baseContainerCount, mapDataCount,
+	))
+}
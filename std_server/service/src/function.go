@@ -2,15 +2,22 @@ package service
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/mcpol-studio/flowers-for-machines/command_translator"
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
 	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/block_helper"
+	nbt_assigner_interface "github.com/mcpol-studio/flowers-for-machines/nbt_assigner/interface"
+	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_analyzer"
 	"github.com/mcpol-studio/flowers-for-machines/nbt_assigner/nbt_console"
+	nbt_diff "github.com/mcpol-studio/flowers-for-machines/nbt_parser/diff"
 	nbt_hash "github.com/mcpol-studio/flowers-for-machines/nbt_parser/hash"
 	nbt_parser_interface "github.com/mcpol-studio/flowers-for-machines/nbt_parser/interface"
 	"github.com/mcpol-studio/flowers-for-machines/std_server/define"
@@ -63,6 +70,13 @@ func ChangeConsolePosition(c *gin.Context) {
 			request.CenterZ,
 		},
 	)
+	if entitiesErr, ok := err.(*nbt_console.EntitiesError); ok {
+		c.JSON(http.StatusOK, define.ChangeConsolePosResponse{
+			Success:       true,
+			EntityWarning: entitiesErr.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusOK, define.ChangeConsolePosResponse{
 			Success:   false,
@@ -87,7 +101,6 @@ func PlaceNBTBlock(c *gin.Context) {
 	defer mu.Unlock()
 
 	var request define.PlaceNBTBlockRequest
-	var blockNBT map[string]any
 
 	err := c.BindJSON(&request)
 	if err != nil {
@@ -99,56 +112,189 @@ func PlaceNBTBlock(c *gin.Context) {
 		return
 	}
 
-	blockNBTBytes, err := base64.StdEncoding.DecodeString(request.BlockNBTBase64String)
-	if err != nil {
-		c.JSON(http.StatusOK, define.PlaceNBTBlockResponse{
-			Success:   false,
-			ErrorType: define.ResponseErrorTypeParseError,
-			ErrorInfo: fmt.Sprintf("Failed to parse block NBT base64 string; err = %v", err),
-		})
-		return
+	response, placeErr := placeNBTBlock(request)
+	c.JSON(http.StatusOK, response)
+	if placeErr != nil {
+		sendLogRecord(
+			define.SourceDefault,
+			userName,
+			gameInterface.GetBotInfo().BotName,
+			define.SystemNamePlaceNBTBlock,
+			request,
+			fmt.Sprintf("%v", placeErr),
+		)
 	}
-	err = nbt.UnmarshalEncoding(blockNBTBytes, &blockNBT, nbt.LittleEndian)
+}
+
+// placeNBTBlock 处理放置 NBT 方块的请求 request 并返回其响应。
+// 如果放置方块时出现运行时错误，则返回的错误是该错误。
+// 调用者应当持有 mu
+func placeNBTBlock(request define.PlaceNBTBlockRequest) (define.PlaceNBTBlockResponse, error) {
+	var blockNBT map[string]any
+	var err error
+
+	if len(request.BlockNBTSNBTString) > 0 {
+		err = nbt.UnmarshalSNBT(request.BlockNBTSNBTString, &blockNBT)
+		if err != nil {
+			return define.PlaceNBTBlockResponse{
+				Success:   false,
+				ErrorType: define.ResponseErrorTypeParseError,
+				ErrorInfo: fmt.Sprintf("Failed to parse block NBT SNBT string; err = %v", err),
+			}, nil
+		}
+	} else {
+		blockNBTBytes, err := base64.StdEncoding.DecodeString(request.BlockNBTBase64String)
+		if err != nil {
+			return define.PlaceNBTBlockResponse{
+				Success:   false,
+				ErrorType: define.ResponseErrorTypeParseError,
+				ErrorInfo: fmt.Sprintf("Failed to parse block NBT base64 string; err = %v", err),
+			}, nil
+		}
+		err = nbt.UnmarshalEncoding(blockNBTBytes, &blockNBT, nbt.LittleEndian)
+		if err != nil {
+			return define.PlaceNBTBlockResponse{
+				Success:   false,
+				ErrorType: define.ResponseErrorTypeParseError,
+				ErrorInfo: fmt.Sprintf("Block NBT bytes is broken; err = %v", err),
+			}, nil
+		}
+	}
+
+	// 地图数据只对本次请求有效
+	defer wrapper.CleanMapData()
+	for mapUUID, mapDataBase64String := range request.MapDataBase64String {
+		var mapData map[string]any
+
+		mapDataBytes, err := base64.StdEncoding.DecodeString(mapDataBase64String)
+		if err != nil {
+			return define.PlaceNBTBlockResponse{
+				Success:   false,
+				ErrorType: define.ResponseErrorTypeParseError,
+				ErrorInfo: fmt.Sprintf("Failed to parse map data base64 string (map_uuid = %d); err = %v", mapUUID, err),
+			}, nil
+		}
+		err = nbt.UnmarshalEncoding(mapDataBytes, &mapData, nbt.LittleEndian)
+		if err != nil {
+			return define.PlaceNBTBlockResponse{
+				Success:   false,
+				ErrorType: define.ResponseErrorTypeParseError,
+				ErrorInfo: fmt.Sprintf("Map data bytes is broken (map_uuid = %d); err = %v", mapUUID, err),
+			}, nil
+		}
+
+		err = wrapper.StoreMapData(mapUUID, mapData)
+		if err != nil {
+			return define.PlaceNBTBlockResponse{
+				Success:   false,
+				ErrorType: define.ResponseErrorTypeParseError,
+				ErrorInfo: fmt.Sprintf("Map data is invalid (map_uuid = %d); err = %v", mapUUID, err),
+			}, nil
+		}
+	}
+
+	blockName := request.BlockName
+	blockStates := utils.ParseBlockStatesString(request.BlockStatesString)
+	var blockUpgrades, blockDefaults, blockCorrections []string
+	if request.BlockValidationMode != define.BlockValidationModeNone {
+		result, err := wrapper.ValidateBlock(blockName, blockStates)
+		if err == nil && !result.Valid() && request.BlockValidationMode == define.BlockValidationModeReject {
+			err = fmt.Errorf("Block is invalid: %s", strings.Join(result.Corrections, "; "))
+		}
+		if err != nil {
+			return define.PlaceNBTBlockResponse{
+				Success:   false,
+				ErrorType: define.ResponseErrorTypeParseError,
+				ErrorInfo: fmt.Sprintf("Block validation failed; err = %v", err),
+			}, nil
+		}
+		blockName, blockStates = result.Name, result.States
+		blockUpgrades, blockDefaults = result.Upgrades, result.Defaults
+		blockCorrections = result.Corrections
+	}
+
+	var legacyChanges []string
+	var legacyError string
+	if command, ok := blockNBT["Command"].(string); ok && request.UpgradeLegacyExecute {
+		result, changes, err := command_translator.UpgradeLegacyExecute(command)
+		if err != nil {
+			legacyError = fmt.Sprintf("%v", err)
+		} else {
+			blockNBT["Command"] = result
+			legacyChanges = changes
+		}
+	}
+
+	var translationWarnings []string
+	var translationError string
+	if command, ok := blockNBT["Command"].(string); ok && request.TranslateJavaCommand {
+		result, warnings, err := command_translator.Translate(command)
+		if err != nil {
+			translationError = fmt.Sprintf("%v", err)
+		} else {
+			blockNBT["Command"] = result
+			translationWarnings = warnings
+		}
+	}
+
+	unreproducibleFields, err := wrapper.UnreproducibleFields(
+		blockName,
+		blockStates,
+		blockNBT,
+	)
 	if err != nil {
-		c.JSON(http.StatusOK, define.PlaceNBTBlockResponse{
+		return define.PlaceNBTBlockResponse{
 			Success:   false,
 			ErrorType: define.ResponseErrorTypeParseError,
-			ErrorInfo: fmt.Sprintf("Block NBT bytes is broken; err = %v", err),
-		})
-		return
+			ErrorInfo: fmt.Sprintf("Failed to check unreproducible fields; err = %v", err),
+		}, nil
 	}
 
 	canFast, uniqueID, offset, err := wrapper.PlaceNBTBlock(
-		request.BlockName,
-		utils.ParseBlockStatesString(request.BlockStatesString),
+		blockName,
+		blockStates,
 		blockNBT,
 	)
 	if err != nil {
-		c.JSON(http.StatusOK, define.PlaceNBTBlockResponse{
-			Success:   false,
-			ErrorType: define.ResponseErrorTypeRuntimeError,
-			ErrorInfo: fmt.Sprintf("Runtime error: Failed to place NBT block; err = %v", err),
-		})
-		sendLogRecord(
-			define.SourceDefault,
-			userName,
-			gameInterface.GetBotInfo().BotName,
-			define.SystemNamePlaceNBTBlock,
-			request,
-			fmt.Sprintf("%v", err),
-		)
-		return
+		var differences []string
+		var incompleteErr *nbt_assigner_interface.IncompleteBlockError
+		if errors.As(err, &incompleteErr) {
+			differences = nbt_diff.Strings(incompleteErr.Differences)
+		}
+		response := define.PlaceNBTBlockResponse{
+			Success:              false,
+			ErrorType:            define.ResponseErrorTypeRuntimeError,
+			ErrorInfo:            fmt.Sprintf("Runtime error: Failed to place NBT block; err = %v", err),
+			UnreproducibleFields: unreproducibleFields,
+			Differences:          differences,
+		}
+		if uniqueID != uuid.Nil {
+			response.StructureUniqueID = uniqueID.String()
+			response.StructureName = utils.MakeUUIDSafeString(uniqueID)
+		}
+		return response, err
 	}
 
-	c.JSON(http.StatusOK, define.PlaceNBTBlockResponse{
-		Success:           true,
-		CanFast:           canFast,
-		StructureUniqueID: uniqueID.String(),
-		StructureName:     utils.MakeUUIDSafeString(uniqueID),
-		OffsetX:           offset.X(),
-		OffsetY:           offset.Y(),
-		OffsetZ:           offset.Z(),
-	})
+	return define.PlaceNBTBlockResponse{
+		Success:              true,
+		CanFast:              canFast,
+		StructureUniqueID:    uniqueID.String(),
+		StructureName:        utils.MakeUUIDSafeString(uniqueID),
+		OffsetX:              offset.X(),
+		OffsetY:              offset.Y(),
+		OffsetZ:              offset.Z(),
+		UnreproducibleFields: unreproducibleFields,
+
+		CommandTranslationWarnings: translationWarnings,
+		CommandTranslationError:    translationError,
+
+		LegacyExecuteChanges: legacyChanges,
+		LegacyExecuteError:   legacyError,
+
+		BlockUpgrades:    blockUpgrades,
+		BlockDefaults:    blockDefaults,
+		BlockCorrections: blockCorrections,
+	}, nil
 }
 
 func PlaceLargeChest(c *gin.Context) {
@@ -405,3 +551,102 @@ func GetNBTBlockHash(c *gin.Context) {
 		Hash:    hash,
 	})
 }
+
+func AnalyzeObtainability(c *gin.Context) {
+	var request define.AnalyzeObtainabilityRequest
+	var blocks []nbt_analyzer.Block
+	var report nbt_analyzer.Report
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusOK, define.AnalyzeObtainabilityResponse{
+			Success:   false,
+			ErrorInfo: fmt.Sprintf("Failed to parse request; err = %v", err),
+		})
+		return
+	}
+
+	for index, value := range request.Blocks {
+		var blockNBT map[string]any
+		if len(value.BlockNBTBase64String) > 0 {
+			blockNBTBytes, err := base64.StdEncoding.DecodeString(value.BlockNBTBase64String)
+			if err != nil {
+				c.JSON(http.StatusOK, define.AnalyzeObtainabilityResponse{
+					Success:   false,
+					ErrorInfo: fmt.Sprintf("Failed to parse block NBT base64 string of block %d; err = %v", index, err),
+				})
+				return
+			}
+			err = nbt.UnmarshalEncoding(blockNBTBytes, &blockNBT, nbt.LittleEndian)
+			if err != nil {
+				c.JSON(http.StatusOK, define.AnalyzeObtainabilityResponse{
+					Success:   false,
+					ErrorInfo: fmt.Sprintf("Block NBT bytes of block %d is broken; err = %v", index, err),
+				})
+				return
+			}
+		}
+		blocks = append(blocks, nbt_analyzer.Block{
+			Position: protocol.BlockPos{value.X, value.Y, value.Z},
+			Name:     value.BlockName,
+			States:   utils.ParseBlockStatesString(value.BlockStatesString),
+			NBT:      blockNBT,
+		})
+	}
+
+	if len(request.StructureBase64String) > 0 {
+		structureBytes, err := base64.StdEncoding.DecodeString(request.StructureBase64String)
+		if err != nil {
+			c.JSON(http.StatusOK, define.AnalyzeObtainabilityResponse{
+				Success:   false,
+				ErrorInfo: fmt.Sprintf("Failed to parse structure base64 string; err = %v", err),
+			})
+			return
+		}
+		structure, err := mcstructure.Decode(structureBytes)
+		if err != nil {
+			c.JSON(http.StatusOK, define.AnalyzeObtainabilityResponse{
+				Success:   false,
+				ErrorInfo: fmt.Sprintf("Structure is broken; err = %v", err),
+			})
+			sendLogRecord(
+				define.SourceDefault,
+				userName,
+				gameInterface.GetBotInfo().BotName,
+				define.SystemNameAnalyzeObtainability,
+				// 结构文件可能很大，因此只记录请求的摘要
+				struct {
+					StructureBytes int `json:"structure_bytes"`
+					BlockCount     int `json:"block_count"`
+				}{len(structureBytes), len(request.Blocks)},
+				fmt.Sprintf("%v", err),
+			)
+			return
+		}
+		blocks = append(blocks, nbt_analyzer.StructureBlocks(structure)...)
+	}
+	report = wrapper.Analyzer().Analyze(blocks...)
+
+	entries := make([]define.AnalyzeEntry, 0, len(report.Entries))
+	for _, entry := range report.Entries {
+		entries = append(entries, define.AnalyzeEntry{
+			X:      entry.Position[0],
+			Y:      entry.Position[1],
+			Z:      entry.Position[2],
+			IsItem: entry.IsItem,
+			Path:   entry.Path,
+			Name:   entry.Name,
+			Kind:   entry.Kind,
+			Makers: entry.Makers,
+			Reason: entry.Reason,
+		})
+	}
+
+	c.JSON(http.StatusOK, define.AnalyzeObtainabilityResponse{
+		Success:         true,
+		Entries:         entries,
+		BlockStatistics: report.BlockStatistics[:],
+		ItemStatistics:  report.ItemStatistics[:],
+		Operations:      report.Operations,
+	})
+}
@@ -17,6 +17,7 @@ func initRouter() *gin.Engine {
 	router.POST("/place_nbt_block", PlaceNBTBlock)
 	router.POST("/place_large_chest", PlaceLargeChest)
 	router.POST("/get_nbt_block_hash", GetNBTBlockHash)
+	router.POST("/analyze_obtainability", AnalyzeObtainability)
 
 	router.NoRoute(func(c *gin.Context) {
 		c.AbortWithStatus(http.StatusNotFound)
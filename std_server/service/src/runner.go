@@ -43,6 +43,7 @@ func RunServer(
 	consoleCenterX int,
 	consoleCenterY int,
 	consoleCenterZ int,
+	chatCommandWhitelist string,
 ) {
 	var err error
 	cfg := client.Config{
@@ -87,12 +88,20 @@ func RunServer(
 			int32(consoleCenterZ),
 		},
 	)
-	if err != nil {
+	if entitiesErr, ok := err.(*nbt_console.EntitiesError); ok {
+		pterm.Warning.Printfln("操作台区域中有 %d 个实体 (例如位于 %v 的 %s)，它们可能会妨碍导入",
+			len(entitiesErr.Entities), entitiesErr.Entities[0].Position, entitiesErr.Entities[0].EntityType)
+	} else if err != nil {
 		panic(err)
 	}
 	cache = nbt_cache.NewNBTCacheSystem(console)
 	wrapper = nbt_assigner.NewNBTAssigner(console, cache)
 
+	err = registerChatCommands(chatCommandWhitelist)
+	if err != nil {
+		panic(err)
+	}
+
 	runHttpServer(standardServerPort)
 }
 
@@ -1,17 +1,12 @@
 package main
 
 import (
-	"bytes"
 	"math/rand"
-	"os"
 
-	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
 	"github.com/mcpol-studio/flowers-for-machines/mapping"
 )
 
 func GenerateRandomBanner() {
-	var m map[string]any
-
 	itemList := make([]any, 0)
 	for index := range 27 {
 		base := int32(rand.Intn(16))
@@ -47,22 +42,5 @@ func GenerateRandomBanner() {
 		})
 	}
 
-	bs, err := os.ReadFile("mcstructure/ori.mcstructure")
-	if err != nil {
-		panic(err)
-	}
-
-	buf := bytes.NewBuffer(bs)
-	err = nbt.NewDecoderWithEncoding(buf, nbt.LittleEndian).Decode(&m)
-	if err != nil {
-		panic(err)
-	}
-
-	m1 := m["structure"].(map[string]any)["palette"].(map[string]any)["default"].(map[string]any)
-	m1 = m1["block_position_data"].(map[string]any)["0"].(map[string]any)["block_entity_data"].(map[string]any)
-	m1["Items"] = itemList
-
-	buf = bytes.NewBuffer(nil)
-	nbt.NewEncoderWithEncoding(buf, nbt.LittleEndian).Encode(m)
-	os.WriteFile("mcstructure/banner.mcstructure", buf.Bytes(), 0600)
+	setOriginItems("banner.mcstructure", itemList)
 }
@@ -1,17 +1,12 @@
 package main
 
 import (
-	"bytes"
 	"math/rand"
-	"os"
 
-	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
 	"github.com/mcpol-studio/flowers-for-machines/mapping"
 )
 
 func GenerateRandomShield() {
-	var m map[string]any
-
 	itemList := make([]any, 0)
 	for index := range 27 {
 		m2 := map[string]any{
@@ -94,22 +89,5 @@ func GenerateRandomShield() {
 		})
 	}
 
-	bs, err := os.ReadFile("mcstructure/ori.mcstructure")
-	if err != nil {
-		panic(err)
-	}
-
-	buf := bytes.NewBuffer(bs)
-	err = nbt.NewDecoderWithEncoding(buf, nbt.LittleEndian).Decode(&m)
-	if err != nil {
-		panic(err)
-	}
-
-	m1 := m["structure"].(map[string]any)["palette"].(map[string]any)["default"].(map[string]any)
-	m1 = m1["block_position_data"].(map[string]any)["0"].(map[string]any)["block_entity_data"].(map[string]any)
-	m1["Items"] = itemList
-
-	buf = bytes.NewBuffer(nil)
-	nbt.NewEncoderWithEncoding(buf, nbt.LittleEndian).Encode(m)
-	os.WriteFile("mcstructure/shield.mcstructure", buf.Bytes(), 0600)
+	setOriginItems("shield.mcstructure", itemList)
 }
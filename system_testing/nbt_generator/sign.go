@@ -1,19 +1,14 @@
 package main
 
 import (
-	"bytes"
 	"math/rand"
-	"os"
 
-	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/google/uuid"
 	"github.com/mcpol-studio/flowers-for-machines/mapping"
 	"github.com/mcpol-studio/flowers-for-machines/utils"
-	"github.com/google/uuid"
 )
 
 func GenerateRandomSign() {
-	var m map[string]any
-
 	itemList := make([]any, 0)
 	for index := range 20 {
 		m2 := map[string]any{
@@ -131,22 +126,5 @@ func GenerateRandomSign() {
 		})
 	}
 
-	bs, err := os.ReadFile("mcstructure/ori.mcstructure")
-	if err != nil {
-		panic(err)
-	}
-
-	buf := bytes.NewBuffer(bs)
-	err = nbt.NewDecoderWithEncoding(buf, nbt.LittleEndian).Decode(&m)
-	if err != nil {
-		panic(err)
-	}
-
-	m1 := m["structure"].(map[string]any)["palette"].(map[string]any)["default"].(map[string]any)
-	m1 = m1["block_position_data"].(map[string]any)["0"].(map[string]any)["block_entity_data"].(map[string]any)
-	m1["Items"] = itemList
-
-	buf = bytes.NewBuffer(nil)
-	nbt.NewEncoderWithEncoding(buf, nbt.LittleEndian).Encode(m)
-	os.WriteFile("mcstructure/sign.mcstructure", buf.Bytes(), 0600)
+	setOriginItems("sign.mcstructure", itemList)
 }
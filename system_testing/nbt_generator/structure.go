@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
+)
+
+// modifyStructure 读取 mcstructure 目录下名为 src 的结构文件，
+// 使用 modify 修改它，然后将结果写入同一目录下名为 dst 的结构文件
+func modifyStructure(src string, dst string, modify func(structure *mcstructure.Structure)) {
+	bs, err := os.ReadFile("mcstructure/" + src)
+	if err != nil {
+		panic(err)
+	}
+
+	structure, err := mcstructure.Decode(bs)
+	if err != nil {
+		panic(err)
+	}
+	modify(structure)
+
+	bs, err = structure.Encode()
+	if err != nil {
+		panic(err)
+	}
+	err = os.WriteFile("mcstructure/"+dst, bs, 0600)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// setOriginItems 将 ori.mcstructure 原点处容器的物品设置为 itemList，
+// 然后将结果写入 mcstructure 目录下名为 dst 的结构文件
+func setOriginItems(dst string, itemList []any) {
+	modifyStructure("ori.mcstructure", dst, func(structure *mcstructure.Structure) {
+		blockNBT, found := structure.BlockEntity(protocol.BlockPos{0, 0, 0})
+		if !found {
+			panic("setOriginItems: The origin of ori.mcstructure has no block entity")
+		}
+		blockNBT["Items"] = itemList
+	})
+}
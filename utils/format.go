@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/mcpol-studio/flowers-for-machines/mapping"
 )
@@ -35,3 +36,20 @@ func FormatEnch(id int16, level int16) string {
 
 	return mapping.EnchantFormat[id] + " " + levelString
 }
+
+// StripFormatting 移除 input 中所有以 § 开头的格式化代码
+func StripFormatting(input string) string {
+	var result strings.Builder
+	skip := false
+	for _, char := range input {
+		switch {
+		case skip:
+			skip = false
+		case char == '§':
+			skip = true
+		default:
+			result.WriteRune(char)
+		}
+	}
+	return result.String()
+}
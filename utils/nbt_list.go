@@ -0,0 +1,19 @@
+package utils
+
+import "reflect"
+
+// NBTListToSlice 将 NBT 列表或数组 value 转换为 []any。
+// 解码得到的 NBT 列表可能是具体类型的切片 (例如 []int32 或
+// []map[string]any)，而 NBT 数组则是定长数组 (例如 [4]byte)。
+// 如果 value 不是列表或数组，则返回 nil
+func NBTListToSlice(value any) []any {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil
+	}
+	result := make([]any, rv.Len())
+	for index := range result {
+		result[index] = rv.Index(index).Interface()
+	}
+	return result
+}
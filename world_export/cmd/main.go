@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/world_export"
+)
+
+var (
+	worldDir     *string
+	dimensionID  *int
+	startX       *int
+	startY       *int
+	startZ       *int
+	endX         *int
+	endY         *int
+	endZ         *int
+	outputPath   *string
+	outputFormat *string
+	includeAir   *bool
+)
+
+func init() {
+	worldDir = flag.String("world", "", "The directory of the bedrock world (which contains level.dat and db).")
+	dimensionID = flag.Int("dim", 0, "The dimension ID to export. (e.g. overworld = 0, nether = 1, end = 2)")
+	startX = flag.Int("sx", 0, "The X position of the start of the region.")
+	startY = flag.Int("sy", 0, "The Y position of the start of the region.")
+	startZ = flag.Int("sz", 0, "The Z position of the start of the region.")
+	endX = flag.Int("ex", 0, "The X position of the end of the region.")
+	endY = flag.Int("ey", 0, "The Y position of the end of the region.")
+	endZ = flag.Int("ez", 0, "The Z position of the end of the region.")
+	outputPath = flag.String("o", "", "The output file path. If empty, the result will be written to stdout.")
+	outputFormat = flag.String("format", "mcstructure", "The output format. (mcstructure, or records which are JSON lines in the form of the PlaceNBTBlock request)")
+	includeAir = flag.Bool("air", false, "Whether to include air blocks in records output.")
+
+	flag.Parse()
+	if len(*worldDir) == 0 {
+		log.Fatalln("Please provide the world directory by -world")
+	}
+}
+
+func main() {
+	world, err := world_export.Open(*worldDir)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer world.Close()
+
+	output := os.Stdout
+	if len(*outputPath) > 0 {
+		output, err = os.Create(*outputPath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer output.Close()
+	}
+
+	start := protocol.BlockPos{int32(*startX), int32(*startY), int32(*startZ)}
+	end := protocol.BlockPos{int32(*endX), int32(*endY), int32(*endZ)}
+
+	switch *outputFormat {
+	case "mcstructure":
+		structure, err := world.ExportStructure(int32(*dimensionID), start, end)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		structureBytes, err := structure.Encode()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if _, err = output.Write(structureBytes); err != nil {
+			log.Fatalln(err)
+		}
+	case "records":
+		writer := bufio.NewWriter(output)
+		encoder := json.NewEncoder(writer)
+		err = world.Blocks(int32(*dimensionID), start, end, *includeAir, func(block world_export.Block) error {
+			record, err := block.Record()
+			if err != nil {
+				return err
+			}
+			return encoder.Encode(record)
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if err = writer.Flush(); err != nil {
+			log.Fatalln(err)
+		}
+	default:
+		log.Fatalf("Unknown output format %#v\n", *outputFormat)
+	}
+}
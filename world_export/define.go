@@ -0,0 +1,59 @@
+package world_export
+
+// 维度 ID
+const (
+	DimensionOverworld int32 = iota
+	DimensionNether
+	DimensionEnd
+)
+
+// SubChunkSize 是子区块在每个轴上的尺寸
+const SubChunkSize = 16
+
+// subChunkVolume 是一个子区块中方块的数量
+const subChunkVolume = SubChunkSize * SubChunkSize * SubChunkSize
+
+// AirBlockName 是空气方块的名称
+const AirBlockName = "minecraft:air"
+
+// Block 是从存档中导出的一个方块。
+// 它的字段与 NBTAssigner.PlaceNBTBlock 的参数一一对应
+type Block struct {
+	// Pos 是方块在世界中的坐标
+	Pos [3]int32
+	// Name 和 States 是方块的名称和方块状态
+	Name   string
+	States map[string]any
+	// NBT 是方块的方块实体数据，它可能为空
+	NBT map[string]any
+}
+
+// Record 是方块以 JSON 形式导出时的记录。
+// 它的字段与 PlaceNBTBlock 请求的同名字段一致，
+// 因此导出的记录可以被直接用于导入
+type Record struct {
+	X                    int32  `json:"x"`
+	Y                    int32  `json:"y"`
+	Z                    int32  `json:"z"`
+	BlockName            string `json:"block_name"`
+	BlockStatesString    string `json:"block_states_string"`
+	BlockNBTBase64String string `json:"block_nbt_base64_string,omitempty"`
+}
+
+// blockState 是子区块调色板中的一个方块
+type blockState struct {
+	Name    string
+	States  map[string]any
+	Version int32
+}
+
+// palettedStorage 是子区块中的一个方块层
+type palettedStorage struct {
+	palette []blockState
+	indices [subChunkVolume]uint16
+}
+
+// at 返回子区块内 (x, y, z) 处的方块
+func (p *palettedStorage) at(x int32, y int32, z int32) blockState {
+	return p.palette[p.indices[(x<<8)|(z<<4)|y]]
+}
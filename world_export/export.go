@@ -0,0 +1,190 @@
+package world_export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
+	"github.com/mcpol-studio/flowers-for-machines/utils"
+)
+
+// airBlock 是不存在的子区块中的方块
+var airBlock = blockState{
+	Name:    AirBlockName,
+	States:  map[string]any{},
+	Version: mcstructure.DefaultBlockVersion,
+}
+
+// floorDiv 返回 a 除以 b 向下取整的结果
+func floorDiv(a int32, b int32) int32 {
+	result := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		result--
+	}
+	return result
+}
+
+// regionBounds 返回由 start 和 end 围成的区域的最小坐标与最大坐标
+func regionBounds(start protocol.BlockPos, end protocol.BlockPos) (minPos protocol.BlockPos, maxPos protocol.BlockPos) {
+	for index := range 3 {
+		minPos[index] = min(start[index], end[index])
+		maxPos[index] = max(start[index], end[index])
+	}
+	return
+}
+
+// chunkVisitor 在遍历区域时被调用。
+// pos 是方块在世界中的坐标，storages 是该方块所在子区块的方块层，
+// 它可能为空；blockNBT 是该方块的方块实体数据，它也可能为空
+type chunkVisitor func(pos protocol.BlockPos, storages []*palettedStorage, blockNBT map[string]any) error
+
+// walk 逐区块遍历维度 dimension 中由 start 和 end 围成的区域，
+// 并对区域内的每个位置调用 visitor
+func (w *World) walk(dimension int32, start protocol.BlockPos, end protocol.BlockPos, visitor chunkVisitor) error {
+	minPos, maxPos := regionBounds(start, end)
+
+	for chunkX := floorDiv(minPos[0], SubChunkSize); chunkX <= floorDiv(maxPos[0], SubChunkSize); chunkX++ {
+		for chunkZ := floorDiv(minPos[2], SubChunkSize); chunkZ <= floorDiv(maxPos[2], SubChunkSize); chunkZ++ {
+			blockEntities, err := w.blockEntities(dimension, chunkX, chunkZ)
+			if err != nil {
+				return fmt.Errorf("walk: %v", err)
+			}
+
+			for subChunkY := floorDiv(minPos[1], SubChunkSize); subChunkY <= floorDiv(maxPos[1], SubChunkSize); subChunkY++ {
+				storages, err := w.subChunk(dimension, chunkX, chunkZ, int8(subChunkY))
+				if err != nil {
+					return fmt.Errorf("walk: Failed to read sub chunk (%d, %d, %d); err = %v", chunkX, subChunkY, chunkZ, err)
+				}
+
+				for x := max(minPos[0], chunkX*SubChunkSize); x <= min(maxPos[0], chunkX*SubChunkSize+SubChunkSize-1); x++ {
+					for y := max(minPos[1], subChunkY*SubChunkSize); y <= min(maxPos[1], subChunkY*SubChunkSize+SubChunkSize-1); y++ {
+						for z := max(minPos[2], chunkZ*SubChunkSize); z <= min(maxPos[2], chunkZ*SubChunkSize+SubChunkSize-1); z++ {
+							pos := protocol.BlockPos{x, y, z}
+							if err = visitor(pos, storages, blockEntities[pos]); err != nil {
+								return err
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// blockAt 返回子区块 storages 中第 layer 层
+// 位于世界坐标 pos 处的方块
+func blockAt(storages []*palettedStorage, layer int, pos protocol.BlockPos) blockState {
+	if layer >= len(storages) {
+		return airBlock
+	}
+	return storages[layer].at(
+		pos[0]-floorDiv(pos[0], SubChunkSize)*SubChunkSize,
+		pos[1]-floorDiv(pos[1], SubChunkSize)*SubChunkSize,
+		pos[2]-floorDiv(pos[2], SubChunkSize)*SubChunkSize,
+	)
+}
+
+// Record 将方块 b 转换为以 JSON 形式导出时的记录。
+// 方块实体数据将被编码为经过 Base64 编码的小端序 NBT，
+// 因此其中各个标签的类型都将被保留
+func (b Block) Record() (result Record, err error) {
+	result = Record{
+		X:                 b.Pos[0],
+		Y:                 b.Pos[1],
+		Z:                 b.Pos[2],
+		BlockName:         b.Name,
+		BlockStatesString: utils.MarshalBlockStates(b.States),
+	}
+	if b.NBT == nil {
+		return result, nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err = nbt.NewEncoderWithEncoding(buf, nbt.LittleEndian).Encode(b.NBT)
+	if err != nil {
+		return Record{}, fmt.Errorf("Record: %v", err)
+	}
+	result.BlockNBTBase64String = base64.StdEncoding.EncodeToString(buf.Bytes())
+	return result, nil
+}
+
+// Blocks 遍历维度 dimension 中由 start 和 end 围成的区域内的所有方块，
+// 并对每个方块调用 fn。方块按区块依次遍历，而非严格按照坐标顺序。
+//
+// 如果 includeAir 为假，则空气方块将被跳过。
+// 含水方块的水所在的方块层不会被导出。
+// 如果 fn 返回了非空错误，则遍历将立即终止并返回该错误
+func (w *World) Blocks(
+	dimension int32,
+	start protocol.BlockPos,
+	end protocol.BlockPos,
+	includeAir bool,
+	fn func(block Block) error,
+) error {
+	err := w.walk(dimension, start, end, func(pos protocol.BlockPos, storages []*palettedStorage, blockNBT map[string]any) error {
+		block := blockAt(storages, mcstructure.LayerNormal, pos)
+		if !includeAir && block.Name == AirBlockName {
+			return nil
+		}
+		return fn(Block{
+			Pos:    pos,
+			Name:   block.Name,
+			States: block.States,
+			NBT:    blockNBT,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("Blocks: %v", err)
+	}
+	return nil
+}
+
+// ExportStructure 将维度 dimension 中由 start 和 end 围成的区域导出为结构。
+// 结构的原点为区域的最小坐标，并且区域内的空气方块也将被导出
+func (w *World) ExportStructure(
+	dimension int32,
+	start protocol.BlockPos,
+	end protocol.BlockPos,
+) (*mcstructure.Structure, error) {
+	minPos, maxPos := regionBounds(start, end)
+	result := mcstructure.NewStructure([3]int32{
+		maxPos[0] - minPos[0] + 1,
+		maxPos[1] - minPos[1] + 1,
+		maxPos[2] - minPos[2] + 1,
+	})
+	result.Origin = minPos
+
+	err := w.walk(dimension, start, end, func(pos protocol.BlockPos, storages []*palettedStorage, blockNBT map[string]any) error {
+		relative := protocol.BlockPos{pos[0] - minPos[0], pos[1] - minPos[1], pos[2] - minPos[2]}
+
+		for layer := range mcstructure.LayerCount {
+			block := blockAt(storages, layer, pos)
+			if layer != mcstructure.LayerNormal && block.Name == AirBlockName {
+				continue
+			}
+			err := result.SetBlock(relative, layer, mcstructure.BlockPalette{
+				Name:    block.Name,
+				States:  block.States,
+				Version: block.Version,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if blockNBT != nil {
+			return result.SetBlockEntity(relative, blockNBT)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ExportStructure: %v", err)
+	}
+
+	return result, nil
+}
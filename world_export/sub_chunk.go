@@ -0,0 +1,120 @@
+package world_export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+)
+
+// decodeSubChunk 解码存档数据库中的一个子区块 data，
+// 并返回其包含的所有方块层。
+// 只支持 1.2.13 及以后的子区块格式 (版本 1, 8 和 9)。
+//
+// bedrock-world-operator 的 chunk 和 world 包依赖于
+// github.com/Happy2018new/worldupgrader，而该模块无法
+// 从模块代理获取，因此此处没有使用 chunk.DecodeSubChunk
+func decodeSubChunk(data []byte) (storages []*palettedStorage, err error) {
+	buf := bytes.NewBuffer(data)
+
+	version, err := buf.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("decodeSubChunk: %v", err)
+	}
+
+	storageCount := byte(1)
+	switch version {
+	case 1:
+	case 8, 9:
+		storageCount, err = buf.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("decodeSubChunk: %v", err)
+		}
+		if version == 9 {
+			// 子区块的 Y 轴索引，它同样存在于数据库的键中
+			if _, err = buf.ReadByte(); err != nil {
+				return nil, fmt.Errorf("decodeSubChunk: %v", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("decodeSubChunk: Unsupported sub chunk version %d", version)
+	}
+
+	for range storageCount {
+		storage, err := decodePalettedStorage(buf)
+		if err != nil {
+			return nil, fmt.Errorf("decodeSubChunk: %v", err)
+		}
+		storages = append(storages, storage)
+	}
+	return storages, nil
+}
+
+// decodePalettedStorage 从 buf 解码一个方块层
+func decodePalettedStorage(buf *bytes.Buffer) (storage *palettedStorage, err error) {
+	header, err := buf.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("decodePalettedStorage: %v", err)
+	}
+	if header&1 == 1 {
+		return nil, fmt.Errorf("decodePalettedStorage: Runtime palette is not expected in world storage")
+	}
+
+	bitsPerBlock := int(header >> 1)
+	switch bitsPerBlock {
+	case 0, 1, 2, 3, 4, 5, 6, 8, 16:
+	default:
+		return nil, fmt.Errorf("decodePalettedStorage: Invalid bits per block %d", bitsPerBlock)
+	}
+
+	storage = new(palettedStorage)
+	paletteCount := uint32(1)
+
+	if bitsPerBlock != 0 {
+		blocksPerWord := 32 / bitsPerBlock
+		wordCount := (subChunkVolume + blocksPerWord - 1) / blocksPerWord
+		words := make([]uint32, wordCount)
+		if err = binary.Read(buf, binary.LittleEndian, words); err != nil {
+			return nil, fmt.Errorf("decodePalettedStorage: %v", err)
+		}
+
+		mask := uint32(1)<<bitsPerBlock - 1
+		for index := range subChunkVolume {
+			word := words[index/blocksPerWord]
+			shift := (index % blocksPerWord) * bitsPerBlock
+			storage.indices[index] = uint16((word >> shift) & mask)
+		}
+
+		if err = binary.Read(buf, binary.LittleEndian, &paletteCount); err != nil {
+			return nil, fmt.Errorf("decodePalettedStorage: %v", err)
+		}
+	}
+
+	decoder := nbt.NewDecoderWithEncoding(buf, nbt.LittleEndian)
+	storage.palette = make([]blockState, paletteCount)
+	for index := range storage.palette {
+		var blockMap map[string]any
+		if err = decoder.Decode(&blockMap); err != nil {
+			return nil, fmt.Errorf("decodePalettedStorage: %v", err)
+		}
+		name, _ := blockMap["name"].(string)
+		states, _ := blockMap["states"].(map[string]any)
+		version, _ := blockMap["version"].(int32)
+		if states == nil {
+			states = make(map[string]any)
+		}
+		storage.palette[index] = blockState{
+			Name:    name,
+			States:  states,
+			Version: version,
+		}
+	}
+
+	for _, value := range storage.indices {
+		if int(value) >= len(storage.palette) {
+			return nil, fmt.Errorf("decodePalettedStorage: Palette index %d out of range (palette size = %d)", value, len(storage.palette))
+		}
+	}
+	return storage, nil
+}
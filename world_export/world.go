@@ -0,0 +1,102 @@
+package world_export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+
+	"github.com/TriM-Organization/bedrock-world-operator/define"
+	world_define "github.com/TriM-Organization/bedrock-world-operator/world/define"
+	"github.com/df-mc/goleveldb/leveldb"
+	"github.com/df-mc/goleveldb/leveldb/opt"
+)
+
+// World 是以只读方式打开的国际版存档
+type World struct {
+	db *leveldb.DB
+}
+
+// Open 以只读方式打开位于 dir 的国际版存档。
+// dir 是存档的根目录，即包含 level.dat 和 db 目录的目录
+func Open(dir string) (*World, error) {
+	db, err := leveldb.OpenFile(filepath.Join(dir, "db"), &opt.Options{
+		Compression:    opt.FlateCompression,
+		ReadOnly:       true,
+		ErrorIfMissing: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Open: %v", err)
+	}
+	return &World{db: db}, nil
+}
+
+// Close 关闭存档
+func (w *World) Close() error {
+	err := w.db.Close()
+	if err != nil {
+		return fmt.Errorf("Close: %v", err)
+	}
+	return nil
+}
+
+// chunkKey 返回维度 dimension 中位于 (chunkX, chunkZ)
+// 的区块的数据库键，其标签为 tag
+func chunkKey(dimension int32, chunkX int32, chunkZ int32, tag ...byte) []byte {
+	return world_define.Sum(define.Dimension(dimension), define.ChunkPos{chunkX, chunkZ}, tag...)
+}
+
+// get 从数据库读取键 key 的值。
+// 如果键不存在，则 found 为假
+func (w *World) get(key []byte) (value []byte, found bool, err error) {
+	value, err = w.db.Get(key, nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// subChunk 读取维度 dimension 中位于 (chunkX, chunkZ) 的区块的
+// 第 subChunkY 个子区块。如果子区块不存在，则 storages 为空
+func (w *World) subChunk(dimension int32, chunkX int32, chunkZ int32, subChunkY int8) (storages []*palettedStorage, err error) {
+	key := chunkKey(dimension, chunkX, chunkZ, world_define.KeySubChunkData, byte(subChunkY))
+	data, found, err := w.get(key)
+	if err != nil || !found {
+		return nil, err
+	}
+	return decodeSubChunk(data)
+}
+
+// blockEntities 读取维度 dimension 中位于 (chunkX, chunkZ) 的区块的所有方块实体，
+// 返回的映射的键是方块实体在世界中的坐标
+func (w *World) blockEntities(dimension int32, chunkX int32, chunkZ int32) (result map[[3]int32]map[string]any, err error) {
+	result = make(map[[3]int32]map[string]any)
+
+	data, found, err := w.get(chunkKey(dimension, chunkX, chunkZ, world_define.KeyBlockEntities))
+	if err != nil || !found {
+		return result, err
+	}
+
+	buf := bytes.NewBuffer(data)
+	decoder := nbt.NewDecoderWithEncoding(buf, nbt.LittleEndian)
+	for buf.Len() > 0 {
+		var blockNBT map[string]any
+		if err = decoder.Decode(&blockNBT); err != nil {
+			return nil, fmt.Errorf("blockEntities: %v", err)
+		}
+		x, ok1 := blockNBT["x"].(int32)
+		y, ok2 := blockNBT["y"].(int32)
+		z, ok3 := blockNBT["z"].(int32)
+		if !ok1 || !ok2 || !ok3 {
+			continue
+		}
+		result[[3]int32{x, y, z}] = blockNBT
+	}
+
+	return result, nil
+}
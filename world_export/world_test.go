@@ -0,0 +1,203 @@
+package world_export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/nbt"
+	"github.com/mcpol-studio/flowers-for-machines/core/minecraft/protocol"
+	"github.com/mcpol-studio/flowers-for-machines/mcstructure"
+
+	world_define "github.com/TriM-Organization/bedrock-world-operator/world/define"
+	"github.com/df-mc/goleveldb/leveldb"
+)
+
+// chestNBT 是测试存档中箱子的方块实体数据。
+// 它含有多种类型的标签，用于检查导出时标签的类型是否被保留
+var chestNBT = map[string]any{
+	"id":       "Chest",
+	"x":        int32(1),
+	"y":        int32(2),
+	"z":        int32(-3),
+	"Findable": byte(0),
+	"Items": []any{
+		map[string]any{
+			"Name":   "minecraft:apple",
+			"Count":  byte(3),
+			"Damage": int16(0),
+			"Slot":   byte(5),
+		},
+	},
+}
+
+// encodeSubChunk 将只有一个方块层的子区块编码为存档中的格式 (版本 8)。
+// blocks 是子区块中除空气以外的方块，其键是方块在子区块中的索引
+func encodeSubChunk(t *testing.T, blocks map[int]blockState) []byte {
+	palette := []blockState{airBlock}
+	indices := make([]uint32, subChunkVolume)
+	for index, block := range blocks {
+		indices[index] = uint32(len(palette))
+		palette = append(palette, block)
+	}
+
+	buf := bytes.NewBuffer([]byte{8, 1, 4 << 1})
+	words := make([]uint32, subChunkVolume/8)
+	for index, value := range indices {
+		words[index/8] |= value << ((index % 8) * 4)
+	}
+	_ = binary.Write(buf, binary.LittleEndian, words)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(palette)))
+
+	encoder := nbt.NewEncoderWithEncoding(buf, nbt.LittleEndian)
+	for _, block := range palette {
+		err := encoder.Encode(map[string]any{
+			"name":    block.Name,
+			"states":  block.States,
+			"version": block.Version,
+		})
+		if err != nil {
+			t.Fatalf("encodeSubChunk: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// newTestWorld 创建一个测试存档并返回其根目录。
+// 主世界的 (1, 2, -3) 处是一个含有方块实体的箱子，
+// (0, 0, 0) 处是石头；下界的 (0, 0, 0) 处是下界岩
+func newTestWorld(t *testing.T) string {
+	dir := t.TempDir()
+	db, err := leveldb.OpenFile(filepath.Join(dir, "db"), nil)
+	if err != nil {
+		t.Fatalf("newTestWorld: %v", err)
+	}
+	defer db.Close()
+
+	chest := blockState{
+		Name:    "minecraft:chest",
+		States:  map[string]any{"minecraft:cardinal_direction": "east"},
+		Version: mcstructure.DefaultBlockVersion,
+	}
+	stone := blockState{Name: "minecraft:stone", States: map[string]any{}, Version: mcstructure.DefaultBlockVersion}
+	netherrack := blockState{Name: "minecraft:netherrack", States: map[string]any{}, Version: mcstructure.DefaultBlockVersion}
+
+	// 子区块内的索引为 (x << 8) | (z << 4) | y
+	put := func(key []byte, value []byte) {
+		if err := db.Put(key, value, nil); err != nil {
+			t.Fatalf("newTestWorld: %v", err)
+		}
+	}
+	put(
+		chunkKey(DimensionOverworld, 0, -1, world_define.KeySubChunkData, 0),
+		encodeSubChunk(t, map[int]blockState{(1 << 8) | (13 << 4) | 2: chest}),
+	)
+	put(
+		chunkKey(DimensionOverworld, 0, 0, world_define.KeySubChunkData, 0),
+		encodeSubChunk(t, map[int]blockState{0: stone}),
+	)
+	put(
+		chunkKey(DimensionNether, 0, 0, world_define.KeySubChunkData, 0),
+		encodeSubChunk(t, map[int]blockState{0: netherrack}),
+	)
+
+	buf := bytes.NewBuffer(nil)
+	if err = nbt.NewEncoderWithEncoding(buf, nbt.LittleEndian).Encode(chestNBT); err != nil {
+		t.Fatalf("newTestWorld: %v", err)
+	}
+	put(chunkKey(DimensionOverworld, 0, -1, world_define.KeyBlockEntities), buf.Bytes())
+
+	return dir
+}
+
+func TestBlocksAndRecord(t *testing.T) {
+	world, err := Open(newTestWorld(t))
+	if err != nil {
+		t.Fatalf("TestBlocksAndRecord: %v", err)
+	}
+	defer world.Close()
+
+	var blocks []Block
+	err = world.Blocks(DimensionOverworld, protocol.BlockPos{-2, 0, -4}, protocol.BlockPos{2, 3, 1}, false, func(block Block) error {
+		blocks = append(blocks, block)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("TestBlocksAndRecord: %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("TestBlocksAndRecord: Expected 2 blocks, but got %#v", blocks)
+	}
+
+	var chest Block
+	for _, block := range blocks {
+		if block.Name == "minecraft:chest" {
+			chest = block
+		}
+	}
+	if chest.Pos != [3]int32{1, 2, -3} || chest.NBT == nil {
+		t.Fatalf("TestBlocksAndRecord: Unexpected chest %#v", chest)
+	}
+
+	record, err := chest.Record()
+	if err != nil {
+		t.Fatalf("TestBlocksAndRecord: %v", err)
+	}
+	if record.X != 1 || record.Y != 2 || record.Z != -3 || record.BlockStatesString != `["minecraft:cardinal_direction"="east"]` {
+		t.Fatalf("TestBlocksAndRecord: Unexpected record %#v", record)
+	}
+
+	// 记录中的方块实体数据应当保留每个标签的类型
+	nbtBytes, err := base64.StdEncoding.DecodeString(record.BlockNBTBase64String)
+	if err != nil {
+		t.Fatalf("TestBlocksAndRecord: %v", err)
+	}
+	var blockNBT map[string]any
+	if err = nbt.NewDecoderWithEncoding(bytes.NewBuffer(nbtBytes), nbt.LittleEndian).Decode(&blockNBT); err != nil {
+		t.Fatalf("TestBlocksAndRecord: %v", err)
+	}
+	if !reflect.DeepEqual(blockNBT, chestNBT) {
+		t.Fatalf("TestBlocksAndRecord: Expected %#v, but got %#v", chestNBT, blockNBT)
+	}
+}
+
+func TestExportStructure(t *testing.T) {
+	world, err := Open(newTestWorld(t))
+	if err != nil {
+		t.Fatalf("TestExportStructure: %v", err)
+	}
+	defer world.Close()
+
+	structure, err := world.ExportStructure(DimensionOverworld, protocol.BlockPos{1, 2, 0}, protocol.BlockPos{0, 0, -3})
+	if err != nil {
+		t.Fatalf("TestExportStructure: %v", err)
+	}
+	if structure.Size != [3]int32{2, 3, 4} {
+		t.Fatalf("TestExportStructure: Unexpected size %v", structure.Size)
+	}
+
+	for pos, name := range map[protocol.BlockPos]string{
+		{0, 0, 3}: "minecraft:stone",
+		{1, 2, 0}: "minecraft:chest",
+		{1, 1, 1}: AirBlockName,
+	} {
+		block, found := structure.Block(pos, mcstructure.LayerNormal)
+		if !found || block.Name != name {
+			t.Errorf("TestExportStructure: Expected %s at %v, but got %#v", name, pos, block)
+		}
+	}
+	if _, found := structure.BlockEntity(protocol.BlockPos{1, 2, 0}); !found {
+		t.Errorf("TestExportStructure: Block entity of chest is not exported")
+	}
+
+	structure, err = world.ExportStructure(DimensionNether, protocol.BlockPos{0, 0, 0}, protocol.BlockPos{0, 0, 0})
+	if err != nil {
+		t.Fatalf("TestExportStructure: %v", err)
+	}
+	if block, _ := structure.Block(protocol.BlockPos{}, mcstructure.LayerNormal); block.Name != "minecraft:netherrack" {
+		t.Errorf("TestExportStructure: Unexpected nether block %#v", block)
+	}
+}